@@ -42,7 +42,9 @@ Features:
 	// Add subcommands
 	rootCmd.AddCommand(commands.NewInitCommand())
 	rootCmd.AddCommand(commands.NewDevCommand())
+	rootCmd.AddCommand(commands.NewBuildCommand())
 	rootCmd.AddCommand(commands.NewDeployCommand())
+	rootCmd.AddCommand(commands.NewDeploymentsCommand())
 	rootCmd.AddCommand(commands.NewLoginCommand())
 	rootCmd.AddCommand(commands.NewLinkCommand())
 	rootCmd.AddCommand(commands.NewWhoamiCommand())
@@ -59,10 +61,12 @@ Features:
 	rootCmd.AddCommand(commands.NewUICommand())
 	rootCmd.AddCommand(commands.NewFunctionsCommand())
 	rootCmd.AddCommand(commands.NewMigrateCommand())
+	rootCmd.AddCommand(commands.NewEjectCommand())
 	rootCmd.AddCommand(commands.NewMcpCommand())
 	rootCmd.AddCommand(commands.NewUninstallCommand())
 	rootCmd.AddCommand(commands.NewSkillCommand())
 	rootCmd.AddCommand(commands.NewWorkspaceCommand())
+	rootCmd.AddCommand(commands.NewConfigCommand())
 
 	// No args: show welcome screen instead of default help
 	if len(os.Args) == 1 {
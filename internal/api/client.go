@@ -7,6 +7,7 @@ import (
 	"io"
 	"mime/multipart"
 	"net/http"
+	"net/url"
 	"os"
 	"time"
 )
@@ -191,9 +192,10 @@ type DeployResponse struct {
 		Name string `json:"name"`
 		Slug string `json:"slug"`
 	} `json:"project"`
-	PublicURL string `json:"publicUrl"`
-	Env       string `json:"env"`
-	IsPublic  bool   `json:"isPublic"`
+	PublicURL    string `json:"publicUrl"`
+	Env          string `json:"env"`
+	IsPublic     bool   `json:"isPublic"`
+	DeploymentID string `json:"deploymentId"`
 }
 
 type CommunityFunction struct {
@@ -434,6 +436,104 @@ func ListProjects(apiKey string) ([]ProjectListItem, error) {
 	return out.Projects, nil
 }
 
+// ─── Deployments ──────────────────────────────────────────────────────────────
+
+// Deployment is one entry in a project's server-side deploy history.
+type Deployment struct {
+	ID          string `json:"id"`
+	Env         string `json:"env"`
+	ServiceName string `json:"serviceName"`
+	URL         string `json:"url"`
+	Status      string `json:"status"`
+	Active      bool   `json:"active"`
+	CreatedAt   string `json:"createdAt"`
+}
+
+// deploymentsError turns a non-2xx deployments API response into an error.
+func deploymentsError(op string, status int, body []byte) error {
+	var errBody DeployError
+	_ = json.Unmarshal(body, &errBody)
+	msg := errBody.Error.Message
+	if msg == "" {
+		msg = string(body)
+	}
+	if errBody.Error.Details != "" {
+		msg = fmt.Sprintf("%s - %s", msg, errBody.Error.Details)
+	}
+	return fmt.Errorf("%s failed (%d): %s", op, status, msg)
+}
+
+// ListDeployments returns a project's deployments, newest first. env filters by environment when set.
+func ListDeployments(apiKey, projectID, env string) ([]Deployment, error) {
+	endpoint := fmt.Sprintf("%s/api/v1/cli/projects/%s/deployments", getBaseURL(), url.PathEscape(projectID))
+	if env != "" {
+		endpoint += "?env=" + url.QueryEscape(env)
+	}
+	req, err := http.NewRequest("GET", endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("X-API-Key", apiKey)
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != 200 {
+		return nil, deploymentsError("list deployments", resp.StatusCode, body)
+	}
+
+	var out struct {
+		Deployments []Deployment `json:"deployments"`
+	}
+	if err := json.Unmarshal(body, &out); err != nil {
+		return nil, err
+	}
+	return out.Deployments, nil
+}
+
+// RollbackDeployment re-activates a previous deployment in the env it was deployed to.
+func RollbackDeployment(apiKey, projectID, deploymentID string) (*DeployResponse, error) {
+	endpoint := fmt.Sprintf("%s/api/v1/cli/projects/%s/deployments/%s/rollback", getBaseURL(), url.PathEscape(projectID), url.PathEscape(deploymentID))
+	return postDeployment(apiKey, endpoint, nil, "rollback")
+}
+
+// PromoteDeployment copies serviceName's active deployment in fromEnv (same bundle and bindings) to toEnv.
+func PromoteDeployment(apiKey, projectID, serviceName, fromEnv, toEnv string) (*DeployResponse, error) {
+	endpoint := fmt.Sprintf("%s/api/v1/cli/projects/%s/deployments/promote", getBaseURL(), url.PathEscape(projectID))
+	data, _ := json.Marshal(map[string]string{"serviceName": serviceName, "from": fromEnv, "to": toEnv})
+	return postDeployment(apiKey, endpoint, data, "promote")
+}
+
+func postDeployment(apiKey, endpoint string, data []byte, op string) (*DeployResponse, error) {
+	req, err := http.NewRequest("POST", endpoint, bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("X-API-Key", apiKey)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := httpClientLong.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != 200 && resp.StatusCode != 201 {
+		return nil, deploymentsError(op, resp.StatusCode, body)
+	}
+
+	var out DeployResponse
+	if err := json.Unmarshal(body, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
 // ─── Community Functions ───────────────────────────────────────────────────
 
 func CommunityPush(apiKey string, fn CommunityFunction) (*CommunityPushResponse, error) {
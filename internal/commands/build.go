@@ -0,0 +1,184 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/aerostackdev/cli/internal/deployments"
+	"github.com/aerostackdev/cli/internal/devserver"
+	"github.com/aerostackdev/cli/internal/printer"
+	"github.com/spf13/cobra"
+)
+
+// NewBuildCommand creates the 'aerostack build' command
+func NewBuildCommand() *cobra.Command {
+	var opts devserver.BuildOptions
+	var analyze bool
+	var top int
+
+	cmd := &cobra.Command{
+		Use:   "build",
+		Short: "Bundle the worker with the built-in esbuild pipeline",
+		Long: `Bundle your worker in-process with esbuild — no Node.js or npx needed.
+
+This is the same pipeline 'aerostack deploy', 'aerostack test' and 'aerostack dev'
+use: @shared alias, Node built-in aliases and mocks, and the require() shim banner,
+with or without nodejs_compat. Errors are reported with file and line.
+
+Examples:
+  aerostack build
+  aerostack build --sourcemap
+  aerostack build --analyze
+  aerostack build --entry src/auth.ts --outfile dist/auth.js`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if analyze {
+				opts.Metafile = true
+			}
+			return runBuild(opts, analyze, top)
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.Entry, "entry", "", "Entrypoint to bundle (default: main from aerostack.toml)")
+	cmd.Flags().StringVar(&opts.Outfile, "outfile", "dist/worker.js", "Output file")
+	cmd.Flags().BoolVar(&opts.Minify, "minify", true, "Minify the bundle")
+	cmd.Flags().BoolVar(&opts.Sourcemap, "sourcemap", false, "Write a linked source map next to the bundle")
+	cmd.Flags().BoolVar(&opts.Metafile, "metafile", false, "Write esbuild's metafile to .aerostack/ for size analysis")
+	cmd.Flags().BoolVar(&analyze, "analyze", false, "Build with deploy's options and print the largest modules, duplicate packages and node-mocks aliases that were hit")
+	cmd.Flags().IntVar(&top, "top", 15, "Number of modules to list with --analyze")
+	cmd.Flags().BoolVarP(&opts.Quiet, "quiet", "q", false, "Only print errors (used by wrangler's build step)")
+	return cmd
+}
+
+func runBuild(opts devserver.BuildOptions, analyze bool, top int) error {
+	cfg, err := loadBuildConfig()
+	if err != nil {
+		return err
+	}
+
+	if analyze {
+		// Analyze what deploy would upload, not a differently configured build
+		deployOpts := deployBuildOptions(cfg)
+		if opts.Entry != "" {
+			deployOpts.Entry = opts.Entry
+		}
+		deployOpts.Outfile = opts.Outfile
+		deployOpts.Quiet = opts.Quiet
+		opts = deployOpts
+	}
+
+	res, err := devserver.BuildWorker(cfg, opts)
+	if err != nil {
+		return err
+	}
+	if opts.Quiet {
+		return nil
+	}
+
+	info, err := os.Stat(res.Outfile)
+	if err != nil {
+		return err
+	}
+	printer.Success("Built %s (%s)", res.Outfile, formatKB(info.Size()))
+	if cfg.MaxBundleKB > 0 {
+		// Measure what deploy measures: every uploaded file, not just the entry bundle
+		files, err := uploadFiles(res)
+		if err != nil {
+			return err
+		}
+		size, err := deployments.BundleSize(files)
+		if err != nil {
+			return err
+		}
+		fmt.Println(printer.KeyVal("Budget", fmt.Sprintf("%s of %d KB ([build] max_bundle_kb)", formatKB(size), cfg.MaxBundleKB)))
+		if size > int64(cfg.MaxBundleKB)*1024 {
+			printer.Warn("Over budget: 'aerostack deploy' will refuse this bundle")
+		}
+	}
+	if res.MetafilePath != "" {
+		fmt.Println(printer.KeyVal("Metafile", res.MetafilePath))
+	}
+
+	if analyze {
+		analysis, err := devserver.AnalyzeMetafile(res.Metafile, res.Outfile)
+		if err != nil {
+			return err
+		}
+		printBundleAnalysis(analysis, top)
+	}
+	return nil
+}
+
+func printBundleAnalysis(a *devserver.BundleAnalysis, top int) {
+	percent := func(n int) float64 {
+		if a.OutputBytes == 0 {
+			return 0
+		}
+		return float64(n) * 100 / float64(a.OutputBytes)
+	}
+
+	printer.Header("Bundle analysis")
+	printer.Step("Top modules by bytes in output")
+	for i, m := range a.Modules {
+		if i == top {
+			printer.Hint("%d more module(s); use --top to see more", len(a.Modules)-top)
+			break
+		}
+		fmt.Printf("  %10s  %5.1f%%  %s\n", formatKB(int64(m.Bytes)), percent(m.Bytes), m.Path)
+	}
+
+	if len(a.Packages) > 0 {
+		fmt.Println()
+		printer.Step("Packages")
+		for i, p := range a.Packages {
+			if i == top {
+				break
+			}
+			fmt.Printf("  %10s  %5.1f%%  %s\n", formatKB(int64(p.Bytes)), percent(p.Bytes), p.Path)
+		}
+	}
+
+	fmt.Println()
+	printer.Step("Duplicate packages")
+	if len(a.Duplicates) == 0 {
+		fmt.Println("  None")
+	}
+	for _, d := range a.Duplicates {
+		printer.Warn("%s is bundled %d times", d.Name, len(d.Locations))
+		for _, loc := range d.Locations {
+			version := loc.Version
+			if version == "" {
+				version = "?"
+			}
+			fmt.Printf("  %10s  %-10s %s\n", formatKB(int64(loc.Bytes)), version, loc.Dir)
+		}
+	}
+
+	fmt.Println()
+	printer.Step("Node built-ins stubbed by %s", devserver.NodeMocksPath)
+	if len(a.MockedImports) == 0 {
+		fmt.Println("  None")
+	}
+	for _, m := range a.MockedImports {
+		fmt.Printf("  %-14s ← %s\n", m.Module, strings.Join(m.Importers, ", "))
+	}
+	if len(a.MockedImports) > 0 {
+		printer.Hint("These calls are no-ops at runtime; make sure the code paths that use them aren't needed in Workers.")
+	}
+}
+
+// loadBuildConfig reads aerostack.toml, falling back to wrangler.toml like 'aerostack dev'.
+// Without either, the defaults apply (src/index.ts, no compatibility flags).
+func loadBuildConfig() (*devserver.AerostackConfig, error) {
+	for _, path := range []string{"aerostack.toml", "wrangler.toml"} {
+		if _, err := os.Stat(path); err != nil {
+			continue
+		}
+		cfg, err := devserver.ParseAerostackToml(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s:\n%w", path, err)
+		}
+		return cfg, nil
+	}
+	return &devserver.AerostackConfig{}, nil
+}
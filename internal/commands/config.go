@@ -0,0 +1,105 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/aerostackdev/cli/internal/devserver"
+	"github.com/aerostackdev/cli/internal/printer"
+	"github.com/spf13/cobra"
+)
+
+// NewConfigCommand creates the 'aerostack config' command
+func NewConfigCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "config",
+		Short: "Inspect and validate aerostack.toml",
+	}
+
+	cmd.AddCommand(newConfigValidateCommand())
+	return cmd
+}
+
+func newConfigValidateCommand() *cobra.Command {
+	var asJSON bool
+
+	cmd := &cobra.Command{
+		Use:   "validate [path]",
+		Short: "Validate aerostack.toml and report problems",
+		Long: `Parse aerostack.toml and run cross-field checks:
+  • unknown keys, type mismatches and incomplete binding blocks
+  • duplicate binding names across D1, KV, Queues and Postgres
+  • [env.*] overrides that point at undeclared bindings
+  • main and [[services]] entrypoints that don't exist
+  • Postgres connection strings referencing undefined env vars
+
+Exits non-zero when any error is found, so it can run as a pre-commit hook.
+
+Examples:
+  aerostack config validate
+  aerostack config validate --json`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			path := "aerostack.toml"
+			if len(args) > 0 {
+				path = args[0]
+			}
+			return runConfigValidate(path, asJSON)
+		},
+	}
+
+	cmd.Flags().BoolVar(&asJSON, "json", false, "Print diagnostics as JSON (file, line, severity, code, message)")
+	return cmd
+}
+
+func runConfigValidate(path string, asJSON bool) error {
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return fmt.Errorf("%s not found. Run 'aerostack init' first", path)
+	}
+
+	diags, err := devserver.ValidateAerostackToml(path)
+	if err != nil {
+		return err
+	}
+
+	errCount, warnCount := 0, 0
+	for _, d := range diags {
+		if d.Severity == devserver.SeverityError {
+			errCount++
+		} else {
+			warnCount++
+		}
+	}
+
+	if asJSON {
+		if diags == nil {
+			diags = []devserver.Diagnostic{}
+		}
+		out, _ := json.MarshalIndent(diags, "", "  ")
+		fmt.Println(string(out))
+	} else {
+		for _, d := range diags {
+			loc := d.File
+			if d.Line > 0 {
+				loc = fmt.Sprintf("%s:%d", d.File, d.Line)
+			}
+			if d.Severity == devserver.SeverityError {
+				printer.Error("%s  %s [%s]", loc, d.Message, d.Code)
+			} else {
+				printer.Warn("%s  %s [%s]", loc, d.Message, d.Code)
+			}
+		}
+		if errCount == 0 {
+			if warnCount > 0 {
+				fmt.Println()
+			}
+			printer.Success("%s is valid (%d warning(s))", path, warnCount)
+		}
+	}
+
+	if errCount > 0 {
+		return fmt.Errorf("%s has %d error(s) and %d warning(s)", path, errCount, warnCount)
+	}
+	return nil
+}
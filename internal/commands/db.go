@@ -1,7 +1,9 @@
 package commands
 
 import (
+	"errors"
 	"fmt"
+	"io/fs"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -22,8 +24,17 @@ func NewDBCommand() *cobra.Command {
 
 Commands:
   aerostack db neon create <name>  Create a new Neon Postgres database
+  aerostack db neon branch         Manage Neon branches per environment or git branch
   aerostack db migrate new <name>  Create a new migration file
-  aerostack db migrate apply       Apply pending migrations`,
+  aerostack db migrate apply       Apply pending migrations
+  aerostack db migrate status      Show applied, pending, modified and missing Postgres migrations
+  aerostack db migrate rollback    Revert the last Postgres migration(s)
+  aerostack db diff                Generate a migration from schema.sql
+  aerostack db erd                 Render an ER diagram (Mermaid, DOT or D2)
+  aerostack db dump [binding]      Export a database's data as SQL or NDJSON
+  aerostack db restore <b> <file>  Load a dump into a database
+  aerostack db copy --from staging Copy another environment's data into local
+  aerostack db shell [binding]     Open an interactive SQL shell`,
 	}
 
 	// Add neon subcommand
@@ -32,21 +43,28 @@ Commands:
 	cmd.AddCommand(newDBMigrateCommand())
 	// Add pull subcommand (alias for generate types)
 	cmd.AddCommand(newDBPullCommand())
+	cmd.AddCommand(newDBDiffCommand())
+	cmd.AddCommand(newDBErdCommand())
+	cmd.AddCommand(newDBDumpCommand())
+	cmd.AddCommand(newDBRestoreCommand())
+	cmd.AddCommand(newDBCopyCommand())
+	cmd.AddCommand(newDBShellCommand())
 
 	return cmd
 }
 
 func newDBPullCommand() *cobra.Command {
-	var outputPath string
+	var outputPath, format string
 	cmd := &cobra.Command{
 		Use:   "pull",
 		Short: "Pull schema and generate TypeScript types (alias for generate types)",
 		Long:  `Introspects all connected databases (D1 and Postgres) and generates TypeScript interfaces. Same as 'aerostack generate types'.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return generateTypes(outputPath)
+			return generateTypes(outputPath, format)
 		},
 	}
 	cmd.Flags().StringVarP(&outputPath, "output", "o", "shared/types.ts", "Output path for generated types")
+	cmd.Flags().StringVar(&format, "format", devserver.TypeFormatInterfaces, "Output format: "+strings.Join(devserver.TypeFormats, ", "))
 	return cmd
 }
 
@@ -58,63 +76,137 @@ func newDBMigrateCommand() *cobra.Command {
 
 	cmd.AddCommand(newMigrateCreateCommand())
 	cmd.AddCommand(newMigrateApplyCommand())
+	cmd.AddCommand(newMigrateStatusCommand())
+	cmd.AddCommand(newMigrateRollbackCommand())
 
 	return cmd
 }
 
 func newMigrateCreateCommand() *cobra.Command {
 	var postgres bool
+	var binding string
 	cmd := &cobra.Command{
 		Use:   "new <name>",
 		Short: "Create a new migration file",
-		Long:  "Creates migrations/ for D1 (default) or migrations_postgres/ with --postgres",
-		Args:  cobra.ExactArgs(1),
+		Long: `Creates a migration in migrations/ for D1 (default) or, with --postgres, in the Postgres
+binding's migrations_dir (migrations_postgres/ unless configured). --binding picks the
+binding when they use different directories.`,
+		Args: cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return createMigration(args[0], postgres)
+			return createMigration(args[0], postgres || binding != "", binding)
 		},
 	}
-	cmd.Flags().BoolVar(&postgres, "postgres", false, "Create migration in migrations_postgres/ for Postgres")
+	cmd.Flags().BoolVar(&postgres, "postgres", false, "Create a Postgres migration (in migrations_postgres/ or the binding's migrations_dir)")
+	cmd.Flags().StringVar(&binding, "binding", "", "Postgres binding whose migrations_dir to use (implies --postgres)")
 	return cmd
 }
 
 func newMigrateApplyCommand() *cobra.Command {
 	var remote string
+	var opts devserver.PostgresMigrateOptions
 	cmd := &cobra.Command{
 		Use:   "apply",
 		Short: "Apply pending migrations",
+		Long: `Applies pending D1 migrations (migrations/) and Postgres migrations (each binding's
+migrations_dir, else migrations_postgres/).
+
+Postgres runs hold an advisory lock on the database for their whole duration, so two CI
+jobs or developers migrating at once apply each file exactly once: the second waits up to
+--lock-timeout for the first and reports which session holds the lock.
+
+Each Postgres migration runs in its own transaction; with --single-transaction the whole
+batch runs in one, so either every pending migration is applied or none is.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return applyMigrations(remote)
+			return applyMigrations(remote, opts)
 		},
 	}
-	cmd.Flags().StringVar(&remote, "remote", "", "Apply to remote environment (staging/production)")
+	cmd.Flags().StringVar(&remote, "remote", "", "Apply to remote environment (staging, production or any [env.<name>])")
+	addMigrateLockFlags(cmd, &opts)
 	return cmd
 }
 
-func createMigration(name string, postgres bool) error {
-	dir := "migrations"
+// addMigrateLockFlags adds the Postgres --lock-timeout and --single-transaction flags.
+func addMigrateLockFlags(cmd *cobra.Command, opts *devserver.PostgresMigrateOptions) {
+	cmd.Flags().DurationVar(&opts.LockTimeout, "lock-timeout", devserver.DefaultMigrationLockTimeout, "How long to wait for another run holding the Postgres migration lock (0 = fail at once)")
+	cmd.Flags().BoolVar(&opts.SingleTransaction, "single-transaction", false, "Run all Postgres migrations in one transaction (all or nothing)")
+	opts.OnWait = func(holder *devserver.MigrationLockHolder) {
+		fmt.Printf("   ⏳ Waiting for the migration lock held by %s...\n", holder)
+	}
+}
+
+func createMigration(name string, postgres bool, binding string) error {
+	dir := devserver.D1MigrationsDir
+	// Postgres migrations get up/down sections for 'db migrate rollback'
+	var content string
 	if postgres {
-		dir = "migrations_postgres"
+		var err error
+		if dir, err = postgresMigrationsDir(binding); err != nil {
+			return err
+		}
+		content = "-- +up\n\n\n-- +down\n\n"
+	}
+	filename, err := writeMigration(dir, name, content)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("✅ Created migration file: %s\n", filename)
+	return nil
+}
+
+// postgresMigrationsDir returns the migrations directory of the named Postgres binding, or
+// the one every binding shares when none is named.
+func postgresMigrationsDir(binding string) (string, error) {
+	cfg, err := devserver.ParseAerostackToml("aerostack.toml")
+	if err != nil {
+		if binding == "" && errors.Is(err, fs.ErrNotExist) {
+			return devserver.PostgresMigrationsDir, nil
+		}
+		return "", fmt.Errorf("failed to parse config:\n%w", err)
+	}
+	if binding != "" {
+		for _, pg := range cfg.PostgresDatabases {
+			if pg.Binding == binding {
+				return pg.MigrationsPath(), nil
+			}
+		}
+		return "", fmt.Errorf("no Postgres binding %q in aerostack.toml", binding)
 	}
+
+	dirs := map[string][]string{}
+	for _, pg := range cfg.PostgresDatabases {
+		dirs[pg.MigrationsPath()] = append(dirs[pg.MigrationsPath()], pg.Binding)
+	}
+	switch len(dirs) {
+	case 0:
+		return devserver.PostgresMigrationsDir, nil
+	case 1:
+		for dir := range dirs {
+			return dir, nil
+		}
+	}
+	var choices []string
+	for _, pg := range cfg.PostgresDatabases {
+		choices = append(choices, fmt.Sprintf("%s → %s/", pg.Binding, pg.MigrationsPath()))
+	}
+	return "", fmt.Errorf("Postgres bindings use different migrations directories; choose one with --binding (%s)", strings.Join(choices, ", "))
+}
+
+// writeMigration writes a new migration named <timestamp>_<name>.sql into dir and returns its path.
+func writeMigration(dir, name, content string) (string, error) {
 	timestamp := time.Now().Format("20060102150405") // YYYYMMDDHHmmss
 	filename := fmt.Sprintf("%s/%s_%s.sql", dir, timestamp, name)
 
 	// Ensure migrations directory exists
 	if err := os.MkdirAll(dir, 0755); err != nil {
-		return fmt.Errorf("failed to create migrations directory: %w", err)
+		return "", fmt.Errorf("failed to create migrations directory: %w", err)
 	}
-
-	// Create the migration file
-	file, err := os.Create(filename)
-	if err != nil {
-		return fmt.Errorf("failed to create migration file %s: %w", filename, err)
+	if err := os.WriteFile(filename, []byte(content), 0644); err != nil {
+		return "", fmt.Errorf("failed to create migration file %s: %w", filename, err)
 	}
-	defer file.Close()
-
-	fmt.Printf("✅ Created migration file: %s\n", filename)
-	return nil
+	return filename, nil
 }
 
-func applyMigrations(remote string) error {
+func applyMigrations(remote string, opts devserver.PostgresMigrateOptions) error {
 	env := "local"
 	if remote != "" {
 		env = remote
@@ -124,7 +216,12 @@ func applyMigrations(remote string) error {
 	// 1. Parse aerostack.toml
 	cfg, err := devserver.ParseAerostackToml("aerostack.toml")
 	if err != nil {
-		return fmt.Errorf("failed to parse config: %w", err)
+		return fmt.Errorf("failed to parse config:\n%w", err)
+	}
+	if remote != "" {
+		if err := cfg.ValidateEnv(remote); err != nil {
+			return err
+		}
 	}
 
 	projectRoot, err := os.Getwd()
@@ -139,73 +236,53 @@ func applyMigrations(remote string) error {
 	// 3. Apply D1 migrations (if migrations/ exists and D1 is configured)
 	useRemote := remote != ""
 	hasD1Migrations := false
-	if _, err := os.Stat("migrations"); err == nil {
+	if _, err := os.Stat(devserver.D1MigrationsDir); err == nil {
 		hasD1Migrations = true
 	}
 	hasMigrated := false
 	if hasD1Migrations && len(cfg.D1Databases) > 0 {
-		// Need wrangler.toml for D1 migrations
-		wranglerPath := filepath.Join(projectRoot, ".aerostack", "wrangler.toml")
-		if err := devserver.GenerateWranglerToml(cfg, wranglerPath); err != nil {
-			return fmt.Errorf("failed to generate wrangler.toml: %w", err)
-		}
-		for _, db := range cfg.D1Databases {
-			fmt.Printf("📦 Applying migrations to D1 %s (%s)...\n", db.Binding, db.DatabaseName)
-			args := []string{"-y", "wrangler@latest", "d1", "migrations", "apply", db.DatabaseName,
-				"--config", filepath.Join(".aerostack", "wrangler.toml")}
-			if useRemote {
-				args = append(args, "--remote")
-			} else {
-				args = append(args, "--local")
+		if useRemote {
+			if err := applyD1MigrationsRemote(cfg, remote, projectRoot); err != nil {
+				return err
 			}
-			cmd := exec.Command("npx", args...)
-			cmd.Dir = projectRoot
-			cmd.Stdout = os.Stdout
-			cmd.Stderr = os.Stderr
-			if err := cmd.Run(); err != nil {
-				return fmt.Errorf("D1 migrations failed for %s: %w", db.DatabaseName, err)
+		} else {
+			// Local D1 is a SQLite file: migrate it in-process, no wrangler or network needed
+			for _, db := range cfg.D1Databases {
+				fmt.Printf("📦 Applying migrations to D1 %s (%s)...\n", db.Binding, db.DatabaseName)
+				applied, err := devserver.ApplyD1MigrationsLocal(db, devserver.D1MigrationsDir)
+				for _, name := range applied {
+					fmt.Printf("   ✓ %s\n", name)
+				}
+				if err != nil {
+					return fmt.Errorf("D1 migrations failed for %s: %w", db.DatabaseName, err)
+				}
+				if len(applied) == 0 {
+					fmt.Printf("   ✓ No new migrations to apply\n")
+				}
 			}
-			hasMigrated = true
 		}
+		hasMigrated = true
 	}
 
-	// 4. Apply Postgres migrations (migrations_postgres/*.sql)
+	// 4. Apply Postgres migrations: each binding's migrations_dir, else migrations_postgres/
 	// For remote: look for DATABASE_URL or per-binding env var as connection string override
 	hasPostgresMigrations := false
-	if _, err := os.Stat("migrations_postgres"); err == nil {
+	for _, pg := range postgresTargets(cfg, remote) {
+		if _, err := os.Stat(pg.MigrationsPath()); err != nil {
+			continue
+		}
 		hasPostgresMigrations = true
-	}
-	if hasPostgresMigrations && len(cfg.PostgresDatabases) > 0 {
-		for _, pg := range cfg.PostgresDatabases {
-			connStr := pg.ConnectionString
-
-			// For remote migrations, allow overriding connection string from env
-			if useRemote {
-				// Try env var override: e.g. PRODUCTION_PG_CONN or DATABASE_URL
-				envVarName := strings.ToUpper(pg.Binding) + "_CONN"
-				if override := os.Getenv(envVarName); override != "" {
-					connStr = override
-				} else if dbURL := os.Getenv("DATABASE_URL"); dbURL != "" {
-					connStr = dbURL
-				}
-			}
-
-			if strings.Contains(connStr, "$") {
-				fmt.Printf("⚠️  Skipping Postgres %s: connection string has unresolved env vars. Set DATABASE_URL or %s_CONN in your environment.\n", pg.Binding, strings.ToUpper(pg.Binding))
-				continue
-			}
-			fmt.Printf("📦 Applying Postgres migrations to %s (%s env)...\n", pg.Binding, env)
-			n, err := devserver.ApplyPostgresMigrations(connStr, pg.Binding)
-			if err != nil {
-				return fmt.Errorf("Postgres migrations failed for %s: %w", pg.Binding, err)
-			}
-			if n > 0 {
-				fmt.Printf("   ✓ Applied %d migration(s)\n", n)
-			} else {
-				fmt.Printf("   ✓ No new migrations to apply\n")
-			}
-			hasMigrated = true
+		fmt.Printf("📦 Applying Postgres migrations from %s/ to %s (%s env)...\n", pg.MigrationsPath(), pg.Binding, env)
+		n, err := devserver.ApplyPostgresMigrations(pg, opts)
+		if err != nil {
+			return fmt.Errorf("Postgres migrations failed for %s: %w", pg.Binding, err)
 		}
+		if n > 0 {
+			fmt.Printf("   ✓ Applied %d migration(s)\n", n)
+		} else {
+			fmt.Printf("   ✓ No new migrations to apply\n")
+		}
+		hasMigrated = true
 	}
 
 	// 5. Summary
@@ -221,6 +298,114 @@ func applyMigrations(remote string) error {
 	return nil
 }
 
+// applyD1MigrationsRemote runs 'wrangler d1 migrations apply --remote' for the env's D1 bindings.
+func applyD1MigrationsRemote(cfg *devserver.AerostackConfig, remote, projectRoot string) error {
+	// Need wrangler.toml for D1 migrations
+	wranglerPath := filepath.Join(projectRoot, ".aerostack", "wrangler.toml")
+	if err := devserver.GenerateWranglerToml(cfg, wranglerPath); err != nil {
+		return fmt.Errorf("failed to generate wrangler.toml: %w", err)
+	}
+	// Remote runs target the env's own bindings ([env.<name>] overrides)
+	for _, db := range cfg.ForEnv(remote).D1Databases {
+		fmt.Printf("📦 Applying migrations to D1 %s (%s)...\n", db.Binding, db.DatabaseName)
+		args := []string{"-y", "wrangler@latest", "d1", "migrations", "apply", db.DatabaseName,
+			"--config", filepath.Join(".aerostack", "wrangler.toml"), "--remote", "--env", remote}
+		cmd := exec.Command("npx", args...)
+		cmd.Dir = projectRoot
+		cmd.Stdout = os.Stdout
+		cmd.Stderr = os.Stderr
+		if err := cmd.Run(); err != nil {
+			return fmt.Errorf("D1 migrations failed for %s: %w", db.DatabaseName, err)
+		}
+	}
+	return nil
+}
+
+// dbTarget is a D1 or Postgres database a command can work on.
+type dbTarget struct {
+	binding string
+	dialect string
+	d1      devserver.D1Database
+	pg      devserver.PostgresDatabase
+}
+
+// scope names what a reset of the target empties: the database, or a Postgres binding's schema.
+func (t dbTarget) scope() string {
+	if t.dialect == devserver.DialectPostgres {
+		return fmt.Sprintf("%s (schema %s)", t.binding, t.pg.SchemaName())
+	}
+	return t.binding
+}
+
+// dbTargets lists every D1 and usable Postgres database of the local or --remote env.
+func dbTargets(cfg *devserver.AerostackConfig, remote string) []dbTarget {
+	d1s := cfg.D1Databases
+	if remote != "" {
+		d1s = cfg.ForEnv(remote).D1Databases
+	}
+	var targets []dbTarget
+	for _, db := range d1s {
+		targets = append(targets, dbTarget{binding: db.Binding, dialect: devserver.DialectSQLite, d1: db})
+	}
+	for _, pg := range postgresTargets(cfg, remote) {
+		targets = append(targets, dbTarget{binding: pg.Binding, dialect: devserver.DialectPostgres, pg: pg})
+	}
+	return targets
+}
+
+// pickTarget returns the target named by --binding, or the only one when there is just one.
+func pickTarget(targets []dbTarget, binding string) (*dbTarget, error) {
+	switch {
+	case binding != "":
+		for i := range targets {
+			if targets[i].binding == binding {
+				return &targets[i], nil
+			}
+		}
+		return nil, fmt.Errorf("no D1 or Postgres binding %q with a usable connection string", binding)
+	case len(targets) == 1:
+		return &targets[0], nil
+	case len(targets) == 0:
+		return nil, fmt.Errorf("no databases configured")
+	default:
+		names := make([]string, len(targets))
+		for i, t := range targets {
+			names[i] = t.binding
+		}
+		return nil, fmt.Errorf("more than one database binding; choose one with --binding (%s)", strings.Join(names, ", "))
+	}
+}
+
+// postgresTargets returns the Postgres bindings to migrate with their effective connection
+// strings. Remote runs use the env's bindings and honour <BINDING>_CONN or DATABASE_URL;
+// bindings whose connection string still has unresolved env vars are skipped with a warning.
+func postgresTargets(cfg *devserver.AerostackConfig, remote string) []devserver.PostgresDatabase {
+	pgs := cfg.PostgresDatabases
+	if remote != "" {
+		pgs = cfg.ForEnv(remote).PostgresDatabases
+	}
+	var targets []devserver.PostgresDatabase
+	for _, pg := range pgs {
+		// For remote migrations, allow overriding connection string from env
+		if remote != "" {
+			// Try env var override: e.g. PRODUCTION_PG_CONN or DATABASE_URL
+			envVarName := strings.ToUpper(pg.Binding) + "_CONN"
+			if override := os.Getenv(envVarName); override != "" {
+				pg.ConnectionString = override
+			} else if dbURL := os.Getenv("DATABASE_URL"); dbURL != "" {
+				pg.ConnectionString = dbURL
+			}
+		}
+
+		if strings.Contains(pg.ConnectionString, "$") {
+			fmt.Printf("⚠️  Skipping Postgres %s: connection string has unresolved env vars. Set DATABASE_URL or %s_CONN in your environment.\n", pg.Binding, strings.ToUpper(pg.Binding))
+			continue
+		}
+		targets = append(targets, pg)
+	}
+	return targets
+}
+
 func newNeonCommand() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "neon",
@@ -252,6 +437,7 @@ Example:
 	createCmd.Flags().BoolVar(&addToConfig, "add-to-config", true, "Automatically add to aerostack.toml")
 
 	cmd.AddCommand(createCmd)
+	cmd.AddCommand(newNeonBranchCommand())
 	return cmd
 }
 
@@ -290,7 +476,7 @@ func createNeonDatabase(name, region string, addToConfig bool) error {
 
 	// 5. Add to aerostack.toml if requested
 	if addToConfig {
-		if err := addPostgresToConfig(name, envVarName); err != nil {
+		if err := addPostgresToConfig(name, envVarName, result.Project.ID); err != nil {
 			fmt.Printf("⚠️  Failed to update aerostack.toml: %v\n", err)
 			fmt.Println("   Please add manually:")
 			showManualConfigInstructions(name, envVarName)
@@ -305,7 +491,7 @@ func createNeonDatabase(name, region string, addToConfig bool) error {
 	return nil
 }
 
-func addPostgresToConfig(name, envVarName string) error {
+func addPostgresToConfig(name, envVarName, projectID string) error {
 	if name == "" {
 		return fmt.Errorf("database name cannot be empty")
 	}
@@ -333,7 +519,8 @@ func addPostgresToConfig(name, envVarName string) error {
 binding = "%s"
 connection_string = "$%s"
 pool_size = 10
-`, binding, envVarName)
+neon_project_id = "%s"
+`, binding, envVarName, projectID)
 
 	// Append to file
 	newData := append(data, []byte(postgresBlock)...)
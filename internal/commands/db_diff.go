@@ -0,0 +1,159 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/aerostackdev/cli/internal/devserver"
+	"github.com/aerostackdev/cli/internal/printer"
+	"github.com/charmbracelet/huh"
+	"github.com/spf13/cobra"
+)
+
+// defaultSchemaFile is the declarative schema 'db diff' reads when no other is configured.
+const defaultSchemaFile = "schema.sql"
+
+func newDBDiffCommand() *cobra.Command {
+	var binding, schema, name string
+	var dryRun, yes bool
+	cmd := &cobra.Command{
+		Use:   "diff",
+		Short: "Generate a migration from the difference between schema.sql and the local database",
+		Long: `Compares a declarative schema file with the live local database and writes the
+CREATE / ALTER / DROP statements that close the gap as a new migration.
+
+The schema file is --schema, else the Postgres binding's schema_file setting, else
+schema.sql. D1 migrations go to migrations/ in SQLite syntax (tables SQLite can't alter in
+place are rebuilt); Postgres migrations go to the binding's migrations_dir (migrations_postgres/
+unless configured) with a -- +down section when every change can be reverted mechanically;
+otherwise the down section is left out and 'db migrate rollback' refuses the migration.
+A Postgres binding with a schema setting is compared within that schema. CREATE INDEX
+indexes are created, dropped and recreated to match the schema file.
+
+Changes that can lose data (dropped tables or columns, type changes) are flagged and need
+confirmation.
+
+Example:
+  aerostack db diff
+  aerostack db diff --binding PG --name add_posts
+  aerostack db diff --dry-run`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return diffSchema(binding, schema, name, dryRun, yes)
+		},
+	}
+	cmd.Flags().StringVar(&binding, "binding", "", "Database binding to diff (required with more than one)")
+	cmd.Flags().StringVar(&schema, "schema", "", "Declarative schema file (default: the binding's schema_file or schema.sql)")
+	cmd.Flags().StringVar(&name, "name", "schema_diff", "Name of the generated migration")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Print the SQL without writing a migration")
+	cmd.Flags().BoolVarP(&yes, "yes", "y", false, "Write destructive changes without asking")
+	return cmd
+}
+
+func diffSchema(binding, schemaPath, name string, dryRun, yes bool) error {
+	cfg, err := loadMigrateConfig("")
+	if err != nil {
+		return err
+	}
+	devserver.EnsureDefaultD1(cfg)
+
+	target, err := pickTarget(dbTargets(cfg, ""), binding)
+	if err != nil {
+		return err
+	}
+
+	if schemaPath == "" {
+		schemaPath = defaultSchemaFile
+		if target.pg.SchemaFile != "" {
+			schemaPath = target.pg.SchemaFile
+		}
+	}
+	if _, err := os.Stat(schemaPath); err != nil {
+		return fmt.Errorf("schema file %s not found. Pass --schema or create it with the tables you want", schemaPath)
+	}
+
+	var current []devserver.TableSchema
+	var desired *devserver.DesiredSchema
+	dir := devserver.D1MigrationsDir
+	if target.dialect == devserver.DialectPostgres {
+		dir = target.pg.MigrationsPath()
+		if desired, err = devserver.LoadDesiredSchemaPostgres(target.pg.ConnectionString, schemaPath); err != nil {
+			return err
+		}
+		if current, err = devserver.IntrospectPostgres(target.pg.ConnectionString, target.binding, target.pg.SchemaName()); err != nil {
+			return fmt.Errorf("failed to introspect %s: %w", target.binding, err)
+		}
+	} else {
+		if desired, err = devserver.LoadDesiredSchemaSQLite(schemaPath); err != nil {
+			return err
+		}
+		if current, err = devserver.CurrentSchemaD1Local(target.d1); err != nil {
+			return fmt.Errorf("failed to introspect %s: %w", target.binding, err)
+		}
+	}
+
+	changes := devserver.DiffSchemas(current, desired, target.dialect)
+	printer.Header(fmt.Sprintf("%s %s vs %s", dialectLabel(target.dialect), target.binding, schemaPath))
+	if len(changes) == 0 {
+		printer.Success("Local database matches %s, nothing to migrate", schemaPath)
+		return nil
+	}
+
+	destructive := 0
+	for _, c := range changes {
+		if c.Destructive {
+			destructive++
+			fmt.Printf("  %s %s\n", printer.GlyphWarn, c.Summary)
+		} else {
+			fmt.Printf("  %s %s\n", printer.GlyphHint, c.Summary)
+		}
+	}
+	fmt.Println()
+
+	content := devserver.SchemaMigration(changes, target.dialect)
+	if dryRun {
+		fmt.Print(content)
+		return nil
+	}
+
+	if destructive > 0 && !yes {
+		printer.Warn("%d change(s) can lose data. Review them before applying.", destructive)
+		confirm := false
+		form := huh.NewForm(
+			huh.NewGroup(
+				huh.NewConfirm().
+					Title("Write the migration anyway?").
+					Value(&confirm),
+			),
+		)
+		if err := form.Run(); err != nil {
+			return err
+		}
+		if !confirm {
+			fmt.Println("Aborted, no migration written.")
+			return nil
+		}
+	}
+
+	filename, err := writeMigration(dir, name, content)
+	if err != nil {
+		return err
+	}
+	printer.Success("Created migration %s (%d change(s))", filename, len(changes))
+	if target.dialect == devserver.DialectPostgres {
+		for _, c := range changes {
+			if len(c.Down) == 0 {
+				printer.Warn("%q can't be reverted, so the migration has no down section and 'aerostack db migrate rollback' will refuse it", c.Summary)
+				break
+			}
+		}
+	}
+	printer.Hint("Review it, then run 'aerostack db migrate apply'")
+	return nil
+}
+
+func dialectLabel(dialect string) string {
+	if dialect == devserver.DialectPostgres {
+		return "Postgres"
+	}
+	return "D1"
+}
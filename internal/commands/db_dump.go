@@ -0,0 +1,403 @@
+package commands
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/aerostackdev/cli/internal/devserver"
+	"github.com/aerostackdev/cli/internal/printer"
+	"github.com/charmbracelet/huh"
+	"github.com/spf13/cobra"
+)
+
+func newDBDumpCommand() *cobra.Command {
+	var format, outputPath, remote string
+	var tables []string
+	cmd := &cobra.Command{
+		Use:   "dump [binding]",
+		Short: "Export a database's data as SQL or NDJSON",
+		Long: `Writes every row of a D1 or Postgres database, parents before children, as INSERT
+statements (sql) or one {"table": ..., "row": {...}} object per line (ndjson).
+
+Dumps hold data only; restore them into a database migrated to the same schema. Both
+formats restore into either dialect; binary columns in a SQL dump use the source
+database's syntax, so prefer ndjson to move binary data between D1 and Postgres.
+
+The format follows --output's extension (.ndjson or .jsonl for ndjson) unless --format
+is given. Remote D1 databases are exported with 'wrangler d1 export'.
+
+Example:
+  aerostack db dump DB > backup.sql
+  aerostack db dump PG --output pg.ndjson
+  aerostack db dump DB --remote staging --output staging.sql --table users`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			binding := ""
+			if len(args) == 1 {
+				binding = args[0]
+			}
+			if format == "" {
+				format = devserver.DumpFormatFor(outputPath)
+			}
+			return dumpDatabase(binding, remote, outputPath, devserver.DumpOptions{Format: format, Tables: tables})
+		},
+	}
+	cmd.Flags().StringVar(&format, "format", "", "Dump format: "+strings.Join(devserver.DumpFormats, ", ")+" (default: from --output, else sql)")
+	cmd.Flags().StringVarP(&outputPath, "output", "o", "", "File to write the dump to (default: stdout)")
+	cmd.Flags().StringVar(&remote, "remote", "", "Dump a remote environment (staging, production or any [env.<name>])")
+	cmd.Flags().StringSliceVar(&tables, "table", nil, "Only dump these tables (repeatable)")
+	return cmd
+}
+
+func newDBRestoreCommand() *cobra.Command {
+	var format, remote string
+	var reset, force, yes bool
+	cmd := &cobra.Command{
+		Use:   "restore <binding> <file>",
+		Short: "Load a dump into a database",
+		Long: `Loads a file written by 'aerostack db dump' into a D1 or Postgres database in one
+transaction; if any row fails nothing is written. Postgres id sequences are moved past
+the restored rows.
+
+With --reset every table is emptied first. Restoring into production needs --force.
+A remote D1 database can only take a .sql dump, which is run with 'wrangler d1 execute'.
+
+Example:
+  aerostack db restore DB backup.sql --reset
+  aerostack db restore PG pg.ndjson
+  aerostack db restore PG pg.ndjson --remote staging`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if format == "" {
+				format = devserver.DumpFormatFor(args[1])
+			}
+			return restoreDatabase(args[0], args[1], remote, devserver.RestoreOptions{Format: format, Reset: reset}, force, yes)
+		},
+	}
+	cmd.Flags().StringVar(&format, "format", "", "Dump format: "+strings.Join(devserver.DumpFormats, ", ")+" (default: from the file extension)")
+	cmd.Flags().StringVar(&remote, "remote", "", "Restore into a remote environment (staging, production or any [env.<name>])")
+	cmd.Flags().BoolVar(&reset, "reset", false, "Empty every table before restoring")
+	cmd.Flags().BoolVar(&force, "force", false, "Allow restoring into a production environment")
+	cmd.Flags().BoolVarP(&yes, "yes", "y", false, "Don't ask before emptying tables")
+	return cmd
+}
+
+func newDBCopyCommand() *cobra.Command {
+	var from, to string
+	var bindings, tables []string
+	var force, yes bool
+	cmd := &cobra.Command{
+		Use:   "copy",
+		Short: "Copy data from one environment's databases into another's",
+		Long: `Replaces the data of each database in --to with the data of the database with the
+same binding in --from, e.g. to reproduce a staging bug against a local copy of its data.
+The target must already be migrated to the same schema. Its tables (only the --table ones,
+and only in a Postgres binding's schema) are emptied first, after a confirmation that
+--yes skips.
+
+'local' is the local miniflare D1 databases and the Postgres connection strings in
+aerostack.toml. Remote D1 sources are exported with 'wrangler d1 export'; copying into
+remote D1 is not supported. Copying into production needs --force.
+
+Example:
+  aerostack db copy --from staging --to local
+  aerostack db copy --from staging --binding PG --table users --table orders
+  aerostack db copy --from production --to staging --force`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return copyDatabases(from, to, bindings, tables, force, yes)
+		},
+	}
+	cmd.Flags().StringVar(&from, "from", "", "Environment to copy from (local, staging, production or any [env.<name>])")
+	cmd.Flags().StringVar(&to, "to", "local", "Environment to copy into")
+	cmd.Flags().StringSliceVar(&bindings, "binding", nil, "Only copy these bindings (repeatable)")
+	cmd.Flags().StringSliceVar(&tables, "table", nil, "Only copy these tables (repeatable)")
+	cmd.Flags().BoolVar(&force, "force", false, "Allow copying into a production environment")
+	cmd.Flags().BoolVarP(&yes, "yes", "y", false, "Don't ask before replacing the target's data")
+	_ = cmd.MarkFlagRequired("from")
+	return cmd
+}
+
+func dumpDatabase(binding, remote, outputPath string, opts devserver.DumpOptions) error {
+	if !slices.Contains(devserver.DumpFormats, opts.Format) {
+		return fmt.Errorf("unknown --format %q (expected %s)", opts.Format, strings.Join(devserver.DumpFormats, ", "))
+	}
+	cfg, err := loadMigrateConfig(remote)
+	if err != nil {
+		return err
+	}
+	devserver.EnsureDefaultD1(cfg)
+	target, err := pickTarget(dbTargets(cfg, remote), binding)
+	if err != nil {
+		return err
+	}
+
+	var w io.Writer = os.Stdout
+	if outputPath != "" {
+		if err := os.MkdirAll(filepath.Dir(outputPath), 0755); err != nil {
+			return fmt.Errorf("failed to create directory for dump: %w", err)
+		}
+		f, err := os.Create(outputPath)
+		if err != nil {
+			return fmt.Errorf("failed to create %s: %w", outputPath, err)
+		}
+		defer f.Close()
+		w = f
+	}
+
+	counts, err := dumpTarget(cfg, target, remote, w, opts)
+	if err != nil {
+		if outputPath != "" {
+			os.Remove(outputPath)
+		}
+		return fmt.Errorf("dump failed for %s: %w", target.binding, err)
+	}
+	// Progress goes to stderr so 'db dump > file' stays a clean dump
+	total := 0
+	for _, c := range counts {
+		total += c.Rows
+	}
+	dest := outputPath
+	if dest == "" {
+		dest = "stdout"
+	}
+	fmt.Fprintf(os.Stderr, "%s Dumped %d row(s) from %d table(s) of %s (%s env) → %s\n", printer.GlyphSuccess, total, len(counts), target.binding, envLabel(remote), dest)
+	return nil
+}
+
+// dumpTarget dumps one database; remote D1 goes through 'wrangler d1 export'.
+func dumpTarget(cfg *devserver.AerostackConfig, target *dbTarget, remote string, w io.Writer, opts devserver.DumpOptions) ([]devserver.TableRows, error) {
+	if target.dialect == devserver.DialectPostgres {
+		return devserver.DumpPostgres(target.pg, w, opts)
+	}
+	if remote == "" {
+		return devserver.DumpD1Local(target.d1, w, opts)
+	}
+	exportPath, cleanup, err := exportRemoteD1(cfg, remote, target.d1)
+	if err != nil {
+		return nil, err
+	}
+	defer cleanup()
+	return devserver.DumpD1Export(exportPath, target.binding, w, opts)
+}
+
+// exportRemoteD1 runs 'wrangler d1 export' for a remote D1 database into a temporary file.
+func exportRemoteD1(cfg *devserver.AerostackConfig, remote string, db devserver.D1Database) (string, func(), error) {
+	if err := devserver.GenerateWranglerToml(cfg, filepath.Join(".aerostack", "wrangler.toml")); err != nil {
+		return "", nil, fmt.Errorf("failed to generate wrangler.toml: %w", err)
+	}
+	dir, err := os.MkdirTemp("", "aerostack-d1-export-")
+	if err != nil {
+		return "", nil, err
+	}
+	cleanup := func() { os.RemoveAll(dir) }
+	exportPath := filepath.Join(dir, "export.sql")
+
+	fmt.Fprintf(os.Stderr, "📥 Exporting D1 %s (%s env) with wrangler...\n", db.DatabaseName, remote)
+	cmd := exec.Command("npx", "-y", "wrangler@latest", "d1", "export", db.DatabaseName,
+		"--config", filepath.Join(".aerostack", "wrangler.toml"), "--remote", "--env", remote, "--output", exportPath)
+	cmd.Stdout = os.Stderr
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		cleanup()
+		return "", nil, fmt.Errorf("wrangler d1 export failed for %s: %w", db.DatabaseName, err)
+	}
+	return exportPath, cleanup, nil
+}
+
+func restoreDatabase(binding, path, remote string, opts devserver.RestoreOptions, force, yes bool) error {
+	if !slices.Contains(devserver.DumpFormats, opts.Format) {
+		return fmt.Errorf("unknown --format %q (expected %s)", opts.Format, strings.Join(devserver.DumpFormats, ", "))
+	}
+	if isProductionEnv(remote) && !force {
+		return fmt.Errorf("refusing to restore into %s without --force", remote)
+	}
+	cfg, err := loadMigrateConfig(remote)
+	if err != nil {
+		return err
+	}
+	devserver.EnsureDefaultD1(cfg)
+	target, err := pickTarget(dbTargets(cfg, remote), binding)
+	if err != nil {
+		return err
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open dump: %w", err)
+	}
+	defer f.Close()
+
+	if opts.Reset && !yes {
+		ok, err := confirm(fmt.Sprintf("Empty every table in %s (%s env) before restoring?", target.scope(), envLabel(remote)))
+		if err != nil || !ok {
+			if err == nil {
+				fmt.Println("Aborted, nothing restored.")
+			}
+			return err
+		}
+	}
+
+	fmt.Printf("📦 Restoring %s into %s (%s env)...\n", path, target.binding, envLabel(remote))
+	var counts []devserver.TableRows
+	switch {
+	case target.dialect == devserver.DialectPostgres:
+		counts, err = devserver.RestorePostgres(target.pg, f, opts)
+	case remote == "":
+		counts, err = devserver.RestoreD1Local(target.d1, f, opts)
+	default:
+		if opts.Format != devserver.DumpFormatSQL || opts.Reset {
+			return fmt.Errorf("remote D1 can only be restored from a .sql dump, without --reset")
+		}
+		return executeRemoteD1(cfg, remote, target.d1, path)
+	}
+	if err != nil {
+		return fmt.Errorf("restore failed for %s (nothing was written): %w", target.binding, err)
+	}
+	printTableRows(counts)
+	printer.Success("Restored %s into %s", path, target.binding)
+	return nil
+}
+
+// executeRemoteD1 runs a SQL file against a remote D1 database with 'wrangler d1 execute'.
+func executeRemoteD1(cfg *devserver.AerostackConfig, remote string, db devserver.D1Database, path string) error {
+	if err := devserver.GenerateWranglerToml(cfg, filepath.Join(".aerostack", "wrangler.toml")); err != nil {
+		return fmt.Errorf("failed to generate wrangler.toml: %w", err)
+	}
+	cmd := exec.Command("npx", "-y", "wrangler@latest", "d1", "execute", db.DatabaseName,
+		"--config", filepath.Join(".aerostack", "wrangler.toml"), "--remote", "--env", remote, "--file", path, "--yes")
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("wrangler d1 execute failed for %s: %w", db.DatabaseName, err)
+	}
+	printer.Success("Restored %s into %s", path, db.Binding)
+	return nil
+}
+
+func copyDatabases(from, to string, bindings, tables []string, force, yes bool) error {
+	if from == "local" {
+		from = ""
+	}
+	if to == "local" {
+		to = ""
+	}
+	if from == to {
+		return fmt.Errorf("--from and --to are the same environment")
+	}
+	if isProductionEnv(to) && !force {
+		return fmt.Errorf("refusing to overwrite %s data without --force", to)
+	}
+	cfg, err := devserver.ParseAerostackToml("aerostack.toml")
+	if err != nil {
+		return fmt.Errorf("failed to parse config:\n%w", err)
+	}
+	for _, env := range []string{from, to} {
+		if env != "" {
+			if err := cfg.ValidateEnv(env); err != nil {
+				return err
+			}
+		}
+	}
+	devserver.EnsureDefaultD1(cfg)
+
+	// Pair databases by binding name
+	type pair struct{ src, dst dbTarget }
+	var pairs []pair
+	dsts := dbTargets(cfg, to)
+	for _, src := range dbTargets(cfg, from) {
+		if len(bindings) > 0 && !slices.Contains(bindings, src.binding) {
+			continue
+		}
+		for _, dst := range dsts {
+			if dst.binding == src.binding {
+				pairs = append(pairs, pair{src, dst})
+			}
+		}
+	}
+	if len(pairs) == 0 {
+		return fmt.Errorf("no database bindings exist in both %s and %s", envLabel(from), envLabel(to))
+	}
+	for _, p := range pairs {
+		if p.dst.dialect == devserver.DialectSQLite && to != "" {
+			return fmt.Errorf("copying into remote D1 (%s) is not supported; dump to SQL and use 'aerostack db restore %s <file> --remote %s'", p.dst.binding, p.dst.binding, to)
+		}
+	}
+
+	// The copy restores with a reset: say exactly what gets emptied before asking
+	emptied := "every table"
+	if len(tables) > 0 {
+		emptied = strings.Join(tables, ", ")
+	}
+	names := make([]string, len(pairs))
+	for i, p := range pairs {
+		names[i] = p.dst.scope()
+		printer.Warn("Emptying %s in %s (%s env) before copying", emptied, p.dst.scope(), envLabel(to))
+	}
+	if !yes {
+		ok, err := confirm(fmt.Sprintf("Empty %s in %s (%s env) and replace it with %s data?", emptied, strings.Join(names, ", "), envLabel(to), envLabel(from)))
+		if err != nil || !ok {
+			if err == nil {
+				fmt.Println("Aborted, nothing copied.")
+			}
+			return err
+		}
+	}
+
+	for _, p := range pairs {
+		printer.Header(fmt.Sprintf("%s: %s → %s", p.src.binding, envLabel(from), envLabel(to)))
+		tmp, err := os.CreateTemp("", "aerostack-copy-*.ndjson")
+		if err != nil {
+			return err
+		}
+		defer os.Remove(tmp.Name())
+
+		if _, err := dumpTarget(cfg, &p.src, from, tmp, devserver.DumpOptions{Format: devserver.DumpFormatNDJSON, Tables: tables}); err != nil {
+			tmp.Close()
+			return fmt.Errorf("failed to read %s from %s: %w", p.src.binding, envLabel(from), err)
+		}
+		if _, err := tmp.Seek(0, io.SeekStart); err != nil {
+			tmp.Close()
+			return err
+		}
+		opts := devserver.RestoreOptions{Format: devserver.DumpFormatNDJSON, Reset: true, Tables: tables}
+		var counts []devserver.TableRows
+		if p.dst.dialect == devserver.DialectPostgres {
+			counts, err = devserver.RestorePostgres(p.dst.pg, tmp, opts)
+		} else {
+			counts, err = devserver.RestoreD1Local(p.dst.d1, tmp, opts)
+		}
+		tmp.Close()
+		if err != nil {
+			return fmt.Errorf("failed to write %s in %s (nothing was written): %w", p.dst.binding, envLabel(to), err)
+		}
+		printTableRows(counts)
+	}
+	printer.Success("Copied %d database(s) from %s to %s", len(pairs), envLabel(from), envLabel(to))
+	return nil
+}
+
+func printTableRows(counts []devserver.TableRows) {
+	for _, c := range counts {
+		fmt.Printf("  %s %-24s %6d row(s)\n", printer.GlyphSuccess, c.Table, c.Rows)
+	}
+}
+
+// confirm asks a yes/no question.
+func confirm(title string) (bool, error) {
+	ok := false
+	form := huh.NewForm(
+		huh.NewGroup(
+			huh.NewConfirm().
+				Title(title).
+				Value(&ok),
+		),
+	)
+	if err := form.Run(); err != nil {
+		return false, err
+	}
+	return ok, nil
+}
@@ -0,0 +1,140 @@
+package commands
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/aerostackdev/cli/internal/devserver"
+	"github.com/aerostackdev/cli/internal/printer"
+	"github.com/spf13/cobra"
+)
+
+func newDBErdCommand() *cobra.Command {
+	var format, outputPath string
+	var bindings []string
+	var check bool
+	cmd := &cobra.Command{
+		Use:   "erd",
+		Short: "Render an entity-relationship diagram of your databases",
+		Long: `Introspects every D1 and Postgres database and renders their tables as an
+entity-relationship diagram. Tables are grouped by binding and relationships come
+from foreign keys. Nothing besides the CLI needs to be installed.
+
+Formats:
+  mermaid  Mermaid erDiagram (default). Written to a .md file it is wrapped in a
+           mermaid code fence, so GitHub renders it inline
+  dot      Graphviz DOT, one cluster per binding
+  d2       D2, one container of sql_table shapes per binding
+
+With --check the diagram is not written; the command fails if --output is missing
+or out of date, so CI can require the docs to be regenerated after a migration.
+A database that can't be introspected (e.g. a local D1 that hasn't been migrated
+yet) is an error rather than a gap in the diagram.
+
+Example:
+  aerostack db erd
+  aerostack db erd --output docs/schema.md
+  aerostack db erd --format d2 --binding DB --output docs/schema.d2
+  aerostack db erd --output docs/schema.md --check`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return renderERD(format, outputPath, bindings, check)
+		},
+	}
+	cmd.Flags().StringVar(&format, "format", devserver.ERDFormatMermaid, "Diagram format: "+strings.Join(devserver.ERDFormats, ", "))
+	cmd.Flags().StringVarP(&outputPath, "output", "o", "", "File to write the diagram to (default: stdout)")
+	cmd.Flags().StringSliceVar(&bindings, "binding", nil, "Only include these database bindings (repeatable)")
+	cmd.Flags().BoolVar(&check, "check", false, "Fail if --output is not up to date instead of writing it")
+	return cmd
+}
+
+func renderERD(format, outputPath string, bindings []string, check bool) error {
+	if !slices.Contains(devserver.ERDFormats, format) {
+		return fmt.Errorf("unknown --format %q (expected %s)", format, strings.Join(devserver.ERDFormats, ", "))
+	}
+	if check && outputPath == "" {
+		return fmt.Errorf("--check needs --output to compare against")
+	}
+
+	cfg, err := devserver.ParseAerostackToml("aerostack.toml")
+	if err != nil {
+		return fmt.Errorf("failed to parse config:\n%w", err)
+	}
+	devserver.EnsureDefaultD1(cfg)
+	if len(bindings) > 0 {
+		if err := filterBindings(cfg, bindings); err != nil {
+			return err
+		}
+	}
+
+	// Keep stdout clean for the diagram itself
+	var progress io.Writer = os.Stdout
+	if outputPath == "" {
+		progress = os.Stderr
+	}
+	// A database that can't be read would silently drop out of the diagram; fail instead
+	schemas, err := introspectDatabases(cfg, progress, true)
+	if err != nil {
+		return err
+	}
+
+	diagram, err := devserver.GenerateERD(schemas, format)
+	if err != nil {
+		return err
+	}
+	if format == devserver.ERDFormatMermaid && strings.EqualFold(filepath.Ext(outputPath), ".md") {
+		diagram = "```mermaid\n" + diagram + "```\n"
+	}
+
+	if outputPath == "" {
+		fmt.Print(diagram)
+		return nil
+	}
+
+	if check {
+		existing, err := os.ReadFile(outputPath)
+		if err != nil || string(existing) != diagram {
+			return fmt.Errorf("%s is out of date. Run 'aerostack db erd --format %s --output %s' and commit the result", outputPath, format, outputPath)
+		}
+		printer.Success("%s is up to date", outputPath)
+		return nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(outputPath), 0755); err != nil {
+		return fmt.Errorf("failed to create directory for diagram: %w", err)
+	}
+	if err := os.WriteFile(outputPath, []byte(diagram), 0644); err != nil {
+		return fmt.Errorf("failed to write diagram: %w", err)
+	}
+	printer.Success("Rendered %s diagram (%d tables) → %s", format, len(devserver.UserTables(schemas)), outputPath)
+	return nil
+}
+
+// filterBindings drops every D1 and Postgres database whose binding is not listed.
+func filterBindings(cfg *devserver.AerostackConfig, bindings []string) error {
+	var d1s []devserver.D1Database
+	var pgs []devserver.PostgresDatabase
+	found := map[string]bool{}
+	for _, db := range cfg.D1Databases {
+		if slices.Contains(bindings, db.Binding) {
+			d1s = append(d1s, db)
+			found[db.Binding] = true
+		}
+	}
+	for _, pg := range cfg.PostgresDatabases {
+		if slices.Contains(bindings, pg.Binding) {
+			pgs = append(pgs, pg)
+			found[pg.Binding] = true
+		}
+	}
+	for _, b := range bindings {
+		if !found[b] {
+			return fmt.Errorf("no D1 or Postgres binding %q in aerostack.toml", b)
+		}
+	}
+	cfg.D1Databases, cfg.PostgresDatabases = d1s, pgs
+	return nil
+}
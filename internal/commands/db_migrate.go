@@ -0,0 +1,184 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/aerostackdev/cli/internal/devserver"
+	"github.com/aerostackdev/cli/internal/printer"
+	"github.com/spf13/cobra"
+)
+
+func newMigrateStatusCommand() *cobra.Command {
+	var remote string
+	cmd := &cobra.Command{
+		Use:   "status",
+		Short: "Show applied, pending, modified and missing Postgres migrations",
+		Long: `Compares each Postgres binding's migrations directory (migrations_dir, else
+migrations_postgres/) with its rows in _aerostack_migrations.
+
+  applied   recorded and unchanged
+  pending   not applied yet ('aerostack db migrate apply' will run it)
+  modified  applied, but the file changed since — apply and rollback refuse to run
+  missing   applied, but the file is no longer in the migrations directory`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return migrationStatus(remote)
+		},
+	}
+	cmd.Flags().StringVar(&remote, "remote", "", "Check a remote environment (staging, production or any [env.<name>])")
+	return cmd
+}
+
+func newMigrateRollbackCommand() *cobra.Command {
+	var remote, binding string
+	var steps int
+	var opts devserver.PostgresMigrateOptions
+	cmd := &cobra.Command{
+		Use:   "rollback",
+		Short: "Revert the last applied Postgres migration(s)",
+		Long: `Reverts the most recently applied Postgres migrations, newest first.
+
+The down SQL comes from a paired <name>.down.sql file or a "-- +down" section in the migration:
+
+  -- +up
+  CREATE TABLE posts (id SERIAL PRIMARY KEY);
+
+  -- +down
+  DROP TABLE posts;
+
+Example:
+  aerostack db migrate rollback
+  aerostack db migrate rollback --steps 3 --binding PG`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return rollbackMigrations(remote, binding, steps, opts)
+		},
+	}
+	cmd.Flags().IntVar(&steps, "steps", 1, "Number of migrations to revert")
+	cmd.Flags().StringVar(&binding, "binding", "", "Postgres binding to roll back (required with more than one)")
+	cmd.Flags().StringVar(&remote, "remote", "", "Roll back a remote environment (staging, production or any [env.<name>])")
+	addMigrateLockFlags(cmd, &opts)
+	return cmd
+}
+
+// loadMigrateConfig parses aerostack.toml and validates the --remote env, like 'db migrate apply'.
+func loadMigrateConfig(remote string) (*devserver.AerostackConfig, error) {
+	cfg, err := devserver.ParseAerostackToml("aerostack.toml")
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse config:\n%w", err)
+	}
+	if remote != "" {
+		if err := cfg.ValidateEnv(remote); err != nil {
+			return nil, err
+		}
+	}
+	return cfg, nil
+}
+
+func migrationStatus(remote string) error {
+	cfg, err := loadMigrateConfig(remote)
+	if err != nil {
+		return err
+	}
+	targets := postgresTargets(cfg, remote)
+	if len(targets) == 0 {
+		printer.Hint("No Postgres databases configured. D1 migrations are tracked by wrangler: npx wrangler d1 migrations list <db>")
+		return nil
+	}
+
+	blocked := false
+	for _, pg := range targets {
+		states, err := devserver.PostgresMigrationStatus(pg)
+		if err != nil {
+			return fmt.Errorf("failed to read migration status for %s: %w", pg.Binding, err)
+		}
+		printer.Header(fmt.Sprintf("Postgres %s (%s)", pg.Binding, envLabel(remote)))
+		if len(states) == 0 {
+			fmt.Printf("  No migrations in %s/\n", pg.MigrationsPath())
+			continue
+		}
+		counts := map[string]int{}
+		for _, s := range states {
+			counts[s.Status]++
+			applied := ""
+			if !s.AppliedAt.IsZero() {
+				applied = s.AppliedAt.Local().Format("2006-01-02 15:04")
+			}
+			fmt.Printf("  %s %-9s %-16s %s\n", migrationGlyph(s.Status), s.Status, applied, s.Name)
+		}
+		fmt.Println()
+		fmt.Println(printer.KeyVal("Summary", fmt.Sprintf("%d applied, %d pending, %d modified, %d missing",
+			counts[devserver.MigrationApplied], counts[devserver.MigrationPending], counts[devserver.MigrationModified], counts[devserver.MigrationMissing])))
+		if counts[devserver.MigrationModified] > 0 {
+			blocked = true
+		}
+	}
+	if blocked {
+		printer.Warn("Modified migrations block 'db migrate apply' and 'rollback'. Restore the original files and put changes in a new migration.")
+	}
+	return nil
+}
+
+func rollbackMigrations(remote, binding string, steps int, opts devserver.PostgresMigrateOptions) error {
+	cfg, err := loadMigrateConfig(remote)
+	if err != nil {
+		return err
+	}
+	targets := postgresTargets(cfg, remote)
+	var pg *devserver.PostgresDatabase
+	switch {
+	case binding != "":
+		for i := range targets {
+			if targets[i].Binding == binding {
+				pg = &targets[i]
+			}
+		}
+		if pg == nil {
+			return fmt.Errorf("no Postgres binding %q with a usable connection string", binding)
+		}
+	case len(targets) == 1:
+		pg = &targets[0]
+	case len(targets) == 0:
+		return fmt.Errorf("no Postgres databases configured")
+	default:
+		names := make([]string, len(targets))
+		for i, t := range targets {
+			names[i] = t.Binding
+		}
+		return fmt.Errorf("more than one Postgres binding; choose one with --binding (%s)", strings.Join(names, ", "))
+	}
+
+	fmt.Printf("⏪ Rolling back %d migration(s) on %s (%s env)...\n", steps, pg.Binding, envLabel(remote))
+	reverted, err := devserver.RollbackPostgresMigrations(*pg, steps, opts)
+	for _, name := range reverted {
+		fmt.Printf("   ✓ Reverted %s\n", name)
+	}
+	if err != nil {
+		return fmt.Errorf("rollback failed for %s: %w", pg.Binding, err)
+	}
+	if len(reverted) == 0 {
+		fmt.Println("   No applied migrations to roll back")
+		return nil
+	}
+	fmt.Printf("✅ Rolled back %d migration(s)\n", len(reverted))
+	return nil
+}
+
+func envLabel(remote string) string {
+	if remote == "" {
+		return "local"
+	}
+	return remote
+}
+
+func migrationGlyph(status string) string {
+	switch status {
+	case devserver.MigrationApplied:
+		return printer.GlyphSuccess
+	case devserver.MigrationPending:
+		return printer.GlyphHint
+	case devserver.MigrationModified:
+		return printer.GlyphError
+	default:
+		return printer.GlyphWarn
+	}
+}
@@ -0,0 +1,383 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/aerostackdev/cli/internal/devserver"
+	"github.com/aerostackdev/cli/internal/neon"
+	"github.com/aerostackdev/cli/internal/printer"
+	"github.com/spf13/cobra"
+)
+
+func newNeonBranchCommand() *cobra.Command {
+	var project, binding string
+	cmd := &cobra.Command{
+		Use:   "branch",
+		Short: "Manage Neon branches for environments and previews",
+		Long: `Neon branches are copy-on-write copies of a database. Create one per environment
+(staging) or per git branch (preview/<git branch>) and reset it from its parent to start
+over with fresh data.
+
+The Neon project comes from --project, the neon_project_id of the Postgres binding
+(chosen with --binding when there is more than one) or NEON_PROJECT_ID.
+
+Example:
+  aerostack db neon branch create --env staging
+  aerostack db neon branch create              # preview/<current git branch>
+  aerostack db neon branch list
+  aerostack db neon branch reset preview/feature-x
+  aerostack db neon branch delete preview/feature-x`,
+	}
+	cmd.PersistentFlags().StringVar(&project, "project", "", "Neon project ID (default: neon_project_id of the Postgres binding or NEON_PROJECT_ID)")
+	cmd.PersistentFlags().StringVar(&binding, "binding", "", "Postgres binding whose neon_project_id to use")
+
+	var env, parent string
+	createCmd := &cobra.Command{
+		Use:   "create [name]",
+		Short: "Create a branch for an environment or the current git branch",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			name := env
+			if len(args) == 1 {
+				if env != "" {
+					return fmt.Errorf("give either a branch name or --env, not both")
+				}
+				name = args[0]
+			}
+			return createNeonBranch(project, binding, name, parent, env != "")
+		},
+	}
+	createCmd.Flags().StringVar(&env, "env", "", "Name the branch after this environment (e.g. staging)")
+	createCmd.Flags().StringVar(&parent, "parent", "", "Branch to copy (default: the project's default branch)")
+
+	listCmd := &cobra.Command{
+		Use:   "list",
+		Short: "List the project's branches",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return listNeonBranches(project, binding)
+		},
+	}
+
+	var yes bool
+	deleteCmd := &cobra.Command{
+		Use:   "delete <name>",
+		Short: "Delete a branch and its compute",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return deleteNeonBranch(project, binding, args[0], yes)
+		},
+	}
+	deleteCmd.Flags().BoolVarP(&yes, "yes", "y", false, "Skip the confirmation prompt")
+
+	resetCmd := &cobra.Command{
+		Use:   "reset <name>",
+		Short: "Replace a branch's data with its parent's current data",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return resetNeonBranch(project, binding, args[0], yes)
+		},
+	}
+	resetCmd.Flags().BoolVarP(&yes, "yes", "y", false, "Skip the confirmation prompt")
+
+	cmd.AddCommand(createCmd, listCmd, deleteCmd, resetCmd)
+	return cmd
+}
+
+// neonProject resolves the Neon project and the Postgres binding it belongs to: --project,
+// then the binding's neon_project_id, then NEON_PROJECT_ID.
+func neonProject(project, binding string) (string, string, error) {
+	var pgs []devserver.PostgresDatabase
+	if cfg, err := devserver.ParseAerostackToml("aerostack.toml"); err == nil {
+		pgs = cfg.PostgresDatabases
+	} else if !errors.Is(err, fs.ErrNotExist) && project == "" {
+		return "", "", fmt.Errorf("failed to parse config:\n%w", err)
+	}
+
+	var pg *devserver.PostgresDatabase
+	switch {
+	case binding != "":
+		for i := range pgs {
+			if pgs[i].Binding == binding {
+				pg = &pgs[i]
+			}
+		}
+		if pg == nil {
+			return "", "", fmt.Errorf("no Postgres binding %q in aerostack.toml", binding)
+		}
+	case len(pgs) == 1:
+		pg = &pgs[0]
+	}
+	if pg != nil {
+		binding = pg.Binding
+	}
+
+	switch {
+	case project != "":
+		return project, binding, nil
+	case pg != nil && pg.NeonProjectID != "":
+		return pg.NeonProjectID, binding, nil
+	case os.Getenv("NEON_PROJECT_ID") != "":
+		return os.Getenv("NEON_PROJECT_ID"), binding, nil
+	case pg == nil && len(pgs) > 1:
+		return "", "", fmt.Errorf("more than one Postgres binding; choose one with --binding or pass --project")
+	default:
+		return "", "", fmt.Errorf("no Neon project: set neon_project_id on the Postgres binding, NEON_PROJECT_ID, or pass --project")
+	}
+}
+
+func neonClient() (*neon.Client, error) {
+	apiKey, err := neon.GetAPIKeyFromEnv()
+	if err != nil {
+		return nil, err
+	}
+	return neon.NewClient(apiKey), nil
+}
+
+// gitBranch returns the checked-out git branch, failing outside a repo or on a detached HEAD.
+func gitBranch() (string, error) {
+	out, err := exec.Command("git", "rev-parse", "--abbrev-ref", "HEAD").Output()
+	if err != nil {
+		return "", fmt.Errorf("not in a git repository; name the branch or use --env")
+	}
+	branch := strings.TrimSpace(string(out))
+	if branch == "HEAD" {
+		return "", fmt.Errorf("HEAD is detached; check out a branch, name the Neon branch or use --env")
+	}
+	return branch, nil
+}
+
+func createNeonBranch(project, binding, name, parent string, forEnv bool) error {
+	projectID, binding, err := neonProject(project, binding)
+	if err != nil {
+		return err
+	}
+	if name == "" {
+		branch, err := gitBranch()
+		if err != nil {
+			return err
+		}
+		name = neon.PreviewBranchName(branch)
+	}
+	client, err := neonClient()
+	if err != nil {
+		return err
+	}
+
+	branches, err := client.ListBranches(projectID)
+	if err != nil {
+		return err
+	}
+	if neon.FindBranch(branches, name) != nil {
+		return fmt.Errorf("branch %s already exists. Reset it with 'aerostack db neon branch reset %s'", name, name)
+	}
+	parentID := ""
+	if parent != "" {
+		p := neon.FindBranch(branches, parent)
+		if p == nil {
+			return fmt.Errorf("no parent branch %q in project %s", parent, projectID)
+		}
+		parentID = p.ID
+	}
+
+	fmt.Printf("🌿 Creating Neon branch %s...\n", name)
+	result, err := client.CreateBranch(projectID, name, parentID)
+	if err != nil {
+		return err
+	}
+	connStr := ""
+	if len(result.ConnectionURIs) > 0 {
+		connStr = result.ConnectionURIs[0].ConnectionURI
+	}
+	if connStr == "" {
+		if connStr, err = client.BranchConnectionString(projectID, result.Branch.ID, "", ""); err != nil {
+			return err
+		}
+	}
+
+	printer.Success("Created branch %s (%s)", name, result.Branch.ID)
+	fmt.Println()
+	envVar := "DATABASE_URL"
+	if binding != "" {
+		envVar = strings.ToUpper(binding) + "_CONN"
+	}
+	fmt.Printf("📋 Connection String:\n   %s=\"%s\"\n\n", envVar, connStr)
+	if forEnv {
+		printer.Hint("Set %s when running 'aerostack db migrate apply --remote %s' or deploying", envVar, name)
+	} else {
+		printer.Hint("'aerostack dev --neon-branch' connects to this branch while it is checked out")
+	}
+	return nil
+}
+
+func listNeonBranches(project, binding string) error {
+	projectID, _, err := neonProject(project, binding)
+	if err != nil {
+		return err
+	}
+	client, err := neonClient()
+	if err != nil {
+		return err
+	}
+	branches, err := client.ListBranches(projectID)
+	if err != nil {
+		return err
+	}
+
+	names := make(map[string]string, len(branches))
+	for _, b := range branches {
+		names[b.ID] = b.Name
+	}
+	current := ""
+	if branch, err := gitBranch(); err == nil {
+		current = neon.PreviewBranchName(branch)
+	}
+	rows := make([][]string, len(branches))
+	for i, b := range branches {
+		name := b.Name
+		switch {
+		case b.IsDefault():
+			name += printer.Muted(" (default)")
+		case b.Name == current:
+			name += printer.Muted(" (current git branch)")
+		}
+		rows[i] = []string{name, names[b.ParentID], b.CurrentState, b.CreatedAt}
+	}
+	printer.Header(fmt.Sprintf("Neon branches of %s", projectID))
+	fmt.Println(printer.Table([]string{"branch", "parent", "state", "created"}, rows))
+	return nil
+}
+
+// findNeonBranch looks a branch up by name or ID, refusing the project's default branch.
+func findNeonBranch(client *neon.Client, projectID, name, action string) (*neon.Branch, error) {
+	branches, err := client.ListBranches(projectID)
+	if err != nil {
+		return nil, err
+	}
+	b := neon.FindBranch(branches, name)
+	if b == nil {
+		return nil, fmt.Errorf("no branch %q in project %s", name, projectID)
+	}
+	if b.IsDefault() {
+		return nil, fmt.Errorf("refusing to %s %s: it is the project's default branch", action, b.Name)
+	}
+	return b, nil
+}
+
+func deleteNeonBranch(project, binding, name string, yes bool) error {
+	projectID, _, err := neonProject(project, binding)
+	if err != nil {
+		return err
+	}
+	client, err := neonClient()
+	if err != nil {
+		return err
+	}
+	b, err := findNeonBranch(client, projectID, name, "delete")
+	if err != nil {
+		return err
+	}
+	if !yes {
+		ok, err := confirm(fmt.Sprintf("Delete Neon branch %s and all of its data?", b.Name))
+		if err != nil || !ok {
+			if err == nil {
+				fmt.Println("Aborted, nothing deleted.")
+			}
+			return err
+		}
+	}
+	if err := client.DeleteBranch(projectID, b.ID); err != nil {
+		return err
+	}
+	printer.Success("Deleted branch %s", b.Name)
+	return nil
+}
+
+func resetNeonBranch(project, binding, name string, yes bool) error {
+	projectID, _, err := neonProject(project, binding)
+	if err != nil {
+		return err
+	}
+	client, err := neonClient()
+	if err != nil {
+		return err
+	}
+	b, err := findNeonBranch(client, projectID, name, "reset")
+	if err != nil {
+		return err
+	}
+	if !yes {
+		ok, err := confirm(fmt.Sprintf("Replace all data in %s with its parent's current data?", b.Name))
+		if err != nil || !ok {
+			if err == nil {
+				fmt.Println("Aborted, nothing reset.")
+			}
+			return err
+		}
+	}
+	if err := client.ResetBranch(projectID, *b); err != nil {
+		return err
+	}
+	printer.Success("Reset branch %s from its parent", b.Name)
+	return nil
+}
+
+// neonPreviewConnections points every Postgres binding with a Neon project at the
+// preview/<git branch> branch, creating it on first use. Each binding keeps the database
+// and role of its own connection string. It returns connection strings by binding.
+func neonPreviewConnections(cfg *devserver.AerostackConfig, out func(format string, args ...any)) (map[string]string, error) {
+	branch, err := gitBranch()
+	if err != nil {
+		return nil, fmt.Errorf("--neon-branch: %w", err)
+	}
+	name := neon.PreviewBranchName(branch)
+	client, err := neonClient()
+	if err != nil {
+		return nil, err
+	}
+
+	conns := map[string]string{}
+	branches := map[string]*neon.Branch{} // one branch per project, shared by its bindings
+	byTarget := map[string]string{}       // project/database/role -> connection string
+	for _, pg := range cfg.PostgresDatabases {
+		projectID := pg.NeonProjectID
+		if projectID == "" {
+			projectID = os.Getenv("NEON_PROJECT_ID")
+		}
+		if projectID == "" {
+			out("⚠️  Postgres %s has no neon_project_id; keeping its connection string\n", pg.Binding)
+			continue
+		}
+		database, role := neon.ConnectionTarget(pg.ConnectionString)
+		key := projectID + "/" + database + "/" + role
+		if connStr, ok := byTarget[key]; ok {
+			conns[pg.Binding] = connStr
+			continue
+		}
+		b, ok := branches[projectID]
+		if !ok {
+			var created bool
+			if b, created, err = client.EnsureBranch(projectID, name); err != nil {
+				return nil, err
+			}
+			verb := "Using"
+			if created {
+				verb = "Created"
+			}
+			out("🌿 %s Neon branch %s for Postgres %s\n", verb, name, pg.Binding)
+			branches[projectID] = b
+		}
+		connStr, err := client.BranchConnectionString(projectID, b.ID, database, role)
+		if err != nil {
+			return nil, fmt.Errorf("Postgres %s: %w", pg.Binding, err)
+		}
+		byTarget[key] = connStr
+		conns[pg.Binding] = connStr
+	}
+	return conns, nil
+}
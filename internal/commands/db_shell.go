@@ -0,0 +1,541 @@
+package commands
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/aerostackdev/cli/internal/devserver"
+	"github.com/aerostackdev/cli/internal/printer"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/spf13/cobra"
+)
+
+const (
+	shellOutputTable = "table"
+	shellOutputJSON  = "json"
+	shellOutputCSV   = "csv"
+
+	shellHistoryLimit = 500
+)
+
+func newDBShellCommand() *cobra.Command {
+	var remote, command string
+	var asJSON, asCSV bool
+	cmd := &cobra.Command{
+		Use:   "shell [binding]",
+		Short: "Open an interactive SQL shell on a database",
+		Long: `Opens a SQL prompt on a D1 or Postgres database. Local D1 is the SQLite file under
+.aerostack/; Postgres uses the binding's connection string; remote D1 goes through the
+Cloudflare D1 HTTP API and needs CLOUDFLARE_API_TOKEN and CLOUDFLARE_ACCOUNT_ID.
+
+Statements end with ';' and may span lines. Up and down walk the history, which is kept
+in ~/.aerostack/db_shell_history. Meta-commands:
+  \dt          List tables
+  \d <table>   Describe a table's columns, indexes and foreign keys
+  \?           Show help
+  \q           Quit
+
+With --command or piped input the shell runs the statements and exits, stopping at the
+first error. --json and --csv print results for scripts instead of tables.
+
+Example:
+  aerostack db shell
+  aerostack db shell PG --remote staging
+  aerostack db shell DB -c "SELECT count(*) FROM users;" --json
+  aerostack db shell DB --csv < report.sql > report.csv`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if asJSON && asCSV {
+				return fmt.Errorf("--json and --csv cannot be used together")
+			}
+			binding := ""
+			if len(args) == 1 {
+				binding = args[0]
+			}
+			output := shellOutputTable
+			if asJSON {
+				output = shellOutputJSON
+			} else if asCSV {
+				output = shellOutputCSV
+			}
+			return runDBShell(binding, remote, command, output)
+		},
+	}
+	cmd.Flags().StringVar(&remote, "remote", "", "Connect to a remote environment (staging, production or any [env.<name>])")
+	cmd.Flags().StringVarP(&command, "command", "c", "", "Run these statements and exit")
+	cmd.Flags().BoolVar(&asJSON, "json", false, "Print results as JSON arrays of row objects")
+	cmd.Flags().BoolVar(&asCSV, "csv", false, "Print results as CSV with a header row")
+	return cmd
+}
+
+func runDBShell(binding, remote, command, output string) error {
+	cfg, err := loadMigrateConfig(remote)
+	if err != nil {
+		return err
+	}
+	devserver.EnsureDefaultD1(cfg)
+	target, err := pickTarget(dbTargets(cfg, remote), binding)
+	if err != nil {
+		return err
+	}
+
+	var session *devserver.ShellSession
+	switch {
+	case target.dialect == devserver.DialectPostgres:
+		session, err = devserver.OpenShellPostgres(target.pg)
+	case remote == "":
+		session, err = devserver.OpenShellD1Local(target.d1)
+	default:
+		session, err = devserver.OpenShellD1Remote(target.d1)
+	}
+	if err != nil {
+		return err
+	}
+	defer session.Close()
+
+	sh := &dbShell{session: session, binding: target.binding, output: output, out: os.Stdout}
+	if command != "" {
+		return sh.runScript(strings.NewReader(command))
+	}
+	if info, err := os.Stdin.Stat(); err == nil && info.Mode()&os.ModeCharDevice == 0 {
+		return sh.runScript(os.Stdin)
+	}
+
+	printer.Step("Connected to %s (%s, %s env)", target.binding, dialectLabel(target.dialect), envLabel(remote))
+	printer.Hint(`End statements with ";". Type \? for help, \q to quit.`)
+	return sh.interactive()
+}
+
+// dbShell runs statements and meta-commands against one session.
+type dbShell struct {
+	session *devserver.ShellSession
+	binding string
+	output  string
+	out     io.Writer
+}
+
+// runScript runs every statement from r, stopping at the first error.
+func (sh *dbShell) runScript(r io.Reader) error {
+	var buf strings.Builder
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if buf.Len() == 0 && strings.HasPrefix(strings.TrimSpace(line), `\`) {
+			quit, err := sh.meta(strings.TrimSpace(line))
+			if err != nil || quit {
+				return err
+			}
+			continue
+		}
+		buf.WriteString(line)
+		buf.WriteByte('\n')
+		if devserver.StatementComplete(buf.String()) {
+			if err := sh.execute(buf.String()); err != nil {
+				return err
+			}
+			buf.Reset()
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	// A final statement may leave off its semicolon
+	if strings.TrimSpace(buf.String()) != "" {
+		return sh.execute(buf.String())
+	}
+	return nil
+}
+
+// interactive reads statements from the terminal until \q or Ctrl+D.
+func (sh *dbShell) interactive() error {
+	history := loadShellHistory()
+	var buf strings.Builder
+	for {
+		prompt := sh.binding + "=> "
+		if buf.Len() > 0 {
+			prompt = sh.binding + "-> "
+		}
+		line, err := readShellLine(prompt, history)
+		if errors.Is(err, errShellInterrupt) {
+			buf.Reset()
+			continue
+		}
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		trimmed := strings.TrimSpace(line)
+		if buf.Len() == 0 && trimmed == "" {
+			continue
+		}
+		if buf.Len() == 0 && strings.HasPrefix(trimmed, `\`) {
+			history = appendShellHistory(history, trimmed)
+			quit, err := sh.meta(trimmed)
+			if err != nil {
+				printer.Error("%v", err)
+			}
+			if quit {
+				return nil
+			}
+			continue
+		}
+		buf.WriteString(line)
+		buf.WriteByte('\n')
+		if !devserver.StatementComplete(buf.String()) {
+			continue
+		}
+		stmt := buf.String()
+		buf.Reset()
+		history = appendShellHistory(history, strings.Join(strings.Fields(stmt), " "))
+		if err := sh.execute(stmt); err != nil {
+			printer.Error("%v", err)
+		}
+	}
+}
+
+// execute runs each statement in buf and prints its result.
+func (sh *dbShell) execute(buf string) error {
+	for _, stmt := range devserver.SplitStatements(buf) {
+		result, err := sh.session.Run(stmt)
+		if err != nil {
+			return err
+		}
+		if err := sh.print(result); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// meta runs a backslash command and reports whether the shell should quit.
+func (sh *dbShell) meta(line string) (bool, error) {
+	name, arg, _ := strings.Cut(line, " ")
+	arg = strings.Trim(strings.TrimSpace(arg), `";`)
+	switch name {
+	case `\q`:
+		return true, nil
+	case `\?`:
+		fmt.Fprintln(sh.out, `  \dt          List tables
+  \d <table>   Describe a table
+  \q           Quit`)
+		return false, nil
+	case `\dt`, `\d`:
+		tables, err := sh.session.Tables()
+		if err != nil {
+			return false, fmt.Errorf("failed to introspect %s: %w", sh.binding, err)
+		}
+		if name == `\d` && arg != "" {
+			return false, sh.describe(tables, arg)
+		}
+		return false, sh.listTables(tables)
+	default:
+		return false, fmt.Errorf(`unknown command %s (try \?)`, name)
+	}
+}
+
+func (sh *dbShell) listTables(tables []devserver.TableSchema) error {
+	result := &devserver.QueryResult{Columns: []string{"table", "columns", "primary key"}}
+	for _, t := range tables {
+		name := t.Name
+		if t.Schema != "" && t.Schema != "public" {
+			name = t.Schema + "." + t.Name
+		}
+		var pk []string
+		for _, c := range t.Columns {
+			if c.IsPrimary {
+				pk = append(pk, c.Name)
+			}
+		}
+		result.Rows = append(result.Rows, []any{name, int64(len(t.Columns)), strings.Join(pk, ", ")})
+	}
+	return sh.print(result)
+}
+
+func (sh *dbShell) describe(tables []devserver.TableSchema, name string) error {
+	var table *devserver.TableSchema
+	for i, t := range tables {
+		if strings.EqualFold(t.Name, name) || strings.EqualFold(t.Schema+"."+t.Name, name) {
+			table = &tables[i]
+			break
+		}
+	}
+	if table == nil {
+		return fmt.Errorf("no table %q in %s", name, sh.binding)
+	}
+
+	columns := &devserver.QueryResult{Columns: []string{"column", "type", "nullable", "default", "key"}}
+	for _, c := range table.Columns {
+		key := ""
+		if c.IsPrimary {
+			key = "PK"
+		}
+		for _, fk := range table.ForeignKeys {
+			for i, col := range fk.Columns {
+				if col == c.Name && i < len(fk.RefColumns) {
+					key = strings.TrimSpace(key + " → " + fk.RefTable + "." + fk.RefColumns[i])
+				}
+			}
+		}
+		nullable := "no"
+		if c.IsNullable && !c.IsPrimary {
+			nullable = "yes"
+		}
+		columns.Rows = append(columns.Rows, []any{c.Name, c.SQLType, nullable, c.Default, key})
+	}
+	if err := sh.print(columns); err != nil || sh.output != shellOutputTable {
+		return err
+	}
+	if len(table.Indexes) > 0 {
+		indexes := &devserver.QueryResult{Columns: []string{"index", "columns", "unique"}}
+		for _, idx := range table.Indexes {
+			unique := "no"
+			if idx.Unique {
+				unique = "yes"
+			}
+			indexes.Rows = append(indexes.Rows, []any{idx.Name, strings.Join(idx.Columns, ", "), unique})
+		}
+		return sh.print(indexes)
+	}
+	return nil
+}
+
+// print writes a result as a table, JSON or CSV.
+func (sh *dbShell) print(result *devserver.QueryResult) error {
+	if result.Columns == nil {
+		switch sh.output {
+		case shellOutputJSON:
+			fmt.Fprintf(sh.out, "{\"rows_affected\":%d}\n", result.RowsAffected)
+		case shellOutputTable:
+			fmt.Fprintln(sh.out, printer.Muted(fmt.Sprintf("%d row(s) affected", result.RowsAffected)))
+		}
+		return nil
+	}
+
+	switch sh.output {
+	case shellOutputJSON:
+		return writeShellJSON(sh.out, result)
+	case shellOutputCSV:
+		w := csv.NewWriter(sh.out)
+		w.Write(result.Columns)
+		for _, row := range result.Rows {
+			record := make([]string, len(row))
+			for i, v := range row {
+				if v != nil {
+					record[i] = shellValue(v)
+				}
+			}
+			w.Write(record)
+		}
+		w.Flush()
+		return w.Error()
+	}
+
+	rows := make([][]string, len(result.Rows))
+	for i, row := range result.Rows {
+		rows[i] = make([]string, len(row))
+		for j, v := range row {
+			if v == nil {
+				rows[i][j] = printer.Muted("NULL")
+			} else {
+				rows[i][j] = shellValue(v)
+			}
+		}
+	}
+	fmt.Fprintln(sh.out, printer.Table(result.Columns, rows))
+	fmt.Fprintln(sh.out, printer.Muted(fmt.Sprintf("(%d row(s))", len(result.Rows))))
+	return nil
+}
+
+// writeShellJSON writes rows as an array of objects, keeping the query's column order.
+func writeShellJSON(w io.Writer, result *devserver.QueryResult) error {
+	var b strings.Builder
+	b.WriteString("[")
+	for i, row := range result.Rows {
+		if i > 0 {
+			b.WriteString(",")
+		}
+		b.WriteString("\n  {")
+		for j, col := range result.Columns {
+			if j > 0 {
+				b.WriteString(",")
+			}
+			key, _ := json.Marshal(col)
+			v := row[j]
+			switch t := v.(type) {
+			case []byte:
+				v = string(t)
+			case time.Time:
+				v = shellValue(t)
+			}
+			val, err := json.Marshal(v)
+			if err != nil {
+				return err
+			}
+			b.Write(key)
+			b.WriteString(":")
+			b.Write(val)
+		}
+		b.WriteString("}")
+	}
+	if len(result.Rows) > 0 {
+		b.WriteString("\n")
+	}
+	b.WriteString("]\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func shellValue(v any) string {
+	switch t := v.(type) {
+	case []byte:
+		return string(t)
+	case time.Time:
+		if t.Hour() == 0 && t.Minute() == 0 && t.Second() == 0 && t.Nanosecond() == 0 {
+			return t.Format("2006-01-02")
+		}
+		return t.Format("2006-01-02 15:04:05.999999Z07:00")
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+var errShellInterrupt = errors.New("interrupted")
+
+// shellPrompt is a one-line editor with history, run once per input line.
+type shellPrompt struct {
+	input   textinput.Model
+	history []string
+	pos     int    // index into history; len(history) is the line being typed
+	draft   string // the line being typed while browsing history
+	err     error
+	done    bool
+}
+
+func (m shellPrompt) Init() tea.Cmd { return textinput.Blink }
+
+func (m shellPrompt) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if key, ok := msg.(tea.KeyMsg); ok {
+		switch key.String() {
+		case "enter":
+			m.done = true
+			return m, tea.Quit
+		case "ctrl+c":
+			m.err, m.done = errShellInterrupt, true
+			return m, tea.Quit
+		case "ctrl+d":
+			if m.input.Value() == "" {
+				m.err, m.done = io.EOF, true
+				return m, tea.Quit
+			}
+		case "up":
+			if m.pos > 0 {
+				if m.pos == len(m.history) {
+					m.draft = m.input.Value()
+				}
+				m.pos--
+				m.input.SetValue(m.history[m.pos])
+				m.input.CursorEnd()
+			}
+			return m, nil
+		case "down":
+			if m.pos < len(m.history) {
+				m.pos++
+				if m.pos == len(m.history) {
+					m.input.SetValue(m.draft)
+				} else {
+					m.input.SetValue(m.history[m.pos])
+				}
+				m.input.CursorEnd()
+			}
+			return m, nil
+		}
+	}
+	var cmd tea.Cmd
+	m.input, cmd = m.input.Update(msg)
+	return m, cmd
+}
+
+func (m shellPrompt) View() string {
+	if m.done {
+		// Leave the entered line on screen without a cursor
+		if m.err != nil && !errors.Is(m.err, errShellInterrupt) {
+			return ""
+		}
+		return m.input.Prompt + m.input.Value() + "\n"
+	}
+	return m.input.View()
+}
+
+// readShellLine reads one line from the terminal.
+func readShellLine(prompt string, history []string) (string, error) {
+	input := textinput.New()
+	input.Prompt = prompt
+	input.PromptStyle = input.PromptStyle.Foreground(printer.BrandCyan)
+	input.Focus()
+	final, err := tea.NewProgram(shellPrompt{input: input, history: history, pos: len(history)}).Run()
+	if err != nil {
+		return "", err
+	}
+	m := final.(shellPrompt)
+	if m.err != nil {
+		return "", m.err
+	}
+	return m.input.Value(), nil
+}
+
+func shellHistoryPath() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".aerostack", "db_shell_history")
+}
+
+// loadShellHistory reads saved entries; a missing or unreadable file is an empty history.
+func loadShellHistory() []string {
+	path := shellHistoryPath()
+	if path == "" {
+		return nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	var history []string
+	for _, line := range strings.Split(string(data), "\n") {
+		if line != "" {
+			history = append(history, line)
+		}
+	}
+	return history
+}
+
+// appendShellHistory adds an entry, skipping repeats, and saves the most recent entries.
+func appendShellHistory(history []string, entry string) []string {
+	if len(history) > 0 && history[len(history)-1] == entry {
+		return history
+	}
+	history = append(history, entry)
+	if len(history) > shellHistoryLimit {
+		history = history[len(history)-shellHistoryLimit:]
+	}
+	if path := shellHistoryPath(); path != "" {
+		if err := os.MkdirAll(filepath.Dir(path), 0700); err == nil {
+			os.WriteFile(path, []byte(strings.Join(history, "\n")+"\n"), 0600)
+		}
+	}
+	return history
+}
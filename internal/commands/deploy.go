@@ -5,13 +5,13 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"strings"
 
 	"github.com/aerostackdev/cli/internal/agent"
 	"github.com/aerostackdev/cli/internal/api"
 	"github.com/aerostackdev/cli/internal/credentials"
+	"github.com/aerostackdev/cli/internal/deployments"
 	"github.com/aerostackdev/cli/internal/devserver"
 	"github.com/aerostackdev/cli/internal/link"
 	"github.com/aerostackdev/cli/internal/modules/deploy"
@@ -27,6 +27,7 @@ func NewDeployCommand() *cobra.Command {
 	var isPublic bool
 	var isPrivate bool
 	var syncSecrets bool
+	var dryRun bool
 
 	cmd := &cobra.Command{
 		Use:   "deploy [service-name]",
@@ -39,7 +40,8 @@ func NewDeployCommand() *cobra.Command {
 Examples:
   aerostack deploy --env staging
   aerostack deploy --env production
-  aerostack deploy --public`,
+  aerostack deploy --public
+  aerostack deploy --env production --dry-run`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			// Initialize Agent for Deploy logic
 			cwd, _ := os.Getwd()
@@ -67,7 +69,7 @@ Examples:
 			}
 
 			// 2. Actual Deploy Logic
-			if err := deployService(serviceName, environment, allServices, isPublic, isPrivate, syncSecrets); err != nil {
+			if err := deployService(serviceName, environment, allServices, isPublic, isPrivate, syncSecrets, dryRun); err != nil {
 				importStrings := true // just a flag to know we might need strings package
 				_ = importStrings
 
@@ -88,12 +90,13 @@ Examples:
 		},
 	}
 
-	cmd.Flags().StringVarP(&environment, "env", "e", "staging", "Target environment (staging/production)")
+	cmd.Flags().StringVarP(&environment, "env", "e", "staging", "Target environment (staging, production or any [env.<name>] in aerostack.toml)")
 	cmd.Flags().BoolVar(&allServices, "all", false, "Deploy all services")
 	// --cloudflare flag removed: all deploys go through Aerostack dispatch namespace
 	cmd.Flags().BoolVar(&isPublic, "public", false, "Make the deployed service publicly accessible")
 	cmd.Flags().BoolVar(&isPrivate, "private", false, "Make the deployed service private (requires authentication)")
 	cmd.Flags().BoolVar(&syncSecrets, "sync-secrets", false, "Push non-standard .dev.vars keys as secrets to the target environment before deploying")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Build and print a plan (binding changes, bundle size delta, missing secrets) without uploading")
 
 	// Subcommands
 	cmd.AddCommand(NewDeployMcpCommand())
@@ -102,12 +105,7 @@ Examples:
 	return cmd
 }
 
-func deployService(service, env string, all bool, isPublic bool, isPrivate bool, syncSecrets bool) error {
-	// Validate env
-	if env != "staging" && env != "production" {
-		return fmt.Errorf("invalid env %q: use staging or production", env)
-	}
-
+func deployService(service, env string, all bool, isPublic bool, isPrivate bool, syncSecrets bool, dryRun bool) error {
 	// 1. Check aerostack.toml
 	if _, err := os.Stat("aerostack.toml"); os.IsNotExist(err) {
 		return fmt.Errorf("aerostack deploy requires you to have an aerostack.toml file in your project")
@@ -118,20 +116,27 @@ func deployService(service, env string, all bool, isPublic bool, isPrivate bool,
 		return fmt.Errorf("cannot specify both --public and --private flags")
 	}
 
-	// 2. Check Node.js
-	if _, err := devserver.CheckNode(); err != nil {
-		return err
-	}
-
-	// 3. Parse config
+	// 2. Parse config before any other work so a bad aerostack.toml fails early
 	cfg, err := devserver.ParseAerostackToml("aerostack.toml")
 	if err != nil {
-		return fmt.Errorf("failed to parse config: %w", err)
+		return fmt.Errorf("failed to parse config:\n%w", err)
+	}
+	if err := cfg.ValidateEnv(env); err != nil {
+		return err
 	}
 	devserver.EnsureDefaultD1(cfg)
 
-	// Push .dev.vars secrets to the target environment before deploying (--sync-secrets)
+	// --dry-run: build and diff against the last recorded deploy, no login or upload
+	if dryRun {
+		return planDeploy(cfg, env, deployServiceName(cfg, service))
+	}
+
+	// Push .dev.vars secrets to the target environment before deploying (--sync-secrets).
+	// This is the only step that runs wrangler; the bundle and upload don't need Node.
 	if syncSecrets {
+		if _, err := devserver.CheckNode(); err != nil {
+			return err
+		}
 		if err := syncSecretsFromDevVars(env); err != nil {
 			printer.Warn("Failed to sync secrets: %v", err)
 		}
@@ -203,195 +208,33 @@ func deployService(service, env string, all bool, isPublic bool, isPrivate bool,
 	return deployToAerostack(cfg, env, cred.APIKey, projectID, service, isPublic, isPrivate)
 }
 
-func deployToAerostack(cfg *devserver.AerostackConfig, env string, apiKey string, projectID string, serviceName string, isPublic bool, isPrivate bool) error {
-	// If serviceName is empty (no CLI arg), use name from aerostack.toml
+// deployServiceName is the service a deploy targets: the CLI argument, else the name
+// from aerostack.toml, else "default".
+func deployServiceName(cfg *devserver.AerostackConfig, serviceName string) string {
 	if serviceName == "" {
 		serviceName = cfg.Name
 	}
 	if serviceName == "" {
 		serviceName = "default"
 	}
+	return serviceName
+}
 
-	fmt.Println()
-	printer.Step("Deploying to Aerostack (%s)...", env)
-
-	// Build worker with esbuild
-	mainEntry := cfg.Main
-	if mainEntry == "" {
-		mainEntry = "src/index.ts"
-	}
-
-	// Clean dist directory to avoid stale builds
-	os.RemoveAll("dist")
-	os.MkdirAll("dist", 0755)
-
-	// Check for nodejs_compat_v2
-	hasNodeCompatV2 := false
-	for _, flag := range cfg.CompatibilityFlags {
-		if flag == "nodejs_compat_v2" {
-			hasNodeCompatV2 = true
-			break
-		}
-	}
-
-	// Create node-mocks for both legacy and nodejs_compat_v2 paths if they load modules like express
-	mockDir := ".aerostack"
-	os.MkdirAll(mockDir, 0755)
-	mockPath := filepath.Join(mockDir, "node-mocks.cjs")
-	// Minimal mock: exports most things as empty objects or dummy functions
-	mockContent := `
-const noop = () => {};
-const emptyObj = {};
-const handler = {
-	get: (target, prop) => {
-		if (prop === 'prototype') return emptyObj;
-		if (prop === 'on' || prop === 'once' || prop === 'emit') return noop;
-		return proxy;
-	},
-	construct: () => proxy,
-	apply: () => proxy
-};
-const proxy = new Proxy(noop, handler);
-
-Object.assign(proxy, { 
-	isatty: () => false,
-	createServer: () => ({ listen: () => ({ on: () => {} }), on: () => {} }),
-	readFileSync: () => { throw new Error("fs.readFileSync is not supported in Workers. Use cache or bindings.") },
-	METHODS: ["GET", "POST", "PUT", "DELETE", "PATCH", "HEAD", "OPTIONS"],
-	STATUS_CODES: { 200: "OK", 404: "Not Found", 500: "Internal Server Error" },
-	IncomingMessage: proxy,
-	ServerResponse: proxy,
-	Agent: proxy,
-	Socket: proxy,
-	networkInterfaces: () => ({}),
-	arch: () => "arm64",
-	platform: () => "linux"
-});
-
-module.exports = proxy;
-`
-	os.WriteFile(mockPath, []byte(mockContent), 0644)
-
-	// esbuild requires relative paths to start with ./ or ../ or it treats them as bare modules
-	mockPathForEsbuild := "./" + filepath.ToSlash(filepath.Join(".aerostack", "node-mocks.cjs"))
-
-	args := []string{
-		"--yes",
-		"esbuild",
-		mainEntry,
-		"--bundle",
-		"--outfile=dist/worker.js",
-		"--format=esm",
-		"--alias:@shared=./shared",
-		"--minify",
-		"--external:node:*",
-		"--external:cloudflare:*",
-	}
-
-	// Add banner with createRequire shim and prototype fixes
-	banner := "import { createRequire } from 'node:module'; const require = createRequire('/'); if (typeof Buffer !== 'undefined' && !Buffer.prototype.hasOwnProperty) Buffer.prototype.hasOwnProperty = Object.prototype.hasOwnProperty;"
-	args = append(args, "--banner:js="+banner)
-
-	// Add aliases for Node.js modules
-	nodeModules := []string{"path", "url", "crypto", "events", "util", "stream", "buffer", "string_decoder", "assert", "timers", "async_hooks", "console", "querystring", "zlib", "punycode"}
-
-	if hasNodeCompatV2 {
-		for _, m := range nodeModules {
-			args = append(args, fmt.Sprintf("--alias:%s=node:%s", m, m))
-		}
-	} else {
-		// Legacy / Default
-		args = append(args, "--platform=node")
-		for _, m := range nodeModules {
-			args = append(args, fmt.Sprintf("--alias:%s=node:%s", m, m))
-		}
-	}
-
-	// Mock common unsupported modules
-	unsupported := []string{"fs", "os", "http", "https", "net", "dns", "tty", "tls", "child_process"}
-	for _, m := range unsupported {
-		args = append(args, fmt.Sprintf("--alias:%s=%s", m, mockPathForEsbuild))
-		args = append(args, fmt.Sprintf("--alias:node:%s=%s", m, mockPathForEsbuild))
-	}
+func deployToAerostack(cfg *devserver.AerostackConfig, env string, apiKey string, projectID string, serviceName string, isPublic bool, isPrivate bool) error {
+	serviceName = deployServiceName(cfg, serviceName)
 
-	printer.Step("Bundling %s...", mainEntry)
+	// Apply [env.<name>] overrides once; everything below sees the effective config.
+	cfg = cfg.ForEnv(env)
 
-	cmd := exec.Command("npx", args...)
-	cmd.Dir = "."
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
-	if err := cmd.Run(); err != nil {
-		return fmt.Errorf("build failed: %w", err)
-	}
+	fmt.Println()
+	printer.Step("Deploying to Aerostack (%s)...", env)
 
-	distPath := "dist"
-	files := make(map[string]string)
-	entries, err := os.ReadDir(distPath)
+	files, err := bundleForDeploy(cfg)
 	if err != nil {
-		return fmt.Errorf("failed to read dist directory: %w", err)
-	}
-
-	for _, entry := range entries {
-		if !entry.IsDir() {
-			name := entry.Name()
-			// We only upload .js, .mjs, .wasm, etc. as modules.
-			// Cloudflare Workers expect the main entry as worker.js.
-			localPath := filepath.Join(distPath, name)
-			if name == "worker.js" {
-				files["worker"] = localPath
-			} else {
-				// Upload other files as modules
-				files[name] = localPath
-			}
-		}
-	}
-
-	if _, ok := files["worker"]; !ok {
-		return fmt.Errorf("build output worker.js not found in %s", distPath)
-	}
-
-	// Prepare bindings JSON
-	// Combine default bindings with env overrides
-	d1s := cfg.D1Databases
-	if overrides, ok := cfg.EnvOverrides[env]; ok && len(overrides) > 0 {
-		d1s = overrides
-	}
-
-	// Filter out stub/local-only bindings that only exist for local dev
-	// (e.g. 'local-queue', 'local-kv') — these are not real Cloudflare resources
-	var realQueues []devserver.Queue
-	for _, q := range cfg.Queues {
-		if !strings.HasPrefix(q.Name, "local-") {
-			realQueues = append(realQueues, q)
-		}
-	}
-	var realKVNamespaces []devserver.KVNamespace
-	for _, ns := range cfg.KVNamespaces {
-		if !strings.HasPrefix(ns.ID, "local-") {
-			realKVNamespaces = append(realKVNamespaces, ns)
-		}
-	}
-	var realD1s []devserver.D1Database
-	for _, db := range d1s {
-		if db.DatabaseID != "aerostack-local" && !strings.HasPrefix(db.DatabaseID, "local-") {
-			realD1s = append(realD1s, db)
-		}
-	}
-
-	type BindingPayload struct {
-		D1Databases  []devserver.D1Database  `json:"d1_databases,omitempty"`
-		KVNamespaces []devserver.KVNamespace `json:"kv_namespaces,omitempty"`
-		Queues       []devserver.Queue       `json:"queues,omitempty"`
-		AI           bool                    `json:"ai,omitempty"`
-	}
-
-	bindingsPayload := BindingPayload{
-		D1Databases:  realD1s,
-		KVNamespaces: realKVNamespaces,
-		Queues:       realQueues,
-		AI:           cfg.AI,
+		return err
 	}
 
+	bindingsPayload, _ := buildBindingPayload(cfg)
 	bData, _ := json.Marshal(bindingsPayload)
 	bindingsJSON := string(bData)
 
@@ -400,9 +243,30 @@ module.exports = proxy;
 		return err
 	}
 
+	// Record the deploy locally (bundle hash, commit, bindings) for 'aerostack deployments'
+	bundleHash, _ := deployments.BundleHash(files)
+	bundleBytes, _ := deployments.BundleSize(files)
+	if err := deployments.Append(".", deployments.Record{
+		ID:          deployResp.DeploymentID,
+		Action:      deployments.ActionDeploy,
+		Env:         env,
+		Service:     serviceName,
+		ProjectID:   projectID,
+		URL:         deployResp.PublicURL,
+		BundleHash:  bundleHash,
+		BundleBytes: bundleBytes,
+		GitCommit:   deployments.GitCommit("."),
+		Bindings:    json.RawMessage(bData),
+	}); err != nil {
+		printer.Warn("Could not record deployment in %s: %v", deployments.Path("."), err)
+	}
+
 	fmt.Println()
 	printer.Success("Deployed to Aerostack!")
 	fmt.Println(printer.KeyVal("URL", deployResp.PublicURL))
+	if deployResp.DeploymentID != "" {
+		fmt.Println(printer.KeyVal("Deployment", deployResp.DeploymentID))
+	}
 	printSecretsReminder(cfg, env)
 
 	if deployResp.Project.Slug != "" {
@@ -431,6 +295,122 @@ module.exports = proxy;
 	return nil
 }
 
+// bundleForDeploy builds the worker into dist/ and returns the files to upload, keyed by
+// form field name ("worker" for the entry, file name for extra modules).
+func bundleForDeploy(cfg *devserver.AerostackConfig) (map[string]string, error) {
+	opts := deployBuildOptions(cfg)
+
+	// Clean dist directory to avoid stale builds
+	os.RemoveAll("dist")
+	os.MkdirAll("dist", 0755)
+
+	printer.Step("Bundling %s...", opts.Entry)
+	res, err := devserver.BuildWorker(cfg, opts)
+	if err != nil {
+		return nil, err
+	}
+	files, err := uploadFiles(res)
+	if err != nil {
+		return nil, err
+	}
+
+	// Enforce [build] max_bundle_kb so a dependency can't quietly push us past the Workers size limit
+	if cfg.MaxBundleKB > 0 {
+		size, err := deployments.BundleSize(files)
+		if err != nil {
+			return nil, err
+		}
+		if size > int64(cfg.MaxBundleKB)*1024 {
+			return nil, fmt.Errorf("bundle is %s, over the [build] max_bundle_kb budget of %d KB.\nRun 'aerostack build --analyze' to see what's taking up space", formatKB(size), cfg.MaxBundleKB)
+		}
+	}
+	return files, nil
+}
+
+// deployBuildOptions are the options deploy bundles with. 'aerostack build --analyze'
+// starts from them too, so its report describes the bundle deploy uploads.
+func deployBuildOptions(cfg *devserver.AerostackConfig) devserver.BuildOptions {
+	mainEntry := cfg.Main
+	if mainEntry == "" {
+		mainEntry = "src/index.ts"
+	}
+	return devserver.BuildOptions{
+		Entry:     mainEntry,
+		Outfile:   filepath.Join("dist", "worker.js"),
+		Minify:    true,
+		Sourcemap: true,
+		Metafile:  true,
+	}
+}
+
+// uploadFiles maps a build's outputs to deploy form fields. Cloudflare Workers expect the
+// main entry as "worker"; other outputs (chunks, .wasm) are uploaded as modules. Source
+// maps stay local.
+func uploadFiles(res *devserver.BuildResult) (map[string]string, error) {
+	files := make(map[string]string)
+	for _, path := range res.Files {
+		name := filepath.Base(path)
+		switch {
+		case path == res.Outfile:
+			files["worker"] = path
+		case strings.HasSuffix(name, ".map"):
+			continue
+		default:
+			files[name] = path
+		}
+	}
+	if _, ok := files["worker"]; !ok {
+		return nil, fmt.Errorf("build output %s not found", res.Outfile)
+	}
+	return files, nil
+}
+
+// BindingPayload is the bindings JSON sent with a deploy.
+type BindingPayload struct {
+	D1Databases  []devserver.D1Database  `json:"d1_databases,omitempty"`
+	KVNamespaces []devserver.KVNamespace `json:"kv_namespaces,omitempty"`
+	R2Buckets    []devserver.R2Bucket    `json:"r2_buckets,omitempty"`
+	Queues       []devserver.Queue       `json:"queues,omitempty"`
+	AI           bool                    `json:"ai,omitempty"`
+}
+
+// buildBindingPayload computes the bindings for a deploy from an env-resolved config.
+// Stub/local-only bindings (e.g. 'local-queue', 'local-kv', 'local-uploads') only exist for local dev and are
+// not real Cloudflare resources, so they're left out; their descriptions are returned as stripped.
+func buildBindingPayload(cfg *devserver.AerostackConfig) (BindingPayload, []string) {
+	payload := BindingPayload{AI: cfg.AI}
+	var stripped []string
+	for _, db := range cfg.D1Databases {
+		if db.DatabaseID != "aerostack-local" && !strings.HasPrefix(db.DatabaseID, "local-") {
+			payload.D1Databases = append(payload.D1Databases, db)
+		} else {
+			stripped = append(stripped, fmt.Sprintf("D1 %s (database_id %q)", db.Binding, db.DatabaseID))
+		}
+	}
+	for _, ns := range cfg.KVNamespaces {
+		if !strings.HasPrefix(ns.ID, "local-") {
+			payload.KVNamespaces = append(payload.KVNamespaces, ns)
+		} else {
+			stripped = append(stripped, fmt.Sprintf("KV %s (id %q)", ns.Binding, ns.ID))
+		}
+	}
+	for _, b := range cfg.R2Buckets {
+		if !strings.HasPrefix(b.BucketName, "local-") {
+			payload.R2Buckets = append(payload.R2Buckets, b)
+		} else {
+			stripped = append(stripped, fmt.Sprintf("R2 %s (bucket_name %q)", b.Binding, b.BucketName))
+		}
+	}
+	for _, q := range cfg.Queues {
+		if !strings.HasPrefix(q.Name, "local-") {
+			payload.Queues = append(payload.Queues, q)
+		} else {
+			stripped = append(stripped, fmt.Sprintf("Queue %s (queue %q)", q.Binding, q.Name))
+		}
+	}
+	return payload, stripped
+}
+
 // standardAerostackKeys are keys managed by the Aerostack platform and should not be
 // auto-pushed as user secrets.
 var standardAerostackKeys = map[string]bool{
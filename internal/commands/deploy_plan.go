@@ -0,0 +1,170 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/aerostackdev/cli/internal/deployments"
+	"github.com/aerostackdev/cli/internal/devserver"
+	"github.com/aerostackdev/cli/internal/printer"
+)
+
+// bindingKindLabels maps bindings JSON keys to the names used in plan output.
+var bindingKindLabels = map[string]string{
+	"d1_databases":  "D1",
+	"kv_namespaces": "KV",
+	"r2_buckets":    "R2",
+	"queues":        "Queue",
+	"ai":            "AI",
+}
+
+// planDeploy builds the bundle and bindings exactly like deployToAerostack, then prints what
+// a deploy of service to env would change compared to that service's last deploy recorded in
+// .aerostack/deployments.jsonl. Nothing is uploaded.
+func planDeploy(cfg *devserver.AerostackConfig, env, service string) error {
+	cfg = cfg.ForEnv(env)
+
+	fmt.Println()
+	printer.Step("Planning deploy to %s (dry run — nothing will be uploaded)...", env)
+
+	files, err := bundleForDeploy(cfg)
+	if err != nil {
+		return err
+	}
+	payload, stripped := buildBindingPayload(cfg)
+	bData, _ := json.Marshal(payload)
+
+	history, err := deployments.Load(".")
+	if err != nil {
+		printer.Warn("Could not read %s: %v", deployments.Path("."), err)
+	}
+	prev := deployments.LatestForService(history, env, service)
+
+	printer.Header(fmt.Sprintf("Deploy plan: %s → %s", service, env))
+
+	// 1. Bundle
+	size, err := deployments.BundleSize(files)
+	if err != nil {
+		return fmt.Errorf("failed to stat bundle: %w", err)
+	}
+	hash, _ := deployments.BundleHash(files)
+	fmt.Println(printer.KeyVal("Bundle", fmt.Sprintf("%s in %d file(s)", formatKB(size), len(files))))
+	if prev != nil && prev.BundleBytes > 0 {
+		delta := size - prev.BundleBytes
+		sign := "+"
+		if delta < 0 {
+			sign, delta = "-", -delta
+		}
+		fmt.Println(printer.KeyVal("Delta", fmt.Sprintf("%s%s vs %s", sign, formatKB(delta), describeRecord(prev))))
+	}
+	if prev != nil && prev.BundleHash == hash {
+		fmt.Println(printer.KeyVal("Code", "unchanged since last deploy"))
+	}
+	if prev == nil {
+		fmt.Println(printer.KeyVal("Previous", fmt.Sprintf("no deploy of %s to %s recorded in %s", service, env, deployments.Path("."))))
+	}
+
+	// 2. Bindings
+	fmt.Println()
+	printer.Step("Bindings")
+	var prevBindings json.RawMessage
+	if prev != nil {
+		prevBindings = prev.Bindings
+	}
+	changes, err := deployments.DiffBindings(prevBindings, bData)
+	if err != nil {
+		return err
+	}
+	if len(changes) == 0 {
+		fmt.Println("  No binding changes")
+	}
+	for _, c := range changes {
+		label := bindingKindLabels[c.Kind]
+		switch c.Op {
+		case deployments.OpAdd:
+			fmt.Printf("  %s %s %s  %s\n", printer.GlyphSuccess, label, c.Binding, c.Detail)
+		case deployments.OpRemove:
+			fmt.Printf("  %s %s %s  %s\n", printer.GlyphError, label, c.Binding, c.Detail)
+		case deployments.OpChange:
+			fmt.Printf("  %s %s %s  %s\n", printer.GlyphWarn, label, c.Binding, c.Detail)
+		}
+	}
+	if len(stripped) > 0 {
+		fmt.Println()
+		printer.Step("Local stub bindings (not deployed)")
+		for _, s := range stripped {
+			fmt.Printf("  • %s\n", s)
+		}
+		printer.Hint("Provision real resources with: %s", printer.Command("aerostack resources create --env "+env))
+	}
+
+	// 3. Secrets named in aerostack.toml (env = [...]) that the target env doesn't have
+	if len(cfg.EnvVars) > 0 {
+		fmt.Println()
+		printer.Step("Secrets")
+		remote, err := listRemoteSecrets(env)
+		if err != nil {
+			printer.Warn("Could not list remote secrets: %v", err)
+		} else {
+			missing := 0
+			for _, name := range cfg.EnvVars {
+				if !remote[name] {
+					missing++
+					fmt.Printf("  %s %s missing  →  %s\n", printer.GlyphError, name,
+						printer.Command(fmt.Sprintf("aerostack secrets set %s --env %s", name, env)))
+				}
+			}
+			if missing == 0 {
+				fmt.Printf("  %s all %d secret(s) are set\n", printer.GlyphSuccess, len(cfg.EnvVars))
+			}
+		}
+	}
+
+	fmt.Println()
+	printer.Hint("Run without --dry-run to deploy.")
+	return nil
+}
+
+// listRemoteSecrets returns the secret names set on the env's worker.
+func listRemoteSecrets(env string) (map[string]bool, error) {
+	if err := ensureWranglerToml(env); err != nil {
+		return nil, err
+	}
+	c := exec.Command("npx", "-y", "wrangler@latest", "secret", "list",
+		"--config", filepath.Join(".aerostack", "wrangler.toml"), "--env", env, "--format", "json")
+	c.Env = append(os.Environ(), "NPX_UPDATE_NOTIFIER=false")
+	out, err := c.Output()
+	if err != nil {
+		return nil, fmt.Errorf("wrangler secret list failed: %w", err)
+	}
+	var secrets []struct {
+		Name string `json:"name"`
+	}
+	if err := json.Unmarshal(out, &secrets); err != nil {
+		return nil, fmt.Errorf("unexpected wrangler output: %w", err)
+	}
+	names := make(map[string]bool, len(secrets))
+	for _, s := range secrets {
+		names[s.Name] = true
+	}
+	return names, nil
+}
+
+func describeRecord(r *deployments.Record) string {
+	parts := []string{r.CreatedAt.Local().Format("2006-01-02 15:04")}
+	if r.GitCommit != "" {
+		parts = append(parts, r.GitCommit)
+	}
+	if r.ID != "" {
+		parts = append(parts, r.ID)
+	}
+	return fmt.Sprintf("last deploy (%s)", strings.Join(parts, ", "))
+}
+
+func formatKB(n int64) string {
+	return fmt.Sprintf("%.1f KB", float64(n)/1024)
+}
@@ -0,0 +1,299 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/aerostackdev/cli/internal/api"
+	"github.com/aerostackdev/cli/internal/credentials"
+	"github.com/aerostackdev/cli/internal/deployments"
+	"github.com/aerostackdev/cli/internal/devserver"
+	"github.com/aerostackdev/cli/internal/link"
+	"github.com/aerostackdev/cli/internal/printer"
+	"github.com/spf13/cobra"
+)
+
+// NewDeploymentsCommand creates the 'aerostack deployments' command
+func NewDeploymentsCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "deployments",
+		Short: "List, roll back and promote deployments",
+		Long: `Inspect and manage deploys made with 'aerostack deploy'.
+
+Every deploy is also recorded in .aerostack/deployments.jsonl with its bundle hash,
+git commit, bindings and URL.
+
+Examples:
+  aerostack deployments list --env production
+  aerostack deployments rollback <deployment-id>
+  aerostack deployments promote --from staging --to production
+  aerostack deployments promote --service auth --from staging --to production`,
+	}
+
+	cmd.AddCommand(newDeploymentsListCommand())
+	cmd.AddCommand(newDeploymentsRollbackCommand())
+	cmd.AddCommand(newDeploymentsPromoteCommand())
+	return cmd
+}
+
+func newDeploymentsListCommand() *cobra.Command {
+	var env string
+	var localOnly bool
+
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List deployments for this project",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runDeploymentsList(env, localOnly)
+		},
+	}
+	cmd.Flags().StringVarP(&env, "env", "e", "", "Only show deployments to this environment")
+	cmd.Flags().BoolVar(&localOnly, "local", false, "Only read .aerostack/deployments.jsonl (no API call)")
+	return cmd
+}
+
+func newDeploymentsRollbackCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "rollback <deployment-id>",
+		Short: "Re-activate a previous deployment",
+		Long: `Re-activate a previous deployment in the environment it was deployed to.
+No rebuild happens: the exact bundle and bindings from that deploy are served again.
+Find IDs with 'aerostack deployments list'.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runDeploymentsRollback(args[0])
+		},
+	}
+}
+
+func newDeploymentsPromoteCommand() *cobra.Command {
+	var service, from, to string
+
+	cmd := &cobra.Command{
+		Use:   "promote",
+		Short: "Promote the active deployment from one environment to another",
+		Long: `Copy the active deployment of --from (same bundle and bindings) to --to,
+so production runs exactly what was tested in staging.
+
+Each [[services]] entry is its own worker; --service picks which one to promote
+(default: the project's main worker, as with 'aerostack deploy').`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runDeploymentsPromote(service, from, to)
+		},
+	}
+	cmd.Flags().StringVar(&service, "service", "", "Service to promote (default: the project's main worker)")
+	cmd.Flags().StringVar(&from, "from", "staging", "Environment to promote from")
+	cmd.Flags().StringVar(&to, "to", "production", "Environment to promote to")
+	return cmd
+}
+
+// resolveDeploymentsProject finds the API key and project the deployments commands act on,
+// using the same priority as deploy: project-scoped key, project_id in aerostack.toml, then
+// the linked project.
+func resolveDeploymentsProject() (apiKey, projectID string, err error) {
+	apiKey = credentials.GetAPIKey()
+	if apiKey == "" {
+		return "", "", fmt.Errorf("not logged in. Run 'aerostack login' first")
+	}
+	if v, err := api.Validate(apiKey); err == nil && v.KeyType == "project" && v.ProjectID != "" {
+		return apiKey, v.ProjectID, nil
+	}
+	if _, statErr := os.Stat("aerostack.toml"); statErr == nil {
+		cfg, err := devserver.ParseAerostackToml("aerostack.toml")
+		if err != nil {
+			return "", "", fmt.Errorf("failed to parse config:\n%w", err)
+		}
+		if cfg.ProjectID != "" {
+			return apiKey, cfg.ProjectID, nil
+		}
+	}
+	if projLink, _ := link.Load(); projLink != nil && projLink.ProjectID != "" {
+		return apiKey, projLink.ProjectID, nil
+	}
+	return "", "", fmt.Errorf("no project found. Run 'aerostack link' or 'aerostack deploy' first")
+}
+
+// promoteServiceName resolves --service the way deploy does: the flag, else the
+// project's main worker from aerostack.toml.
+func promoteServiceName(service string) (string, error) {
+	if service != "" {
+		return service, nil
+	}
+	cfg := &devserver.AerostackConfig{}
+	if _, err := os.Stat("aerostack.toml"); err == nil {
+		if cfg, err = devserver.ParseAerostackToml("aerostack.toml"); err != nil {
+			return "", fmt.Errorf("failed to parse config:\n%w", err)
+		}
+	}
+	return deployServiceName(cfg, ""), nil
+}
+
+// validateDeployEnv checks env against aerostack.toml when one is present.
+func validateDeployEnv(env string) error {
+	if _, err := os.Stat("aerostack.toml"); err != nil {
+		return nil
+	}
+	cfg, err := devserver.ParseAerostackToml("aerostack.toml")
+	if err != nil {
+		return fmt.Errorf("failed to parse config:\n%w", err)
+	}
+	return cfg.ValidateEnv(env)
+}
+
+func runDeploymentsList(env string, localOnly bool) error {
+	local, err := deployments.Load(".")
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", deployments.Path("."), err)
+	}
+
+	if !localOnly {
+		remote, err := listRemoteDeployments(env)
+		if err == nil {
+			printRemoteDeployments(remote, local)
+			return nil
+		}
+		printer.Warn("Could not fetch deployments from Aerostack: %v", err)
+		printer.Hint("Showing local history from %s", deployments.Path("."))
+		fmt.Println()
+	}
+
+	var filtered []deployments.Record
+	for _, r := range local {
+		if env == "" || r.Env == env {
+			filtered = append(filtered, r)
+		}
+	}
+	if len(filtered) == 0 {
+		fmt.Println("No deployments recorded yet. Deploy with: aerostack deploy")
+		return nil
+	}
+
+	fmt.Printf("%-14s %-12s %-10s %-17s %-14s %s\n", "ID", "ENV", "ACTION", "CREATED", "COMMIT", "URL")
+	for _, r := range filtered {
+		fmt.Printf("%-14s %-12s %-10s %-17s %-14s %s\n", orDefault(r.ID, "-"), r.Env, r.Action,
+			r.CreatedAt.Local().Format("2006-01-02 15:04"), orDefault(r.GitCommit, "-"), r.URL)
+	}
+	return nil
+}
+
+func listRemoteDeployments(env string) ([]api.Deployment, error) {
+	apiKey, projectID, err := resolveDeploymentsProject()
+	if err != nil {
+		return nil, err
+	}
+	return api.ListDeployments(apiKey, projectID, env)
+}
+
+func printRemoteDeployments(remote []api.Deployment, local []deployments.Record) {
+	if len(remote) == 0 {
+		fmt.Println("No deployments yet. Deploy with: aerostack deploy")
+		return
+	}
+
+	fmt.Printf("%-2s %-14s %-12s %-17s %-14s %s\n", "", "ID", "ENV", "CREATED", "COMMIT", "URL")
+	for _, d := range remote {
+		active := "  "
+		if d.Active {
+			active = "* "
+		}
+		// The API doesn't know the git commit; join it from the local history when available.
+		commit := "-"
+		if rec := deployments.Find(local, d.ID); rec != nil && rec.GitCommit != "" {
+			commit = rec.GitCommit
+		}
+		created := d.CreatedAt
+		if t, err := time.Parse(time.RFC3339, d.CreatedAt); err == nil {
+			created = t.Local().Format("2006-01-02 15:04")
+		}
+		fmt.Printf("%-2s %-14s %-12s %-17s %-14s %s\n", active, d.ID, d.Env, created, commit, d.URL)
+	}
+	fmt.Println("\n* = active in its environment")
+}
+
+func runDeploymentsRollback(deploymentID string) error {
+	apiKey, projectID, err := resolveDeploymentsProject()
+	if err != nil {
+		return err
+	}
+
+	printer.Step("Rolling back to deployment %s...", deploymentID)
+	resp, err := api.RollbackDeployment(apiKey, projectID, deploymentID)
+	if err != nil {
+		return err
+	}
+
+	// Carry the bundle hash and commit forward from the original deploy when we have it
+	rec := deployments.Record{
+		ID:        resp.DeploymentID,
+		Action:    deployments.ActionRollback,
+		Env:       resp.Env,
+		ProjectID: projectID,
+		URL:       resp.PublicURL,
+		Source:    deploymentID,
+	}
+	if local, _ := deployments.Load("."); local != nil {
+		if orig := deployments.Find(local, deploymentID); orig != nil {
+			rec.Service, rec.BundleHash, rec.GitCommit, rec.Bindings = orig.Service, orig.BundleHash, orig.GitCommit, orig.Bindings
+		}
+	}
+	if err := deployments.Append(".", rec); err != nil {
+		printer.Warn("Could not record rollback in %s: %v", deployments.Path("."), err)
+	}
+
+	printer.Success("Rolled back %s to %s", orDefault(resp.Env, "environment"), deploymentID)
+	fmt.Println(printer.KeyVal("URL", resp.PublicURL))
+	return nil
+}
+
+func runDeploymentsPromote(service, from, to string) error {
+	if from == to {
+		return fmt.Errorf("--from and --to must be different environments")
+	}
+	for _, env := range []string{from, to} {
+		if err := validateDeployEnv(env); err != nil {
+			return err
+		}
+	}
+
+	apiKey, projectID, err := resolveDeploymentsProject()
+	if err != nil {
+		return err
+	}
+
+	service, err = promoteServiceName(service)
+	if err != nil {
+		return err
+	}
+
+	printer.Step("Promoting %s: %s → %s...", service, from, to)
+	resp, err := api.PromoteDeployment(apiKey, projectID, service, from, to)
+	if err != nil {
+		return err
+	}
+
+	rec := deployments.Record{
+		ID:        resp.DeploymentID,
+		Action:    deployments.ActionPromote,
+		Env:       to,
+		ProjectID: projectID,
+		Service:   service,
+		URL:       resp.PublicURL,
+		Source:    from,
+	}
+	if local, _ := deployments.Load("."); local != nil {
+		if src := deployments.LatestForService(local, from, service); src != nil {
+			rec.BundleHash, rec.GitCommit, rec.Bindings = src.BundleHash, src.GitCommit, src.Bindings
+		}
+	}
+	if err := deployments.Append(".", rec); err != nil {
+		printer.Warn("Could not record promote in %s: %v", deployments.Path("."), err)
+	}
+
+	printer.Success("Promoted %s from %s to %s", service, from, to)
+	fmt.Println(printer.KeyVal("URL", resp.PublicURL))
+	if resp.DeploymentID != "" {
+		fmt.Println(printer.KeyVal("Deployment", resp.DeploymentID))
+	}
+	return nil
+}
@@ -2,11 +2,18 @@ package commands
 
 import (
 	"fmt"
+	"io"
+	"maps"
 	"os"
 	"os/exec"
 	"os/signal"
 	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"syscall"
+	"time"
 
 	"github.com/aerostackdev/cli/internal/devserver"
 	"github.com/spf13/cobra"
@@ -16,6 +23,8 @@ import (
 func NewDevCommand() *cobra.Command {
 	var port int
 	var remote string
+	var logOpts devserver.LogMuxOptions
+	var saveLogs, neonBranch bool
 
 	cmd := &cobra.Command{
 		Use:   "dev",
@@ -27,47 +36,87 @@ What Aerostack dev gives you (vs raw npx wrangler dev):
   • Auto D1 binding — blank projects get env.DB by default
   • --remote staging — debug with real staging data
   • Same stack as deploy — init, dev, deploy share one config
+  • Live config — editing aerostack.toml or .dev.vars restarts only the affected workers
+  • Service bindings — [[services.bindings]] between workers resolve locally, so
+    env.AUTH.fetch() behaves like production
 
 Requires Node.js 18+.
 
 Example:
   aerostack dev                    # Start local dev server (default port 8788)
   aerostack dev --port 8787        # Use custom port
-  aerostack dev --remote           # Use real Cloudflare bindings`,
+  aerostack dev --remote           # Use real Cloudflare bindings
+  aerostack dev --filter auth      # Only show logs from the auth service
+  aerostack dev --neon-branch      # Postgres on the Neon branch preview/<git branch>
+  aerostack dev --json | jq .      # Structured logs, one JSON object per line`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return startDevServer(port, remote)
+			if saveLogs {
+				logOpts.LogDir = devserver.LogDir
+			}
+			return startDevServer(port, remote, neonBranch, logOpts)
 		},
 	}
 
 	cmd.Flags().IntVarP(&port, "port", "p", 8788, "Port for the dev server (default: 8788)")
-	cmd.Flags().StringVar(&remote, "remote", "", "Connect to remote environment (staging/production)")
+	cmd.Flags().StringVar(&remote, "remote", "", "Connect to remote environment (staging, production or any [env.<name>])")
+	cmd.Flags().StringSliceVar(&logOpts.Filter, "filter", nil, "Only show logs from these workers (main or a [[services]] name; repeatable)")
+	cmd.Flags().BoolVar(&saveLogs, "save-logs", false, "Also write each worker's logs to .aerostack/logs/<worker>.log")
+	cmd.Flags().BoolVar(&logOpts.JSON, "json", false, "Print worker logs as JSON lines on stdout (status messages go to stderr)")
+	cmd.Flags().BoolVar(&neonBranch, "neon-branch", false, "Point Postgres bindings with a Neon project at the branch preview/<git branch>, creating it if needed")
 
 	return cmd
 }
 
-func startDevServer(port int, remote string) error {
-	fmt.Println("┌─────────────────────────────────────────────────────────┐")
-	fmt.Println("│  Aerostack dev  —  One config, D1 included, ready to go  │")
-	fmt.Println("└─────────────────────────────────────────────────────────┘")
-	fmt.Printf("\n🔧 Starting on http://localhost:%d\n", port)
+func startDevServer(port int, remote string, neonBranch bool, logOpts devserver.LogMuxOptions) error {
+	// With --json, stdout carries only log lines so it can be piped; status goes to stderr
+	var out io.Writer = os.Stdout
+	if logOpts.JSON {
+		out = os.Stderr
+	}
+
+	fmt.Fprintln(out, "┌─────────────────────────────────────────────────────────┐")
+	fmt.Fprintln(out, "│  Aerostack dev  —  One config, D1 included, ready to go  │")
+	fmt.Fprintln(out, "└─────────────────────────────────────────────────────────┘")
+	fmt.Fprintf(out, "\n🔧 Starting on http://localhost:%d\n", port)
 
 	// 1. Check for aerostack.toml (fallback to wrangler.toml)
 	configPath := "aerostack.toml"
 	if _, err := os.Stat(configPath); os.IsNotExist(err) {
 		if _, err := os.Stat("wrangler.toml"); err == nil {
 			configPath = "wrangler.toml"
-			fmt.Println("⚠️  aerostack.toml not found. Falling back to wrangler.toml")
+			fmt.Fprintln(out, "⚠️  aerostack.toml not found. Falling back to wrangler.toml")
 		} else {
 			return fmt.Errorf("aerostack.toml not found. Run 'aerostack init' first")
 		}
 	}
 
-	// 2. Check Node.js (required for D1 via Wrangler/Miniflare)
+	// 2. Parse config up front so a bad aerostack.toml fails before anything is started.
+	// With --neon-branch, Postgres bindings are first pointed at the git branch's Neon branch.
+	var neonConns map[string]string
+	if neonBranch {
+		if remote != "" {
+			return fmt.Errorf("--neon-branch is for local dev and cannot be combined with --remote")
+		}
+		raw, err := devserver.ParseAerostackToml(configPath)
+		if err != nil {
+			return fmt.Errorf("failed to parse %s:\n%w", configPath, err)
+		}
+		neonConns, err = neonPreviewConnections(raw, func(format string, args ...any) { fmt.Fprintf(out, format, args...) })
+		if err != nil {
+			return err
+		}
+	}
+	cfg, err := loadDevConfig(configPath, remote, neonConns)
+	if err != nil {
+		return err
+	}
+
+	// 2a. Check Node.js (required for D1 via Wrangler/Miniflare)
 	nodeVersion, err := devserver.CheckNode()
 	if err != nil {
 		return err
 	}
-	fmt.Printf("✓ Node.js %s\n", nodeVersion)
+	fmt.Fprintf(out, "✓ Node.js %s\n", nodeVersion)
 
 	// 2b. Pre-flight: make sure the target port is free.
 	// Wrangler hangs silently if the port is occupied — fail fast instead.
@@ -75,49 +124,16 @@ func startDevServer(port int, remote string) error {
 		return fmt.Errorf("cannot start dev server: %w", err)
 	}
 
-	// 3. Parse config and generate wrangler.toml
-	cfg, err := devserver.ParseAerostackToml(configPath)
-	if err != nil {
-		return fmt.Errorf("failed to parse %s: %w", configPath, err)
-	}
-
-	// Ensure at least one D1 binding for local dev (blank template may not have it)
-	devserver.EnsureDefaultD1(cfg)
-	// Ensure CACHE KV binding (required by SDK)
-	devserver.EnsureDefaultKV(cfg)
-	// Ensure QUEUE binding (required by SDK)
-	devserver.EnsureDefaultQueues(cfg)
-	// Ensure AI binding
-	devserver.EnsureDefaultAI(cfg)
-
-	// Validate Postgres connection strings
-	for _, pg := range cfg.PostgresDatabases {
-		if err := devserver.ValidatePostgresConnectionString(pg.ConnectionString); err != nil {
-			return fmt.Errorf("invalid Postgres connection for binding '%s': %w", pg.Binding, err)
-		}
-	}
-
 	// 4. Initialize .aerostack directory for local state
-	dotAerostack := ".aerostack"
-	if err := os.MkdirAll(dotAerostack, 0755); err != nil {
+	if err := os.MkdirAll(devStateDir, 0755); err != nil {
 		return fmt.Errorf("failed to create .aerostack directory: %w", err)
 	}
 
 	// 5. Generate wrangler.toml inside .aerostack/ (and per-service configs for multi-worker)
 	// This keeps the project root clean — users only see aerostack.toml
-	wranglerPath := filepath.Join(dotAerostack, "wrangler.toml")
-	if err := devserver.GenerateWranglerToml(cfg, wranglerPath); err != nil {
-		return fmt.Errorf("failed to generate wrangler config: %w", err)
-	}
-
-	// Multi-worker: generate configs for each service
-	workerConfigs := []struct{ name, path string }{{name: "main", path: wranglerPath}}
-	for _, svc := range cfg.Services {
-		svcPath := filepath.Join(dotAerostack, "wrangler-"+svc.Name+".toml")
-		if err := devserver.GenerateWranglerTomlForService(cfg, svc, svcPath); err != nil {
-			return fmt.Errorf("failed to generate wrangler config for %s: %w", svc.Name, err)
-		}
-		workerConfigs = append(workerConfigs, struct{ name, path string }{svc.Name, svcPath})
+	workers, err := devserver.GenerateDevWorkers(cfg, devStateDir, port)
+	if err != nil {
+		return err
 	}
 
 	// Show database configuration
@@ -128,59 +144,64 @@ func startDevServer(port int, remote string) error {
 	if len(cfg.Services) > 0 {
 		dbMsg += fmt.Sprintf(", Services: %d", len(cfg.Services)+1)
 	}
-	fmt.Printf("📄 Generated %s (%s)\n", wranglerPath, dbMsg)
+	fmt.Fprintf(out, "📄 Generated %s (%s)\n", workers[0].ConfigPath, dbMsg)
 
-	// Wrangler loads .dev.vars from the same dir as wrangler.toml (.aerostack/).
-	// Copy project root .dev.vars to .aerostack/.dev.vars so secrets reach the worker.
-	if rootVars, err := os.Stat(".dev.vars"); err == nil && rootVars.Mode().IsRegular() {
-		destPath := filepath.Join(dotAerostack, ".dev.vars")
-		if rootData, err := os.ReadFile(".dev.vars"); err == nil {
-			if err := os.WriteFile(destPath, rootData, 0600); err == nil {
-				fmt.Println("🔐 Loaded .dev.vars for local secrets")
-			}
-		}
+	if copyDevVars() {
+		fmt.Fprintln(out, "🔐 Loaded .dev.vars for local secrets")
 	}
 
 	if remote != "" {
-		fmt.Printf("🌐 Connected to remote environment: %s\n", remote)
+		fmt.Fprintf(out, "🌐 Connected to remote environment: %s\n", remote)
 	}
 
-	// 6. Build Hyperdrive env vars for local Postgres
-	hyperdriveEnv := make(map[string]string)
-	if remote == "" {
-		for _, pg := range cfg.PostgresDatabases {
-			envKey := "CLOUDFLARE_HYPERDRIVE_LOCAL_CONNECTION_STRING_" + pg.Binding
-			hyperdriveEnv[envKey] = pg.ConnectionString
+	// Worker output is multiplexed: one line at a time, prefixed with the worker's name
+	names := make([]string, len(workers))
+	for i, w := range workers {
+		names[i] = w.Name
+	}
+	for _, f := range logOpts.Filter {
+		if !slices.Contains(names, f) {
+			return fmt.Errorf("--filter %q does not match a worker (available: %s)", f, strings.Join(names, ", "))
 		}
 	}
+	logs := devserver.NewLogMux(os.Stdout, logOpts)
+	logs.Register(names...)
+	defer logs.Close()
+	if logOpts.LogDir != "" {
+		fmt.Fprintf(out, "📝 Writing worker logs to %s\n", logOpts.LogDir)
+	}
 
-	// 7. Run wrangler dev (single or multi-worker)
-	var cmds []*exec.Cmd
-	exitChan := make(chan error, len(workerConfigs))
-	for i, wc := range workerConfigs {
-		p := port + i
-		cmd, err := devserver.RunWranglerDev(wc.path, p, remote, hyperdriveEnv)
-		if err != nil {
-			// Kill any already started
-			for _, c := range cmds {
-				if c != nil && c.Process != nil {
-					devserver.KillProcessGroup(c.Process)
-				}
-			}
+	// 6-7. Run wrangler dev (single or multi-worker) with Hyperdrive env vars for local Postgres
+	session := &devSession{
+		configPath:    configPath,
+		remote:        remote,
+		port:          port,
+		neonConns:     neonConns,
+		hyperdriveEnv: hyperdriveEnvFor(cfg, remote),
+		out:           out,
+		logs:          logs,
+		procs:         map[string]*devProcess{},
+		exitChan:      make(chan error, 1),
+	}
+	for _, w := range workers {
+		if err := session.start(w); err != nil {
+			session.stopAll()
 			return err
 		}
-		cmds = append(cmds, cmd)
-		fmt.Printf("   [%s] http://localhost:%d\n", wc.name, p)
+	}
 
-		// Monitor process exit
-		go func(c *exec.Cmd, name string) {
-			err := c.Wait()
-			exitChan <- fmt.Errorf("worker [%s] exited: %v", name, err)
-		}(cmd, wc.name)
+	// Regenerate and restart affected workers when the config or local secrets change
+	watcher, err := devserver.NewConfigWatcher(configPath, ".dev.vars")
+	if err != nil {
+		fmt.Fprintf(out, "⚠️  Config watching disabled: %v\n", err)
+	} else {
+		watcher.Start(session.reload)
+		defer watcher.Close()
 	}
 
-	fmt.Println("\n✅ Dev server ready!")
-	fmt.Println("   Press Ctrl+C to stop")
+	fmt.Fprintln(out, "\n✅ Dev server ready!")
+	fmt.Fprintf(out, "   Watching %s and .dev.vars for changes\n", configPath)
+	fmt.Fprintln(out, "   Press Ctrl+C to stop")
 
 	// Wait for interrupt signal OR process exit
 	sigChan := make(chan os.Signal, 1)
@@ -188,15 +209,234 @@ func startDevServer(port int, remote string) error {
 
 	select {
 	case s := <-sigChan:
-		fmt.Printf("\n👋 Received %v. Shutting down dev server...\n", s)
-	case err := <-exitChan:
-		fmt.Printf("\n❌ %v\n", err)
+		fmt.Fprintf(out, "\n👋 Received %v. Shutting down dev server...\n", s)
+	case err := <-session.exitChan:
+		fmt.Fprintf(out, "\n❌ %v\n", err)
+	}
+
+	session.stopAll()
+	return nil
+}
+
+// devStateDir holds the generated wrangler configs and the .dev.vars copy.
+const devStateDir = ".aerostack"
+
+// devRestartGrace is how long a worker gets to exit after SIGTERM before it is killed.
+const devRestartGrace = 5 * time.Second
+
+// loadDevConfig parses the project config and applies the local dev defaults. Postgres
+// bindings in neonConns use that connection string instead of the configured one.
+func loadDevConfig(configPath, remote string, neonConns map[string]string) (*devserver.AerostackConfig, error) {
+	cfg, err := devserver.ParseAerostackToml(configPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s:\n%w", configPath, err)
+	}
+	if remote != "" {
+		if err := cfg.ValidateEnv(remote); err != nil {
+			return nil, err
+		}
+	}
+
+	// 3. Apply defaults
+	// Ensure at least one D1 binding for local dev (blank template may not have it)
+	devserver.EnsureDefaultD1(cfg)
+	// Ensure CACHE KV binding (required by SDK)
+	devserver.EnsureDefaultKV(cfg)
+	// Ensure QUEUE binding (required by SDK)
+	devserver.EnsureDefaultQueues(cfg)
+	// Ensure AI binding
+	devserver.EnsureDefaultAI(cfg)
+
+	// Validate Postgres connection strings
+	for i, pg := range cfg.PostgresDatabases {
+		if connStr, ok := neonConns[pg.Binding]; ok {
+			cfg.PostgresDatabases[i].ConnectionString = connStr
+			continue
+		}
+		if err := devserver.ValidatePostgresConnectionString(pg.ConnectionString); err != nil {
+			return nil, fmt.Errorf("invalid Postgres connection for binding '%s': %w", pg.Binding, err)
+		}
+	}
+	return cfg, nil
+}
+
+// copyDevVars mirrors the project root .dev.vars into .aerostack/, since wrangler loads it
+// from the same dir as wrangler.toml. A stale copy is removed when the root file is gone.
+func copyDevVars() bool {
+	destPath := filepath.Join(devStateDir, ".dev.vars")
+	rootVars, err := os.Stat(".dev.vars")
+	if err != nil || !rootVars.Mode().IsRegular() {
+		_ = os.Remove(destPath)
+		return false
+	}
+	rootData, err := os.ReadFile(".dev.vars")
+	if err != nil {
+		return false
 	}
+	return os.WriteFile(destPath, rootData, 0600) == nil
+}
 
-	for _, c := range cmds {
-		if c != nil && c.Process != nil {
-			devserver.KillProcessGroup(c.Process)
+// hyperdriveEnvFor builds the Hyperdrive env vars that point local Postgres bindings at their connection strings.
+func hyperdriveEnvFor(cfg *devserver.AerostackConfig, remote string) map[string]string {
+	hyperdriveEnv := make(map[string]string)
+	if remote == "" {
+		for _, pg := range cfg.PostgresDatabases {
+			envKey := "CLOUDFLARE_HYPERDRIVE_LOCAL_CONNECTION_STRING_" + pg.Binding
+			hyperdriveEnv[envKey] = pg.ConnectionString
 		}
 	}
+	return hyperdriveEnv
+}
+
+// devProcess is a running wrangler dev for one worker.
+type devProcess struct {
+	worker devserver.DevWorker
+	cmd    *exec.Cmd
+	// stopping marks an intentional stop so the exit isn't reported as a crash.
+	stopping atomic.Bool
+	done     chan struct{}
+}
+
+// devSession owns the multi-worker set for 'aerostack dev' and reconciles it on config changes.
+type devSession struct {
+	configPath    string
+	remote        string
+	port          int
+	neonConns     map[string]string // --neon-branch connection strings by Postgres binding
+	hyperdriveEnv map[string]string
+
+	// out receives status messages; worker output goes through logs.
+	out  io.Writer
+	logs *devserver.LogMux
+
+	mu       sync.Mutex
+	procs    map[string]*devProcess
+	closed   bool
+	exitChan chan error
+}
+
+func (s *devSession) start(w devserver.DevWorker) error {
+	stdout, stderr := s.logs.Writer(w.Name, "stdout"), s.logs.Writer(w.Name, "stderr")
+	cmd, err := devserver.RunWranglerDev(w.ConfigPath, w.Port, s.remote, s.hyperdriveEnv, stdout, stderr)
+	if err != nil {
+		return err
+	}
+	p := &devProcess{worker: w, cmd: cmd, done: make(chan struct{})}
+	s.procs[w.Name] = p
+	fmt.Fprintf(s.out, "   [%s] http://localhost:%d\n", w.Name, w.Port)
+
+	// Monitor process exit
+	go func() {
+		err := cmd.Wait()
+		stdout.Close()
+		stderr.Close()
+		close(p.done)
+		if p.stopping.Load() {
+			return
+		}
+		select {
+		case s.exitChan <- fmt.Errorf("worker [%s] exited: %v", w.Name, err):
+		default:
+		}
+	}()
 	return nil
 }
+
+// stop terminates a worker gracefully, killing its process group if it outlives devRestartGrace.
+func (s *devSession) stop(name string) {
+	p, ok := s.procs[name]
+	if !ok {
+		return
+	}
+	delete(s.procs, name)
+	p.stopping.Store(true)
+	devserver.TerminateProcessGroup(p.cmd.Process)
+	select {
+	case <-p.done:
+	case <-time.After(devRestartGrace):
+		devserver.KillProcessGroup(p.cmd.Process)
+		<-p.done
+	}
+}
+
+func (s *devSession) stopAll() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = true
+	for _, p := range s.procs {
+		p.stopping.Store(true)
+		devserver.KillProcessGroup(p.cmd.Process)
+	}
+	// Let the output pipes drain so the last lines reach the terminal and log files
+	for _, p := range s.procs {
+		select {
+		case <-p.done:
+		case <-time.After(devRestartGrace):
+		}
+	}
+}
+
+// reload regenerates the wrangler configs after aerostack.toml or .dev.vars changed and
+// restarts only the workers whose config changed. A config that fails to parse leaves the
+// running workers untouched.
+func (s *devSession) reload(changed []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return
+	}
+	fmt.Fprintf(s.out, "\n🔄 %s changed, reloading...\n", strings.Join(changed, ", "))
+
+	cfg, err := loadDevConfig(s.configPath, s.remote, s.neonConns)
+	if err != nil {
+		fmt.Fprintf(s.out, "❌ %v\n   Keeping the running workers until the config is fixed.\n", err)
+		return
+	}
+	workers, err := devserver.GenerateDevWorkers(cfg, devStateDir, s.port)
+	if err != nil {
+		fmt.Fprintf(s.out, "❌ %v\n", err)
+		return
+	}
+
+	// .dev.vars and the Hyperdrive env are shared by every worker
+	restartAll := false
+	for _, f := range changed {
+		if filepath.Base(f) == ".dev.vars" {
+			copyDevVars()
+			restartAll = true
+		}
+	}
+	if env := hyperdriveEnvFor(cfg, s.remote); !maps.Equal(env, s.hyperdriveEnv) {
+		s.hyperdriveEnv = env
+		restartAll = true
+	}
+
+	running := make([]devserver.DevWorker, 0, len(s.procs))
+	for _, p := range s.procs {
+		running = append(running, p.worker)
+	}
+	changes := devserver.DiffDevWorkers(running, workers, restartAll)
+	if changes.Empty() {
+		fmt.Fprintln(s.out, "   No worker config changed")
+		return
+	}
+
+	// Stop everything first so a worker moving ports doesn't collide with another
+	for _, w := range changes.Stop {
+		fmt.Fprintf(s.out, "   [%s] stopped\n", w.Name)
+		s.stop(w.Name)
+	}
+	for _, w := range changes.Restart {
+		s.stop(w.Name)
+	}
+	for _, w := range append(changes.Restart, changes.Start...) {
+		if err := devserver.CheckPortAvailable(w.Port); err != nil {
+			fmt.Fprintf(s.out, "❌ [%s] %v\n", w.Name, err)
+			continue
+		}
+		if err := s.start(w); err != nil {
+			fmt.Fprintf(s.out, "❌ [%s] failed to start: %v\n", w.Name, err)
+		}
+	}
+	fmt.Fprintf(s.out, "✅ Reloaded (%d restarted, %d started, %d stopped)\n", len(changes.Restart), len(changes.Start), len(changes.Stop))
+}
@@ -0,0 +1,174 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/aerostackdev/cli/internal/devserver"
+	"github.com/aerostackdev/cli/internal/printer"
+	"github.com/spf13/cobra"
+)
+
+// Files 'aerostack eject' writes, relative to the project root.
+var (
+	ejectBuildScript = filepath.Join("scripts", "build.mjs")
+	ejectNodeMocks   = filepath.Join("scripts", "node-mocks.cjs")
+)
+
+// NewEjectCommand creates the 'aerostack eject' command
+func NewEjectCommand() *cobra.Command {
+	var force bool
+	cmd := &cobra.Command{
+		Use:   "eject",
+		Short: "Write a standalone wrangler project from aerostack.toml",
+		Long: `The reverse of 'aerostack migrate': writes a wrangler.toml at the project root that
+builds and deploys with wrangler alone.
+
+  wrangler.toml            every [env.<name>] section with its real resource IDs
+  wrangler.<service>.toml  one per [[services]] worker
+  scripts/build.mjs        the esbuild bundle step 'aerostack build' ran (wrangler's [build])
+  scripts/node-mocks.cjs   the stub for Node built-ins Workers can't run
+
+Local-only stub bindings (database_id "aerostack-local", "local-*" KV, R2 and queues) are
+left out, and Postgres bindings become [[hyperdrive]] templates to fill in. aerostack.toml
+is not modified; delete it once the wrangler project works.
+
+Example:
+  aerostack eject
+  npm install --save-dev wrangler esbuild
+  npx wrangler deploy --env production`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return ejectProject(force)
+		},
+	}
+	cmd.Flags().BoolVar(&force, "force", false, "Overwrite an existing wrangler.toml and build script")
+	return cmd
+}
+
+func ejectProject(force bool) error {
+	cfg, err := devserver.ParseAerostackToml("aerostack.toml")
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("no aerostack.toml in this directory")
+		}
+		return fmt.Errorf("failed to parse config:\n%w", err)
+	}
+
+	files := []string{"wrangler.toml", ejectBuildScript, ejectNodeMocks}
+	for _, svc := range cfg.Services {
+		files = append(files, ejectServiceToml(svc))
+	}
+	if !force {
+		for _, f := range files {
+			if _, err := os.Stat(f); err == nil {
+				return fmt.Errorf("%s already exists; pass --force to overwrite it", f)
+			}
+		}
+	}
+
+	stripped := stripEjectStubs(cfg)
+
+	workers := []devserver.BuildScriptWorker{{Entry: cfg.Main, Outfile: "dist/worker.js"}}
+	for _, svc := range cfg.Services {
+		workers = append(workers, devserver.BuildScriptWorker{Entry: svc.Main, Outfile: devserver.ServiceOutfile(svc)})
+	}
+	script, err := devserver.GenerateBuildScript(cfg, workers, ejectNodeMocks)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(ejectBuildScript), 0755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(ejectBuildScript, []byte(script), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", ejectBuildScript, err)
+	}
+	if err := devserver.WriteNodeMocks(ejectNodeMocks); err != nil {
+		return fmt.Errorf("failed to write %s: %w", ejectNodeMocks, err)
+	}
+
+	opts := devserver.WranglerTomlOptions{
+		BuildCommand: "node " + filepath.ToSlash(ejectBuildScript),
+		Standalone:   true,
+	}
+	if err := devserver.GenerateWranglerTomlWithOptions(cfg, "wrangler.toml", opts); err != nil {
+		return err
+	}
+	for _, svc := range cfg.Services {
+		if err := devserver.GenerateWranglerTomlForServiceWithOptions(cfg, svc, ejectServiceToml(svc), opts); err != nil {
+			return err
+		}
+	}
+
+	for _, f := range files {
+		printer.Success("Wrote %s", f)
+	}
+	if len(stripped) > 0 {
+		fmt.Println()
+		printer.Warn("Left out %d local-only stub binding(s):", len(stripped))
+		for _, s := range stripped {
+			fmt.Printf("   %s\n", s)
+		}
+		printer.Hint("Create the real resources with wrangler and add their bindings to wrangler.toml")
+	}
+	if len(cfg.PostgresDatabases) > 0 {
+		printer.Warn("Postgres bindings are commented [[hyperdrive]] templates; create a Hyperdrive config for each and fill in its id")
+	}
+
+	fmt.Println()
+	printer.Step("Next steps:")
+	printer.Hint("npm install --save-dev wrangler esbuild")
+	for _, secret := range cfg.EnvVars {
+		printer.Hint("npx wrangler secret put %s", secret)
+	}
+	printer.Hint("npx wrangler deploy --env production")
+	printer.Hint("aerostack.toml is unchanged; delete it once the wrangler project deploys")
+	return nil
+}
+
+func ejectServiceToml(svc devserver.Service) string {
+	return fmt.Sprintf("wrangler.%s.toml", svc.Name)
+}
+
+// stripEjectStubs removes local-only stub bindings from cfg and every env override, the
+// way deploy leaves them out, and describes what was removed.
+func stripEjectStubs(cfg *devserver.AerostackConfig) []string {
+	payload, stripped := buildBindingPayload(cfg)
+	cfg.D1Databases, cfg.KVNamespaces, cfg.R2Buckets, cfg.Queues = payload.D1Databases, payload.KVNamespaces, payload.R2Buckets, payload.Queues
+
+	envs := make([]string, 0, len(cfg.EnvOverrides))
+	for name := range cfg.EnvOverrides {
+		envs = append(envs, name)
+	}
+	sort.Strings(envs)
+	for _, name := range envs {
+		ov := cfg.EnvOverrides[name]
+		payload, envStripped := buildBindingPayload(&devserver.AerostackConfig{
+			D1Databases:  ov.D1Databases,
+			KVNamespaces: ov.KVNamespaces,
+			R2Buckets:    ov.R2Buckets,
+			Queues:       ov.Queues,
+		})
+		// A nil list inherits the top-level bindings, so an override emptied by stripping stays empty
+		ov.D1Databases = keepEmpty(ov.D1Databases, payload.D1Databases)
+		ov.KVNamespaces = keepEmpty(ov.KVNamespaces, payload.KVNamespaces)
+		ov.R2Buckets = keepEmpty(ov.R2Buckets, payload.R2Buckets)
+		ov.Queues = keepEmpty(ov.Queues, payload.Queues)
+		cfg.EnvOverrides[name] = ov
+		for _, s := range envStripped {
+			stripped = append(stripped, fmt.Sprintf("%s [env.%s]", s, name))
+		}
+	}
+	return stripped
+}
+
+func keepEmpty[T any](before, after []T) []T {
+	if before != nil && after == nil {
+		return []T{}
+	}
+	return after
+}
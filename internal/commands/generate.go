@@ -2,8 +2,10 @@ package commands
 
 import (
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 
 	"github.com/aerostackdev/cli/internal/api"
@@ -25,27 +27,38 @@ func NewGenerateCommand() *cobra.Command {
 }
 
 func newGenerateTypesCommand() *cobra.Command {
-	var outputPath string
+	var outputPath, format string
 
 	cmd := &cobra.Command{
 		Use:   "types",
 		Short: "Generate TypeScript types from database schema",
 		Long: `Introspects all connected databases (D1 and Postgres) and generates 
-TypeScript interfaces and a type-safe database client.
+TypeScript interfaces and a type-safe database client, plus a Bindings interface for the
+worker's env (D1, Postgres, KV, R2, Queues, services, AI and vars) from aerostack.toml.
+
+Formats:
+  interfaces  Plain TypeScript interfaces (default)
+  zod         Zod schemas for each table and collection, with inferred select/insert types
+  drizzle     Drizzle sqliteTable/pgTable definitions with foreign keys, indexes and defaults
 
 Example:
-  aerostack generate types --output src/db/types.ts`,
+  aerostack generate types --output src/db/types.ts
+  aerostack generate types --format drizzle --output src/db/schema.ts`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return generateTypes(outputPath)
+			return generateTypes(outputPath, format)
 		},
 	}
 
 	cmd.Flags().StringVarP(&outputPath, "output", "o", "shared/types.ts", "File path for generated types")
+	cmd.Flags().StringVar(&format, "format", devserver.TypeFormatInterfaces, "Output format: "+strings.Join(devserver.TypeFormats, ", "))
 
 	return cmd
 }
 
-func generateTypes(outputPath string) error {
+func generateTypes(outputPath, format string) error {
+	if !slices.Contains(devserver.TypeFormats, format) {
+		return fmt.Errorf("unknown --format %q (expected %s)", format, strings.Join(devserver.TypeFormats, ", "))
+	}
 	fmt.Println("📊 Starting deep introspection...")
 
 	// 1. Parse aerostack.toml
@@ -54,11 +67,6 @@ func generateTypes(outputPath string) error {
 		return fmt.Errorf("failed to parse config: %w", err)
 	}
 
-	projectRoot, err := os.Getwd()
-	if err != nil {
-		return fmt.Errorf("failed to get project root: %w", err)
-	}
-
 	// 2. Fetch Project Metadata (Collections, Hooks, Queues, etc.)
 	apiKey := os.Getenv("AEROSTACK_API_KEY")
 	var metadata *api.ProjectMetadata
@@ -75,52 +83,29 @@ func generateTypes(outputPath string) error {
 		fmt.Println("ℹ️  AEROSTACK_API_KEY not set. Skipping deep resource introspection (collections, hooks, etc.)")
 	}
 
-	// 3. Ensure wrangler.toml exists for D1 introspection (wrangler needs it)
-	devserver.EnsureDefaultD1(cfg)
+	// 3. Binding types, before introspection adds the local default D1
+	bindings := devserver.GenerateBindingTypes(cfg)
 
-	wranglerPath := filepath.Join(projectRoot, ".aerostack", "wrangler.toml")
-	if _, err := os.Stat(wranglerPath); os.IsNotExist(err) {
-		if err := devserver.GenerateWranglerToml(cfg, wranglerPath); err != nil {
-			return fmt.Errorf("failed to generate wrangler.toml for D1 introspection: %w", err)
-		}
-		fmt.Println("📄 Generated wrangler.toml for D1 introspection")
+	// 4. Introspect D1 and Postgres
+	allSchemas, err := introspectDatabases(cfg, os.Stdout, false)
+	if err != nil {
+		return err
 	}
 
-	var allSchemas []devserver.TableSchema
-
-	// 4. Introspect D1 (if any)
-	for _, d1 := range cfg.D1Databases {
-		fmt.Printf("🔍 Introspecting D1 %s (%s)...\n", d1.Binding, d1.DatabaseName)
-		d1Schemas, err := devserver.IntrospectD1Local(d1.DatabaseName, projectRoot, d1.Binding)
-		if err != nil {
-			fmt.Printf("⚠️  D1 introspection warning: %v\n", err)
-		} else {
-			allSchemas = append(allSchemas, d1Schemas...)
-		}
+	if len(allSchemas) == 0 && metadata == nil && bindings == "" {
+		return fmt.Errorf("no resources found to generate types from (no databases, bindings or metadata)")
 	}
 
-	// 5. Introspect Postgres (if any)
-	for _, pg := range cfg.PostgresDatabases {
-		if strings.Contains(pg.ConnectionString, "$") {
-			return fmt.Errorf("Postgres binding %q: connection string has unresolved env vars. Set the required env var (e.g. in .env) and try again", pg.Binding)
-		}
-		fmt.Printf("🔍 Introspecting Postgres (%s)...\n", pg.Binding)
-		pgSchemas, err := devserver.IntrospectPostgres(pg.ConnectionString, pg.Binding)
-		if err != nil {
-			fmt.Printf("⚠️  Postgres introspection warning: %v\n", err)
-		} else {
-			allSchemas = append(allSchemas, pgSchemas...)
-		}
+	// 5. Generate TypeScript
+	tsCode, err := devserver.GenerateTypes(allSchemas, metadata, format)
+	if err != nil {
+		return err
 	}
-
-	if len(allSchemas) == 0 && metadata == nil {
-		return fmt.Errorf("no resources found to generate types from (no databases and no metadata)")
+	if bindings != "" {
+		tsCode += "\n" + bindings
 	}
 
-	// 6. Generate TypeScript
-	tsCode := devserver.GenerateTypeScript(allSchemas, metadata)
-
-	// 7. Ensure directory exists and write file
+	// 6. Ensure directory exists and write file
 	if err := os.MkdirAll(filepath.Dir(outputPath), 0755); err != nil {
 		return fmt.Errorf("failed to create directory for types: %w", err)
 	}
@@ -129,8 +114,45 @@ func generateTypes(outputPath string) error {
 		return fmt.Errorf("failed to write types file: %w", err)
 	}
 
-	totalTables := len(allSchemas)
-	fmt.Printf("✨ Generated IntelliSense types (%d tables) → %s\n", totalTables, outputPath)
+	totalTables := len(devserver.UserTables(allSchemas))
+	fmt.Printf("✨ Generated %s types (%d tables) → %s\n", format, totalTables, outputPath)
 
 	return nil
 }
+
+// introspectDatabases reads the schema of every local D1 database and every Postgres database,
+// reporting progress to out. A database that can't be read is skipped with a warning, or
+// returned as an error when strict is set.
+func introspectDatabases(cfg *devserver.AerostackConfig, out io.Writer, strict bool) ([]devserver.TableSchema, error) {
+	// Local D1 is read straight from miniflare's SQLite files; no wrangler.toml needed
+	devserver.EnsureDefaultD1(cfg)
+
+	var allSchemas []devserver.TableSchema
+	for _, d1 := range cfg.D1Databases {
+		fmt.Fprintf(out, "🔍 Introspecting D1 %s (%s)...\n", d1.Binding, d1.DatabaseName)
+		d1Schemas, err := devserver.IntrospectD1Local(d1, d1.Binding)
+		if err != nil && strict {
+			return nil, fmt.Errorf("D1 binding %q: %w", d1.Binding, err)
+		} else if err != nil {
+			fmt.Fprintf(out, "⚠️  D1 introspection warning: %v\n", err)
+		} else {
+			allSchemas = append(allSchemas, d1Schemas...)
+		}
+	}
+
+	for _, pg := range cfg.PostgresDatabases {
+		if strings.Contains(pg.ConnectionString, "$") {
+			return nil, fmt.Errorf("Postgres binding %q: connection string has unresolved env vars. Set the required env var (e.g. in .env) and try again", pg.Binding)
+		}
+		fmt.Fprintf(out, "🔍 Introspecting Postgres (%s)...\n", pg.Binding)
+		pgSchemas, err := devserver.IntrospectPostgres(pg.ConnectionString, pg.Binding, pg.SchemaName())
+		if err != nil && strict {
+			return nil, fmt.Errorf("Postgres binding %q: %w", pg.Binding, err)
+		} else if err != nil {
+			fmt.Fprintf(out, "⚠️  Postgres introspection warning: %v\n", err)
+		} else {
+			allSchemas = append(allSchemas, pgSchemas...)
+		}
+	}
+	return allSchemas, nil
+}
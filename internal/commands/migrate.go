@@ -7,6 +7,7 @@ import (
 	"github.com/aerostackdev/cli/internal/agent"
 	"github.com/aerostackdev/cli/internal/modules/migration"
 	"github.com/aerostackdev/cli/internal/pkg"
+	"github.com/aerostackdev/cli/internal/printer"
 	"github.com/spf13/cobra"
 )
 
@@ -14,8 +15,12 @@ func NewMigrateCommand() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "migrate",
 		Short: "Migrate a Cloudflare Worker project to Aerostack",
-		Long: `Automatically detects a wrangler.toml file, generates an aerostack.toml config,
-and uses AI to suggest code updates for compatibility.`,
+		Long: `Automatically detects a wrangler.json, wrangler.jsonc or wrangler.toml file, generates an
+aerostack.toml config, and uses AI to suggest code updates for compatibility.
+
+D1 databases, KV namespaces, R2 buckets, queue producers, Workers AI, vars, compatibility
+flags and [env.<name>] blocks are carried over. Every setting that isn't (Durable Objects,
+cron triggers, routes, ...) is listed afterwards so no binding is lost without notice.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			cwd, _ := os.Getwd()
 
@@ -25,7 +30,7 @@ and uses AI to suggest code updates for compatibility.`,
 			if err != nil {
 				return fmt.Errorf("detection failed: %w", err)
 			}
-			fmt.Printf("✅ Detected Wrangler project: %s\n", wConfig.Name)
+			fmt.Printf("✅ Detected Wrangler project: %s (%s)\n", wConfig.Name, wConfig.File)
 
 			// 2. Convert Config
 			fmt.Println("⚙️  Generating Aerostack configuration...")
@@ -36,6 +41,17 @@ and uses AI to suggest code updates for compatibility.`,
 				return fmt.Errorf("failed to write aerostack.toml: %w", err)
 			}
 			fmt.Println("✅ Created aerostack.toml")
+			if unmapped := wConfig.Unmapped(); len(unmapped) > 0 {
+				fmt.Println()
+				printer.Warn("%d setting(s) in %s were not migrated:", len(unmapped), wConfig.File)
+				rows := make([][]string, len(unmapped))
+				for i, u := range unmapped {
+					rows[i] = []string{u.Key, u.Reason}
+				}
+				fmt.Println(printer.Table([]string{"setting", "reason"}, rows))
+				printer.Hint("None of these are in aerostack.toml; check the Worker doesn't rely on them before deploying (%s is left untouched)", wConfig.File)
+				fmt.Println()
+			}
 
 			// 3. AI Refactor Check
 			fmt.Println("🧠 Initializing AI for code compatibility check...")
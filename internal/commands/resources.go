@@ -32,29 +32,28 @@ func newResourcesCreateCommand() *cobra.Command {
 	var env string
 	cmd := &cobra.Command{
 		Use:   "create",
-		Short: "Create resources (D1, KV, etc.) in your Cloudflare account",
+		Short: "Create resources (D1, KV, R2, etc.) in your Cloudflare account",
 		Long: `Auto-provisions resources from aerostack.toml when IDs are placeholders.
 Updates aerostack.toml with real IDs. Run before deploy --cloudflare or let deploy auto-run this.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return runResourcesCreate(env)
 		},
 	}
-	cmd.Flags().StringVarP(&env, "env", "e", "staging", "Target environment (staging/production)")
+	cmd.Flags().StringVarP(&env, "env", "e", "staging", "Target environment (staging, production or any [env.<name>])")
 	return cmd
 }
 
 func runResourcesCreate(env string) error {
-	if env != "staging" && env != "production" {
-		return fmt.Errorf("invalid env %q: use staging or production", env)
-	}
-
 	if _, err := os.Stat("aerostack.toml"); os.IsNotExist(err) {
 		return fmt.Errorf("aerostack.toml not found. Run 'aerostack init' first")
 	}
 
 	cfg, err := devserver.ParseAerostackToml("aerostack.toml")
 	if err != nil {
-		return fmt.Errorf("failed to parse config: %w", err)
+		return fmt.Errorf("failed to parse config:\n%w", err)
+	}
+	if err := cfg.ValidateEnv(env); err != nil {
+		return err
 	}
 
 	projectRoot, _ := os.Getwd()
@@ -19,8 +19,8 @@ func NewSecretsCommand() *cobra.Command {
 		Long: `Manage Cloudflare Workers secrets.
 Local dev: use .dev.vars (never commit). Staging/Prod: use these commands.
 
-  aerostack secrets list [--env staging|production]
-  aerostack secrets set KEY value [--env staging|production]`,
+  aerostack secrets list [--env staging|production|<name>]
+  aerostack secrets set KEY value [--env staging|production|<name>]`,
 	}
 
 	cmd.AddCommand(NewSecretsListCommand())
@@ -39,7 +39,7 @@ func NewSecretsListCommand() *cobra.Command {
 			return runSecretsList(env)
 		},
 	}
-	cmd.Flags().StringVar(&env, "env", "", "Environment (staging/production)")
+	cmd.Flags().StringVar(&env, "env", "", "Environment (staging, production or any [env.<name>])")
 	return cmd
 }
 
@@ -58,28 +58,34 @@ func NewSecretsSetCommand() *cobra.Command {
 			return runSecretsSet(args[0], args, env)
 		},
 	}
-	cmd.Flags().StringVar(&env, "env", "production", "Environment (staging/production)")
+	cmd.Flags().StringVar(&env, "env", "production", "Environment (staging, production or any [env.<name>])")
 	return cmd
 }
 
-func ensureWranglerToml() error {
-	wranglerPath := filepath.Join(".aerostack", "wrangler.toml")
-	if _, err := os.Stat(wranglerPath); err == nil {
-		return nil
-	}
+// ensureWranglerToml regenerates .aerostack/wrangler.toml so every [env.<name>] in
+// aerostack.toml is visible to wrangler, and rejects envs that aren't declared.
+func ensureWranglerToml(env string) error {
 	cfg, err := devserver.ParseAerostackToml("aerostack.toml")
 	if err != nil {
-		return fmt.Errorf("failed to parse aerostack.toml: %w", err)
+		return fmt.Errorf("failed to parse aerostack.toml:\n%w", err)
+	}
+	if env != "" {
+		if err := cfg.ValidateEnv(env); err != nil {
+			return err
+		}
 	}
 	devserver.EnsureDefaultD1(cfg)
-	return devserver.GenerateWranglerToml(cfg, wranglerPath)
+	if err := os.MkdirAll(".aerostack", 0755); err != nil {
+		return err
+	}
+	return devserver.GenerateWranglerToml(cfg, filepath.Join(".aerostack", "wrangler.toml"))
 }
 
 func runSecretsList(env string) error {
 	if _, err := os.Stat("aerostack.toml"); os.IsNotExist(err) {
 		return fmt.Errorf("aerostack.toml not found. Run 'aerostack init' first")
 	}
-	if err := ensureWranglerToml(); err != nil {
+	if err := ensureWranglerToml(env); err != nil {
 		return err
 	}
 
@@ -108,7 +114,7 @@ func runSecretsSet(key string, args []string, env string) error {
 	if _, err := os.Stat("aerostack.toml"); os.IsNotExist(err) {
 		return fmt.Errorf("aerostack.toml not found. Run 'aerostack init' first")
 	}
-	if err := ensureWranglerToml(); err != nil {
+	if err := ensureWranglerToml(env); err != nil {
 		return err
 	}
 
@@ -15,21 +15,12 @@ func NewStoreCommand() *cobra.Command {
 		Short: "Manage data store resources",
 	}
 
-	storeCmdInit := func() (*agent.Agent, error) {
-		cwd, _ := os.Getwd()
-		pkgStore, err := pkg.NewStore(cwd)
-		if err != nil {
-			return nil, err
-		}
-		return agent.NewAgent(pkgStore, false)
-	}
-
 	schemaCmd := &cobra.Command{
 		Use:   "schema [intent]",
 		Short: "Generate schema changes from natural language",
 		Args:  cobra.MinimumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			ag, err := storeCmdInit()
+			ag, err := newStoreAgent()
 			if err != nil {
 				return err
 			}
@@ -38,22 +29,16 @@ func NewStoreCommand() *cobra.Command {
 		},
 	}
 
-	seedCmd := &cobra.Command{
-		Use:   "seed",
-		Short: "Seed the database with data",
-		RunE: func(cmd *cobra.Command, args []string) error {
-			smart, _ := cmd.Flags().GetBool("smart")
-			ag, err := storeCmdInit()
-			if err != nil {
-				return err
-			}
-			ai := store.NewSeederAI(ag)
-			return ai.Seed(cmd.Context(), smart)
-		},
-	}
-	seedCmd.Flags().Bool("smart", false, "Use AI to generate realistic seed data based on schema")
-
 	cmd.AddCommand(schemaCmd)
-	cmd.AddCommand(seedCmd)
+	cmd.AddCommand(newStoreSeedCommand())
 	return cmd
 }
+
+func newStoreAgent() (*agent.Agent, error) {
+	cwd, _ := os.Getwd()
+	pkgStore, err := pkg.NewStore(cwd)
+	if err != nil {
+		return nil, err
+	}
+	return agent.NewAgent(pkgStore, false)
+}
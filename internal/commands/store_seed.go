@@ -0,0 +1,137 @@
+package commands
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+
+	"github.com/aerostackdev/cli/internal/devserver"
+	"github.com/aerostackdev/cli/internal/modules/store"
+	"github.com/aerostackdev/cli/internal/printer"
+	"github.com/spf13/cobra"
+)
+
+func newStoreSeedCommand() *cobra.Command {
+	var binding, dir, remote string
+	var tables []string
+	var fake int
+	var seed int64
+	var reset, force, smart bool
+	cmd := &cobra.Command{
+		Use:   "seed",
+		Short: "Seed the database with data",
+		Long: `Applies the fixtures in seeds/ to a D1 or Postgres database, then optionally
+generates fake rows from the introspected column types. Everything runs in one
+transaction, so a failing fixture leaves the database untouched.
+
+Fixtures, applied in file name order:
+  seeds/*.sql   Run as-is
+  seeds/*.json  Rows to insert: {"users": [{...}], "posts": [...]}, or a bare array
+                of rows for the table named by the file (01_users.json → users).
+                Tables are filled parents first, whatever order the file uses
+
+--fake N inserts N rows per table (or per --table). Values follow column types, enums,
+CHECK (col IN ...) lists and names like email or created_at; foreign keys point at
+existing rows. The same --seed always generates the same rows.
+
+Seeding a remote env needs --remote; production also needs --force.
+
+Example:
+  aerostack store seed
+  aerostack store seed --reset --fake 20
+  aerostack store seed --binding PG --fake 50 --table orders --seed 7
+  aerostack store seed --remote staging --binding PG
+  aerostack store seed --smart`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if smart {
+				ag, err := newStoreAgent()
+				if err != nil {
+					return err
+				}
+				return store.NewSeederAI(ag).Generate(cmd.Context(), dir, seedMigrationDirs()...)
+			}
+			opts := devserver.SeedOptions{Dir: dir, Fake: fake, Seed: seed, Tables: tables, Reset: reset}
+			return seedDatabase(binding, remote, opts, force)
+		},
+	}
+	cmd.Flags().StringVar(&binding, "binding", "", "Database binding to seed (required with more than one)")
+	cmd.Flags().StringVar(&dir, "dir", devserver.SeedsDir, "Directory of *.sql and *.json fixtures")
+	cmd.Flags().IntVar(&fake, "fake", 0, "Generate this many fake rows per table")
+	cmd.Flags().Int64Var(&seed, "seed", 1, "Random seed for --fake; the same seed gives the same data")
+	cmd.Flags().StringSliceVar(&tables, "table", nil, "Only generate fake rows for these tables (repeatable)")
+	cmd.Flags().BoolVar(&reset, "reset", false, "Empty every table before seeding")
+	cmd.Flags().StringVar(&remote, "remote", "", "Seed a remote environment (staging, production or any [env.<name>])")
+	cmd.Flags().BoolVar(&force, "force", false, "Allow seeding a production environment")
+	cmd.Flags().BoolVar(&smart, "smart", false, "Use AI to write a seed fixture based on your migrations")
+	return cmd
+}
+
+func seedDatabase(binding, remote string, opts devserver.SeedOptions, force bool) error {
+	if isProductionEnv(remote) && !force {
+		return fmt.Errorf("refusing to seed %s: seeds are for development data. Pass --force if you really mean to", remote)
+	}
+	cfg, err := loadMigrateConfig(remote)
+	if err != nil {
+		return err
+	}
+	devserver.EnsureDefaultD1(cfg)
+	target, err := pickTarget(dbTargets(cfg, remote), binding)
+	if err != nil {
+		return err
+	}
+
+	if target.dialect == devserver.DialectSQLite && remote != "" {
+		return fmt.Errorf("seeding remote D1 is not supported; seed a local database or a Postgres binding")
+	}
+
+	printer.Header(fmt.Sprintf("Seeding %s %s (%s env)", dialectLabel(target.dialect), target.binding, envLabel(remote)))
+	if opts.Reset {
+		printer.Warn("Emptying every table in %s first", target.scope())
+	}
+	var reports []devserver.SeedReport
+	if target.dialect == devserver.DialectPostgres {
+		reports, err = devserver.SeedPostgres(target.pg, opts)
+	} else {
+		reports, err = devserver.SeedD1Local(target.d1, opts)
+	}
+	if err != nil {
+		return fmt.Errorf("seed failed for %s (nothing was written): %w", target.binding, err)
+	}
+
+	total := 0
+	for _, r := range reports {
+		if r.Table != "" {
+			fmt.Printf("  %s %-24s %4d row(s) → %s\n", printer.GlyphSuccess, r.Source, r.Rows, r.Table)
+		} else {
+			fmt.Printf("  %s %-24s %4d row(s)\n", printer.GlyphSuccess, r.Source, r.Rows)
+		}
+		total += r.Rows
+	}
+	if len(reports) == 0 {
+		printer.Success("Emptied every table in %s", target.binding)
+		return nil
+	}
+	printer.Success("Seeded %s with %d row(s)", target.binding, total)
+	return nil
+}
+
+// seedMigrationDirs lists the D1 migrations directory and every Postgres binding's.
+func seedMigrationDirs() []string {
+	dirs := []string{devserver.D1MigrationsDir, devserver.PostgresMigrationsDir}
+	if cfg, err := devserver.ParseAerostackToml("aerostack.toml"); err == nil {
+		dirs = dirs[:1]
+		for _, pg := range cfg.PostgresDatabases {
+			if !slices.Contains(dirs, pg.MigrationsPath()) {
+				dirs = append(dirs, pg.MigrationsPath())
+			}
+		}
+	}
+	return dirs
+}
+
+var productionEnvRe = regexp.MustCompile(`(?i)^prod(uction)?([-_].*)?$`)
+
+// isProductionEnv reports env names that refer to production: production, prod, prod-eu, ...
+func isProductionEnv(env string) bool {
+	return productionEnvRe.MatchString(env)
+}
@@ -43,7 +43,7 @@ func runTests(coverage bool) error {
 	// 2. Parse aerostack.toml and generate wrangler.toml (required for vitest config)
 	cfg, err := devserver.ParseAerostackToml("aerostack.toml")
 	if err != nil {
-		return fmt.Errorf("failed to parse aerostack.toml: %w", err)
+		return fmt.Errorf("failed to parse aerostack.toml:\n%w", err)
 	}
 	devserver.EnsureDefaultD1(cfg)
 	devserver.EnsureDefaultKV(cfg)
@@ -55,12 +55,8 @@ func runTests(coverage bool) error {
 	}
 
 	// 3. Run build so dist/worker.js exists (vitest pool uses wrangler config)
-	buildCmd := exec.Command("npx", "esbuild", cfg.Main, "--bundle", "--outfile=dist/worker.js", "--format=esm", "--alias:@shared=./shared")
-	buildCmd.Dir = "."
-	buildCmd.Stdout = os.Stdout
-	buildCmd.Stderr = os.Stderr
-	if err := buildCmd.Run(); err != nil {
-		return fmt.Errorf("build failed (required for tests): %w", err)
+	if _, err := devserver.BuildWorker(cfg, devserver.BuildOptions{Sourcemap: true}); err != nil {
+		return fmt.Errorf("build failed (required for tests):\n%w", err)
 	}
 
 	// 4. Run vitest
@@ -0,0 +1,179 @@
+// Package deployments keeps a local, append-only record of every deploy, rollback and
+// promote in .aerostack/deployments.jsonl so a project's release history survives even
+// when the API is unreachable.
+package deployments
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const historyFile = "deployments.jsonl"
+
+// Actions recorded in the history file
+const (
+	ActionDeploy   = "deploy"
+	ActionRollback = "rollback"
+	ActionPromote  = "promote"
+)
+
+// Record is one line of .aerostack/deployments.jsonl.
+type Record struct {
+	ID          string          `json:"id,omitempty"`
+	Action      string          `json:"action"`
+	Env         string          `json:"env"`
+	Service     string          `json:"service,omitempty"`
+	ProjectID   string          `json:"project_id,omitempty"`
+	URL         string          `json:"url,omitempty"`
+	BundleHash  string          `json:"bundle_hash,omitempty"`
+	BundleBytes int64           `json:"bundle_bytes,omitempty"`
+	GitCommit   string          `json:"git_commit,omitempty"`
+	Bindings    json.RawMessage `json:"bindings,omitempty"`
+	// Source is the deployment ID a rollback restored, or the env a promote copied from.
+	Source    string    `json:"source,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Path returns the history file location for a project root.
+func Path(projectRoot string) string {
+	return filepath.Join(projectRoot, ".aerostack", historyFile)
+}
+
+// Append writes rec as a new line in the project's history file.
+func Append(projectRoot string, rec Record) error {
+	if rec.CreatedAt.IsZero() {
+		rec.CreatedAt = time.Now().UTC()
+	}
+	path := Path(projectRoot)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	line, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = f.Write(append(line, '\n'))
+	return err
+}
+
+// Load returns every record in the history file, newest first. A missing file is not an error.
+// Lines that don't parse (e.g. a truncated write) are skipped.
+func Load(projectRoot string) ([]Record, error) {
+	f, err := os.Open(Path(projectRoot))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer f.Close()
+
+	var records []Record
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		var rec Record
+		if err := json.Unmarshal([]byte(line), &rec); err != nil {
+			continue
+		}
+		records = append(records, rec)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	sort.SliceStable(records, func(i, j int) bool { return records[i].CreatedAt.After(records[j].CreatedAt) })
+	return records, nil
+}
+
+// Latest returns the newest record for env, or nil.
+func Latest(records []Record, env string) *Record {
+	for i := range records {
+		if records[i].Env == env {
+			return &records[i]
+		}
+	}
+	return nil
+}
+
+// LatestForService returns the newest record for service in env, or nil. Each service is
+// its own worker, so another service's deploy says nothing about this one.
+func LatestForService(records []Record, env, service string) *Record {
+	for i := range records {
+		if records[i].Env == env && records[i].Service == service {
+			return &records[i]
+		}
+	}
+	return nil
+}
+
+// Find returns the record with the given deployment ID, or nil.
+func Find(records []Record, id string) *Record {
+	for i := range records {
+		if records[i].ID == id {
+			return &records[i]
+		}
+	}
+	return nil
+}
+
+// BundleHash returns a sha256 over the uploaded files (keyed by form name) in a stable order.
+func BundleHash(files map[string]string) (string, error) {
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	h := sha256.New()
+	for _, name := range names {
+		f, err := os.Open(files[name])
+		if err != nil {
+			return "", fmt.Errorf("hash %s: %w", files[name], err)
+		}
+		fmt.Fprintf(h, "%s\x00", name)
+		_, err = io.Copy(h, f)
+		f.Close()
+		if err != nil {
+			return "", fmt.Errorf("hash %s: %w", files[name], err)
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// GitCommit returns the short HEAD commit of the repo at dir, suffixed with "-dirty" when
+// there are uncommitted changes. It returns "" outside a git repo.
+func GitCommit(dir string) string {
+	cmd := exec.Command("git", "rev-parse", "--short", "HEAD")
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	if err != nil {
+		return ""
+	}
+	commit := strings.TrimSpace(string(out))
+
+	status := exec.Command("git", "status", "--porcelain")
+	status.Dir = dir
+	if out, err := status.Output(); err == nil && len(strings.TrimSpace(string(out))) > 0 {
+		commit += "-dirty"
+	}
+	return commit
+}
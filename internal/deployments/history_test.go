@@ -0,0 +1,90 @@
+package deployments
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestAppendLoad_NewestFirst(t *testing.T) {
+	root := t.TempDir()
+	base := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	recs := []Record{
+		{ID: "d1", Action: ActionDeploy, Env: "staging", GitCommit: "abc123", Bindings: json.RawMessage(`{"ai":true}`), CreatedAt: base},
+		{ID: "d2", Action: ActionDeploy, Env: "production", CreatedAt: base.Add(time.Hour)},
+		{ID: "d3", Action: ActionDeploy, Env: "staging", CreatedAt: base.Add(2 * time.Hour)},
+	}
+	for _, r := range recs {
+		if err := Append(root, r); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got, err := Load(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 3 || got[0].ID != "d3" || got[2].ID != "d1" {
+		t.Fatalf("Load order = %+v", got)
+	}
+	if l := Latest(got, "staging"); l == nil || l.ID != "d3" {
+		t.Errorf("Latest(staging) = %+v", l)
+	}
+	if l := LatestForService(got, "staging", "api"); l != nil {
+		t.Errorf("LatestForService(staging, api) = %+v, want nil", l)
+	}
+	if f := Find(got, "d1"); f == nil || f.GitCommit != "abc123" || string(f.Bindings) != `{"ai":true}` {
+		t.Errorf("Find(d1) = %+v", f)
+	}
+}
+
+func TestLoad_MissingFileAndBadLines(t *testing.T) {
+	root := t.TempDir()
+	if got, err := Load(root); err != nil || got != nil {
+		t.Fatalf("missing file: %v, %v", got, err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(Path(root)), 0755); err != nil {
+		t.Fatal(err)
+	}
+	content := `{"id":"ok","action":"deploy","env":"staging","created_at":"2025-01-01T00:00:00Z"}
+{"id":"trunc
+`
+	if err := os.WriteFile(Path(root), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	got, err := Load(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || got[0].ID != "ok" {
+		t.Errorf("Load = %+v", got)
+	}
+}
+
+func TestBundleHash_StableAndContentSensitive(t *testing.T) {
+	dir := t.TempDir()
+	a := filepath.Join(dir, "worker.js")
+	b := filepath.Join(dir, "chunk.js")
+	os.WriteFile(a, []byte("export default {}"), 0644)
+	os.WriteFile(b, []byte("export const x = 1"), 0644)
+
+	files := map[string]string{"worker": a, "chunk.js": b}
+	h1, err := BundleHash(files)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h2, _ := BundleHash(files)
+	if h1 != h2 {
+		t.Errorf("hash not stable: %s != %s", h1, h2)
+	}
+
+	os.WriteFile(b, []byte("export const x = 2"), 0644)
+	h3, _ := BundleHash(files)
+	if h3 == h1 {
+		t.Errorf("hash did not change with content")
+	}
+}
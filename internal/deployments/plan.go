@@ -0,0 +1,166 @@
+package deployments
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// Binding change operations
+const (
+	OpAdd    = "add"
+	OpRemove = "remove"
+	OpChange = "change"
+)
+
+// bindingKinds are the list-valued keys of a deploy's bindings JSON, in display order.
+var bindingKinds = []string{"d1_databases", "kv_namespaces", "r2_buckets", "queues"}
+
+// BindingChange is one difference between two deploys' bindings JSON.
+type BindingChange struct {
+	Op      string
+	Kind    string // d1_databases, kv_namespaces, r2_buckets, queues or ai
+	Binding string
+	// Detail lists changed fields for OpChange ("database_id: a → b"), or the binding's
+	// fields for add/remove.
+	Detail string
+}
+
+// DiffBindings compares the bindings JSON of the previous deploy with the next one.
+// A nil or empty prev is treated as "no bindings", so everything in next is an add.
+func DiffBindings(prev, next json.RawMessage) ([]BindingChange, error) {
+	before, err := decodeBindings(prev)
+	if err != nil {
+		return nil, fmt.Errorf("previous bindings: %w", err)
+	}
+	after, err := decodeBindings(next)
+	if err != nil {
+		return nil, fmt.Errorf("new bindings: %w", err)
+	}
+
+	var changes []BindingChange
+	for _, kind := range bindingKinds {
+		b, a := before[kind], after[kind]
+		for _, name := range sortedKeys(a) {
+			old, ok := b[name]
+			if !ok {
+				changes = append(changes, BindingChange{Op: OpAdd, Kind: kind, Binding: name, Detail: describeFields(a[name])})
+				continue
+			}
+			if diff := diffFields(old, a[name]); diff != "" {
+				changes = append(changes, BindingChange{Op: OpChange, Kind: kind, Binding: name, Detail: diff})
+			}
+		}
+		for _, name := range sortedKeys(b) {
+			if _, ok := a[name]; !ok {
+				changes = append(changes, BindingChange{Op: OpRemove, Kind: kind, Binding: name, Detail: describeFields(b[name])})
+			}
+		}
+	}
+
+	hadAI, hasAI := before["ai"] != nil, after["ai"] != nil
+	if hasAI && !hadAI {
+		changes = append(changes, BindingChange{Op: OpAdd, Kind: "ai", Binding: "AI"})
+	} else if hadAI && !hasAI {
+		changes = append(changes, BindingChange{Op: OpRemove, Kind: "ai", Binding: "AI"})
+	}
+	return changes, nil
+}
+
+// BundleSize returns the total size in bytes of the files to upload.
+func BundleSize(files map[string]string) (int64, error) {
+	var total int64
+	for _, path := range files {
+		info, err := os.Stat(path)
+		if err != nil {
+			return 0, err
+		}
+		total += info.Size()
+	}
+	return total, nil
+}
+
+// decodeBindings indexes a bindings JSON document as kind → binding name → fields.
+// The AI flag is stored as kind "ai" with a single "AI" entry when enabled.
+func decodeBindings(raw json.RawMessage) (map[string]map[string]map[string]any, error) {
+	out := map[string]map[string]map[string]any{}
+	if len(raw) == 0 || string(raw) == "null" {
+		return out, nil
+	}
+	var doc map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &doc); err != nil {
+		return nil, err
+	}
+	for _, kind := range bindingKinds {
+		var items []map[string]any
+		if v, ok := doc[kind]; ok {
+			if err := json.Unmarshal(v, &items); err != nil {
+				return nil, fmt.Errorf("%s: %w", kind, err)
+			}
+		}
+		byName := map[string]map[string]any{}
+		for _, item := range items {
+			name, _ := item["binding"].(string)
+			byName[name] = item
+		}
+		out[kind] = byName
+	}
+	var ai bool
+	if v, ok := doc["ai"]; ok {
+		_ = json.Unmarshal(v, &ai)
+	}
+	if ai {
+		out["ai"] = map[string]map[string]any{"AI": {}}
+	}
+	return out, nil
+}
+
+func diffFields(before, after map[string]any) string {
+	keys := map[string]bool{}
+	for k := range before {
+		keys[k] = true
+	}
+	for k := range after {
+		keys[k] = true
+	}
+	var parts []string
+	for _, k := range sortedKeys(keys) {
+		if k == "binding" {
+			continue
+		}
+		b, a := fmt.Sprint(before[k]), fmt.Sprint(after[k])
+		if b != a {
+			parts = append(parts, fmt.Sprintf("%s: %s → %s", k, orNone(before[k]), orNone(after[k])))
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+func describeFields(fields map[string]any) string {
+	var parts []string
+	for _, k := range sortedKeys(fields) {
+		if k == "binding" {
+			continue
+		}
+		parts = append(parts, fmt.Sprintf("%s: %v", k, fields[k]))
+	}
+	return strings.Join(parts, ", ")
+}
+
+func orNone(v any) string {
+	if v == nil {
+		return "(none)"
+	}
+	return fmt.Sprint(v)
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
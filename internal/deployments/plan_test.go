@@ -0,0 +1,58 @@
+package deployments
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestDiffBindings(t *testing.T) {
+	prev := json.RawMessage(`{
+		"d1_databases": [{"binding": "DB", "database_name": "app", "database_id": "old-id"}],
+		"kv_namespaces": [{"binding": "CACHE", "id": "kv-1"}],
+		"ai": true
+	}`)
+	next := json.RawMessage(`{
+		"d1_databases": [{"binding": "DB", "database_name": "app", "database_id": "new-id"}],
+		"queues": [{"binding": "JOBS", "queue": "app-jobs"}]
+	}`)
+
+	changes, err := DiffBindings(prev, next)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []BindingChange{
+		{Op: OpChange, Kind: "d1_databases", Binding: "DB", Detail: "database_id: old-id → new-id"},
+		{Op: OpRemove, Kind: "kv_namespaces", Binding: "CACHE", Detail: "id: kv-1"},
+		{Op: OpAdd, Kind: "queues", Binding: "JOBS", Detail: "queue: app-jobs"},
+		{Op: OpRemove, Kind: "ai", Binding: "AI"},
+	}
+	if len(changes) != len(want) {
+		t.Fatalf("got %d changes, want %d: %+v", len(changes), len(want), changes)
+	}
+	for i := range want {
+		if changes[i] != want[i] {
+			t.Errorf("change[%d] = %+v, want %+v", i, changes[i], want[i])
+		}
+	}
+}
+
+func TestDiffBindings_NoPreviousDeploy(t *testing.T) {
+	changes, err := DiffBindings(nil, json.RawMessage(`{"kv_namespaces":[{"binding":"CACHE","id":"kv-1"}],"ai":true}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 2 || changes[0].Op != OpAdd || changes[1].Kind != "ai" {
+		t.Errorf("changes = %+v", changes)
+	}
+}
+
+func TestDiffBindings_Unchanged(t *testing.T) {
+	b := json.RawMessage(`{"d1_databases":[{"binding":"DB","database_name":"app","database_id":"id"}]}`)
+	changes, err := DiffBindings(b, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 0 {
+		t.Errorf("changes = %+v", changes)
+	}
+}
@@ -0,0 +1,174 @@
+package devserver
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// BundleAnalysis summarises an esbuild metafile for one output file.
+type BundleAnalysis struct {
+	OutputBytes int
+	// Modules are the inputs that made it into the output, largest first.
+	Modules []ModuleSize
+	// Packages aggregates Modules by npm package, largest first.
+	Packages []ModuleSize
+	// Duplicates are packages bundled from more than one node_modules location.
+	Duplicates []DuplicatePackage
+	// MockedImports are the Node built-ins that resolved to NodeMocksPath.
+	MockedImports []MockedImport
+}
+
+// ModuleSize is a module or package and the bytes it contributes to the output.
+type ModuleSize struct {
+	Path  string
+	Bytes int
+}
+
+// DuplicatePackage is a package bundled more than once, e.g. two versions of the same dependency.
+type DuplicatePackage struct {
+	Name      string
+	Locations []PackageLocation
+}
+
+// PackageLocation is one copy of a duplicated package.
+type PackageLocation struct {
+	Dir     string
+	Version string // from its package.json, when readable
+	Bytes   int
+}
+
+// MockedImport is a Node built-in that was aliased to the node mocks, and who imported it.
+type MockedImport struct {
+	Module    string
+	Importers []string
+}
+
+type metafileJSON struct {
+	Inputs map[string]struct {
+		Imports []struct {
+			Path     string `json:"path"`
+			Original string `json:"original"`
+		} `json:"imports"`
+	} `json:"inputs"`
+	Outputs map[string]struct {
+		Bytes  int `json:"bytes"`
+		Inputs map[string]struct {
+			BytesInOutput int `json:"bytesInOutput"`
+		} `json:"inputs"`
+	} `json:"outputs"`
+}
+
+// AnalyzeMetafile reads an esbuild metafile (as written by BuildWorker with Metafile set) and
+// reports what contributes to outfile. Package versions are read relative to the current directory.
+func AnalyzeMetafile(metafile, outfile string) (*BundleAnalysis, error) {
+	var meta metafileJSON
+	if err := json.Unmarshal([]byte(metafile), &meta); err != nil {
+		return nil, fmt.Errorf("invalid metafile: %w", err)
+	}
+	out, ok := meta.Outputs[filepath.ToSlash(outfile)]
+	if !ok {
+		return nil, fmt.Errorf("metafile has no output %q", outfile)
+	}
+
+	a := &BundleAnalysis{OutputBytes: out.Bytes}
+	packages := map[string]int{}
+	// package name → install dir → bytes
+	locations := map[string]map[string]int{}
+	for path, in := range out.Inputs {
+		if in.BytesInOutput == 0 {
+			continue
+		}
+		a.Modules = append(a.Modules, ModuleSize{Path: path, Bytes: in.BytesInOutput})
+		if name, dir := packageOf(path); name != "" {
+			packages[name] += in.BytesInOutput
+			if locations[name] == nil {
+				locations[name] = map[string]int{}
+			}
+			locations[name][dir] += in.BytesInOutput
+		}
+	}
+	sortBySize(a.Modules)
+	for name, bytes := range packages {
+		a.Packages = append(a.Packages, ModuleSize{Path: name, Bytes: bytes})
+	}
+	sortBySize(a.Packages)
+
+	for name, dirs := range locations {
+		if len(dirs) < 2 {
+			continue
+		}
+		dup := DuplicatePackage{Name: name}
+		for dir, bytes := range dirs {
+			dup.Locations = append(dup.Locations, PackageLocation{Dir: dir, Version: packageVersion(dir), Bytes: bytes})
+		}
+		sort.Slice(dup.Locations, func(i, j int) bool { return dup.Locations[i].Dir < dup.Locations[j].Dir })
+		a.Duplicates = append(a.Duplicates, dup)
+	}
+	sort.Slice(a.Duplicates, func(i, j int) bool { return a.Duplicates[i].Name < a.Duplicates[j].Name })
+
+	mockPath := filepath.ToSlash(NodeMocksPath)
+	importers := map[string]map[string]bool{}
+	for path, in := range meta.Inputs {
+		for _, imp := range in.Imports {
+			if strings.TrimPrefix(imp.Path, "./") != mockPath {
+				continue
+			}
+			module := strings.TrimPrefix(imp.Original, "node:")
+			if importers[module] == nil {
+				importers[module] = map[string]bool{}
+			}
+			importers[module][path] = true
+		}
+	}
+	for module, by := range importers {
+		m := MockedImport{Module: module}
+		for path := range by {
+			m.Importers = append(m.Importers, path)
+		}
+		sort.Strings(m.Importers)
+		a.MockedImports = append(a.MockedImports, m)
+	}
+	sort.Slice(a.MockedImports, func(i, j int) bool { return a.MockedImports[i].Module < a.MockedImports[j].Module })
+	return a, nil
+}
+
+// packageOf returns the npm package name and its install dir for a path inside node_modules,
+// using the innermost node_modules segment (so nested copies are told apart).
+func packageOf(path string) (name, dir string) {
+	const nm = "node_modules/"
+	idx := strings.LastIndex(path, nm)
+	if idx < 0 {
+		return "", ""
+	}
+	rest := strings.Split(path[idx+len(nm):], "/")
+	name = rest[0]
+	if strings.HasPrefix(name, "@") && len(rest) > 1 {
+		name += "/" + rest[1]
+	}
+	return name, path[:idx+len(nm)] + name
+}
+
+func packageVersion(dir string) string {
+	data, err := os.ReadFile(filepath.Join(dir, "package.json"))
+	if err != nil {
+		return ""
+	}
+	var pkg struct {
+		Version string `json:"version"`
+	}
+	_ = json.Unmarshal(data, &pkg)
+	return pkg.Version
+}
+
+func sortBySize(mods []ModuleSize) {
+	sort.Slice(mods, func(i, j int) bool {
+		if mods[i].Bytes != mods[j].Bytes {
+			return mods[i].Bytes > mods[j].Bytes
+		}
+		return mods[i].Path < mods[j].Path
+	})
+}
@@ -1,42 +1,308 @@
 package devserver
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/evanw/esbuild/pkg/api"
 )
 
-// Bundle transpiles and bundles the user's TypeScript/JavaScript code into a single
-// ESM module that can be executed by workerd.
-func Bundle(entryPoint, dotAerostack string) (string, error) {
-	distDir := filepath.Join(dotAerostack, "dist")
-	if err := os.MkdirAll(distDir, 0755); err != nil {
-		return "", err
+// NodeMocksPath is where the stub for Node built-ins Workers can't run (fs, http, ...) is written.
+var NodeMocksPath = filepath.Join(".aerostack", "node-mocks.cjs")
+
+// nodeBuiltins are Node modules Workers support natively under nodejs_compat; bare imports
+// are aliased to their node: form.
+var nodeBuiltins = []string{"path", "url", "crypto", "events", "util", "stream", "buffer", "string_decoder", "assert", "timers", "async_hooks", "console", "querystring", "zlib", "punycode"}
+
+// MockedNodeBuiltins are Node modules Workers do NOT support (mapping fs -> node:fs crashes at
+// runtime). Both the bare and node: forms are aliased to NodeMocksPath.
+var MockedNodeBuiltins = []string{"fs", "os", "http", "https", "net", "dns", "tty", "tls", "child_process"}
+
+// nodeCompatBanner adds a require() shim for CJS dependencies and patches Buffer.prototype.
+const nodeCompatBanner = "import { createRequire } from 'node:module'; const require = createRequire('/'); if (typeof Buffer !== 'undefined' && !Buffer.prototype.hasOwnProperty) Buffer.prototype.hasOwnProperty = Object.prototype.hasOwnProperty;"
+
+// nodeMocksContent is a minimal mock: exports most things as empty objects or dummy functions.
+const nodeMocksContent = `
+const noop = () => {};
+const emptyObj = { prototype: {} };
+const handler = {
+	get: (target, prop) => {
+		if (prop === 'prototype') return emptyObj;
+		if (prop === 'on' || prop === 'once' || prop === 'emit') return noop;
+		if (Reflect.has(target, prop)) return target[prop];
+		return proxy;
+	},
+	construct: () => proxy,
+	apply: () => proxy
+};
+const proxy = new Proxy(noop, handler);
+
+Object.assign(noop, {
+	isatty: () => false,
+	createServer: () => ({ listen: () => ({ on: () => {} }), on: () => {} }),
+	readFileSync: () => { throw new Error("fs.readFileSync is not supported in Workers. Use cache or bindings.") },
+	METHODS: ["GET", "POST", "PUT", "DELETE", "PATCH", "HEAD", "OPTIONS"],
+	STATUS_CODES: { 200: "OK", 404: "Not Found", 500: "Internal Server Error" },
+	IncomingMessage: proxy,
+	ServerResponse: proxy,
+	Server: proxy,
+	Agent: proxy,
+	Socket: proxy,
+	networkInterfaces: () => ({}),
+	arch: () => "arm64",
+	platform: () => "linux"
+});
+
+module.exports = proxy;
+`
+
+// BuildOptions configures BuildWorker.
+type BuildOptions struct {
+	// Entry is the source entrypoint; defaults to cfg.Main.
+	Entry string
+	// Outfile defaults to dist/worker.js.
+	Outfile string
+	Minify  bool
+	// Sourcemap writes <outfile>.map next to the bundle.
+	Sourcemap bool
+	// Metafile writes esbuild's metafile to .aerostack/<outfile name>.meta.json for size analysis.
+	Metafile bool
+	// Quiet suppresses esbuild warnings on stdout (they're still returned).
+	Quiet bool
+}
+
+// BuildMessage is an esbuild error or warning with its source location.
+type BuildMessage struct {
+	File   string
+	Line   int
+	Column int
+	Text   string
+}
+
+func (m BuildMessage) String() string {
+	if m.File == "" {
+		return m.Text
+	}
+	return fmt.Sprintf("%s:%d:%d: %s", m.File, m.Line, m.Column, m.Text)
+}
+
+// BuildError is returned when esbuild reports errors; each message carries file and line.
+type BuildError struct {
+	Messages []BuildMessage
+}
+
+func (e *BuildError) Error() string {
+	lines := make([]string, len(e.Messages))
+	for i, m := range e.Messages {
+		lines[i] = "  " + m.String()
 	}
+	return fmt.Sprintf("build failed with %d error(s):\n%s", len(e.Messages), strings.Join(lines, "\n"))
+}
 
-	outputPath := filepath.Join(distDir, "index.js")
+// BuildResult describes what BuildWorker wrote.
+type BuildResult struct {
+	Outfile string
+	// Files are every file written, including chunks and the sourcemap.
+	Files []string
+	// MetafilePath is empty unless BuildOptions.Metafile was set.
+	MetafilePath string
+	Metafile     string
+	Warnings     []BuildMessage
+}
 
-	result := api.Build(api.BuildOptions{
-		EntryPoints: []string{entryPoint},
-		Outfile:     outputPath,
-		Bundle:      true,
-		Format:      api.FormatESModule,
-		Target:      api.ESNext,
-		Platform:    api.PlatformBrowser, // Cloudflare Workers are browser-like
-		Write:       true,
-		LogLevel:    api.LogLevelInfo,
-		External:    []string{"node:*"}, // Cloudflare Workers don't support Node built-ins directly
-	})
+// BuildWorker bundles a worker in-process with esbuild. It is the single build pipeline
+// shared by deploy, test and the wrangler [build] command, so dev and deploy produce the
+// same bundle. Bare Node imports are always aliased to node:*, unsupported built-ins are
+// stubbed with NodeMocksPath and a require() shim banner is added, with or without
+// nodejs_compat; the flags only pick esbuild's platform.
+func BuildWorker(cfg *AerostackConfig, opts BuildOptions) (*BuildResult, error) {
+	entry := opts.Entry
+	if entry == "" {
+		entry = cfg.Main
+	}
+	if entry == "" {
+		entry = "src/index.ts"
+	}
+	outfile := opts.Outfile
+	if outfile == "" {
+		outfile = filepath.Join("dist", "worker.js")
+	}
+	cwd, err := os.Getwd()
+	if err != nil {
+		return nil, err
+	}
 
+	buildOpts := api.BuildOptions{
+		EntryPoints:   []string{entry},
+		Outfile:       outfile,
+		AbsWorkingDir: cwd,
+		Bundle:        true,
+		Format:        api.FormatESModule,
+		Target:        api.ESNext,
+		Platform:      api.PlatformBrowser, // Cloudflare Workers are browser-like
+		Write:         true,
+		LogLevel:      api.LogLevelSilent,
+		Alias:         map[string]string{"@shared": "./shared"},
+		External:      []string{"node:*", "cloudflare:*"},
+		Metafile:      opts.Metafile,
+	}
+	if opts.Minify {
+		buildOpts.MinifyWhitespace = true
+		buildOpts.MinifyIdentifiers = true
+		buildOpts.MinifySyntax = true
+	}
+	if opts.Sourcemap {
+		buildOpts.Sourcemap = api.SourceMapLinked
+	}
+
+	// Node built-ins get the same handling whatever the flags say, so a worker that
+	// builds here builds the same way everywhere else.
+	if _, nodeCompatV2 := nodeCompatFlags(cfg.CompatibilityFlags); !nodeCompatV2 {
+		buildOpts.Platform = api.PlatformNode
+	}
+	for _, m := range nodeBuiltins {
+		buildOpts.Alias[m] = "node:" + m
+	}
+	if err := writeNodeMocks(); err != nil {
+		return nil, err
+	}
+	// esbuild requires relative paths to start with ./ or ../ or it treats them as bare modules
+	mockPath := "./" + filepath.ToSlash(NodeMocksPath)
+	for _, m := range MockedNodeBuiltins {
+		buildOpts.Alias[m] = mockPath
+		buildOpts.Alias["node:"+m] = mockPath
+	}
+	buildOpts.Banner = map[string]string{"js": nodeCompatBanner}
+
+	result := api.Build(buildOpts)
+
+	warnings := buildMessages(result.Warnings)
+	if !opts.Quiet {
+		for _, w := range warnings {
+			fmt.Printf("⚠ esbuild: %s\n", w)
+		}
+	}
 	if len(result.Errors) > 0 {
-		for _, err := range result.Errors {
-			fmt.Printf("❌ esbuild error: %s\n", err.Text)
+		return nil, &BuildError{Messages: buildMessages(result.Errors)}
+	}
+
+	out := &BuildResult{Outfile: outfile, Warnings: warnings}
+	for _, f := range result.OutputFiles {
+		rel, err := filepath.Rel(cwd, f.Path)
+		if err != nil {
+			rel = f.Path
 		}
-		return "", fmt.Errorf("bundling failed with %d errors", len(result.Errors))
+		out.Files = append(out.Files, rel)
 	}
+	if opts.Metafile {
+		out.Metafile = result.Metafile
+		out.MetafilePath = MetafilePath(outfile)
+		if err := os.MkdirAll(filepath.Dir(out.MetafilePath), 0755); err != nil {
+			return nil, err
+		}
+		if err := os.WriteFile(out.MetafilePath, []byte(result.Metafile), 0644); err != nil {
+			return nil, fmt.Errorf("failed to write metafile: %w", err)
+		}
+	}
+	return out, nil
+}
+
+// MetafilePath returns where BuildWorker writes the metafile for an outfile. It lives in
+// .aerostack/ rather than dist/ so it's never uploaded with the bundle.
+func MetafilePath(outfile string) string {
+	base := strings.TrimSuffix(filepath.Base(outfile), filepath.Ext(outfile))
+	return filepath.Join(".aerostack", base+".meta.json")
+}
 
-	fmt.Printf("📦 Code bundled successfully: %s\n", outputPath)
-	return outputPath, nil
+func writeNodeMocks() error {
+	return WriteNodeMocks(NodeMocksPath)
+}
+
+// WriteNodeMocks writes the Node built-in stub to path.
+func WriteNodeMocks(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, []byte(nodeMocksContent), 0644)
+}
+
+// BuildScriptWorker is one bundle written by GenerateBuildScript.
+type BuildScriptWorker struct {
+	Entry   string `json:"entry"`
+	Outfile string `json:"outfile"`
+}
+
+// GenerateBuildScript returns a Node ESM script that bundles workers with the esbuild
+// options BuildWorker uses, so an ejected project builds the same without aerostack.
+// mocksPath is the node-mocks.cjs stub relative to the project root.
+func GenerateBuildScript(cfg *AerostackConfig, workers []BuildScriptWorker, mocksPath string) (string, error) {
+	options := map[string]any{
+		"bundle":   true,
+		"format":   "esm",
+		"target":   "esnext",
+		"platform": "browser",
+		"external": []string{"node:*", "cloudflare:*"},
+		"logLevel": "warning",
+	}
+	alias := map[string]string{"@shared": "./shared"}
+	if _, nodeCompatV2 := nodeCompatFlags(cfg.CompatibilityFlags); !nodeCompatV2 {
+		options["platform"] = "node"
+	}
+	for _, m := range nodeBuiltins {
+		alias[m] = "node:" + m
+	}
+	mockPath := "./" + filepath.ToSlash(mocksPath)
+	for _, m := range MockedNodeBuiltins {
+		alias[m] = mockPath
+		alias["node:"+m] = mockPath
+	}
+	options["banner"] = map[string]string{"js": nodeCompatBanner}
+	options["alias"] = alias
+
+	optionsJSON, err := json.MarshalIndent(options, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	workersJSON, err := json.MarshalIndent(workers, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	var sb strings.Builder
+	sb.WriteString("// Bundles the project's workers with the options 'aerostack build' used.\n")
+	sb.WriteString("// wrangler runs it as the [build] command; it needs esbuild as a dev dependency.\n")
+	sb.WriteString("import { build } from \"esbuild\";\n\n")
+	sb.WriteString(fmt.Sprintf("const options = %s;\n\n", optionsJSON))
+	sb.WriteString(fmt.Sprintf("const workers = %s;\n\n", workersJSON))
+	sb.WriteString("for (const { entry, outfile } of workers) {\n")
+	sb.WriteString("  await build({ ...options, entryPoints: [entry], outfile });\n")
+	sb.WriteString("}\n")
+	return sb.String(), nil
+}
+
+func nodeCompatFlags(flags []string) (nodeCompat, v2 bool) {
+	for _, f := range flags {
+		switch f {
+		case "nodejs_compat":
+			nodeCompat = true
+		case "nodejs_compat_v2":
+			nodeCompat, v2 = true, true
+		}
+	}
+	return nodeCompat, v2
+}
+
+func buildMessages(msgs []api.Message) []BuildMessage {
+	out := make([]BuildMessage, len(msgs))
+	for i, m := range msgs {
+		out[i] = BuildMessage{Text: m.Text}
+		if m.Location != nil {
+			out[i].File = m.Location.File
+			out[i].Line = m.Location.Line
+			out[i].Column = m.Location.Column
+		}
+	}
+	return out
 }
@@ -0,0 +1,167 @@
+package devserver
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		p := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestBuildWorker_NodeCompat(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+	writeFiles(t, dir, map[string]string{
+		"src/index.ts": `import { greet } from "@shared/greet";
+import fs from "fs";
+import path from "path";
+export default { fetch() { return new Response(greet(path.join("a", "b")) + typeof fs); } };
+`,
+		"shared/greet.ts": `export const greet = (s: string) => "hi " + s;`,
+	})
+
+	cfg := &AerostackConfig{Main: "src/index.ts", CompatibilityFlags: []string{"nodejs_compat_v2"}}
+	res, err := BuildWorker(cfg, BuildOptions{Sourcemap: true, Metafile: true, Quiet: true})
+	if err != nil {
+		t.Fatalf("BuildWorker: %v", err)
+	}
+
+	out, err := os.ReadFile(res.Outfile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bundle := string(out)
+	if !strings.Contains(bundle, "createRequire") {
+		t.Errorf("node compat banner missing")
+	}
+	if !strings.Contains(bundle, `from "node:path"`) {
+		t.Errorf("path should be aliased to external node:path")
+	}
+	if !strings.Contains(bundle, "not supported in Workers") {
+		t.Errorf("fs should be bundled from the node mocks")
+	}
+	if _, err := os.Stat(NodeMocksPath); err != nil {
+		t.Errorf("node mocks not written: %v", err)
+	}
+	if _, err := os.Stat(res.Outfile + ".map"); err != nil {
+		t.Errorf("sourcemap not written: %v", err)
+	}
+	if res.MetafilePath != filepath.Join(".aerostack", "worker.meta.json") || !strings.Contains(res.Metafile, "shared/greet.ts") {
+		t.Errorf("metafile = %q", res.MetafilePath)
+	}
+}
+
+func TestBuildWorker_NodeBuiltinsWithoutNodeCompat(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+	writeFiles(t, dir, map[string]string{
+		"src/index.ts": `import fs from "fs";
+import { createHash } from "crypto";
+export default { fetch() { return new Response(String(fs.existsSync) + String(createHash)); } };
+`,
+	})
+
+	// No compat flag: Node imports still bundle, the same for every caller
+	res, err := BuildWorker(&AerostackConfig{Main: "src/index.ts"}, BuildOptions{Minify: true, Quiet: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, _ := os.ReadFile(res.Outfile)
+	if !strings.Contains(string(out), "createRequire") {
+		t.Errorf("require() shim banner missing")
+	}
+	if !strings.Contains(string(out), `"node:crypto"`) {
+		t.Errorf("crypto should be aliased to node:crypto:\n%s", out)
+	}
+	if _, err := os.Stat(NodeMocksPath); err != nil {
+		t.Errorf("node mocks not written: %v", err)
+	}
+}
+
+func TestBuildWorker_ErrorsHaveLocation(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+	writeFiles(t, dir, map[string]string{
+		"src/index.ts": "export default {\n  fetch() { return missing(; }\n};\n",
+	})
+
+	_, err := BuildWorker(&AerostackConfig{Main: "src/index.ts"}, BuildOptions{Quiet: true})
+	var buildErr *BuildError
+	if !errors.As(err, &buildErr) {
+		t.Fatalf("expected *BuildError, got %v", err)
+	}
+	m := buildErr.Messages[0]
+	if m.File != "src/index.ts" || m.Line != 2 {
+		t.Errorf("location = %s:%d", m.File, m.Line)
+	}
+	if !strings.Contains(err.Error(), "src/index.ts:2:") {
+		t.Errorf("error text = %q", err.Error())
+	}
+}
+
+func TestWorkerBuildCommand(t *testing.T) {
+	cmd := WorkerBuildCommand("src/auth.ts", "dist/auth.js")
+	if !strings.Contains(cmd, ` build --quiet --entry "src/auth.ts" --outfile "dist/auth.js"`) {
+		t.Errorf("WorkerBuildCommand = %q", cmd)
+	}
+	if strings.Contains(cmd, "npx") {
+		t.Errorf("build command should not shell out to npx: %q", cmd)
+	}
+}
+
+func TestAnalyzeMetafile(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+	writeFiles(t, dir, map[string]string{
+		"src/index.ts": `import a from "pkg-a";
+import { pad } from "left-pad";
+import { readFileSync } from "fs";
+export default { fetch() { return new Response(a + pad + String(readFileSync)); } };
+`,
+		"node_modules/left-pad/package.json":                    `{"name":"left-pad","version":"2.0.0","main":"index.js"}`,
+		"node_modules/left-pad/index.js":                        `export const pad = "v2-` + strings.Repeat("x", 4000) + `";`,
+		"node_modules/pkg-a/package.json":                       `{"name":"pkg-a","version":"1.0.0","main":"index.js"}`,
+		"node_modules/pkg-a/index.js":                           `import { pad } from "left-pad"; export default "a" + pad;`,
+		"node_modules/pkg-a/node_modules/left-pad/package.json": `{"name":"left-pad","version":"1.0.0","main":"index.js"}`,
+		"node_modules/pkg-a/node_modules/left-pad/index.js":     `export const pad = "v1";`,
+	})
+
+	cfg := &AerostackConfig{Main: "src/index.ts", CompatibilityFlags: []string{"nodejs_compat"}}
+	res, err := BuildWorker(cfg, BuildOptions{Metafile: true, Quiet: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	a, err := AnalyzeMetafile(res.Metafile, res.Outfile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if a.OutputBytes == 0 || len(a.Modules) == 0 {
+		t.Fatalf("analysis = %+v", a)
+	}
+	if a.Modules[0].Path != "node_modules/left-pad/index.js" {
+		t.Errorf("largest module = %s", a.Modules[0].Path)
+	}
+	if len(a.Duplicates) != 1 || a.Duplicates[0].Name != "left-pad" || len(a.Duplicates[0].Locations) != 2 {
+		t.Fatalf("duplicates = %+v", a.Duplicates)
+	}
+	if v := a.Duplicates[0].Locations[0].Version; v != "2.0.0" {
+		t.Errorf("version = %q", v)
+	}
+	if len(a.MockedImports) != 1 || a.MockedImports[0].Module != "fs" || a.MockedImports[0].Importers[0] != "src/index.ts" {
+		t.Errorf("mocked imports = %+v", a.MockedImports)
+	}
+}
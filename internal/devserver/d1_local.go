@@ -0,0 +1,380 @@
+package devserver
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// D1MigrationsDir holds the D1 migrations, as wrangler's migrations_dir default.
+const D1MigrationsDir = "migrations"
+
+// d1MigrationsTable matches the table 'wrangler d1 migrations apply' creates, so either tool
+// can pick up where the other left off.
+const d1MigrationsTable = `CREATE TABLE IF NOT EXISTS d1_migrations(
+	id         INTEGER PRIMARY KEY AUTOINCREMENT,
+	name       TEXT UNIQUE,
+	applied_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL
+);`
+
+// d1ObjectDir is miniflare's storage dir for D1 under a wrangler state dir.
+const d1ObjectDir = "v3/d1/miniflare-D1DatabaseObject"
+
+// LocalD1StateDirs are the wrangler state dirs that may hold local D1 databases, most likely
+// first. Wrangler persists next to its config, so 'aerostack dev' (which runs with
+// .aerostack/wrangler.toml) uses .aerostack/.wrangler/state; a plain 'wrangler dev' in the
+// project root uses .wrangler/state.
+var LocalD1StateDirs = []string{
+	filepath.Join(".aerostack", ".wrangler", "state"),
+	filepath.Join(".wrangler", "state"),
+}
+
+// d1ObjectID is the file name miniflare gives a D1 database: its Durable Object ID derived
+// from the database_id, the same way miniflare's idFromName does.
+func d1ObjectID(databaseID string) string {
+	key := sha256.Sum256([]byte("miniflare-D1DatabaseObject"))
+	mac := hmac.New(sha256.New, key[:])
+	mac.Write([]byte(databaseID))
+	nameHmac := mac.Sum(nil)[:16]
+	mac = hmac.New(sha256.New, key[:])
+	mac.Write(nameHmac)
+	check := mac.Sum(nil)[:16]
+	return hex.EncodeToString(append(append([]byte{}, nameHmac...), check...))
+}
+
+// LocalD1Path returns the SQLite file backing a local D1 database and whether it exists yet.
+// When it doesn't, the path is where 'aerostack dev' will look for it.
+func LocalD1Path(db D1Database) (string, bool) {
+	file := d1ObjectID(db.DatabaseID) + ".sqlite"
+	for _, dir := range LocalD1StateDirs {
+		p := filepath.Join(dir, d1ObjectDir, file)
+		if _, err := os.Stat(p); err == nil {
+			return p, true
+		}
+	}
+	return filepath.Join(LocalD1StateDirs[0], d1ObjectDir, file), false
+}
+
+// OpenLocalD1 opens the SQLite file for a local D1 database. With create, a database that
+// hasn't been used yet is created where miniflare will find it.
+func OpenLocalD1(db D1Database, create bool) (*sql.DB, error) {
+	path, exists := LocalD1Path(db)
+	if !exists {
+		if !create {
+			return nil, fmt.Errorf("no local D1 database for %s yet. Run 'aerostack db migrate apply' or 'aerostack dev' first", db.Binding)
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return nil, err
+		}
+	}
+	conn, err := sql.Open("sqlite3", path+"?_busy_timeout=5000&_foreign_keys=on")
+	if err != nil {
+		return nil, fmt.Errorf("failed to open local D1 %s: %w", db.Binding, err)
+	}
+	if err := conn.Ping(); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to open local D1 %s: %w", db.Binding, err)
+	}
+	return conn, nil
+}
+
+// D1MigrationFiles lists dir's *.sql files in the order wrangler applies them.
+func D1MigrationFiles(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read %s: %w", dir, err)
+	}
+	var files []string
+	for _, e := range entries {
+		if !e.IsDir() && strings.HasSuffix(e.Name(), ".sql") {
+			files = append(files, e.Name())
+		}
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// ApplyD1MigrationsLocal applies dir's pending migrations to a local D1 database directly
+// through SQLite, recording them in d1_migrations exactly like wrangler. Each migration runs
+// in its own transaction; it returns the names applied.
+func ApplyD1MigrationsLocal(db D1Database, dir string) ([]string, error) {
+	files, err := D1MigrationFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+	conn, err := OpenLocalD1(db, true)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	if _, err := conn.Exec(d1MigrationsTable); err != nil {
+		return nil, fmt.Errorf("failed to create d1_migrations table: %w", err)
+	}
+	applied := map[string]bool{}
+	rows, err := conn.Query("SELECT name FROM d1_migrations")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read d1_migrations: %w", err)
+	}
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		applied[name] = true
+	}
+	rows.Close()
+
+	var done []string
+	for _, f := range files {
+		if applied[f] {
+			continue
+		}
+		content, err := os.ReadFile(filepath.Join(dir, f))
+		if err != nil {
+			return done, fmt.Errorf("failed to read %s: %w", f, err)
+		}
+		tx, err := conn.Begin()
+		if err != nil {
+			return done, fmt.Errorf("failed to begin tx for %s: %w", f, err)
+		}
+		if _, err := tx.Exec(string(content)); err != nil {
+			tx.Rollback()
+			return done, fmt.Errorf("migration %s failed: %w", f, err)
+		}
+		if _, err := tx.Exec("INSERT INTO d1_migrations (name) VALUES (?)", f); err != nil {
+			tx.Rollback()
+			return done, fmt.Errorf("failed to record migration %s: %w", f, err)
+		}
+		if err := tx.Commit(); err != nil {
+			return done, fmt.Errorf("failed to commit %s: %w", f, err)
+		}
+		done = append(done, f)
+	}
+	return done, nil
+}
+
+// IntrospectD1Local introspects a local D1 database by reading its SQLite file directly
+// (sqlite_master and PRAGMA table_info), without wrangler or network access.
+// sourceBinding: binding name for namespacing (e.g. "DB").
+func IntrospectD1Local(db D1Database, sourceBinding string) ([]TableSchema, error) {
+	conn, err := OpenLocalD1(db, false)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	return introspectSQLite(conn, sourceBinding)
+}
+
+func introspectSQLite(conn queryer, sourceBinding string) ([]TableSchema, error) {
+	rows, err := conn.Query("SELECT name, COALESCE(sql, '') FROM sqlite_master WHERE type='table' AND name NOT LIKE 'sqlite_%' AND name NOT LIKE '_cf_%' ORDER BY name")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list D1 tables: %w", err)
+	}
+	var names, ddl []string
+	for rows.Next() {
+		var name, createSQL string
+		if err := rows.Scan(&name, &createSQL); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		names = append(names, name)
+		ddl = append(ddl, createSQL)
+	}
+	rows.Close()
+
+	var schemas []TableSchema
+	for i, name := range names {
+		table := TableSchema{Name: name, SourceBinding: sourceBinding, Dialect: DialectSQLite}
+		if table.Columns, err = sqliteColumns(conn, name); err != nil {
+			return nil, fmt.Errorf("failed to read columns of %s: %w", name, err)
+		}
+		if table.ForeignKeys, err = sqliteForeignKeys(conn, name); err != nil {
+			return nil, fmt.Errorf("failed to read foreign keys of %s: %w", name, err)
+		}
+		if table.Indexes, err = sqliteIndexes(conn, name); err != nil {
+			return nil, fmt.Errorf("failed to read indexes of %s: %w", name, err)
+		}
+		applyRelations(&table, sqliteChecks(ddl[i]))
+		schemas = append(schemas, table)
+	}
+
+	// REFERENCES users without a column list points at the primary key
+	for i := range schemas {
+		for j, fk := range schemas[i].ForeignKeys {
+			if len(fk.RefColumns) > 0 {
+				continue
+			}
+			for _, t := range schemas {
+				if strings.EqualFold(t.Name, fk.RefTable) {
+					for _, c := range t.Columns {
+						if c.IsPrimary {
+							schemas[i].ForeignKeys[j].RefColumns = append(schemas[i].ForeignKeys[j].RefColumns, c.Name)
+						}
+					}
+				}
+			}
+		}
+	}
+	return schemas, nil
+}
+
+func sqliteColumns(conn queryer, table string) ([]ColumnSchema, error) {
+	rows, err := conn.Query(fmt.Sprintf("PRAGMA table_info(%s)", quoteIdent(table)))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var cols []ColumnSchema
+	for rows.Next() {
+		var cid, notNull, pk int
+		var colName, colType string
+		var dflt sql.NullString
+		if err := rows.Scan(&cid, &colName, &colType, &notNull, &dflt, &pk); err != nil {
+			return nil, err
+		}
+		cols = append(cols, ColumnSchema{
+			Name:       colName,
+			Type:       mapSQLiteType(colType),
+			SQLType:    colType,
+			IsNullable: notNull == 0,
+			IsPrimary:  pk > 0,
+			Default:    dflt.String,
+		})
+	}
+	return cols, rows.Err()
+}
+
+func sqliteForeignKeys(conn queryer, table string) ([]ForeignKey, error) {
+	rows, err := conn.Query(fmt.Sprintf("PRAGMA foreign_key_list(%s)", quoteIdent(table)))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	// One row per column; composite keys share an id and are ordered by seq
+	var fks []ForeignKey
+	byID := map[int]int{}
+	for rows.Next() {
+		var id, seq int
+		var refTable, from, onUpdate, onDelete, match string
+		var to sql.NullString
+		if err := rows.Scan(&id, &seq, &refTable, &from, &to, &onUpdate, &onDelete, &match); err != nil {
+			return nil, err
+		}
+		idx, ok := byID[id]
+		if !ok {
+			idx = len(fks)
+			byID[id] = idx
+			fks = append(fks, ForeignKey{RefTable: refTable, OnDelete: sqliteFKAction(onDelete), OnUpdate: sqliteFKAction(onUpdate)})
+		}
+		fks[idx].Columns = append(fks[idx].Columns, from)
+		if to.Valid {
+			fks[idx].RefColumns = append(fks[idx].RefColumns, to.String)
+		}
+	}
+	return fks, rows.Err()
+}
+
+func sqliteFKAction(action string) string {
+	if strings.EqualFold(action, "NO ACTION") {
+		return ""
+	}
+	return strings.ToUpper(action)
+}
+
+func sqliteIndexes(conn queryer, table string) ([]IndexSchema, error) {
+	rows, err := conn.Query(fmt.Sprintf("PRAGMA index_list(%s)", quoteIdent(table)))
+	if err != nil {
+		return nil, err
+	}
+	var indexes []IndexSchema
+	for rows.Next() {
+		var seq, unique, partial int
+		var name, origin string
+		if err := rows.Scan(&seq, &name, &unique, &origin, &partial); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		// origin is "c" for CREATE INDEX, "u" for UNIQUE constraints and "pk" for the primary key
+		if origin == "pk" {
+			continue
+		}
+		indexes = append(indexes, IndexSchema{Name: name, Unique: unique == 1, Constraint: origin == "u"})
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	sort.Slice(indexes, func(i, j int) bool { return indexes[i].Name < indexes[j].Name })
+
+	// Read columns only after index_list is closed; a single-connection pool can't interleave
+	for i := range indexes {
+		cols, err := sqliteIndexColumns(conn, indexes[i].Name)
+		if err != nil {
+			return nil, err
+		}
+		indexes[i].Columns = cols
+	}
+	return indexes, nil
+}
+
+// sqliteIndexColumns lists an index's columns in order; nil when it indexes an expression.
+func sqliteIndexColumns(conn queryer, index string) ([]string, error) {
+	rows, err := conn.Query(fmt.Sprintf("PRAGMA index_info(%s)", quoteIdent(index)))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var cols []string
+	expr := false
+	for rows.Next() {
+		var seqno, cid int
+		var name sql.NullString
+		if err := rows.Scan(&seqno, &cid, &name); err != nil {
+			return nil, err
+		}
+		if !name.Valid {
+			expr = true
+		}
+		cols = append(cols, name.String)
+	}
+	if expr {
+		return nil, rows.Err()
+	}
+	return cols, rows.Err()
+}
+
+var checkRe = regexp.MustCompile(`(?i)\bCHECK\s*\(`)
+
+// sqliteChecks extracts the CHECK (...) expressions from a CREATE TABLE statement; SQLite has no
+// catalog for them.
+func sqliteChecks(createSQL string) []string {
+	var checks []string
+	for _, loc := range checkRe.FindAllStringIndex(createSQL, -1) {
+		open := loc[1] - 1
+		if end := closingParen(createSQL[open:]); end > 0 {
+			checks = append(checks, createSQL[open:open+end+1])
+		}
+	}
+	return checks
+}
+
+// quoteIdent double-quotes an identifier; valid in both SQLite and Postgres.
+func quoteIdent(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
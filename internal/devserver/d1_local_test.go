@@ -0,0 +1,162 @@
+package devserver
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestD1ObjectID_MatchesMiniflare(t *testing.T) {
+	// Reference value from miniflare's durableObjectNamespaceIdFromName("miniflare-D1DatabaseObject", id)
+	want := "2c5939e231d2999c6fb1ca3f8ef04131175cf03f0735c91a1b088c09658a6389"
+	if got := d1ObjectID("aerostack-local"); got != want {
+		t.Errorf("d1ObjectID = %s, want %s", got, want)
+	}
+}
+
+func TestApplyD1MigrationsLocal(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+	writeFiles(t, dir, map[string]string{
+		"migrations/0001_users.sql": "CREATE TABLE users (id INTEGER PRIMARY KEY, email TEXT NOT NULL, bio TEXT);",
+		"migrations/0002_posts.sql": "CREATE TABLE posts (id INTEGER PRIMARY KEY, user_id INTEGER REFERENCES users(id));\nCREATE INDEX idx_posts_user ON posts(user_id);",
+	})
+	db := D1Database{Binding: "DB", DatabaseName: "local-db", DatabaseID: "aerostack-local"}
+
+	applied, err := ApplyD1MigrationsLocal(db, D1MigrationsDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(applied) != 2 {
+		t.Fatalf("applied = %v", applied)
+	}
+	path, exists := LocalD1Path(db)
+	if !exists || !strings.HasPrefix(path, filepath.Join(".aerostack", ".wrangler", "state", "v3", "d1")) {
+		t.Errorf("database path = %s (exists %v)", path, exists)
+	}
+
+	// Re-running is a no-op, and a new file is picked up on its own
+	writeFiles(t, dir, map[string]string{"migrations/0003_bio.sql": "ALTER TABLE users ADD COLUMN age INTEGER;"})
+	applied, err = ApplyD1MigrationsLocal(db, D1MigrationsDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(applied) != 1 || applied[0] != "0003_bio.sql" {
+		t.Errorf("second run applied = %v", applied)
+	}
+
+	schemas, err := IntrospectD1Local(db, "DB")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, s := range schemas {
+		names = append(names, s.Name)
+	}
+	if strings.Join(names, ",") != "d1_migrations,posts,users" {
+		t.Fatalf("tables = %v", names)
+	}
+	users := schemas[2]
+	if len(users.Columns) != 4 || !users.Columns[0].IsPrimary || users.Columns[1].IsNullable || !users.Columns[3].IsNullable {
+		t.Errorf("users columns = %+v", users.Columns)
+	}
+}
+
+func TestApplyD1MigrationsLocal_FailedMigrationIsNotRecorded(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+	writeFiles(t, dir, map[string]string{
+		"migrations/0001_ok.sql":  "CREATE TABLE a (id INTEGER);",
+		"migrations/0002_bad.sql": "CREATE TABLE b (id INTEGER); INSERT INTO missing VALUES (1);",
+	})
+	db := D1Database{Binding: "DB", DatabaseID: "test-db"}
+
+	applied, err := ApplyD1MigrationsLocal(db, D1MigrationsDir)
+	if err == nil || !strings.Contains(err.Error(), "0002_bad.sql") {
+		t.Fatalf("expected failure in 0002, got %v", err)
+	}
+	if len(applied) != 1 {
+		t.Errorf("applied = %v", applied)
+	}
+	schemas, _ := IntrospectD1Local(db, "DB")
+	for _, s := range schemas {
+		if s.Name == "b" {
+			t.Errorf("failed migration should be rolled back")
+		}
+	}
+}
+
+func TestIntrospectD1Local_MissingDatabase(t *testing.T) {
+	t.Chdir(t.TempDir())
+	_, err := IntrospectD1Local(D1Database{Binding: "DB", DatabaseID: "nope"}, "DB")
+	if err == nil || !strings.Contains(err.Error(), "migrate apply") {
+		t.Errorf("err = %v", err)
+	}
+}
+
+func TestIntrospectD1Local_Relations(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+	writeFiles(t, dir, map[string]string{
+		"migrations/0001_init.sql": `CREATE TABLE users (
+  id INTEGER PRIMARY KEY,
+  email TEXT NOT NULL UNIQUE,
+  role TEXT NOT NULL DEFAULT 'member' CHECK (role IN ('admin', 'member'))
+);
+CREATE TABLE memberships (
+  user_id INTEGER NOT NULL REFERENCES users ON DELETE CASCADE,
+  org_id INTEGER NOT NULL,
+  PRIMARY KEY (user_id, org_id)
+);
+CREATE INDEX idx_memberships_org ON memberships(org_id);
+CREATE INDEX idx_users_lower_email ON users(lower(email));`,
+	})
+	db := D1Database{Binding: "DB", DatabaseName: "local-db", DatabaseID: "aerostack-local"}
+	if _, err := ApplyD1MigrationsLocal(db, D1MigrationsDir); err != nil {
+		t.Fatal(err)
+	}
+	schemas, err := IntrospectD1Local(db, "DB")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var users, memberships TableSchema
+	for _, s := range schemas {
+		switch s.Name {
+		case "users":
+			users = s
+		case "memberships":
+			memberships = s
+		}
+	}
+
+	role := users.Columns[2]
+	if role.Default != "'member'" || strings.Join(role.EnumValues, ",") != "admin,member" {
+		t.Errorf("role = %+v", role)
+	}
+	if !users.Columns[1].IsUnique {
+		t.Error("email should be unique")
+	}
+	var constraint, expr bool
+	for _, idx := range users.Indexes {
+		if idx.Constraint && idx.Unique && strings.Join(idx.Columns, ",") == "email" {
+			constraint = true
+		}
+		if idx.Name == "idx_users_lower_email" && idx.Columns == nil {
+			expr = true
+		}
+	}
+	if !constraint || !expr {
+		t.Errorf("users indexes = %+v", users.Indexes)
+	}
+
+	if len(memberships.ForeignKeys) != 1 {
+		t.Fatalf("foreign keys = %+v", memberships.ForeignKeys)
+	}
+	fk := memberships.ForeignKeys[0]
+	if fk.RefTable != "users" || strings.Join(fk.Columns, ",") != "user_id" || strings.Join(fk.RefColumns, ",") != "id" || fk.OnDelete != "CASCADE" || fk.OnUpdate != "" {
+		t.Errorf("foreign key = %+v", fk)
+	}
+	if len(memberships.Indexes) != 1 || memberships.Indexes[0].Name != "idx_memberships_org" {
+		t.Errorf("memberships indexes = %+v (the primary key index must be skipped)", memberships.Indexes)
+	}
+}
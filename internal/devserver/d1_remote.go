@@ -0,0 +1,239 @@
+package devserver
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// cloudflareAPIBase is the Cloudflare API root the D1 HTTP driver talks to.
+var cloudflareAPIBase = "https://api.cloudflare.com/client/v4"
+
+func init() {
+	sql.Register("d1http", d1HTTPDriver{})
+}
+
+// OpenRemoteD1 connects to a deployed D1 database through Cloudflare's D1 HTTP API, using
+// CLOUDFLARE_API_TOKEN and CLOUDFLARE_ACCOUNT_ID like wrangler. Every statement is its own
+// request, so transactions are not available.
+func OpenRemoteD1(db D1Database) (*sql.DB, error) {
+	token, account := os.Getenv("CLOUDFLARE_API_TOKEN"), os.Getenv("CLOUDFLARE_ACCOUNT_ID")
+	if token == "" || account == "" {
+		return nil, fmt.Errorf("remote D1 needs CLOUDFLARE_API_TOKEN and CLOUDFLARE_ACCOUNT_ID (a token with D1 edit permission)")
+	}
+	if db.DatabaseID == "" || !uuidLike(db.DatabaseID) {
+		return nil, fmt.Errorf("D1 %s has no database_id yet. Run 'aerostack resources create' first", db.Binding)
+	}
+	return sql.Open("d1http", account+"/"+db.DatabaseID)
+}
+
+func uuidLike(s string) bool {
+	return len(s) == 36 && strings.Count(s, "-") == 4
+}
+
+type d1HTTPDriver struct{}
+
+// Open takes "<account id>/<database id>"; the token is read from the environment so it
+// never ends up in a DSN.
+func (d1HTTPDriver) Open(dsn string) (driver.Conn, error) {
+	account, database, ok := strings.Cut(dsn, "/")
+	if !ok {
+		return nil, fmt.Errorf("d1http: DSN must be <account id>/<database id>")
+	}
+	return &d1HTTPConn{
+		url:    fmt.Sprintf("%s/accounts/%s/d1/database/%s/raw", cloudflareAPIBase, account, database),
+		token:  os.Getenv("CLOUDFLARE_API_TOKEN"),
+		client: &http.Client{Timeout: 60 * time.Second},
+	}, nil
+}
+
+type d1HTTPConn struct {
+	url    string
+	token  string
+	client *http.Client
+}
+
+// d1RawResult is one statement's result from the /raw endpoint.
+type d1RawResult struct {
+	Results struct {
+		Columns []string `json:"columns"`
+		Rows    [][]any  `json:"rows"`
+	} `json:"results"`
+	Meta struct {
+		Changes   int64 `json:"changes"`
+		LastRowID int64 `json:"last_row_id"`
+	} `json:"meta"`
+	Success bool `json:"success"`
+}
+
+func (c *d1HTTPConn) run(ctx context.Context, query string, args []driver.NamedValue) ([]d1RawResult, error) {
+	params := make([]any, len(args))
+	for i, a := range args {
+		switch v := a.Value.(type) {
+		case []byte:
+			params[i] = string(v)
+		case time.Time:
+			params[i] = v.UTC().Format("2006-01-02 15:04:05")
+		default:
+			params[i] = v
+		}
+	}
+	body, err := json.Marshal(map[string]any{"sql": query, "params": params})
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, "POST", c.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.token)
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("D1 API request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	var out struct {
+		Result  []d1RawResult `json:"result"`
+		Success bool          `json:"success"`
+		Errors  []struct {
+			Code    int    `json:"code"`
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	if err := json.Unmarshal(data, &out); err != nil {
+		return nil, fmt.Errorf("D1 API returned %s: %s", resp.Status, strings.TrimSpace(string(data)))
+	}
+	if !out.Success || resp.StatusCode >= 400 {
+		msgs := make([]string, len(out.Errors))
+		for i, e := range out.Errors {
+			msgs[i] = e.Message
+		}
+		if len(msgs) == 0 {
+			msgs = append(msgs, resp.Status)
+		}
+		return nil, errors.New(strings.Join(msgs, "; "))
+	}
+	return out.Result, nil
+}
+
+func (c *d1HTTPConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	results, err := c.run(ctx, query, args)
+	if err != nil {
+		return nil, err
+	}
+	rows := &d1HTTPRows{}
+	// Several statements return several results; like SQLite, rows come from the last one
+	if len(results) > 0 {
+		last := results[len(results)-1]
+		rows.columns, rows.rows = last.Results.Columns, last.Results.Rows
+	}
+	return rows, nil
+}
+
+func (c *d1HTTPConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	results, err := c.run(ctx, query, args)
+	if err != nil {
+		return nil, err
+	}
+	var res d1HTTPResult
+	for _, r := range results {
+		res.changes += r.Meta.Changes
+		res.lastID = r.Meta.LastRowID
+	}
+	return res, nil
+}
+
+func (c *d1HTTPConn) Prepare(query string) (driver.Stmt, error) {
+	return &d1HTTPStmt{conn: c, query: query}, nil
+}
+
+func (c *d1HTTPConn) Close() error { return nil }
+
+func (c *d1HTTPConn) Begin() (driver.Tx, error) {
+	return nil, fmt.Errorf("transactions are not supported over the D1 HTTP API")
+}
+
+type d1HTTPStmt struct {
+	conn  *d1HTTPConn
+	query string
+}
+
+func (s *d1HTTPStmt) Close() error  { return nil }
+func (s *d1HTTPStmt) NumInput() int { return -1 }
+
+func (s *d1HTTPStmt) Exec(args []driver.Value) (driver.Result, error) {
+	return s.conn.ExecContext(context.Background(), s.query, namedValues(args))
+}
+
+func (s *d1HTTPStmt) Query(args []driver.Value) (driver.Rows, error) {
+	return s.conn.QueryContext(context.Background(), s.query, namedValues(args))
+}
+
+func namedValues(args []driver.Value) []driver.NamedValue {
+	named := make([]driver.NamedValue, len(args))
+	for i, v := range args {
+		named[i] = driver.NamedValue{Ordinal: i + 1, Value: v}
+	}
+	return named
+}
+
+type d1HTTPResult struct {
+	changes, lastID int64
+}
+
+func (r d1HTTPResult) LastInsertId() (int64, error) { return r.lastID, nil }
+func (r d1HTTPResult) RowsAffected() (int64, error) { return r.changes, nil }
+
+type d1HTTPRows struct {
+	columns []string
+	rows    [][]any
+	next    int
+}
+
+func (r *d1HTTPRows) Columns() []string { return r.columns }
+func (r *d1HTTPRows) Close() error      { return nil }
+
+func (r *d1HTTPRows) Next(dest []driver.Value) error {
+	if r.next >= len(r.rows) {
+		return io.EOF
+	}
+	row := r.rows[r.next]
+	r.next++
+	for i := range dest {
+		if i >= len(row) {
+			dest[i] = nil
+			continue
+		}
+		switch v := row[i].(type) {
+		case float64:
+			// JSON has no integers; SQLite INTEGER values come back whole
+			if v == math.Trunc(v) && math.Abs(v) < 1<<53 {
+				dest[i] = int64(v)
+			} else {
+				dest[i] = v
+			}
+		case nil, string, bool:
+			dest[i] = v
+		default:
+			b, _ := json.Marshal(v)
+			dest[i] = string(b)
+		}
+	}
+	return nil
+}
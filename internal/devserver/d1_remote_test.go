@@ -0,0 +1,69 @@
+package devserver
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestOpenRemoteD1(t *testing.T) {
+	var got struct {
+		SQL    string `json:"sql"`
+		Params []any  `json:"params"`
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/accounts/acc/d1/database/11111111-2222-3333-4444-555555555555/raw" || r.Header.Get("Authorization") != "Bearer tok" {
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte(`{"success":false,"errors":[{"code":10000,"message":"Authentication error"}]}`))
+			return
+		}
+		json.NewDecoder(r.Body).Decode(&got)
+		w.Write([]byte(`{"success":true,"result":[{"results":{"columns":["id","email","score"],"rows":[[1,"ada@example.com",1.5],[2,null,3]]},"meta":{"changes":0},"success":true}]}`))
+	}))
+	defer srv.Close()
+	defer func(base string) { cloudflareAPIBase = base }(cloudflareAPIBase)
+	cloudflareAPIBase = srv.URL
+
+	db := D1Database{Binding: "DB", DatabaseName: "app", DatabaseID: "11111111-2222-3333-4444-555555555555"}
+	t.Setenv("CLOUDFLARE_API_TOKEN", "")
+	if _, err := OpenRemoteD1(db); err == nil {
+		t.Error("expected an error without CLOUDFLARE_API_TOKEN")
+	}
+	t.Setenv("CLOUDFLARE_API_TOKEN", "tok")
+	t.Setenv("CLOUDFLARE_ACCOUNT_ID", "acc")
+	if _, err := OpenRemoteD1(D1Database{Binding: "DB", DatabaseID: "aerostack-local"}); err == nil {
+		t.Error("expected an error for a placeholder database_id")
+	}
+
+	conn, err := OpenRemoteD1(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	rows, err := conn.Query("SELECT id, email, score FROM users WHERE id > ?", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		var email *string
+		var score float64
+		if err := rows.Scan(&id, &email, &score); err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, id)
+	}
+	if len(ids) != 2 || got.SQL != "SELECT id, email, score FROM users WHERE id > ?" || len(got.Params) != 1 {
+		t.Errorf("ids = %v, request = %+v", ids, got)
+	}
+
+	t.Setenv("CLOUDFLARE_API_TOKEN", "wrong")
+	bad, _ := OpenRemoteD1(db)
+	defer bad.Close()
+	if _, err := bad.Exec("DELETE FROM users"); err == nil || err.Error() != "Authentication error" {
+		t.Errorf("err = %v, want the API's error message", err)
+	}
+}
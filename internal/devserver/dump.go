@@ -0,0 +1,411 @@
+package devserver
+
+import (
+	"bufio"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Dump formats of 'aerostack db dump'. Dumps hold data only; the schema comes from migrations.
+const (
+	DumpFormatSQL    = "sql"    // INSERT statements
+	DumpFormatNDJSON = "ndjson" // one {"table": ..., "row": {...}} object per line
+)
+
+// DumpFormats lists the formats Dump and Restore accept.
+var DumpFormats = []string{DumpFormatSQL, DumpFormatNDJSON}
+
+// DumpFormatFor picks a format from a file's extension: .ndjson and .jsonl are NDJSON,
+// anything else SQL.
+func DumpFormatFor(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".ndjson", ".jsonl":
+		return DumpFormatNDJSON
+	default:
+		return DumpFormatSQL
+	}
+}
+
+// DumpOptions controls what Dump writes.
+type DumpOptions struct {
+	Format string
+	Tables []string // only these tables (default: all)
+}
+
+// RestoreOptions controls how Restore loads a dump.
+type RestoreOptions struct {
+	Format string
+	Reset  bool     // empty the tables first
+	Tables []string // tables --reset empties (default: all)
+}
+
+// TableRows counts the rows dumped or restored for one table.
+type TableRows struct {
+	Table string
+	Rows  int
+}
+
+// dumpRow is one line of an NDJSON dump.
+type dumpRow struct {
+	Table string         `json:"table"`
+	Row   map[string]any `json:"row"`
+}
+
+// DumpD1Local writes the data of a local D1 database to w, parents before children.
+func DumpD1Local(db D1Database, w io.Writer, opts DumpOptions) ([]TableRows, error) {
+	conn, err := OpenLocalD1(db, false)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	return dumpSQLite(conn, db.Binding, w, opts)
+}
+
+// DumpD1Export dumps the output of 'wrangler d1 export' (schema and data as SQL) by loading
+// it into an in-memory SQLite database first.
+func DumpD1Export(exportPath, binding string, w io.Writer, opts DumpOptions) ([]TableRows, error) {
+	content, err := os.ReadFile(exportPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read D1 export: %w", err)
+	}
+	conn, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	conn.SetMaxOpenConns(1) // each connection would get its own empty :memory: database
+	if _, err := conn.Exec(string(content)); err != nil {
+		return nil, fmt.Errorf("failed to load D1 export: %w", err)
+	}
+	return dumpSQLite(conn, binding, w, opts)
+}
+
+func dumpSQLite(conn *sql.DB, binding string, w io.Writer, opts DumpOptions) ([]TableRows, error) {
+	s, err := newSQLiteSeeder(conn, binding)
+	if err != nil {
+		return nil, err
+	}
+	return s.dump(w, opts)
+}
+
+// DumpPostgres writes the data of a Postgres binding's schema to w from one consistent snapshot.
+func DumpPostgres(pg PostgresDatabase, w io.Writer, opts DumpOptions) ([]TableRows, error) {
+	s, err := newPostgresSeeder(pg.ConnectionString, pg.Binding, pg.SchemaName())
+	if err != nil {
+		return nil, err
+	}
+	defer s.conn.Close()
+	return s.dump(w, opts)
+}
+
+// RestoreD1Local loads a dump into a local D1 database in one transaction.
+func RestoreD1Local(db D1Database, r io.Reader, opts RestoreOptions) ([]TableRows, error) {
+	conn, err := OpenLocalD1(db, false)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	s, err := newSQLiteSeeder(conn, db.Binding)
+	if err != nil {
+		return nil, err
+	}
+	return s.restore(r, opts)
+}
+
+// RestorePostgres loads a dump into a Postgres binding's schema in one transaction and moves
+// every id sequence past the restored rows. Reset only empties tables in that schema.
+func RestorePostgres(pg PostgresDatabase, r io.Reader, opts RestoreOptions) ([]TableRows, error) {
+	s, err := newPostgresSeeder(pg.ConnectionString, pg.Binding, pg.SchemaName())
+	if err != nil {
+		return nil, err
+	}
+	defer s.conn.Close()
+	return s.restore(r, opts)
+}
+
+func (s *seeder) dump(w io.Writer, opts DumpOptions) ([]TableRows, error) {
+	for _, name := range opts.Tables {
+		if _, ok := s.table(name); !ok {
+			return nil, fmt.Errorf("no table %q to dump", name)
+		}
+	}
+	bw := bufio.NewWriter(w)
+	if opts.Format != DumpFormatNDJSON {
+		fmt.Fprintf(bw, "-- Aerostack data dump (%s), %s\n", dialectName(s.dialect), time.Now().UTC().Format(time.RFC3339))
+		bw.WriteString("-- Data only: apply migrations to the target first\n")
+	}
+
+	var counts []TableRows
+	err := s.inTx(func() error {
+		if s.dialect == DialectPostgres {
+			// Every table from the same snapshot
+			if _, err := s.tx.Exec("SET TRANSACTION ISOLATION LEVEL REPEATABLE READ READ ONLY"); err != nil {
+				return err
+			}
+		}
+		for _, t := range s.tables {
+			if len(opts.Tables) > 0 && !containsTable(opts.Tables, t) {
+				continue
+			}
+			n, err := s.dumpTable(bw, t, opts.Format)
+			if err != nil {
+				return fmt.Errorf("failed to dump %s: %w", t.Name, err)
+			}
+			counts = append(counts, TableRows{Table: tableLabel(t), Rows: n})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return counts, bw.Flush()
+}
+
+func (s *seeder) dumpTable(w *bufio.Writer, t TableSchema, format string) (int, error) {
+	var cols []ColumnSchema
+	for _, c := range t.Columns {
+		if !s.generated[t.Schema+"."+t.Name+"."+c.Name] {
+			cols = append(cols, c)
+		}
+	}
+	quoted := make([]string, len(cols))
+	var pk []string
+	for i, c := range cols {
+		quoted[i] = quoteIdent(c.Name)
+		if c.IsPrimary {
+			pk = append(pk, quoted[i])
+		}
+	}
+	order := "1"
+	if len(pk) > 0 {
+		order = strings.Join(pk, ", ")
+	}
+	rows, err := s.tx.Query(fmt.Sprintf("SELECT %s FROM %s ORDER BY %s", strings.Join(quoted, ", "), s.quoteTable(t), order))
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	if format != DumpFormatNDJSON {
+		fmt.Fprintf(w, "\n-- %s\n", tableLabel(t))
+	}
+	n := 0
+	for rows.Next() {
+		vals := make([]any, len(cols))
+		ptrs := make([]any, len(cols))
+		for i := range vals {
+			ptrs[i] = &vals[i]
+		}
+		if err := rows.Scan(ptrs...); err != nil {
+			return n, err
+		}
+		if format == DumpFormatNDJSON {
+			row := make(map[string]any, len(cols))
+			for i, c := range cols {
+				row[c.Name] = jsonValue(vals[i], s.isBinary(c))
+			}
+			line, err := json.Marshal(dumpRow{Table: tableLabel(t), Row: row})
+			if err != nil {
+				return n, err
+			}
+			w.Write(line)
+			w.WriteByte('\n')
+		} else {
+			literals := make([]string, len(cols))
+			for i, c := range cols {
+				literals[i] = s.sqlLiteral(vals[i], s.isBinary(c))
+			}
+			fmt.Fprintf(w, "INSERT INTO %s (%s) VALUES (%s);\n", quoteTableName(t), strings.Join(quoted, ", "), strings.Join(literals, ", "))
+		}
+		n++
+	}
+	return n, rows.Err()
+}
+
+func (s *seeder) restore(r io.Reader, opts RestoreOptions) ([]TableRows, error) {
+	s.restoring = true
+	var counts []TableRows
+	err := s.inTx(func() error {
+		if opts.Reset {
+			if err := s.reset(opts.Tables); err != nil {
+				return err
+			}
+		}
+		var err error
+		if opts.Format == DumpFormatNDJSON {
+			counts, err = s.restoreNDJSON(r)
+		} else {
+			var content []byte
+			if content, err = io.ReadAll(r); err == nil {
+				_, err = s.tx.Exec(string(content))
+			}
+		}
+		if err != nil {
+			return err
+		}
+		return s.syncSequences()
+	})
+	if err != nil {
+		return nil, err
+	}
+	if opts.Format != DumpFormatNDJSON {
+		// A SQL dump is run as-is; count what landed
+		for _, t := range s.tables {
+			var n int
+			if err := s.conn.QueryRow("SELECT COUNT(*) FROM " + s.quoteTable(t)).Scan(&n); err != nil {
+				return nil, err
+			}
+			counts = append(counts, TableRows{Table: tableLabel(t), Rows: n})
+		}
+	}
+	return counts, nil
+}
+
+func (s *seeder) restoreNDJSON(r io.Reader) ([]TableRows, error) {
+	var counts []TableRows
+	index := map[string]int{}
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 1024*1024), 64*1024*1024)
+	line := 0
+	for sc.Scan() {
+		line++
+		raw := strings.TrimSpace(sc.Text())
+		if raw == "" {
+			continue
+		}
+		dec := json.NewDecoder(strings.NewReader(raw))
+		dec.UseNumber()
+		var rec dumpRow
+		if err := dec.Decode(&rec); err != nil {
+			return nil, fmt.Errorf("line %d: invalid JSON: %w", line, err)
+		}
+		t, ok := s.table(rec.Table)
+		if !ok {
+			return nil, fmt.Errorf("line %d: no table %q in the database. Run 'aerostack db migrate apply' first", line, rec.Table)
+		}
+		if err := s.insert(t, rec.Row); err != nil {
+			return nil, fmt.Errorf("line %d (%s): %w", line, rec.Table, err)
+		}
+		i, seen := index[rec.Table]
+		if !seen {
+			i = len(counts)
+			index[rec.Table] = i
+			counts = append(counts, TableRows{Table: rec.Table})
+		}
+		counts[i].Rows++
+	}
+	return counts, sc.Err()
+}
+
+// syncSequences moves Postgres serial and identity sequences past the highest restored id,
+// so the next insert doesn't collide with a restored row.
+func (s *seeder) syncSequences() error {
+	if s.dialect != DialectPostgres {
+		return nil
+	}
+	for _, t := range s.tables {
+		for _, c := range t.Columns {
+			if !strings.Contains(c.Default, "nextval(") && !s.identity[t.Schema+"."+t.Name+"."+c.Name] {
+				continue
+			}
+			_, err := s.tx.Exec(fmt.Sprintf(
+				"SELECT setval(pg_get_serial_sequence($1, $2), COALESCE((SELECT MAX(%s) FROM %s), 0) + 1, false) WHERE pg_get_serial_sequence($1, $2) IS NOT NULL",
+				quoteIdent(c.Name), s.quoteTable(t)), s.quoteTable(t), c.Name)
+			if err != nil {
+				return fmt.Errorf("failed to reset the sequence of %s.%s: %w", t.Name, c.Name, err)
+			}
+		}
+	}
+	return nil
+}
+
+func (s *seeder) isBinary(c ColumnSchema) bool {
+	t := strings.ToLower(c.SQLType)
+	return t == "bytea" || strings.Contains(t, "blob")
+}
+
+// sqlLiteral renders a value for a portable INSERT. Binary values use the source dialect's
+// syntax: X'..' for SQLite, '\x..' for Postgres.
+func (s *seeder) sqlLiteral(v any, binary bool) string {
+	switch val := v.(type) {
+	case nil:
+		return "NULL"
+	case bool:
+		if val {
+			return "TRUE"
+		}
+		return "FALSE"
+	case int64:
+		return strconv.FormatInt(val, 10)
+	case float64:
+		return strconv.FormatFloat(val, 'g', -1, 64)
+	case time.Time:
+		return "'" + formatDumpTime(val) + "'"
+	case []byte:
+		if binary || !utf8.Valid(val) {
+			if s.dialect == DialectPostgres {
+				return `'\x` + hex.EncodeToString(val) + "'"
+			}
+			return "X'" + hex.EncodeToString(val) + "'"
+		}
+		return quoteLiteral(string(val))
+	case string:
+		return quoteLiteral(val)
+	default:
+		return quoteLiteral(fmt.Sprint(val))
+	}
+}
+
+// jsonValue converts a scanned value for an NDJSON dump; binary values become
+// {"$base64": "..."}, which Restore and JSON seed fixtures turn back into bytes.
+func jsonValue(v any, binary bool) any {
+	switch val := v.(type) {
+	case time.Time:
+		return formatDumpTime(val)
+	case []byte:
+		if binary || !utf8.Valid(val) {
+			return map[string]string{"$base64": base64.StdEncoding.EncodeToString(val)}
+		}
+		return string(val)
+	default:
+		return val
+	}
+}
+
+// formatDumpTime keeps UTC timestamps in SQLite's plain "YYYY-MM-DD HH:MM:SS" form.
+func formatDumpTime(t time.Time) string {
+	if t.Location() == time.UTC {
+		return t.Format("2006-01-02 15:04:05.999999999")
+	}
+	return t.Format("2006-01-02 15:04:05.999999999-07:00")
+}
+
+func quoteLiteral(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+// quoteTableName names a table in a dump: schema-qualified only outside public, so dumps
+// restore into either dialect.
+func quoteTableName(t TableSchema) string {
+	if t.Schema != "" && t.Schema != "public" {
+		return quoteIdent(t.Schema) + "." + quoteIdent(t.Name)
+	}
+	return quoteIdent(t.Name)
+}
+
+func dialectName(dialect string) string {
+	if dialect == DialectPostgres {
+		return "Postgres"
+	}
+	return "SQLite"
+}
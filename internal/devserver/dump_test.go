@@ -0,0 +1,123 @@
+package devserver
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func dumpTestDB(t *testing.T) D1Database {
+	t.Helper()
+	db := seedTestDB(t, map[string]string{
+		"migrations/0002_files.sql": "CREATE TABLE files (id INTEGER PRIMARY KEY, data BLOB, note TEXT);",
+	})
+	conn, err := OpenLocalD1(db, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	if _, err := conn.Exec(`
+		INSERT INTO users (id, email, name, role) VALUES (1, 'ada@example.com', 'O''Brien', 'admin'), (2, 'grace@example.com', NULL, 'member');
+		INSERT INTO posts (id, user_id, title, published) VALUES (1, 2, 'Hello', 1);
+		INSERT INTO files (id, data, note) VALUES (1, X'00FF10', 'line1
+line2');
+	`); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func TestDumpRestore_RoundTrip(t *testing.T) {
+	for _, format := range DumpFormats {
+		t.Run(format, func(t *testing.T) {
+			db := dumpTestDB(t)
+			var buf bytes.Buffer
+			counts, err := DumpD1Local(db, &buf, DumpOptions{Format: format})
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, c := range counts {
+				got = append(got, c.Table)
+			}
+			// Parents come before the tables that reference them
+			if strings.Join(got, ",") != "files,users,posts" {
+				t.Errorf("tables = %v", got)
+			}
+			if format == DumpFormatSQL && !strings.Contains(buf.String(), `INSERT INTO "users" ("id", "email", "name", "role", "created_at") VALUES (1, 'ada@example.com', 'O''Brien', 'admin', NULL);`) {
+				t.Errorf("unexpected SQL dump:\n%s", buf.String())
+			}
+			if format == DumpFormatNDJSON && !strings.Contains(buf.String(), `{"table":"files","row":{"data":{"$base64":"AP8Q"},"id":1,"note":"line1\nline2"}}`) {
+				t.Errorf("unexpected NDJSON dump:\n%s", buf.String())
+			}
+
+			dump := buf.String()
+			if _, err := RestoreD1Local(db, strings.NewReader(dump), RestoreOptions{Format: format}); err == nil {
+				t.Error("restoring over existing rows should fail without --reset")
+			}
+			if _, err := RestoreD1Local(db, strings.NewReader(dump), RestoreOptions{Format: format, Reset: true}); err != nil {
+				t.Fatal(err)
+			}
+			var again bytes.Buffer
+			if _, err := DumpD1Local(db, &again, DumpOptions{Format: format}); err != nil {
+				t.Fatal(err)
+			}
+			strip := func(s string) string { return s[strings.Index(s, "\n")+1:] } // SQL header has a timestamp
+			if strip(again.String()) != strip(dump) {
+				t.Errorf("restore changed the data:\n%s\n---\n%s", dump, again.String())
+			}
+		})
+	}
+}
+
+func TestDumpD1Local_Tables(t *testing.T) {
+	db := dumpTestDB(t)
+	var buf bytes.Buffer
+	counts, err := DumpD1Local(db, &buf, DumpOptions{Format: DumpFormatNDJSON, Tables: []string{"users"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(counts) != 1 || counts[0].Rows != 2 || strings.Count(buf.String(), "\n") != 2 {
+		t.Errorf("counts = %v, dump:\n%s", counts, buf.String())
+	}
+	if _, err := DumpD1Local(db, &buf, DumpOptions{Format: DumpFormatNDJSON, Tables: []string{"nope"}}); err == nil {
+		t.Error("expected an error for an unknown table")
+	}
+}
+
+func TestDumpFormatFor(t *testing.T) {
+	for path, want := range map[string]string{"a.sql": DumpFormatSQL, "a.ndjson": DumpFormatNDJSON, "a.JSONL": DumpFormatNDJSON, "": DumpFormatSQL} {
+		if got := DumpFormatFor(path); got != want {
+			t.Errorf("DumpFormatFor(%q) = %s, want %s", path, got, want)
+		}
+	}
+}
+
+func TestDumpRestorePostgres_StaysInBindingSchema(t *testing.T) {
+	connStr, conn := testPostgres(t)
+	schemas := testPostgresSchemas(t, conn, "billing", "shop")
+	billing, shop := schemas[0], schemas[1]
+	if _, err := conn.Exec("INSERT INTO " + shop + ".accounts (name) VALUES ('other app')"); err != nil {
+		t.Fatal(err)
+	}
+
+	pg := PostgresDatabase{Binding: "BILLING", ConnectionString: connStr, Schema: billing}
+	var buf bytes.Buffer
+	counts, err := DumpPostgres(pg, &buf, DumpOptions{Format: DumpFormatNDJSON})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(counts) != 1 || counts[0].Rows != 1 {
+		t.Errorf("dumped %+v, want only %s.accounts with 1 row", counts, billing)
+	}
+
+	if _, err := RestorePostgres(pg, &buf, RestoreOptions{Format: DumpFormatNDJSON, Reset: true}); err != nil {
+		t.Fatal(err)
+	}
+	if n := countRows(t, conn, billing+".accounts"); n != 1 {
+		t.Errorf("%s.accounts has %d rows after restore, want 1", billing, n)
+	}
+	if n := countRows(t, conn, shop+".accounts"); n != 2 {
+		t.Errorf("%s.accounts has %d rows, want 2: restore --reset emptied another binding's schema", shop, n)
+	}
+}
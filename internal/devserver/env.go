@@ -0,0 +1,101 @@
+package devserver
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// DefaultEnvs are always accepted by --env, even without an [env.<name>] table in aerostack.toml.
+var DefaultEnvs = []string{"staging", "production"}
+
+// envNameRe limits env names to what wrangler and the deploy API accept as an identifier.
+var envNameRe = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_-]*$`)
+
+// EnvOverride holds the overrides declared in an [env.<name>] table.
+// A nil slice or map means "inherit from the top level"; a non-nil one replaces it.
+type EnvOverride struct {
+	D1Databases        []D1Database
+	KVNamespaces       []KVNamespace
+	R2Buckets          []R2Bucket
+	Queues             []Queue
+	PostgresDatabases  []PostgresDatabase
+	CompatibilityFlags []string
+	Vars               map[string]string
+}
+
+// EnvNames returns every environment --env accepts: the defaults plus each declared
+// [env.<name>] table, sorted with the defaults first.
+func (cfg *AerostackConfig) EnvNames() []string {
+	names := append([]string{}, DefaultEnvs...)
+	var declared []string
+	for name := range cfg.EnvOverrides {
+		if !isDefaultEnv(name) {
+			declared = append(declared, name)
+		}
+	}
+	sort.Strings(declared)
+	return append(names, declared...)
+}
+
+// ValidateEnv returns an error if name is not staging, production or a declared [env.<name>].
+func (cfg *AerostackConfig) ValidateEnv(name string) error {
+	if !envNameRe.MatchString(name) {
+		return fmt.Errorf("invalid env %q: use letters, digits, '-' and '_'", name)
+	}
+	if isDefaultEnv(name) {
+		return nil
+	}
+	if _, ok := cfg.EnvOverrides[name]; ok {
+		return nil
+	}
+	return fmt.Errorf("unknown env %q: declare [env.%s] in aerostack.toml (available: %s)", name, name, strings.Join(cfg.EnvNames(), ", "))
+}
+
+// ForEnv returns a copy of cfg with the [env.<name>] overrides applied.
+// Binding lists and compatibility flags declared in the env replace the top-level ones;
+// vars are merged on top of the top-level [vars]. An undeclared env returns a plain copy.
+func (cfg *AerostackConfig) ForEnv(name string) *AerostackConfig {
+	out := *cfg
+	out.Vars = make(map[string]string, len(cfg.Vars))
+	for k, v := range cfg.Vars {
+		out.Vars[k] = v
+	}
+
+	ov, ok := cfg.EnvOverrides[name]
+	if !ok {
+		return &out
+	}
+	if ov.D1Databases != nil {
+		out.D1Databases = ov.D1Databases
+	}
+	if ov.KVNamespaces != nil {
+		out.KVNamespaces = ov.KVNamespaces
+	}
+	if ov.R2Buckets != nil {
+		out.R2Buckets = ov.R2Buckets
+	}
+	if ov.Queues != nil {
+		out.Queues = ov.Queues
+	}
+	if ov.PostgresDatabases != nil {
+		out.PostgresDatabases = ov.PostgresDatabases
+	}
+	if ov.CompatibilityFlags != nil {
+		out.CompatibilityFlags = ov.CompatibilityFlags
+	}
+	for k, v := range ov.Vars {
+		out.Vars[k] = v
+	}
+	return &out
+}
+
+func isDefaultEnv(name string) bool {
+	for _, e := range DefaultEnvs {
+		if e == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,160 @@
+package devserver
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const customEnvToml = `name = "app"
+compatibility_flags = ["nodejs_compat"]
+
+[[d1_databases]]
+binding = "DB"
+database_name = "app-db"
+
+[[kv_namespaces]]
+binding = "CACHE"
+id = "local-kv"
+
+[[queues.producers]]
+binding = "JOBS"
+queue = "app-jobs"
+
+[vars]
+LOG_LEVEL = "info"
+REGION = "eu"
+
+[env.preview]
+compatibility_flags = ["nodejs_compat_v2"]
+
+[[env.preview.d1_databases]]
+binding = "DB"
+database_name = "preview-db"
+database_id = "preview-id"
+
+[[env.preview.kv_namespaces]]
+binding = "CACHE"
+id = "preview-kv"
+
+[env.preview.vars]
+LOG_LEVEL = "debug"
+
+[env.qa-alice]
+`
+
+func TestParseEnvOverrides_CustomEnv(t *testing.T) {
+	cfg := mustParseToml(t, customEnvToml)
+
+	ov, ok := cfg.EnvOverrides["preview"]
+	if !ok {
+		t.Fatalf("env.preview missing: %+v", cfg.EnvOverrides)
+	}
+	if len(ov.D1Databases) != 1 || ov.D1Databases[0].DatabaseID != "preview-id" {
+		t.Errorf("d1 = %+v", ov.D1Databases)
+	}
+	if len(ov.KVNamespaces) != 1 || ov.KVNamespaces[0].ID != "preview-kv" {
+		t.Errorf("kv = %+v", ov.KVNamespaces)
+	}
+	if ov.Queues != nil {
+		t.Errorf("queues = %+v, want nil (inherit)", ov.Queues)
+	}
+	if len(ov.CompatibilityFlags) != 1 || ov.CompatibilityFlags[0] != "nodejs_compat_v2" {
+		t.Errorf("compatibility_flags = %v", ov.CompatibilityFlags)
+	}
+	if ov.Vars["LOG_LEVEL"] != "debug" {
+		t.Errorf("vars = %v", ov.Vars)
+	}
+	if _, ok := cfg.EnvOverrides["qa-alice"]; !ok {
+		t.Errorf("empty [env.qa-alice] should still be declared")
+	}
+}
+
+func TestParseEnvOverrides_KVRequiresID(t *testing.T) {
+	_, err := parseTomlString(t, `
+[[env.preview.kv_namespaces]]
+binding = "CACHE"
+`)
+	if err == nil || !strings.Contains(err.Error(), "env.preview.kv_namespaces[0].id") {
+		t.Fatalf("expected id error, got %v", err)
+	}
+}
+
+func TestEnvNames(t *testing.T) {
+	cfg := mustParseToml(t, customEnvToml)
+	got := strings.Join(cfg.EnvNames(), ",")
+	if got != "staging,production,preview,qa-alice" {
+		t.Errorf("EnvNames = %s", got)
+	}
+}
+
+func TestValidateEnv(t *testing.T) {
+	cfg := mustParseToml(t, customEnvToml)
+	for _, name := range []string{"staging", "production", "preview", "qa-alice"} {
+		if err := cfg.ValidateEnv(name); err != nil {
+			t.Errorf("ValidateEnv(%q) = %v", name, err)
+		}
+	}
+	err := cfg.ValidateEnv("qa")
+	if err == nil || !strings.Contains(err.Error(), "[env.qa]") || !strings.Contains(err.Error(), "preview") {
+		t.Errorf("ValidateEnv(qa) = %v", err)
+	}
+	if err := cfg.ValidateEnv("../prod"); err == nil {
+		t.Errorf("ValidateEnv should reject invalid names")
+	}
+}
+
+func TestForEnv(t *testing.T) {
+	cfg := mustParseToml(t, customEnvToml)
+	p := cfg.ForEnv("preview")
+
+	if p.D1Databases[0].DatabaseName != "preview-db" {
+		t.Errorf("d1 = %+v", p.D1Databases)
+	}
+	if p.KVNamespaces[0].ID != "preview-kv" {
+		t.Errorf("kv = %+v", p.KVNamespaces)
+	}
+	if len(p.Queues) != 1 || p.Queues[0].Name != "app-jobs" {
+		t.Errorf("queues should inherit, got %+v", p.Queues)
+	}
+	if p.CompatibilityFlags[0] != "nodejs_compat_v2" {
+		t.Errorf("flags = %v", p.CompatibilityFlags)
+	}
+	if p.Vars["LOG_LEVEL"] != "debug" || p.Vars["REGION"] != "eu" {
+		t.Errorf("vars = %v", p.Vars)
+	}
+	// The base config must be untouched
+	if cfg.Vars["LOG_LEVEL"] != "info" || cfg.D1Databases[0].DatabaseName != "app-db" {
+		t.Errorf("ForEnv mutated the base config: %+v", cfg)
+	}
+
+	s := cfg.ForEnv("staging")
+	if s.D1Databases[0].DatabaseName != "app-db" || s.Vars["LOG_LEVEL"] != "info" {
+		t.Errorf("undeclared env should inherit everything: %+v", s)
+	}
+}
+
+func TestGenerateWranglerToml_CustomEnv(t *testing.T) {
+	t.Chdir(t.TempDir())
+	cfg := mustParseToml(t, customEnvToml)
+	out := filepath.Join(t.TempDir(), "wrangler.toml")
+	if err := GenerateWranglerToml(cfg, out); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := os.ReadFile(out)
+	content := string(data)
+
+	for _, want := range []string{
+		"[env.preview]\ncompatibility_flags = [\"nodejs_compat_v2\"]",
+		"[[env.preview.d1_databases]]\nbinding = \"DB\"\ndatabase_name = \"preview-db\"",
+		"[[env.preview.kv_namespaces]]\nbinding = \"CACHE\"\nid = \"preview-kv\"",
+		"[[env.preview.queues.producers]]\nbinding = \"JOBS\"",
+		"[env.qa-alice]",
+		"[[env.staging.d1_databases]]\nbinding = \"DB\"\ndatabase_name = \"app-db\"",
+	} {
+		if !strings.Contains(content, want) {
+			t.Errorf("wrangler.toml missing %q\n%s", want, content)
+		}
+	}
+}
@@ -0,0 +1,337 @@
+package devserver
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Output formats of 'aerostack db erd'.
+const (
+	ERDFormatMermaid = "mermaid"
+	ERDFormatDOT     = "dot"
+	ERDFormatD2      = "d2"
+)
+
+// ERDFormats lists the formats GenerateERD accepts.
+var ERDFormats = []string{ERDFormatMermaid, ERDFormatDOT, ERDFormatD2}
+
+var nonIdentRe = regexp.MustCompile(`[^A-Za-z0-9_]+`)
+
+// erdEdge is a foreign key between two tables of the same binding.
+type erdEdge struct {
+	from, to TableSchema
+	fk       ForeignKey
+	nullable bool // a row may have no parent
+	unique   bool // at most one child per parent
+}
+
+// GenerateERD renders an entity-relationship diagram of the schemas, grouped by binding, with
+// relationships taken from foreign keys. Migration tracking tables are left out.
+func GenerateERD(schemas []TableSchema, format string) (string, error) {
+	tables := UserTables(schemas)
+	sortSchemas(tables)
+	edges := erdEdges(tables)
+
+	switch format {
+	case ERDFormatMermaid, "":
+		return erdMermaid(tables, edges), nil
+	case ERDFormatDOT:
+		return erdDOT(tables, edges), nil
+	case ERDFormatD2:
+		return erdD2(tables, edges), nil
+	default:
+		return "", fmt.Errorf("unknown format %q (expected %s)", format, strings.Join(ERDFormats, ", "))
+	}
+}
+
+// UserTables returns schemas without the migration tracking tables.
+func UserTables(schemas []TableSchema) []TableSchema {
+	var tables []TableSchema
+	for _, t := range schemas {
+		if !isTrackingTable(t.Name) {
+			tables = append(tables, t)
+		}
+	}
+	return tables
+}
+
+func erdEdges(tables []TableSchema) []erdEdge {
+	var edges []erdEdge
+	for _, t := range tables {
+		for _, fk := range t.ForeignKeys {
+			target, ok := findTable(tables, t, fk)
+			if !ok {
+				continue
+			}
+			e := erdEdge{from: t, to: target, fk: fk}
+			for _, name := range fk.Columns {
+				for _, c := range t.Columns {
+					if strings.EqualFold(c.Name, name) && c.IsNullable {
+						e.nullable = true
+					}
+				}
+			}
+			for _, idx := range t.Indexes {
+				if idx.Unique && sameColumns(idx.Columns, fk.Columns) {
+					e.unique = true
+				}
+			}
+			if sameColumns(primaryKey(t), lowerAll(fk.Columns)) {
+				e.unique = true
+			}
+			edges = append(edges, e)
+		}
+	}
+	return edges
+}
+
+func sameColumns(a, b []string) bool {
+	if len(a) != len(b) || len(a) == 0 {
+		return false
+	}
+	for i := range a {
+		if !strings.EqualFold(a[i], b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func lowerAll(s []string) []string {
+	out := make([]string, len(s))
+	for i, v := range s {
+		out[i] = strings.ToLower(v)
+	}
+	return out
+}
+
+// erdNodeID is a diagram-safe identifier for a table: DB_users, PG_billing_invoices.
+func erdNodeID(t TableSchema) string {
+	parts := []string{t.SourceBinding}
+	if t.Schema != "" && t.Schema != "public" {
+		parts = append(parts, t.Schema)
+	}
+	parts = append(parts, t.Name)
+	return strings.Trim(nonIdentRe.ReplaceAllString(strings.Join(parts, "_"), "_"), "_")
+}
+
+// erdKeys returns a column's key markers: PK, FK and UK.
+func erdKeys(t TableSchema, col ColumnSchema) []string {
+	var keys []string
+	if col.IsPrimary {
+		keys = append(keys, "PK")
+	}
+	if isForeignKeyColumn(t, col.Name) {
+		keys = append(keys, "FK")
+	}
+	if col.IsUnique && !col.IsPrimary {
+		keys = append(keys, "UK")
+	}
+	return keys
+}
+
+func isForeignKeyColumn(t TableSchema, name string) bool {
+	for _, fk := range t.ForeignKeys {
+		for _, c := range fk.Columns {
+			if strings.EqualFold(c, name) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// bindingGroups splits sorted tables into runs sharing a binding.
+func bindingGroups(tables []TableSchema) [][]TableSchema {
+	var groups [][]TableSchema
+	for i, t := range tables {
+		if i == 0 || t.SourceBinding != tables[i-1].SourceBinding {
+			groups = append(groups, nil)
+		}
+		groups[len(groups)-1] = append(groups[len(groups)-1], t)
+	}
+	return groups
+}
+
+// ─── Mermaid ────────────────────────────────────────────────────
+
+// erdMermaid renders a Mermaid erDiagram. Mermaid has no grouping for entities, so the binding
+// prefixes each entity name and heads its section as a comment.
+func erdMermaid(tables []TableSchema, edges []erdEdge) string {
+	var sb strings.Builder
+	sb.WriteString("erDiagram\n")
+	for _, group := range bindingGroups(tables) {
+		if group[0].SourceBinding != "" {
+			sb.WriteString(fmt.Sprintf("    %%%% %s\n", group[0].SourceBinding))
+		}
+		for _, t := range group {
+			sb.WriteString(fmt.Sprintf("    %s {\n", erdNodeID(t)))
+			for _, col := range t.Columns {
+				line := fmt.Sprintf("        %s %s", mermaidWord(col.SQLType), mermaidWord(col.Name))
+				if keys := erdKeys(t, col); len(keys) > 0 {
+					line += " " + strings.Join(keys, ", ")
+				}
+				if col.IsNullable && !col.IsPrimary {
+					line += ` "nullable"`
+				}
+				sb.WriteString(line + "\n")
+			}
+			sb.WriteString("    }\n")
+		}
+	}
+	for _, e := range edges {
+		parent := "||"
+		if e.nullable {
+			parent = "|o"
+		}
+		child := "o{"
+		if e.unique {
+			child = "o|"
+		}
+		sb.WriteString(fmt.Sprintf("    %s %s--%s %s : %q\n", erdNodeID(e.to), parent, child, erdNodeID(e.from), strings.Join(e.fk.Columns, ", ")))
+	}
+	return sb.String()
+}
+
+// mermaidWord makes a type or column name a single Mermaid token: "character varying(20)" →
+// character_varying_20.
+func mermaidWord(s string) string {
+	if s == "" {
+		return "any"
+	}
+	w := strings.Trim(nonIdentRe.ReplaceAllString(s, "_"), "_")
+	if w == "" {
+		return "any"
+	}
+	return w
+}
+
+// ─── Graphviz DOT ───────────────────────────────────────────────
+
+func erdDOT(tables []TableSchema, edges []erdEdge) string {
+	var sb strings.Builder
+	sb.WriteString("digraph erd {\n")
+	sb.WriteString("  graph [rankdir=LR, fontname=\"Helvetica\"];\n")
+	sb.WriteString("  node [shape=plaintext, fontname=\"Helvetica\"];\n")
+	sb.WriteString("  edge [arrowhead=crow, arrowtail=tee, dir=both];\n")
+	for _, group := range bindingGroups(tables) {
+		indent := "  "
+		if b := group[0].SourceBinding; b != "" {
+			sb.WriteString(fmt.Sprintf("\n  subgraph %s {\n", dotID("cluster_"+b)))
+			sb.WriteString(fmt.Sprintf("    label=%s;\n", dotID(b)))
+			indent = "    "
+		}
+		for _, t := range group {
+			title := t.Name
+			if t.Schema != "" && t.Schema != "public" {
+				title = t.Schema + "." + t.Name
+			}
+			sb.WriteString(fmt.Sprintf("%s%s [label=<\n", indent, dotID(erdNodeID(t))))
+			sb.WriteString(fmt.Sprintf("%s  <table border=\"0\" cellborder=\"1\" cellspacing=\"0\">\n", indent))
+			sb.WriteString(fmt.Sprintf("%s    <tr><td colspan=\"3\" bgcolor=\"lightgrey\"><b>%s</b></td></tr>\n", indent, htmlEscape(title)))
+			for _, col := range t.Columns {
+				name := htmlEscape(col.Name)
+				if col.IsNullable && !col.IsPrimary {
+					name = "<i>" + name + "</i>"
+				}
+				sb.WriteString(fmt.Sprintf("%s    <tr><td port=%q align=\"left\">%s</td><td align=\"left\">%s</td><td>%s</td></tr>\n",
+					indent, mermaidWord(col.Name), name, htmlEscape(col.SQLType), strings.Join(erdKeys(t, col), " ")))
+			}
+			sb.WriteString(fmt.Sprintf("%s  </table>\n%s>];\n", indent, indent))
+		}
+		if group[0].SourceBinding != "" {
+			sb.WriteString("  }\n")
+		}
+	}
+	if len(edges) > 0 {
+		sb.WriteString("\n")
+	}
+	for _, e := range edges {
+		attrs := []string{fmt.Sprintf("label=%s", dotID(strings.Join(e.fk.Columns, ", ")))}
+		if e.unique {
+			attrs = append(attrs, "arrowhead=tee")
+		}
+		if e.nullable {
+			attrs = append(attrs, "arrowtail=odot")
+		}
+		sb.WriteString(fmt.Sprintf("  %s:%s -> %s:%s [%s];\n",
+			dotID(erdNodeID(e.to)), dotID(mermaidWord(e.fk.RefColumns[0])),
+			dotID(erdNodeID(e.from)), dotID(mermaidWord(e.fk.Columns[0])),
+			strings.Join(attrs, ", ")))
+	}
+	sb.WriteString("}\n")
+	return sb.String()
+}
+
+func dotID(s string) string {
+	return `"` + strings.ReplaceAll(s, `"`, `\"`) + `"`
+}
+
+func htmlEscape(s string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", `"`, "&quot;").Replace(s)
+}
+
+// ─── D2 ─────────────────────────────────────────────────────────
+
+func erdD2(tables []TableSchema, edges []erdEdge) string {
+	var sb strings.Builder
+	path := func(t TableSchema) string {
+		if t.SourceBinding == "" {
+			return d2Key(erdNodeID(t))
+		}
+		return d2Key(t.SourceBinding) + "." + d2Key(erdNodeID(t))
+	}
+	for i, group := range bindingGroups(tables) {
+		if i > 0 {
+			sb.WriteString("\n")
+		}
+		indent := ""
+		if b := group[0].SourceBinding; b != "" {
+			sb.WriteString(fmt.Sprintf("%s: {\n", d2Key(b)))
+			indent = "  "
+		}
+		for _, t := range group {
+			title := t.Name
+			if t.Schema != "" && t.Schema != "public" {
+				title = t.Schema + "." + t.Name
+			}
+			sb.WriteString(fmt.Sprintf("%s%s: %s {\n", indent, d2Key(erdNodeID(t)), d2Key(title)))
+			sb.WriteString(fmt.Sprintf("%s  shape: sql_table\n", indent))
+			for _, col := range t.Columns {
+				line := fmt.Sprintf("%s  %s: %s", indent, d2Key(col.Name), d2Key(col.SQLType))
+				var constraints []string
+				for _, k := range erdKeys(t, col) {
+					constraints = append(constraints, map[string]string{"PK": "primary_key", "FK": "foreign_key", "UK": "unique"}[k])
+				}
+				switch len(constraints) {
+				case 0:
+				case 1:
+					line += " {constraint: " + constraints[0] + "}"
+				default:
+					line += " {constraint: [" + strings.Join(constraints, "; ") + "]}"
+				}
+				sb.WriteString(line + "\n")
+			}
+			sb.WriteString(indent + "}\n")
+		}
+		if group[0].SourceBinding != "" {
+			sb.WriteString("}\n")
+		}
+	}
+	if len(edges) > 0 {
+		sb.WriteString("\n")
+	}
+	for _, e := range edges {
+		sb.WriteString(fmt.Sprintf("%s.%s -> %s.%s\n", path(e.from), d2Key(e.fk.Columns[0]), path(e.to), d2Key(e.fk.RefColumns[0])))
+	}
+	return sb.String()
+}
+
+// d2Key quotes a D2 key or label unless it is a plain identifier.
+func d2Key(s string) string {
+	if s != "" && !nonIdentRe.MatchString(s) {
+		return s
+	}
+	return `"` + strings.ReplaceAll(s, `"`, `\"`) + `"`
+}
@@ -0,0 +1,101 @@
+package devserver
+
+import (
+	"strings"
+	"testing"
+)
+
+func erdTestSchemas() []TableSchema {
+	schemas := sqliteBlogSchemas()
+	schemas = append(schemas,
+		TableSchema{
+			Name: "d1_migrations", SourceBinding: "DB", Dialect: DialectSQLite,
+			Columns: []ColumnSchema{{Name: "id", SQLType: "INTEGER", IsPrimary: true}},
+		},
+		TableSchema{
+			Name: "invoices", Schema: "billing", SourceBinding: "PG", Dialect: DialectPostgres,
+			Columns: []ColumnSchema{
+				{Name: "id", SQLType: "uuid", IsPrimary: true},
+				{Name: "customer_id", SQLType: "integer", IsNullable: true},
+			},
+			ForeignKeys: []ForeignKey{{Columns: []string{"customer_id"}, RefSchema: "public", RefTable: "customers", RefColumns: []string{"id"}}},
+		},
+		TableSchema{
+			Name: "customers", Schema: "public", SourceBinding: "PG", Dialect: DialectPostgres,
+			Columns: []ColumnSchema{
+				{Name: "id", SQLType: "integer", IsPrimary: true},
+				{Name: "email", SQLType: "character varying(320)", IsUnique: true},
+			},
+		},
+	)
+	return schemas
+}
+
+func TestGenerateERD_Mermaid(t *testing.T) {
+	result, err := GenerateERD(erdTestSchemas(), ERDFormatMermaid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"erDiagram\n",
+		"    %% DB\n",
+		"    DB_users {\n",
+		"        INTEGER user_id FK\n",
+		"        character_varying_320 email UK\n",
+		"        uuid id PK\n",
+		`    DB_users ||--o{ DB_posts : "user_id"`,
+		`    PG_customers |o--o{ PG_billing_invoices : "customer_id"`,
+	} {
+		if !strings.Contains(result, want) {
+			t.Errorf("missing %q in:\n%s", want, result)
+		}
+	}
+	if strings.Contains(result, "d1_migrations") {
+		t.Error("migration tracking table should be left out")
+	}
+}
+
+func TestGenerateERD_DOT(t *testing.T) {
+	result, err := GenerateERD(erdTestSchemas(), ERDFormatDOT)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"digraph erd {\n",
+		`  subgraph "cluster_DB" {`,
+		`  subgraph "cluster_PG" {`,
+		"<b>billing.invoices</b>",
+		`<td port="user_id" align="left">user_id</td><td align="left">INTEGER</td><td>FK</td>`,
+		`  "DB_users":"id" -> "DB_posts":"user_id" [label="user_id"];`,
+		`  "PG_customers":"id" -> "PG_billing_invoices":"customer_id" [label="customer_id", arrowtail=odot];`,
+	} {
+		if !strings.Contains(result, want) {
+			t.Errorf("missing %q in:\n%s", want, result)
+		}
+	}
+}
+
+func TestGenerateERD_D2(t *testing.T) {
+	result, err := GenerateERD(erdTestSchemas(), ERDFormatD2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"DB: {\n  DB_posts: posts {\n    shape: sql_table\n",
+		"    id: INTEGER {constraint: primary_key}\n",
+		"    email: \"VARCHAR(320)\"\n",
+		"  PG_billing_invoices: \"billing.invoices\" {\n",
+		"DB.DB_posts.user_id -> DB.DB_users.id\n",
+		"PG.PG_billing_invoices.customer_id -> PG.PG_customers.id\n",
+	} {
+		if !strings.Contains(result, want) {
+			t.Errorf("missing %q in:\n%s", want, result)
+		}
+	}
+}
+
+func TestGenerateERD_UnknownFormat(t *testing.T) {
+	if _, err := GenerateERD(nil, "plantuml"); err == nil {
+		t.Error("expected an error for an unknown format")
+	}
+}
@@ -4,8 +4,7 @@ import (
 	"database/sql"
 	"encoding/json"
 	"fmt"
-	"os/exec"
-	"path/filepath"
+	"regexp"
 	"sort"
 	"strings"
 
@@ -13,212 +12,422 @@ import (
 	_ "github.com/lib/pq"
 )
 
-// WranglerResult represents the output of wrangler d1 execute --json
-type WranglerResult struct {
-	Results []map[string]interface{} `json:"results"`
-	Success bool                     `json:"success"`
-}
-
 // TableSchema represents the structure of a database table
 // SourceBinding: D1/Postgres binding name for namespacing (e.g. "DB", "PgDb")
 type TableSchema struct {
 	Name          string
+	Schema        string // Postgres schema, e.g. "public"; empty for D1
 	Columns       []ColumnSchema
 	SourceBinding string // e.g. "DB" or "PgDb" — used to avoid collisions across databases
+	Dialect       string // DialectSQLite or DialectPostgres
+	ForeignKeys   []ForeignKey
+	Indexes       []IndexSchema
 }
 
 // ColumnSchema represents a column in a table
 type ColumnSchema struct {
 	Name       string
-	Type       string
+	Type       string // TypeScript type
+	SQLType    string // declared SQL type, e.g. "INTEGER" or "character varying(255)"
 	IsNullable bool
 	IsPrimary  bool
+	IsUnique   bool     // covered on its own by a unique index or constraint
+	Default    string   // default expression as SQL, empty when there is none
+	EnumValues []string // allowed values of an enum type or CHECK (col IN (...)) constraint
+}
+
+// ForeignKey is a (possibly composite) reference from Columns to RefColumns of RefTable.
+type ForeignKey struct {
+	Columns    []string
+	RefSchema  string // Postgres schema of RefTable; empty for D1
+	RefTable   string
+	RefColumns []string
+	OnDelete   string // referential action as SQL, e.g. "CASCADE"; empty for NO ACTION
+	OnUpdate   string
+}
+
+// IndexSchema is a secondary index; indexes on expressions have no Columns.
+type IndexSchema struct {
+	Name    string
+	Columns []string
+	Unique  bool
+	// Constraint marks the index behind a UNIQUE constraint rather than a CREATE INDEX.
+	Constraint bool
+}
+
+// queryer is satisfied by *sql.DB and *sql.Tx, so introspection can run inside a transaction.
+type queryer interface {
+	Query(query string, args ...any) (*sql.Rows, error)
 }
 
-// IntrospectD1Local introspects a local D1 database via Wrangler.
-// projectRoot: directory containing wrangler.toml (where wrangler must run from).
-// sourceBinding: binding name for namespacing (e.g. "DB").
-func IntrospectD1Local(dbName, projectRoot, sourceBinding string) ([]TableSchema, error) {
-	// Use wrangler d1 execute --json to get table list
-	wranglerConfig := filepath.Join(".aerostack", "wrangler.toml")
-	cmd := exec.Command("npx", "wrangler", "d1", "execute", dbName, "--local", "--config", wranglerConfig, "--command", "SELECT name FROM sqlite_master WHERE type='table' AND name NOT LIKE 'sqlite_%' AND name NOT LIKE '_cf_%'", "--json")
-	cmd.Dir = projectRoot
-	out, err := cmd.Output()
+// IntrospectPostgres introspects an external Postgres database: the given schemas, or every
+// user schema when none are given.
+// sourceBinding: binding name for namespacing (e.g. "PgDb").
+func IntrospectPostgres(connStr, sourceBinding string, schemas ...string) ([]TableSchema, error) {
+	db, err := sql.Open("postgres", connStr)
 	if err != nil {
-		return nil, fmt.Errorf("failed to list D1 tables: %w (output: %s)", err, string(out))
+		return nil, fmt.Errorf("failed to connect to Postgres: %w", err)
 	}
+	defer db.Close()
 
-	var results []WranglerResult
-	if err := json.Unmarshal(out, &results); err != nil {
-		return nil, fmt.Errorf("failed to parse D1 tables JSON: %w (output: %s)", err, string(out))
+	if len(schemas) == 0 {
+		if schemas, err = queryStrings(db, `
+			SELECT nspname FROM pg_namespace
+			WHERE nspname NOT IN ('pg_catalog', 'information_schema')
+			AND nspname NOT LIKE 'pg\_%' AND nspname NOT LIKE '\_aerostack\_diff\_%'
+			ORDER BY nspname
+		`); err != nil {
+			return nil, fmt.Errorf("failed to list Postgres schemas: %w", err)
+		}
+	}
+	var tables []TableSchema
+	for _, schema := range schemas {
+		t, err := introspectPostgresSchema(db, schema, sourceBinding)
+		if err != nil {
+			return nil, err
+		}
+		tables = append(tables, t...)
 	}
+	return tables, nil
+}
 
-	if len(results) == 0 || !results[0].Success {
-		return nil, fmt.Errorf("D1 introspection failed or returned no results")
+// introspectPostgresSchema reads the tables of one Postgres schema from the catalog.
+func introspectPostgresSchema(db queryer, schema, sourceBinding string) ([]TableSchema, error) {
+	rows, err := db.Query(`
+		SELECT c.oid::bigint, c.relname
+		FROM pg_class c
+		JOIN pg_namespace n ON n.oid = c.relnamespace
+		WHERE n.nspname = $1 AND c.relkind IN ('r', 'p') AND NOT c.relispartition
+		ORDER BY c.relname
+	`, schema)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list Postgres tables: %w", err)
+	}
+	type pgTable struct {
+		oid  int64
+		name string
+	}
+	var tables []pgTable
+	for rows.Next() {
+		var t pgTable
+		if err := rows.Scan(&t.oid, &t.name); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		tables = append(tables, t)
 	}
+	rows.Close()
 
 	var schemas []TableSchema
-	for _, row := range results[0].Results {
-		name, ok := row["name"].(string)
-		if !ok || name == "" {
-			continue
+	for _, t := range tables {
+		table := TableSchema{Name: t.name, Schema: schema, SourceBinding: sourceBinding, Dialect: DialectPostgres}
+		if table.Columns, err = postgresColumns(db, t.oid); err != nil {
+			return nil, fmt.Errorf("failed to read columns of %s: %w", t.name, err)
 		}
-
-		colCmd := exec.Command("npx", "wrangler", "d1", "execute", dbName, "--local", "--config", wranglerConfig, "--command", fmt.Sprintf("PRAGMA table_info(%s)", name), "--json")
-		colCmd.Dir = projectRoot
-		colOut, err := colCmd.Output()
+		if table.ForeignKeys, err = postgresForeignKeys(db, t.oid); err != nil {
+			return nil, fmt.Errorf("failed to read foreign keys of %s: %w", t.name, err)
+		}
+		if table.Indexes, err = postgresIndexes(db, t.oid); err != nil {
+			return nil, fmt.Errorf("failed to read indexes of %s: %w", t.name, err)
+		}
+		checks, err := queryStrings(db, `SELECT pg_get_constraintdef(oid) FROM pg_constraint WHERE conrelid = $1 AND contype = 'c' ORDER BY conname`, t.oid)
 		if err != nil {
-			continue
+			return nil, fmt.Errorf("failed to read checks of %s: %w", t.name, err)
 		}
+		applyRelations(&table, checks)
+		schemas = append(schemas, table)
+	}
+	return schemas, nil
+}
 
-		var colResults []WranglerResult
-		if err := json.Unmarshal(colOut, &colResults); err != nil {
-			continue
-		}
+func postgresColumns(db queryer, oid int64) ([]ColumnSchema, error) {
+	rows, err := db.Query(`
+		SELECT a.attname, format_type(a.atttypid, a.atttypmod), NOT a.attnotnull,
+		       EXISTS (
+			       SELECT 1 FROM pg_index i
+			       WHERE i.indrelid = a.attrelid AND i.indisprimary AND a.attnum = ANY(i.indkey)
+		       ) AS is_primary,
+		       COALESCE(pg_get_expr(d.adbin, d.adrelid), ''),
+		       COALESCE((SELECT json_agg(e.enumlabel ORDER BY e.enumsortorder) FROM pg_enum e WHERE e.enumtypid = a.atttypid)::text, '')
+		FROM pg_attribute a
+		LEFT JOIN pg_attrdef d ON d.adrelid = a.attrelid AND d.adnum = a.attnum
+		WHERE a.attrelid = $1 AND a.attnum > 0 AND NOT a.attisdropped
+		ORDER BY a.attnum
+	`, oid)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
 
-		if len(colResults) == 0 || !colResults[0].Success {
-			continue
+	var cols []ColumnSchema
+	for rows.Next() {
+		var colName, sqlType, dflt, enumLabels string
+		var isNullable, isPrimary bool
+		if err := rows.Scan(&colName, &sqlType, &isNullable, &isPrimary, &dflt, &enumLabels); err != nil {
+			return nil, err
 		}
-
-		var cols []ColumnSchema
-		for _, colRow := range colResults[0].Results {
-			colName, _ := colRow["name"].(string)
-			colType, _ := colRow["type"].(string)
-			notNull, _ := colRow["notnull"].(float64)
-			pk, _ := colRow["pk"].(float64)
-
-			cols = append(cols, ColumnSchema{
-				Name:       colName,
-				Type:       mapSQLiteType(colType),
-				IsNullable: notNull == 0,
-				IsPrimary:  pk == 1,
-			})
+		col := ColumnSchema{
+			Name:       colName,
+			Type:       mapPostgresType(baseType(sqlType)),
+			SQLType:    sqlType,
+			IsNullable: isNullable,
+			IsPrimary:  isPrimary,
+			Default:    dflt,
+			EnumValues: jsonStrings(enumLabels),
 		}
-
-		schemas = append(schemas, TableSchema{
-			Name:          name,
-			Columns:       cols,
-			SourceBinding: sourceBinding,
-		})
+		if len(col.EnumValues) > 0 {
+			col.Type = "string"
+		}
+		cols = append(cols, col)
 	}
-
-	return schemas, nil
+	return cols, rows.Err()
 }
 
-// IntrospectPostgres introspects an external Postgres database.
-// sourceBinding: binding name for namespacing (e.g. "PgDb").
-func IntrospectPostgres(connStr, sourceBinding string) ([]TableSchema, error) {
-	db, err := sql.Open("postgres", connStr)
+// pgFKActions maps pg_constraint's confdeltype/confupdtype codes to SQL; 'a' (NO ACTION) is "".
+var pgFKActions = map[string]string{"r": "RESTRICT", "c": "CASCADE", "n": "SET NULL", "d": "SET DEFAULT"}
+
+func postgresForeignKeys(db queryer, oid int64) ([]ForeignKey, error) {
+	rows, err := db.Query(`
+		SELECT
+			(SELECT json_agg(a.attname ORDER BY k.ord) FROM unnest(con.conkey) WITH ORDINALITY k(num, ord)
+			 JOIN pg_attribute a ON a.attrelid = con.conrelid AND a.attnum = k.num)::text,
+			rn.nspname, rc.relname,
+			(SELECT json_agg(a.attname ORDER BY k.ord) FROM unnest(con.confkey) WITH ORDINALITY k(num, ord)
+			 JOIN pg_attribute a ON a.attrelid = con.confrelid AND a.attnum = k.num)::text,
+			con.confdeltype::text, con.confupdtype::text
+		FROM pg_constraint con
+		JOIN pg_class rc ON rc.oid = con.confrelid
+		JOIN pg_namespace rn ON rn.oid = rc.relnamespace
+		WHERE con.conrelid = $1 AND con.contype = 'f'
+		ORDER BY con.conname
+	`, oid)
 	if err != nil {
-		return nil, fmt.Errorf("failed to connect to Postgres: %w", err)
+		return nil, err
 	}
-	defer db.Close()
+	defer rows.Close()
+
+	var fks []ForeignKey
+	for rows.Next() {
+		var cols, refCols, onDelete, onUpdate string
+		var fk ForeignKey
+		if err := rows.Scan(&cols, &fk.RefSchema, &fk.RefTable, &refCols, &onDelete, &onUpdate); err != nil {
+			return nil, err
+		}
+		fk.Columns, fk.RefColumns = jsonStrings(cols), jsonStrings(refCols)
+		fk.OnDelete, fk.OnUpdate = pgFKActions[onDelete], pgFKActions[onUpdate]
+		fks = append(fks, fk)
+	}
+	return fks, rows.Err()
+}
 
+func postgresIndexes(db queryer, oid int64) ([]IndexSchema, error) {
 	rows, err := db.Query(`
-		SELECT table_name 
-		FROM information_schema.tables 
-		WHERE table_schema = 'public' 
-		AND table_type = 'BASE TABLE'
-	`)
+		SELECT ic.relname, i.indisunique,
+		       EXISTS (SELECT 1 FROM pg_constraint con WHERE con.conindid = i.indexrelid AND con.contype = 'u'),
+		       i.indexprs IS NOT NULL,
+		       COALESCE((SELECT json_agg(a.attname ORDER BY k.ord) FROM unnest(i.indkey::int2[]) WITH ORDINALITY k(num, ord)
+		                 JOIN pg_attribute a ON a.attrelid = i.indrelid AND a.attnum = k.num
+		                 WHERE k.ord <= i.indnkeyatts)::text, '')
+		FROM pg_index i
+		JOIN pg_class ic ON ic.oid = i.indexrelid
+		WHERE i.indrelid = $1 AND NOT i.indisprimary
+		ORDER BY ic.relname
+	`, oid)
 	if err != nil {
-		return nil, fmt.Errorf("failed to list Postgres tables: %w", err)
+		return nil, err
 	}
 	defer rows.Close()
 
-	var schemas []TableSchema
+	var indexes []IndexSchema
 	for rows.Next() {
-		var name string
-		if err := rows.Scan(&name); err != nil {
-			continue
+		var idx IndexSchema
+		var expr bool
+		var cols string
+		if err := rows.Scan(&idx.Name, &idx.Unique, &idx.Constraint, &expr, &cols); err != nil {
+			return nil, err
 		}
+		if !expr {
+			idx.Columns = jsonStrings(cols)
+		}
+		indexes = append(indexes, idx)
+	}
+	return indexes, rows.Err()
+}
 
-		colRows, err := db.Query(`
-			SELECT column_name, data_type, is_nullable, 
-			       EXISTS (
-				       SELECT 1 FROM information_schema.key_column_usage kcu
-				       JOIN information_schema.table_constraints tc ON kcu.constraint_name = tc.constraint_name
-				       WHERE kcu.table_name = $1 AND kcu.column_name = information_schema.columns.column_name 
-				       AND tc.constraint_type = 'PRIMARY KEY'
-			       ) as is_primary
-			FROM information_schema.columns
-			WHERE table_name = $1
-		`, name)
-		if err != nil {
+// applyRelations derives column-level facts from table-level ones: IsUnique from single-column
+// unique indexes, and EnumValues from CHECK (col IN (...)) constraints.
+func applyRelations(table *TableSchema, checks []string) {
+	for i := range table.Columns {
+		col := &table.Columns[i]
+		for _, idx := range table.Indexes {
+			if idx.Unique && len(idx.Columns) == 1 && strings.EqualFold(idx.Columns[0], col.Name) {
+				col.IsUnique = true
+			}
+		}
+	}
+	for _, check := range checks {
+		name, values, ok := checkEnum(check)
+		if !ok {
 			continue
 		}
+		for i := range table.Columns {
+			if strings.EqualFold(table.Columns[i].Name, name) && len(table.Columns[i].EnumValues) == 0 {
+				table.Columns[i].EnumValues = values
+			}
+		}
+	}
+}
+
+var (
+	checkInRe  = regexp.MustCompile(`(?is)^"?(\w+)"?\s+IN\s*\((.*)\)$`)
+	checkAnyRe = regexp.MustCompile(`(?is)^\(?"?(\w+)"?\)?(?:::[\w ]+)?\s*=\s*ANY\s*\(+\s*ARRAY\s*\[([^\]]*)\]\)*(?:::[\w \[\]]+)?\s*\)+$`)
+	enumItemRe = regexp.MustCompile(`^'((?:[^']|'')*)'(?:::[\w ]+)?$`)
+)
+
+// checkEnum recognises a CHECK constraint limiting one column to a list of strings, as written in
+// SQLite (status IN ('a', 'b')) or as Postgres normalises it (status = ANY (ARRAY['a'::text, ...])).
+func checkEnum(def string) (string, []string, bool) {
+	expr := strings.TrimSpace(def)
+	if len(expr) >= 5 && strings.EqualFold(expr[:5], "CHECK") {
+		expr = strings.TrimSpace(expr[5:])
+	}
+	for strings.HasPrefix(expr, "(") && strings.HasSuffix(expr, ")") && closingParen(expr) == len(expr)-1 {
+		expr = strings.TrimSpace(expr[1 : len(expr)-1])
+	}
+	m := checkInRe.FindStringSubmatch(expr)
+	if m == nil {
+		m = checkAnyRe.FindStringSubmatch(expr)
+	}
+	if m == nil {
+		return "", nil, false
+	}
+	var values []string
+	for _, item := range splitTopLevel(m[2]) {
+		v := enumItemRe.FindStringSubmatch(strings.TrimSpace(item))
+		if v == nil {
+			return "", nil, false
+		}
+		values = append(values, strings.ReplaceAll(v[1], "''", "'"))
+	}
+	return m[1], values, len(values) > 0
+}
 
-		var cols []ColumnSchema
-		for colRows.Next() {
-			var colName, dataType, isNullable string
-			var isPrimary bool
-			if err := colRows.Scan(&colName, &dataType, &isPrimary, &isNullable); err != nil {
-				continue
+// closingParen returns the index of the parenthesis closing the one at s[0], or -1.
+func closingParen(s string) int {
+	depth := 0
+	var quote byte
+	for i := 0; i < len(s); i++ {
+		switch ch := s[i]; {
+		case quote != 0:
+			if ch == quote {
+				quote = 0
+			}
+		case ch == '\'' || ch == '"':
+			quote = ch
+		case ch == '(':
+			depth++
+		case ch == ')':
+			depth--
+			if depth == 0 {
+				return i
 			}
-			cols = append(cols, ColumnSchema{
-				Name:       colName,
-				Type:       mapPostgresType(dataType),
-				IsNullable: isNullable == "YES",
-				IsPrimary:  isPrimary,
-			})
 		}
-		colRows.Close()
+	}
+	return -1
+}
+
+func queryStrings(db queryer, query string, args ...any) ([]string, error) {
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []string
+	for rows.Next() {
+		var s string
+		if err := rows.Scan(&s); err != nil {
+			return nil, err
+		}
+		out = append(out, s)
+	}
+	return out, rows.Err()
+}
 
-		schemas = append(schemas, TableSchema{
-			Name:          name,
-			Columns:       cols,
-			SourceBinding: sourceBinding,
-		})
+// jsonStrings decodes a json_agg text result; empty or invalid input gives nil.
+func jsonStrings(s string) []string {
+	var out []string
+	if s != "" {
+		_ = json.Unmarshal([]byte(s), &out)
 	}
+	return out
+}
 
-	return schemas, nil
+// baseType strips a type modifier: "numeric(10,2)" → "numeric".
+func baseType(t string) string {
+	open, close := strings.Index(t, "("), strings.Index(t, ")")
+	if open < 0 || close < open {
+		return t
+	}
+	return strings.TrimSpace(t[:open] + t[close+1:])
 }
 
 // GenerateTypeScript produces the TypeScript interfaces for the schemas.
 // Tables from different databases are namespaced to avoid collisions (e.g. DBUsers vs PgDbUsers).
 func GenerateTypeScript(schemas []TableSchema, meta *api.ProjectMetadata) string {
 	var sb strings.Builder
-	sb.WriteString("// Generated by Aerostack CLI. Do not edit manually.\n\n")
+	sb.WriteString(generatedHeader)
 
 	// 1. Table Interfaces
-	sort.Slice(schemas, func(i, j int) bool {
-		a, b := schemas[i], schemas[j]
-		if a.SourceBinding != b.SourceBinding {
-			return a.SourceBinding < b.SourceBinding
-		}
-		return a.Name < b.Name
-	})
-
+	sortSchemas(schemas)
 	for _, table := range schemas {
-		baseName := toPascalCase(table.Name)
-		interfaceName := baseName
-		if table.SourceBinding != "" {
-			interfaceName = toPascalCase(table.SourceBinding) + baseName
-		}
-		sb.WriteString(fmt.Sprintf("export interface %s {\n", interfaceName))
+		sb.WriteString(fmt.Sprintf("export interface %s {\n", tableTypeName(table)))
 		for _, col := range table.Columns {
 			nullable := ""
-			if col.IsNullable {
+			if nullableColumn(col) {
 				nullable = "?"
 			}
-			sb.WriteString(fmt.Sprintf("  %s%s: %s;\n", col.Name, nullable, col.Type))
+			sb.WriteString(fmt.Sprintf("  %s%s: %s;\n", jsKey(col.Name), nullable, tsColumnType(col)))
 		}
 		sb.WriteString("}\n\n")
 	}
 
 	// 2. Collection Data Types (from metadata)
-	if meta != nil {
-		for _, col := range meta.Collections {
-			interfaceName := toPascalCase(col.Slug) + "Item"
-			sb.WriteString(fmt.Sprintf("export interface %s {\n", interfaceName))
-			sb.WriteString("  id: string;\n")
-			sb.WriteString("  slug: string;\n")
-			sb.WriteString("  data: any;\n")
-			sb.WriteString("  created_at: number;\n")
-			sb.WriteString("}\n\n")
+	writeCollectionInterfaces(&sb, meta)
+
+	// 3. Custom API Types and the final Project Schema
+	writeProjectSchema(&sb, schemas, meta)
+	return sb.String()
+}
+
+// sortSchemas orders tables by binding, schema, then name, so output is stable across runs.
+func sortSchemas(schemas []TableSchema) {
+	sort.Slice(schemas, func(i, j int) bool {
+		a, b := schemas[i], schemas[j]
+		if a.SourceBinding != b.SourceBinding {
+			return a.SourceBinding < b.SourceBinding
+		}
+		if a.Schema != b.Schema {
+			return a.Schema < b.Schema
 		}
+		return a.Name < b.Name
+	})
+}
+
+// tableTypeName is the generated type for a table, prefixed with its binding and any
+// non-public Postgres schema: DbUsers, PgBillingInvoices.
+func tableTypeName(table TableSchema) string {
+	name := toPascalCase(table.Name)
+	if table.Schema != "" && table.Schema != "public" {
+		name = toPascalCase(table.Schema) + name
 	}
+	if table.SourceBinding != "" {
+		name = toPascalCase(table.SourceBinding) + name
+	}
+	return name
+}
 
-	// 3. Custom API Types
+// writeProjectSchema writes the custom API types and the ProjectSchema every format ends with.
+func writeProjectSchema(sb *strings.Builder, schemas []TableSchema, meta *api.ProjectMetadata) {
 	if meta != nil && len(meta.Hooks) > 0 {
 		sb.WriteString("export interface CustomApiSchema {\n")
 		for _, hook := range meta.Hooks {
@@ -230,15 +439,13 @@ func GenerateTypeScript(schemas []TableSchema, meta *api.ProjectMetadata) string
 		sb.WriteString("}\n\n")
 	}
 
-	// 4. Final Project Schema
 	sb.WriteString("export interface ProjectSchema {\n")
 
 	// Collections section
 	sb.WriteString("  collections: {\n")
 	if meta != nil {
 		for _, col := range meta.Collections {
-			interfaceName := toPascalCase(col.Slug) + "Item"
-			sb.WriteString(fmt.Sprintf("    %q: %s;\n", col.Slug, interfaceName))
+			sb.WriteString(fmt.Sprintf("    %q: %s;\n", col.Slug, collectionTypeName(col.Slug)))
 		}
 	}
 	sb.WriteString("  };\n")
@@ -254,23 +461,20 @@ func GenerateTypeScript(schemas []TableSchema, meta *api.ProjectMetadata) string
 	// Database section
 	sb.WriteString("  db: {\n")
 	for _, table := range schemas {
-		interfaceName := toPascalCase(table.Name)
-		if table.SourceBinding != "" {
-			interfaceName = toPascalCase(table.SourceBinding) + interfaceName
-		}
 		key := table.Name
+		if table.Schema != "" && table.Schema != "public" {
+			key = table.Schema + "." + key
+		}
 		if table.SourceBinding != "" {
-			key = table.SourceBinding + "." + table.Name
+			key = table.SourceBinding + "." + key
 		}
-		sb.WriteString(fmt.Sprintf("    %q: %s;\n", key, interfaceName))
+		sb.WriteString(fmt.Sprintf("    %q: %s;\n", key, tableTypeName(table)))
 	}
 	sb.WriteString("  };\n")
 
 	sb.WriteString("  queues: Record<string, any>;\n")
 	sb.WriteString("  cache: Record<string, any>;\n")
 	sb.WriteString("}\n")
-
-	return sb.String()
 }
 
 func parseTableNames(out string) []string {
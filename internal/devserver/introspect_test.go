@@ -239,6 +239,36 @@ func TestGenerateTypeScript_SingleTable(t *testing.T) {
 	}
 }
 
+func TestGenerateTypeScript_QuotesKeysAndPrimaryKeysAreRequired(t *testing.T) {
+	schemas := []TableSchema{
+		{
+			Name:    "users",
+			Dialect: DialectSQLite,
+			Columns: []ColumnSchema{
+				// SQLite's PRAGMA reports notnull=0 for INTEGER PRIMARY KEY
+				{Name: "id", Type: "number", SQLType: "INTEGER", IsNullable: true, IsPrimary: true},
+				{Name: "first name", Type: "string", IsNullable: true},
+			},
+		},
+	}
+
+	result := GenerateTypeScript(schemas, nil)
+	if !strings.Contains(result, "  id: number;") {
+		t.Errorf("primary key should not be optional:\n%s", result)
+	}
+	if !strings.Contains(result, `  'first name'?: string;`) {
+		t.Errorf("column with a space should be quoted:\n%s", result)
+	}
+
+	zod, err := GenerateTypes(schemas, nil, TypeFormatZod)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(zod, "  id: z.number().int(),") {
+		t.Errorf("primary key should not be nullable in zod:\n%s", zod)
+	}
+}
+
 func TestGenerateTypeScript_WithSourceBinding(t *testing.T) {
 	schemas := []TableSchema{
 		{
@@ -397,3 +427,41 @@ func TestGenerateTypeScript_HeaderComment(t *testing.T) {
 		t.Error("missing header comment")
 	}
 }
+
+// ─── checkEnum ──────────────────────────────────────────────────
+
+func TestCheckEnum(t *testing.T) {
+	tests := []struct {
+		def, col string
+		values   []string
+	}{
+		{"CHECK (role IN ('admin', 'member'))", "role", []string{"admin", "member"}},
+		{`("status" in ('it''s', 'b'))`, "status", []string{"it's", "b"}},
+		{"CHECK ((status = ANY (ARRAY['draft'::text, 'live'::text])))", "status", []string{"draft", "live"}},
+		{"CHECK (((kind)::text = ANY ((ARRAY['a'::character varying, 'b'::character varying])::text[])))", "kind", []string{"a", "b"}},
+	}
+	for _, tt := range tests {
+		col, values, ok := checkEnum(tt.def)
+		if !ok || col != tt.col || strings.Join(values, "|") != strings.Join(tt.values, "|") {
+			t.Errorf("checkEnum(%q) = %q %q %v", tt.def, col, values, ok)
+		}
+	}
+	for _, def := range []string{"CHECK (price > 0)", "CHECK (n IN (1, 2))", "CHECK (a IN ('x') AND b > 1)"} {
+		if _, _, ok := checkEnum(def); ok {
+			t.Errorf("checkEnum(%q) should not match", def)
+		}
+	}
+}
+
+func TestGenerateTypeScript_NonPublicSchema(t *testing.T) {
+	schemas := []TableSchema{
+		{Name: "invoices", Schema: "billing", SourceBinding: "PG", Columns: []ColumnSchema{{Name: "id", Type: "number"}}},
+		{Name: "invoices", Schema: "public", SourceBinding: "PG", Columns: []ColumnSchema{{Name: "id", Type: "number"}}},
+	}
+	result := GenerateTypeScript(schemas, nil)
+	for _, want := range []string{"export interface PgBillingInvoices {", "export interface PgInvoices {", `"PG.billing.invoices": PgBillingInvoices;`, `"PG.invoices": PgInvoices;`} {
+		if !strings.Contains(result, want) {
+			t.Errorf("missing %q in:\n%s", want, result)
+		}
+	}
+}
@@ -0,0 +1,193 @@
+package devserver
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aerostackdev/cli/internal/printer"
+)
+
+// LogDir is where LogMux writes per-worker logs when enabled.
+var LogDir = filepath.Join(".aerostack", "logs")
+
+// ansiRe matches terminal color/cursor escapes wrangler emits; they are kept on the terminal
+// but stripped from JSON and log files.
+var ansiRe = regexp.MustCompile(`\x1b\[[0-9;?]*[A-Za-z]`)
+
+// LogMuxOptions configures NewLogMux.
+type LogMuxOptions struct {
+	// Filter limits terminal output to these workers; log files always get every worker.
+	Filter []string
+	// LogDir, when set, appends each worker's output to <LogDir>/<name>.log.
+	LogDir string
+	// JSON writes one LogLine per line instead of prefixed, colored text.
+	JSON bool
+}
+
+// LogLine is the --json shape of one line of worker output.
+type LogLine struct {
+	Time    time.Time `json:"time"`
+	Service string    `json:"service"`
+	Stream  string    `json:"stream"`
+	Message string    `json:"message"`
+}
+
+// LogMux routes the output of several wrangler dev processes into one stream, so a
+// multi-worker 'aerostack dev' reads line by line instead of interleaving mid-line.
+type LogMux struct {
+	mu     sync.Mutex
+	out    io.Writer
+	opts   LogMuxOptions
+	filter map[string]bool
+	colors map[string]int
+	width  int
+	files  map[string]*os.File
+	now    func() time.Time
+}
+
+func NewLogMux(out io.Writer, opts LogMuxOptions) *LogMux {
+	m := &LogMux{
+		out:    out,
+		opts:   opts,
+		colors: map[string]int{},
+		files:  map[string]*os.File{},
+		now:    time.Now,
+	}
+	if len(opts.Filter) > 0 {
+		m.filter = map[string]bool{}
+		for _, name := range opts.Filter {
+			m.filter[name] = true
+		}
+	}
+	return m
+}
+
+// Register assigns colors in order and widens the prefix column so it stays aligned.
+func (m *LogMux) Register(names ...string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, name := range names {
+		m.register(name)
+	}
+}
+
+func (m *LogMux) register(name string) int {
+	idx, ok := m.colors[name]
+	if !ok {
+		idx = len(m.colors)
+		m.colors[name] = idx
+	}
+	if len(name) > m.width {
+		m.width = len(name)
+	}
+	return idx
+}
+
+// Writer returns a writer for one stream ("stdout" or "stderr") of a worker. It buffers
+// partial lines; Close flushes whatever is left.
+func (m *LogMux) Writer(service, stream string) io.WriteCloser {
+	m.Register(service)
+	return &lineWriter{mux: m, service: service, stream: stream}
+}
+
+// Line writes a single line for a worker.
+func (m *LogMux) Line(service, stream, message string) error {
+	message = strings.TrimRight(message, "\r")
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	idx := m.register(service)
+	now := m.now()
+
+	// A failing log file must not hide output from the terminal
+	var fileErr error
+	if m.opts.LogDir != "" {
+		fileErr = m.writeFile(service, stream, message, now)
+	}
+	if m.filter != nil && !m.filter[service] {
+		return fileErr
+	}
+	if m.opts.JSON {
+		data, err := json.Marshal(LogLine{Time: now, Service: service, Stream: stream, Message: ansiRe.ReplaceAllString(message, "")})
+		if err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintf(m.out, "%s\n", data); err != nil {
+			return err
+		}
+		return fileErr
+	}
+	if _, err := fmt.Fprintf(m.out, "%s %s\n", printer.ServiceTag(service, idx, m.width), message); err != nil {
+		return err
+	}
+	return fileErr
+}
+
+func (m *LogMux) writeFile(service, stream, message string, now time.Time) error {
+	f, ok := m.files[service]
+	if !ok {
+		if err := os.MkdirAll(m.opts.LogDir, 0755); err != nil {
+			return err
+		}
+		var err error
+		f, err = os.OpenFile(filepath.Join(m.opts.LogDir, service+".log"), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+		if err != nil {
+			return fmt.Errorf("failed to open log file for %s: %w", service, err)
+		}
+		m.files[service] = f
+	}
+	_, err := fmt.Fprintf(f, "%s %s %s\n", now.Format(time.RFC3339), stream, ansiRe.ReplaceAllString(message, ""))
+	return err
+}
+
+// Close closes the per-worker log files.
+func (m *LogMux) Close() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var firstErr error
+	for name, f := range m.files {
+		if err := f.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+		delete(m.files, name)
+	}
+	return firstErr
+}
+
+type lineWriter struct {
+	mux     *LogMux
+	service string
+	stream  string
+	buf     []byte
+}
+
+func (w *lineWriter) Write(p []byte) (int, error) {
+	w.buf = append(w.buf, p...)
+	for {
+		i := bytes.IndexByte(w.buf, '\n')
+		if i < 0 {
+			break
+		}
+		// Errors are dropped: failing here would break the pipe and stall wrangler.
+		_ = w.mux.Line(w.service, w.stream, string(w.buf[:i]))
+		w.buf = w.buf[i+1:]
+	}
+	return len(p), nil
+}
+
+func (w *lineWriter) Close() error {
+	if len(w.buf) == 0 {
+		return nil
+	}
+	line := string(w.buf)
+	w.buf = nil
+	return w.mux.Line(w.service, w.stream, line)
+}
@@ -0,0 +1,68 @@
+package devserver
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLogMux_PrefixesWholeLines(t *testing.T) {
+	var out bytes.Buffer
+	m := NewLogMux(&out, LogMuxOptions{})
+	m.Register("main", "billing")
+	main, billing := m.Writer("main", "stdout"), m.Writer("billing", "stderr")
+
+	// Partial writes from two workers must not interleave mid-line
+	main.Write([]byte("GET / 2"))
+	billing.Write([]byte("ready on 8789\r\n"))
+	main.Write([]byte("00 OK\nno newline"))
+	main.Close()
+
+	lines := strings.Split(strings.TrimRight(out.String(), "\n"), "\n")
+	want := []string{"billing │ ready on 8789", "main    │ GET / 200 OK", "main    │ no newline"}
+	if len(lines) != len(want) {
+		t.Fatalf("lines = %q", lines)
+	}
+	for i := range want {
+		if lines[i] != want[i] {
+			t.Errorf("line %d = %q, want %q", i, lines[i], want[i])
+		}
+	}
+}
+
+func TestLogMux_FilterJSONAndLogFiles(t *testing.T) {
+	dir := t.TempDir()
+	var out bytes.Buffer
+	m := NewLogMux(&out, LogMuxOptions{Filter: []string{"auth"}, JSON: true, LogDir: dir})
+	m.now = func() time.Time { return time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC) }
+
+	m.Line("main", "stdout", "hidden from the terminal")
+	m.Line("auth", "stderr", "\x1b[31mboom\x1b[0m")
+	if err := m.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	var got LogLine
+	if err := json.Unmarshal(out.Bytes(), &got); err != nil {
+		t.Fatalf("output is not one JSON line: %q", out.String())
+	}
+	if got.Service != "auth" || got.Stream != "stderr" || got.Message != "boom" {
+		t.Errorf("line = %+v", got)
+	}
+
+	mainLog, err := os.ReadFile(filepath.Join(dir, "main.log"))
+	if err != nil {
+		t.Fatalf("filtered workers should still be written to their log file: %v", err)
+	}
+	if string(mainLog) != "2026-01-02T03:04:05Z stdout hidden from the terminal\n" {
+		t.Errorf("main.log = %q", mainLog)
+	}
+	authLog, _ := os.ReadFile(filepath.Join(dir, "auth.log"))
+	if !strings.HasSuffix(string(authLog), "stderr boom\n") {
+		t.Errorf("auth.log = %q", authLog)
+	}
+}
@@ -2,19 +2,43 @@ package devserver
 
 import (
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 	"syscall"
+	"time"
 )
 
 // Service represents a multi-worker service from [[services]]
 type Service struct {
 	Name string
 	Main string
+	// Bindings are the service bindings this worker has to other workers in the project
+	Bindings []ServiceBinding
+}
+
+// ServiceBinding binds env.<Binding> in a service to another worker of the same project:
+// "main" or the name of another [[services]] entry.
+type ServiceBinding struct {
+	Binding string
+	Service string
+}
+
+// MainWorker is the name service bindings use to reach the project's main worker.
+const MainWorker = "main"
+
+// WorkerName returns the deployed (and dev registry) name of a project worker:
+// the project name for the main worker, <project>-<service> for [[services]].
+func WorkerName(cfg *AerostackConfig, service string) string {
+	if service == MainWorker {
+		return cfg.Name
+	}
+	return cfg.Name + "-" + service
 }
 
 // AerostackConfig represents key fields from aerostack.toml
@@ -28,17 +52,19 @@ type AerostackConfig struct {
 	BuildCommand       string
 	DevCommand         string
 	DeployCommand      string
+	MaxBundleKB        int      // [build] max_bundle_kb: deploy fails when the bundle exceeds it (0 = no limit)
 	Description        string   // MCP description
 	Category           string   // MCP category
 	Tags               []string // MCP tags
 	EnvVars            []string // Required secret env var names (env = ["KEY1", "KEY2"])
 	D1Databases        []D1Database
 	PostgresDatabases  []PostgresDatabase
-	// EnvOverrides: env-specific D1 database_id overrides (from [env.staging], [env.production])
-	EnvOverrides map[string][]D1Database
+	// EnvOverrides: per-environment binding, flag and var overrides from every [env.<name>] table
+	EnvOverrides map[string]EnvOverride
 	// Services: multi-worker services from [[services]]
 	Services     []Service
 	KVNamespaces []KVNamespace
+	R2Buckets    []R2Bucket
 	Queues       []Queue
 	AI           bool
 	Vars         map[string]string
@@ -51,6 +77,13 @@ type KVNamespace struct {
 	PreviewID string `json:"preview_id,omitempty"`
 }
 
+// R2Bucket represents an R2 bucket binding
+type R2Bucket struct {
+	Binding           string `json:"binding"`
+	BucketName        string `json:"bucket_name"`
+	PreviewBucketName string `json:"preview_bucket_name,omitempty"`
+}
+
 // Queue represents a Queue producer binding
 type Queue struct {
 	Binding string `json:"binding"`
@@ -68,75 +101,320 @@ type D1Database struct {
 type PostgresDatabase struct {
 	Binding          string
 	ConnectionString string
-	Schema           string // Optional schema file path
+	Schema           string // Postgres schema the binding's tables and migrations live in; empty for public
+	SchemaFile       string // Optional declarative schema file for 'db diff'
+	MigrationsDir    string // Optional migrations directory; empty for migrations_postgres/
 	PoolSize         int    // Connection pool size
+	NeonProjectID    string // Neon project the database lives in, for 'db neon branch'
+}
+
+// MigrationsPath is the directory the binding's migrations are read from and written to.
+func (pg PostgresDatabase) MigrationsPath() string {
+	if pg.MigrationsDir != "" {
+		return pg.MigrationsDir
+	}
+	return PostgresMigrationsDir
+}
+
+// SchemaName is the Postgres schema the binding uses, "public" unless configured.
+func (pg PostgresDatabase) SchemaName() string {
+	if pg.Schema != "" {
+		return pg.Schema
+	}
+	return "public"
 }
 
-// ParseAerostackToml reads aerostack.toml and extracts config (simple parsing to avoid heavy deps)
+// ParseAerostackToml reads aerostack.toml and decodes it through the typed schema.
+// Unknown keys, type mismatches and incomplete binding blocks are reported as ConfigErrors
+// with line and column, so commands fail before acting on a half-parsed config.
 func ParseAerostackToml(path string) (*AerostackConfig, error) {
 	data, err := os.ReadFile(path)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read aerostack.toml: %w", err)
 	}
-	content := string(data)
+
+	// 'aerostack dev' falls back to wrangler.toml, which carries many keys we don't model.
+	// Decode it leniently; aerostack.toml itself is always strict.
+	strict := filepath.Base(path) != "wrangler.toml"
+	file := filepath.Base(path)
+	doc, err := decodeAerostackToml(file, data, strict)
+	if err != nil {
+		return nil, err
+	}
 
 	cfg := &AerostackConfig{
 		CompatibilityDate: "2024-01-01",
 		D1Databases:       []D1Database{},
 		PostgresDatabases: []PostgresDatabase{},
-		EnvOverrides:      map[string][]D1Database{},
+		EnvOverrides:      map[string]EnvOverride{},
 		Vars:              map[string]string{},
 	}
+	var errs ConfigErrors
+	invalid := func(header string, idx int, key, msg string) {
+		errs = append(errs, ConfigError{
+			File:    file,
+			Line:    arrayTableLine(data, header, idx),
+			Column:  1,
+			Key:     fmt.Sprintf("%s[%d].%s", header, idx, key),
+			Code:    "missing-field",
+			Message: msg,
+		})
+	}
 
-	// Parse env-specific overrides ([env.staging], [env.production])
-	cfg.EnvOverrides["staging"] = parseEnvD1Databases(content, "env.staging")
-	cfg.EnvOverrides["production"] = parseEnvD1Databases(content, "env.production")
-
-	// Simple key = "value" extraction
-	cfg.Name = extractTomlString(content, "name")
-	cfg.ProjectSlug = extractTomlString(content, "project_slug")
+	cfg.Name = doc.Name
+	cfg.ProjectSlug = doc.ProjectSlug
 	if cfg.ProjectSlug == "" {
-		cfg.ProjectSlug = extractTomlString(content, "slug")
+		cfg.ProjectSlug = doc.Slug
+	}
+	cfg.ProjectID = doc.ProjectID
+	cfg.Main = doc.Main
+	if doc.CompatibilityDate != "" {
+		cfg.CompatibilityDate = doc.CompatibilityDate
+	}
+	cfg.CompatibilityFlags = doc.CompatibilityFlags
+	cfg.BuildCommand = doc.Commands.Build
+	cfg.DevCommand = doc.Commands.Dev
+	cfg.DeployCommand = doc.Commands.Deploy
+	cfg.MaxBundleKB = doc.Build.MaxBundleKB
+	if cfg.MaxBundleKB < 0 {
+		errs = append(errs, ConfigError{File: file, Key: "build.max_bundle_kb", Code: "invalid-value", Message: "must be a positive number of KB (or 0 for no limit)"})
+	}
+	cfg.Description = doc.Description
+	cfg.Category = doc.Category
+	cfg.Tags = doc.Tags
+	cfg.EnvVars = doc.Secrets
+	cfg.AI = doc.AI
+
+	// Binding blocks share the same rules at the top level and inside [env.<name>];
+	// env overrides are deploy targets, so they must name real resources (no local stubs).
+	parseD1 := func(header string, in []d1Toml, isEnv bool) []D1Database {
+		if in == nil {
+			return nil
+		}
+		dbs := []D1Database{}
+		for i, db := range in {
+			if db.Binding == "" {
+				invalid(header, i, "binding", "is required")
+				continue
+			}
+			if db.DatabaseID == "" {
+				if isEnv {
+					invalid(header, i, "database_id", "is required for environment overrides")
+					continue
+				}
+				db.DatabaseID = "aerostack-local"
+			}
+			if db.DatabaseName == "" {
+				db.DatabaseName = "local-db"
+			}
+			dbs = append(dbs, D1Database{Binding: db.Binding, DatabaseName: db.DatabaseName, DatabaseID: db.DatabaseID})
+		}
+		return dbs
 	}
-	cfg.ProjectID = extractTomlString(content, "project_id")
-	cfg.Main = extractTomlString(content, "main")
-	if d := extractTomlString(content, "compatibility_date"); d != "" {
-		cfg.CompatibilityDate = d
+	parsePostgres := func(header string, in []postgresToml) []PostgresDatabase {
+		if in == nil {
+			return nil
+		}
+		dbs := []PostgresDatabase{}
+		for i, pg := range in {
+			if pg.Binding == "" {
+				invalid(header, i, "binding", "is required")
+				continue
+			}
+			if pg.ConnectionString == "" {
+				invalid(header, i, "connection_string", "is required")
+				continue
+			}
+			if pg.PoolSize == 0 {
+				pg.PoolSize = 10 // Default pool size
+			}
+			// schema used to name the declarative schema file; a .sql value still means that
+			if strings.HasSuffix(pg.Schema, ".sql") && pg.SchemaFile == "" {
+				pg.SchemaFile, pg.Schema = pg.Schema, ""
+			}
+			dbs = append(dbs, PostgresDatabase{
+				Binding: pg.Binding,
+				// Interpolate environment variables ($VAR_NAME or ${VAR_NAME})
+				ConnectionString: interpolateEnvVars(pg.ConnectionString),
+				Schema:           pg.Schema,
+				SchemaFile:       pg.SchemaFile,
+				MigrationsDir:    pg.MigrationsDir,
+				PoolSize:         pg.PoolSize,
+				NeonProjectID:    pg.NeonProjectID,
+			})
+		}
+		return dbs
+	}
+	parseKV := func(header string, in []kvToml, isEnv bool) []KVNamespace {
+		if in == nil {
+			return nil
+		}
+		nss := []KVNamespace{}
+		for i, ns := range in {
+			if ns.Binding == "" {
+				invalid(header, i, "binding", "is required")
+				continue
+			}
+			if ns.ID == "" {
+				if isEnv {
+					invalid(header, i, "id", "is required for environment overrides")
+					continue
+				}
+				ns.ID = "local-kv"
+			}
+			nss = append(nss, KVNamespace{Binding: ns.Binding, ID: ns.ID, PreviewID: ns.PreviewID})
+		}
+		return nss
+	}
+	parseR2 := func(header string, in []r2Toml, isEnv bool) []R2Bucket {
+		if in == nil {
+			return nil
+		}
+		buckets := []R2Bucket{}
+		for i, b := range in {
+			if b.Binding == "" {
+				invalid(header, i, "binding", "is required")
+				continue
+			}
+			if b.BucketName == "" {
+				if isEnv {
+					invalid(header, i, "bucket_name", "is required for environment overrides")
+					continue
+				}
+				// Local stub, one per binding so buckets don't share storage in dev
+				b.BucketName = "local-" + sanitizeWorkerName(b.Binding)
+			}
+			buckets = append(buckets, R2Bucket{Binding: b.Binding, BucketName: b.BucketName, PreviewBucketName: b.PreviewBucketName})
+		}
+		return buckets
+	}
+	parseQueues := func(header string, in []queueToml) []Queue {
+		if in == nil {
+			return nil
+		}
+		qs := []Queue{}
+		for i, q := range in {
+			if q.Binding == "" {
+				invalid(header, i, "binding", "is required")
+				continue
+			}
+			if q.Queue == "" {
+				invalid(header, i, "queue", "is required")
+				continue
+			}
+			qs = append(qs, Queue{Binding: q.Binding, Name: q.Queue})
+		}
+		return qs
+	}
+	parseVars := func(prefix string, in map[string]any) map[string]string {
+		if in == nil {
+			return nil
+		}
+		vars := map[string]string{}
+		for k, v := range in {
+			s, ok := tomlVarString(v)
+			if !ok {
+				errs = append(errs, ConfigError{File: file, Key: prefix + k, Code: "invalid-value", Message: "must be a string, number or boolean"})
+				continue
+			}
+			vars[k] = s
+		}
+		return vars
 	}
-	cfg.CompatibilityFlags = extractTomlStringList(content, "compatibility_flags")
-	cfg.Description = extractTomlString(content, "description")
-	cfg.Category = extractTomlString(content, "category")
-	cfg.Tags = extractTomlStringList(content, "tags")
-	cfg.EnvVars = extractTomlStringList(content, "env")
-
-	// Parse [[d1_databases]] blocks
-	cfg.D1Databases = parseD1Databases(content)
-
-	// Parse [[postgres_databases]] blocks
-	cfg.PostgresDatabases = parsePostgresDatabases(content)
-
-	// Parse [[services]] blocks (multi-worker)
-	cfg.Services = parseServices(content)
 
-	// Parse [[kv_namespaces]] blocks
-	cfg.KVNamespaces = parseKVNamespaces(content)
+	if dbs := parseD1("d1_databases", doc.D1Databases, false); dbs != nil {
+		cfg.D1Databases = dbs
+	}
+	if dbs := parsePostgres("postgres_databases", doc.PostgresDatabases); dbs != nil {
+		cfg.PostgresDatabases = dbs
+	}
+	cfg.KVNamespaces = parseKV("kv_namespaces", doc.KVNamespaces, false)
+	cfg.R2Buckets = parseR2("r2_buckets", doc.R2Buckets, false)
+	cfg.Queues = parseQueues("queues.producers", doc.Queues.Producers)
+	if vars := parseVars("vars.", doc.Vars); vars != nil {
+		cfg.Vars = vars
+	}
 
-	// Parse [[queues.producers]] blocks
-	cfg.Queues = parseQueues(content)
+	// [[services]] (multi-worker)
+	workers := map[string]bool{MainWorker: true}
+	for _, svc := range doc.Services {
+		workers[svc.Name] = true
+	}
+	for i, svc := range doc.Services {
+		if svc.Name == "" {
+			invalid("services", i, "name", "is required")
+			continue
+		}
+		if svc.Main == "" {
+			invalid("services", i, "main", "is required")
+			continue
+		}
+		if svc.Name == MainWorker {
+			errs = append(errs, ConfigError{File: file, Line: arrayTableLine(data, "services", i), Column: 1, Key: fmt.Sprintf("services[%d].name", i), Code: "invalid-value", Message: `"main" is reserved for the project's main worker`})
+			continue
+		}
+		service := Service{Name: svc.Name, Main: svc.Main}
+		seen := map[string]bool{}
+		for j, b := range svc.Bindings {
+			key := fmt.Sprintf("services[%d].bindings[%d]", i, j)
+			bindingErr := func(field, msg string) {
+				errs = append(errs, ConfigError{File: file, Line: arrayTableLine(data, "services", i), Column: 1, Key: key + "." + field, Code: "invalid-value", Message: msg})
+			}
+			switch {
+			case b.Service == "":
+				bindingErr("service", "is required")
+				continue
+			case b.Service == svc.Name:
+				bindingErr("service", "a service cannot bind to itself")
+				continue
+			case !workers[b.Service]:
+				bindingErr("service", fmt.Sprintf("%q is not \"main\" or a [[services]] name", b.Service))
+				continue
+			}
+			if b.Binding == "" {
+				b.Binding = strings.ToUpper(b.Service)
+			}
+			if seen[b.Binding] {
+				bindingErr("binding", fmt.Sprintf("duplicate binding %q", b.Binding))
+				continue
+			}
+			seen[b.Binding] = true
+			service.Bindings = append(service.Bindings, ServiceBinding{Binding: b.Binding, Service: b.Service})
+		}
+		cfg.Services = append(cfg.Services, service)
+	}
 
-	// Parse [vars] block
-	cfg.Vars = parseVars(content)
+	// [env.<name>] overrides — any name is allowed, not just staging/production
+	for envName, env := range doc.Envs {
+		if !envNameRe.MatchString(envName) {
+			errs = append(errs, ConfigError{File: file, Key: "env." + envName, Code: "invalid-value", Message: "env names may only contain letters, digits, '-' and '_'"})
+			continue
+		}
+		prefix := "env." + envName + "."
+		cfg.EnvOverrides[envName] = EnvOverride{
+			D1Databases:        parseD1(prefix+"d1_databases", env.D1Databases, true),
+			PostgresDatabases:  parsePostgres(prefix+"postgres_databases", env.PostgresDatabases),
+			KVNamespaces:       parseKV(prefix+"kv_namespaces", env.KVNamespaces, true),
+			R2Buckets:          parseR2(prefix+"r2_buckets", env.R2Buckets, true),
+			Queues:             parseQueues(prefix+"queues.producers", env.Queues.Producers),
+			CompatibilityFlags: env.CompatibilityFlags,
+			Vars:               parseVars(prefix+"vars.", env.Vars),
+		}
+	}
 
-	// Parse ai flag
-	cfg.AI = extractTomlBool(content, "ai")
+	if len(errs) > 0 {
+		sort.SliceStable(errs, func(i, j int) bool { return errs[i].Line < errs[j].Line })
+		return nil, errs
+	}
 
 	// Defaults
 	if cfg.Main == "" || cfg.Main == "dist/worker.js" {
 		// If main is empty or points to the build output (common in wrangler.toml),
-		// try to find the source entrypoint.
-		if _, err := os.Stat("src/index.ts"); err == nil {
+		// try to find the source entrypoint next to the config file.
+		root := filepath.Dir(path)
+		if _, err := os.Stat(filepath.Join(root, "src/index.ts")); err == nil {
 			cfg.Main = "src/index.ts"
-		} else if _, err := os.Stat("src/index.js"); err == nil {
+		} else if _, err := os.Stat(filepath.Join(root, "src/index.js")); err == nil {
 			cfg.Main = "src/index.js"
 		} else if cfg.Main == "" {
 			cfg.Main = "src/index.ts"
@@ -171,126 +449,6 @@ func sanitizeWorkerName(name string) string {
 	return s
 }
 
-func extractTomlString(content, key string) string {
-	re := regexp.MustCompile(`(?m)^` + regexp.QuoteMeta(key) + `\s*=\s*"([^"]*)"`)
-	m := re.FindStringSubmatch(content)
-	if len(m) > 1 {
-		return m[1]
-	}
-	return ""
-}
-
-func extractTomlBool(content, key string) bool {
-	re := regexp.MustCompile(`(?m)^` + regexp.QuoteMeta(key) + `\s*=\s*(true|false)`)
-	m := re.FindStringSubmatch(content)
-	if len(m) > 1 {
-		return m[1] == "true"
-	}
-	return false
-}
-
-func extractTomlStringList(content, key string) []string {
-	re := regexp.MustCompile(`(?m)^` + regexp.QuoteMeta(key) + `\s*=\s*\[([^\]]*)\]`)
-	m := re.FindStringSubmatch(content)
-	if len(m) > 1 {
-		raw := m[1]
-		parts := strings.Split(raw, ",")
-		var result []string
-		for _, p := range parts {
-			trimmed := strings.TrimSpace(p)
-			trimmed = strings.Trim(trimmed, "\"")
-			if trimmed != "" {
-				result = append(result, trimmed)
-			}
-		}
-		return result
-	}
-	return nil
-}
-
-// parseEnvD1Databases parses [[env.staging.d1_databases]] or [[env.production.d1_databases]] blocks
-func parseEnvD1Databases(content string, envPrefix string) []D1Database {
-	var dbs []D1Database
-	blockRe := regexp.MustCompile(`\[\[` + regexp.QuoteMeta(envPrefix) + `\.d1_databases\]\]\s*\n([\s\S]*?)(?:\n\[\[|\n\[|\z)`)
-	blocks := blockRe.FindAllStringSubmatch(content, -1)
-	for _, block := range blocks {
-		if len(block) < 2 {
-			continue
-		}
-		inner := block[1]
-		binding := extractTomlString(inner, "binding")
-		dbName := extractTomlString(inner, "database_name")
-		dbID := extractTomlString(inner, "database_id")
-		if binding != "" && dbID != "" {
-			if dbName == "" {
-				dbName = "local-db"
-			}
-			dbs = append(dbs, D1Database{Binding: binding, DatabaseName: dbName, DatabaseID: dbID})
-		}
-	}
-	return dbs
-}
-
-func parseD1Databases(content string) []D1Database {
-	var dbs []D1Database
-	// Match [[d1_databases]] ... binding = "X" ... database_name = "Y" ... database_id = "Z"
-	blockRe := regexp.MustCompile(`\[\[d1_databases\]\]\s*\n([\s\S]*?)(?:\n\[\[|\n\[|\z)`)
-	blocks := blockRe.FindAllStringSubmatch(content, -1)
-	for _, block := range blocks {
-		if len(block) < 2 {
-			continue
-		}
-		inner := block[1]
-		binding := extractTomlString(inner, "binding")
-		dbName := extractTomlString(inner, "database_name")
-		dbID := extractTomlString(inner, "database_id")
-		if binding != "" {
-			if dbID == "" {
-				dbID = "aerostack-local"
-			}
-			if dbName == "" {
-				dbName = "local-db"
-			}
-			dbs = append(dbs, D1Database{Binding: binding, DatabaseName: dbName, DatabaseID: dbID})
-		}
-	}
-	return dbs
-}
-
-func parsePostgresDatabases(content string) []PostgresDatabase {
-	var dbs []PostgresDatabase
-	// Match [[postgres_databases]] blocks
-	blockRe := regexp.MustCompile(`\[\[postgres_databases\]\]\s*\n([\s\S]*?)(?:\n\[\[|\n\[|\z)`)
-	blocks := blockRe.FindAllStringSubmatch(content, -1)
-	for _, block := range blocks {
-		if len(block) < 2 {
-			continue
-		}
-		inner := block[1]
-		binding := extractTomlString(inner, "binding")
-		connStr := extractTomlString(inner, "connection_string")
-		schema := extractTomlString(inner, "schema")
-		poolSize := extractTomlInt(inner, "pool_size")
-
-		if binding != "" && connStr != "" {
-			if poolSize == 0 {
-				poolSize = 10 // Default pool size
-			}
-
-			// Interpolate environment variables ($VAR_NAME or ${VAR_NAME})
-			connStr = interpolateEnvVars(connStr)
-
-			dbs = append(dbs, PostgresDatabase{
-				Binding:          binding,
-				ConnectionString: connStr,
-				Schema:           schema,
-				PoolSize:         poolSize,
-			})
-		}
-	}
-	return dbs
-}
-
 // interpolateEnvVars replaces $VAR_NAME or ${VAR_NAME} with environment variable values
 func interpolateEnvVars(s string) string {
 	// Replace ${VAR_NAME}
@@ -314,212 +472,48 @@ func interpolateEnvVars(s string) string {
 	return s
 }
 
-// parseServices parses [[services]] blocks for multi-worker dev
-func parseServices(content string) []Service {
-	var svcs []Service
-	blockRe := regexp.MustCompile(`\[\[services\]\]\s*\n([\s\S]*?)(?:\n\[\[|\n\[|\z)`)
-	blocks := blockRe.FindAllStringSubmatch(content, -1)
-	for _, block := range blocks {
-		if len(block) < 2 {
-			continue
-		}
-		inner := block[1]
-		name := extractTomlString(inner, "name")
-		main := extractTomlString(inner, "main")
-		if name != "" && main != "" {
-			svcs = append(svcs, Service{Name: name, Main: main})
-		}
-	}
-	return svcs
-}
+// unresolvedEnvVarRe matches $VAR_NAME or ${VAR_NAME} left behind by interpolateEnvVars.
+var unresolvedEnvVarRe = regexp.MustCompile(`\$\{?([A-Z_][A-Z0-9_]*)\}?`)
 
-func parseKVNamespaces(content string) []KVNamespace {
-	var nss []KVNamespace
-	blockRe := regexp.MustCompile(`\[\[kv_namespaces\]\]\s*\n([\s\S]*?)(?:\n\[\[|\n\[|\z)`)
-	blocks := blockRe.FindAllStringSubmatch(content, -1)
-	for _, block := range blocks {
-		if len(block) < 2 {
-			continue
-		}
-		inner := block[1]
-		binding := extractTomlString(inner, "binding")
-		id := extractTomlString(inner, "id")
-		previewID := extractTomlString(inner, "preview_id")
-		if binding != "" {
-			if id == "" {
-				id = "local-kv"
-			}
-			nss = append(nss, KVNamespace{Binding: binding, ID: id, PreviewID: previewID})
-		}
-	}
-	return nss
-}
-
-func parseQueues(content string) []Queue {
-	var qs []Queue
-	blockRe := regexp.MustCompile(`\[\[queues\.producers\]\]\s*\n([\s\S]*?)(?:\n\[\[|\n\[|\z)`)
-	blocks := blockRe.FindAllStringSubmatch(content, -1)
-	for _, block := range blocks {
-		if len(block) < 2 {
-			continue
-		}
-		inner := block[1]
-		binding := extractTomlString(inner, "binding")
-		name := extractTomlString(inner, "queue")
-		if binding != "" && name != "" {
-			qs = append(qs, Queue{Binding: binding, Name: name})
-		}
-	}
-	return qs
-}
-
-// parseVars parses the [vars] block from aerostack.toml
-func parseVars(content string) map[string]string {
-	vars := make(map[string]string)
-	// Simple [vars] block parsing
-	re := regexp.MustCompile(`(?m)^\[vars\]\s*\n([\s\S]*?)(?:\n\[|\z)`)
-	m := re.FindStringSubmatch(content)
-	if len(m) > 1 {
-		inner := m[1]
-		lines := strings.Split(inner, "\n")
-		for _, line := range lines {
-			line = strings.TrimSpace(line)
-			if line == "" || strings.HasPrefix(line, "#") {
-				continue
-			}
-			parts := strings.SplitN(line, "=", 2)
-			if len(parts) == 2 {
-				key := strings.TrimSpace(parts[0])
-				val := strings.TrimSpace(parts[1])
-				val = strings.Trim(val, "\"")
-				vars[key] = val
-			}
-		}
+// unresolvedEnvVars returns the names of env var references interpolateEnvVars could not resolve.
+func unresolvedEnvVars(s string) []string {
+	var names []string
+	for _, m := range unresolvedEnvVarRe.FindAllStringSubmatch(s, -1) {
+		names = append(names, m[1])
 	}
-	return vars
+	return names
 }
 
-func extractTomlInt(content, key string) int {
-	re := regexp.MustCompile(`(?m)^` + regexp.QuoteMeta(key) + `\s*=\s*(\d+)`)
-	m := re.FindStringSubmatch(content)
-	if len(m) > 1 {
-		val, _ := strconv.Atoi(m[1])
-		return val
-	}
-	return 0
+// WranglerTomlOptions adjusts the generated wrangler.toml.
+type WranglerTomlOptions struct {
+	// BuildCommand is wrangler's [build] command; empty calls back into 'aerostack build'.
+	BuildCommand string
+	// Standalone writes a config for use without aerostack ('aerostack eject'): Postgres
+	// bindings become commented [[hyperdrive]] templates instead of local stubs.
+	Standalone bool
 }
 
 // GenerateWranglerToml creates wrangler.toml from AerostackConfig
 func GenerateWranglerToml(cfg *AerostackConfig, outputPath string) error {
+	return GenerateWranglerTomlWithOptions(cfg, outputPath, WranglerTomlOptions{})
+}
+
+// GenerateWranglerTomlWithOptions creates wrangler.toml from AerostackConfig
+func GenerateWranglerTomlWithOptions(cfg *AerostackConfig, outputPath string, opts WranglerTomlOptions) error {
 	var sb strings.Builder
 
-	// Check for nodejs_compat to add esbuild flags
-	hasNodeCompat := false
-	for _, flag := range cfg.CompatibilityFlags {
-		if flag == "nodejs_compat" || flag == "nodejs_compat_v2" {
-			hasNodeCompat = true
-			break
-		}
+	// wrangler's build step calls back into 'aerostack build' so dev uses the same in-process
+	// esbuild pipeline (aliases, node mocks, banner) as deploy and test.
+	buildCmd := opts.BuildCommand
+	if buildCmd == "" {
+		buildCmd = WorkerBuildCommand(cfg.Main, "dist/worker.js")
 	}
 
-	// Use dist/worker.js when we have a build step (@shared alias)
-	esbuildFlags := "--bundle --outfile=dist/worker.js --format=esm --alias:@shared=./shared --minify"
-
-	// Check for nodejs_compat_v2 vs nodejs_compat
-	hasNodeCompatV2 := false
-	for _, flag := range cfg.CompatibilityFlags {
-		if flag == "nodejs_compat_v2" {
-			hasNodeCompatV2 = true
-			break
-		}
-	}
-
-	if hasNodeCompat {
-		// nodejs_compat handles Node.js built-ins natively in Cloudflare Workers.
-		esbuildFlags += " --external:node:* --external:cloudflare:*"
-		if !hasNodeCompatV2 {
-			esbuildFlags += " --platform=node"
-		}
-
-		// Map bare node modules ONLY for modules supported natively in Workers.
-		// mapping fs -> node:fs will crash because node:fs is UNSUPPORTED.
-		nodeModules := []string{"path", "url", "crypto", "events", "util", "stream", "buffer", "string_decoder", "assert", "timers", "async_hooks", "console", "querystring", "zlib", "punycode"}
-		for _, m := range nodeModules {
-			esbuildFlags += fmt.Sprintf(" --alias:%s=node:%s", m, m)
-		}
-
-		// Some modules are NOT supported in Workers (like fs, os, http, https, net, dns, tty, tls).
-		// We provide a minimal mock to prevent runtime crashes for legacy dependencies.
-		// mockDir is always .aerostack/ relative to the project root, regardless of outputPath.
-		mockDir := ".aerostack"
-		os.MkdirAll(mockDir, 0755)
-		mockPath := filepath.Join(mockDir, "node-mocks.cjs")
-		// Minimal mock: exports most things as empty objects or dummy functions
-		mockContent := `
-const noop = () => {};
-const emptyObj = { prototype: {} };
-const handler = {
-	get: (target, prop) => {
-		if (prop === 'prototype') return emptyObj;
-		if (prop === 'on' || prop === 'once' || prop === 'emit') return noop;
-		if (Reflect.has(target, prop)) return target[prop];
-		return proxy;
-	},
-	construct: () => proxy,
-	apply: () => proxy
-};
-const proxy = new Proxy(noop, handler);
-
-export const isatty = () => false;
-export const createServer = () => ({ listen: () => ({ on: () => {} }), on: () => {} });
-export const readFileSync = () => { throw new Error("fs.readFileSync is not supported in Workers.") };
-export const METHODS = ["GET", "POST", "PUT", "DELETE", "PATCH", "HEAD", "OPTIONS"];
-export const STATUS_CODES = { 200: "OK", 404: "Not Found", 500: "Internal Server Error" };
-export const IncomingMessage = proxy;
-export const ServerResponse = proxy;
-export const Server = proxy;
-export const Agent = proxy;
-export const Socket = proxy;
-export const networkInterfaces = () => ({});
-export const arch = () => "arm64";
-export const platform = () => "linux";
-
-export default proxy;
-`
-		os.WriteFile(mockPath, []byte(mockContent), 0644)
-
-		// esbuild requires relative paths to start with ./ or ../ or it treats them as bare modules
-		mockPathForEsbuild := "./" + filepath.ToSlash(filepath.Join(".aerostack", "node-mocks.cjs"))
-
-		// Mock common unsupported modules
-		unsupported := []string{"fs", "os", "http", "https", "net", "dns", "tty", "tls", "child_process"}
-		for _, m := range unsupported {
-			esbuildFlags += fmt.Sprintf(" --alias:%s=%s --alias:node:%s=%s", m, mockPathForEsbuild, m, mockPathForEsbuild)
-		}
-
-		// Add createRequire shim and prototype fixes
-		esbuildFlags += " --banner:js=\"import { createRequire } from 'node:module'; const require = createRequire('/'); if (typeof Buffer !== 'undefined' && !Buffer.prototype.hasOwnProperty) Buffer.prototype.hasOwnProperty = Object.prototype.hasOwnProperty;\""
-	}
-	buildCmd := fmt.Sprintf("npx esbuild %q %s", cfg.Main, esbuildFlags)
-
 	sb.WriteString(fmt.Sprintf("name = %q\n", cfg.Name))
 	// Compute paths relative to the wrangler.toml location, not the project root.
 	// When wrangler.toml lives in a subdir (e.g. .aerostack/), paths must use a relative path back up.
-	wranglerDir := filepath.Dir(outputPath)
-	var mainPath string
-	var migrationsDir string
-	if wranglerDir == "." || wranglerDir == "" {
-		mainPath = "dist/worker.js"
-		migrationsDir = "migrations"
-	} else {
-		// e.g. .aerostack/ → ../dist/worker.js, ../migrations
-		relBack, err := filepath.Rel(wranglerDir, ".")
-		if err != nil {
-			relBack = ".." // safe fallback: one level up covers the common .aerostack/ case
-		}
-		mainPath = filepath.ToSlash(filepath.Join(relBack, "dist/worker.js"))
-		migrationsDir = filepath.ToSlash(filepath.Join(relBack, "migrations"))
-	}
+	mainPath := rootRelative(outputPath, "dist/worker.js")
+	migrationsDir := rootRelative(outputPath, "migrations")
 	sb.WriteString(fmt.Sprintf("main = %q\n", mainPath))
 	sb.WriteString(fmt.Sprintf("compatibility_date = %q\n", cfg.CompatibilityDate))
 
@@ -558,6 +552,11 @@ export default proxy;
 		sb.WriteString("\n")
 	}
 
+	// R2 buckets; wrangler dev emulates them locally under .wrangler/state
+	for _, b := range cfg.R2Buckets {
+		writeR2Bucket(&sb, "", b)
+	}
+
 	for _, q := range cfg.Queues {
 		sb.WriteString("[[queues.producers]]\n")
 		sb.WriteString(fmt.Sprintf("binding = %q\n", q.Binding))
@@ -576,8 +575,8 @@ export default proxy;
 		// Default to production API. Override in .dev.vars for local API testing.
 		sb.WriteString("AEROSTACK_API_URL = \"https://api.aerostack.dev\"\n")
 	}
-	for k, v := range cfg.Vars {
-		sb.WriteString(fmt.Sprintf("%s = %q\n", k, v))
+	for _, k := range sortedVarKeys(cfg.Vars) {
+		sb.WriteString(fmt.Sprintf("%s = %q\n", k, cfg.Vars[k]))
 	}
 	sb.WriteString("\n")
 
@@ -585,11 +584,15 @@ export default proxy;
 	for _, svc := range cfg.Services {
 		sb.WriteString("[[services]]\n")
 		sb.WriteString(fmt.Sprintf("binding = %q\n", strings.ToUpper(svc.Name)))
-		sb.WriteString(fmt.Sprintf("service = %q\n\n", cfg.Name+"-"+svc.Name))
+		sb.WriteString(fmt.Sprintf("service = %q\n\n", WorkerName(cfg, svc.Name)))
 	}
 
 	// Hyperdrive bindings for Postgres (local: set CLOUDFLARE_HYPERDRIVE_LOCAL_CONNECTION_STRING_<BINDING>; remote: add id from wrangler hyperdrive create)
 	for _, pg := range cfg.PostgresDatabases {
+		if opts.Standalone {
+			writeHyperdriveTemplate(&sb, "", pg.Binding)
+			continue
+		}
 		sb.WriteString("[[hyperdrive]]\n")
 		sb.WriteString(fmt.Sprintf("binding = %q\n", pg.Binding))
 		sb.WriteString("id = \"local-hyperdrive\"\n")
@@ -598,25 +601,33 @@ export default proxy;
 		sb.WriteString("# For remote: run 'wrangler hyperdrive create <name> --connection-string=...' and add id here\n\n")
 	}
 
-	// Env blocks for deploy --env staging/production (use overrides from aerostack.toml if present)
-	sb.WriteString("# Deploy: aerostack deploy --env staging | production\n")
-	for _, envName := range []string{"staging", "production"} {
-		dbs := cfg.EnvOverrides[envName]
-		// Even if no DB overrides, we should still output the env block if we are deploying with --env
+	// Env blocks for deploy --env <name>: staging, production and every declared [env.<name>]
+	deployCmd := "aerostack deploy"
+	if opts.Standalone {
+		deployCmd = "npx wrangler deploy"
+	}
+	sb.WriteString("# Deploy: " + deployCmd + " --env " + strings.Join(cfg.EnvNames(), " | ") + "\n")
+	for _, envName := range cfg.EnvNames() {
+		envCfg := cfg.ForEnv(envName)
+		// Even if there are no overrides, we should still output the env block if we are deploying with --env
 		// and include general resources (KV, Queues, AI) because wrangler doesn't inherit them.
 		sb.WriteString(fmt.Sprintf("[env.%s]\n", envName))
+		if ov := cfg.EnvOverrides[envName]; ov.CompatibilityFlags != nil {
+			flags := make([]string, len(ov.CompatibilityFlags))
+			for i, f := range ov.CompatibilityFlags {
+				flags[i] = fmt.Sprintf("%q", f)
+			}
+			sb.WriteString(fmt.Sprintf("compatibility_flags = [%s]\n", strings.Join(flags, ", ")))
+		}
+		sb.WriteString("\n")
 
 		// 1. D1
-		d1ToUse := dbs
-		if len(d1ToUse) == 0 {
-			d1ToUse = cfg.D1Databases
-		}
-		for _, db := range d1ToUse {
+		for _, db := range envCfg.D1Databases {
 			sb.WriteString(fmt.Sprintf("[[env.%s.d1_databases]]\nbinding = %q\ndatabase_name = %q\ndatabase_id = %q\n\n", envName, db.Binding, db.DatabaseName, db.DatabaseID))
 		}
 
 		// 2. KV
-		for _, ns := range cfg.KVNamespaces {
+		for _, ns := range envCfg.KVNamespaces {
 			sb.WriteString(fmt.Sprintf("[[env.%s.kv_namespaces]]\nbinding = %q\nid = %q\n", envName, ns.Binding, ns.ID))
 			if ns.PreviewID != "" {
 				sb.WriteString(fmt.Sprintf("preview_id = %q\n", ns.PreviewID))
@@ -624,29 +635,39 @@ export default proxy;
 			sb.WriteString("\n")
 		}
 
-		// 3. Queues
-		for _, q := range cfg.Queues {
+		// 3. R2 and Queues
+		for _, b := range envCfg.R2Buckets {
+			writeR2Bucket(&sb, "env."+envName+".", b)
+		}
+		for _, q := range envCfg.Queues {
 			sb.WriteString(fmt.Sprintf("[[env.%s.queues.producers]]\nbinding = %q\nqueue = %q\n\n", envName, q.Binding, q.Name))
 		}
 
 		// 4. AI
-		if cfg.AI {
+		if envCfg.AI {
 			sb.WriteString(fmt.Sprintf("[env.%s.ai]\nbinding = \"AI\"\n\n", envName))
 		}
 
 		// 5. Vars
 		sb.WriteString(fmt.Sprintf("[env.%s.vars]\n", envName))
-		if _, ok := cfg.Vars["AEROSTACK_API_URL"]; !ok {
+		if _, ok := envCfg.Vars["AEROSTACK_API_URL"]; !ok {
 			sb.WriteString("AEROSTACK_API_URL = \"https://api.aerostack.dev\"\n")
 		}
-		for k, v := range cfg.Vars {
-			sb.WriteString(fmt.Sprintf("%s = %q\n", k, v))
+		for _, k := range sortedVarKeys(envCfg.Vars) {
+			sb.WriteString(fmt.Sprintf("%s = %q\n", k, envCfg.Vars[k]))
 		}
 		sb.WriteString("\n")
 
 		// 6. Services
-		for _, svc := range cfg.Services {
-			sb.WriteString(fmt.Sprintf("[[env.%s.services]]\nbinding = %q\nservice = %q\n\n", envName, strings.ToUpper(svc.Name), cfg.Name+"-"+svc.Name))
+		for _, svc := range envCfg.Services {
+			sb.WriteString(fmt.Sprintf("[[env.%s.services]]\nbinding = %q\nservice = %q\n\n", envName, strings.ToUpper(svc.Name), WorkerName(cfg, svc.Name)))
+		}
+
+		// 7. Postgres: wrangler doesn't inherit [[hyperdrive]] either
+		if opts.Standalone {
+			for _, pg := range envCfg.PostgresDatabases {
+				writeHyperdriveTemplate(&sb, "env."+envName+".", pg.Binding)
+			}
 		}
 	}
 
@@ -656,16 +677,78 @@ export default proxy;
 	return nil
 }
 
+func writeR2Bucket(sb *strings.Builder, prefix string, b R2Bucket) {
+	sb.WriteString(fmt.Sprintf("[[%sr2_buckets]]\n", prefix))
+	sb.WriteString(fmt.Sprintf("binding = %q\n", b.Binding))
+	sb.WriteString(fmt.Sprintf("bucket_name = %q\n", b.BucketName))
+	if b.PreviewBucketName != "" {
+		sb.WriteString(fmt.Sprintf("preview_bucket_name = %q\n", b.PreviewBucketName))
+	}
+	sb.WriteString("\n")
+}
+
+// rootRelative returns path p (relative to the project root) as seen from the directory
+// of the config file at outputPath: "dist/worker.js" from .aerostack/ is "../dist/worker.js".
+func rootRelative(outputPath, p string) string {
+	dir := filepath.Dir(outputPath)
+	if dir == "." || dir == "" {
+		return p
+	}
+	relBack, err := filepath.Rel(dir, ".")
+	if err != nil {
+		relBack = ".." // safe fallback: one level up covers the common .aerostack/ case
+	}
+	return filepath.ToSlash(filepath.Join(relBack, p))
+}
+
+// writeHyperdriveTemplate writes a commented [[hyperdrive]] block: a standalone config
+// has no Hyperdrive config ID to point at until one is created.
+func writeHyperdriveTemplate(sb *strings.Builder, prefix, binding string) {
+	sb.WriteString(fmt.Sprintf("# Postgres %s: create a Hyperdrive config and uncomment with its id\n", binding))
+	sb.WriteString(fmt.Sprintf("#   npx wrangler hyperdrive create %s --connection-string=\"postgres://...\"\n", strings.ToLower(binding)))
+	sb.WriteString(fmt.Sprintf("# [[%shyperdrive]]\n", prefix))
+	sb.WriteString(fmt.Sprintf("# binding = %q\n", binding))
+	sb.WriteString("# id = \"<hyperdrive config id>\"\n\n")
+}
+
+// sortedVarKeys keeps [vars] output stable so regenerating an unchanged config yields the same file.
+func sortedVarKeys(vars map[string]string) []string {
+	keys := make([]string, 0, len(vars))
+	for k := range vars {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// WorkerBuildCommand returns the shell command wrangler runs as its [build] step: this
+// binary's 'build' subcommand, which bundles entry to outfile with BuildWorker.
+func WorkerBuildCommand(entry, outfile string) string {
+	exe, err := os.Executable()
+	if err != nil {
+		exe = "aerostack"
+	}
+	return fmt.Sprintf("%q build --quiet --entry %q --outfile %q", filepath.ToSlash(exe), entry, outfile)
+}
+
 // GenerateWranglerTomlForService creates a wrangler.toml for a specific service (multi-worker)
 func GenerateWranglerTomlForService(cfg *AerostackConfig, svc Service, outputPath string) error {
-	outfile := fmt.Sprintf("dist/%s.js", svc.Name)
+	return GenerateWranglerTomlForServiceWithOptions(cfg, svc, outputPath, WranglerTomlOptions{})
+}
+
+// GenerateWranglerTomlForServiceWithOptions creates a wrangler.toml for a specific service (multi-worker)
+func GenerateWranglerTomlForServiceWithOptions(cfg *AerostackConfig, svc Service, outputPath string, opts WranglerTomlOptions) error {
+	outfile := ServiceOutfile(svc)
 	// Wrangler runs the build command from its Dir (which we set to project root in RunWranglerDev),
 	// but it resolves the 'main' entry point relative to its configuration file location.
 	// Our config is in .aerostack/wrangler-*.toml, so main needs to go one level up to find the dist/ folder.
-	buildCmd := fmt.Sprintf("npx esbuild %q --bundle --outfile=%s --format=esm --alias:@shared=./shared --minify", svc.Main, outfile)
+	buildCmd := opts.BuildCommand
+	if buildCmd == "" {
+		buildCmd = WorkerBuildCommand(svc.Main, outfile)
+	}
 	var sb strings.Builder
-	sb.WriteString(fmt.Sprintf("name = %q\n", cfg.Name+"-"+svc.Name))
-	sb.WriteString(fmt.Sprintf("main = %q\n", "../"+outfile))
+	sb.WriteString(fmt.Sprintf("name = %q\n", WorkerName(cfg, svc.Name)))
+	sb.WriteString(fmt.Sprintf("main = %q\n", rootRelative(outputPath, outfile)))
 	sb.WriteString(fmt.Sprintf("compatibility_date = %q\n\n", cfg.CompatibilityDate))
 	sb.WriteString("[build]\n")
 	sb.WriteString(fmt.Sprintf("command = %q\n\n", buildCmd))
@@ -675,7 +758,7 @@ func GenerateWranglerTomlForService(cfg *AerostackConfig, svc Service, outputPat
 		sb.WriteString(fmt.Sprintf("database_name = %q\n", db.DatabaseName))
 		sb.WriteString(fmt.Sprintf("database_id = %q\n", db.DatabaseID))
 		// Service wrangler configs live in .aerostack/ → migrations are one level up
-		sb.WriteString("migrations_dir = \"../migrations\"\n\n")
+		sb.WriteString(fmt.Sprintf("migrations_dir = %q\n\n", rootRelative(outputPath, "migrations")))
 	}
 	for _, ns := range cfg.KVNamespaces {
 		sb.WriteString("[[kv_namespaces]]\n")
@@ -686,22 +769,41 @@ func GenerateWranglerTomlForService(cfg *AerostackConfig, svc Service, outputPat
 		}
 		sb.WriteString("\n")
 	}
+	for _, b := range cfg.R2Buckets {
+		writeR2Bucket(&sb, "", b)
+	}
 	for _, q := range cfg.Queues {
 		sb.WriteString("[[queues.producers]]\n")
 		sb.WriteString(fmt.Sprintf("binding = %q\n", q.Binding))
 		sb.WriteString(fmt.Sprintf("queue = %q\n\n", q.Name))
 	}
 	for _, pg := range cfg.PostgresDatabases {
+		if opts.Standalone {
+			writeHyperdriveTemplate(&sb, "", pg.Binding)
+			continue
+		}
 		sb.WriteString("[[hyperdrive]]\n")
 		sb.WriteString(fmt.Sprintf("binding = %q\n", pg.Binding))
 		sb.WriteString("# Set CLOUDFLARE_HYPERDRIVE_LOCAL_CONNECTION_STRING_" + pg.Binding + " in .env\n\n")
 	}
+	// Service bindings to sibling workers; locally these resolve through wrangler's dev
+	// registry, which every 'aerostack dev' worker process registers with.
+	for _, b := range svc.Bindings {
+		sb.WriteString("[[services]]\n")
+		sb.WriteString(fmt.Sprintf("binding = %q\n", b.Binding))
+		sb.WriteString(fmt.Sprintf("service = %q\n\n", WorkerName(cfg, b.Service)))
+	}
 	if err := os.WriteFile(outputPath, []byte(sb.String()), 0644); err != nil {
 		return fmt.Errorf("failed to write %s: %w", outputPath, err)
 	}
 	return nil
 }
 
+// ServiceOutfile is where a [[services]] worker is bundled to.
+func ServiceOutfile(svc Service) string {
+	return fmt.Sprintf("dist/%s.js", svc.Name)
+}
+
 // CheckNode checks if Node.js 18+ is installed
 func CheckNode() (version string, err error) {
 	cmd := exec.Command("node", "-v")
@@ -727,10 +829,14 @@ func CheckNode() (version string, err error) {
 	return version, nil
 }
 
+// DevRegistryDir is the wrangler dev registry shared by the workers of one 'aerostack dev' session.
+var DevRegistryDir = filepath.Join(".aerostack", "registry")
+
 // RunWranglerDev runs npx wrangler dev with the given config
 // remoteEnv: if non-empty, passes --remote to use real Cloudflare bindings (e.g. "staging")
 // hyperdriveEnvVars: optional map of env var name -> value for Hyperdrive local connection strings
-func RunWranglerDev(wranglerTomlPath string, port int, remoteEnv string, hyperdriveEnvVars map[string]string) (*exec.Cmd, error) {
+// stdout, stderr: where wrangler's output goes (e.g. a LogMux writer); nil means the terminal
+func RunWranglerDev(wranglerTomlPath string, port int, remoteEnv string, hyperdriveEnvVars map[string]string, stdout, stderr io.Writer) (*exec.Cmd, error) {
 	absPath, err := filepath.Abs(wranglerTomlPath)
 	if err != nil {
 		return nil, err
@@ -760,6 +866,14 @@ func RunWranglerDev(wranglerTomlPath string, port int, remoteEnv string, hyperdr
 	cmd.Dir = projectRoot
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
+	if stdout != nil {
+		cmd.Stdout = stdout
+	}
+	if stderr != nil {
+		cmd.Stderr = stderr
+	}
+	// Output through a pipe: don't let a straggling child holding it open block Wait forever.
+	cmd.WaitDelay = 2 * time.Second
 	// Intentionally omitting cmd.Stdin = os.Stdin to prevent SIGTTIN suspension
 	// when Wrangler tries to read interactive keystrokes from a background process group.
 	cmd.Env = os.Environ()
@@ -767,6 +881,9 @@ func RunWranglerDev(wranglerTomlPath string, port int, remoteEnv string, hyperdr
 	// Ensure npx finds packages (use project dir)
 	cmd.Env = append(cmd.Env, "NPX_UPDATE_NOTIFIER=false")
 	cmd.Env = append(cmd.Env, "WRANGLER_SEND_METRICS=false")
+	// Keep the dev registry per project so service bindings between this project's workers
+	// resolve to each other and never to a same-named worker from another checkout.
+	cmd.Env = append(cmd.Env, "WRANGLER_REGISTRY_PATH="+filepath.Join(projectRoot, DevRegistryDir))
 	cmd.SysProcAttr = &syscall.SysProcAttr{}
 	setProcessGroup(cmd.SysProcAttr)
 
@@ -835,7 +952,8 @@ func EnsureDefaultAI(cfg *AerostackConfig) {
 }
 
 // StripLocalStubBindings removes stub bindings that only exist for local development
-// (e.g. database_id = 'aerostack-local', queue name starting with 'local-', KV id starting with 'local-').
+// (e.g. database_id = 'aerostack-local', queue name starting with 'local-', KV id or R2
+// bucket name starting with 'local-').
 // Must be called before deploying to real Cloudflare / Aerostack to avoid 404 errors.
 func StripLocalStubBindings(cfg *AerostackConfig) {
 	var realQueues []Queue
@@ -854,6 +972,14 @@ func StripLocalStubBindings(cfg *AerostackConfig) {
 	}
 	cfg.KVNamespaces = realKV
 
+	var realR2 []R2Bucket
+	for _, b := range cfg.R2Buckets {
+		if !strings.HasPrefix(b.BucketName, "local-") {
+			realR2 = append(realR2, b)
+		}
+	}
+	cfg.R2Buckets = realR2
+
 	var realD1 []D1Database
 	for _, db := range cfg.D1Databases {
 		if db.DatabaseID != "aerostack-local" && !strings.HasPrefix(db.DatabaseID, "local-") {
@@ -2,11 +2,13 @@ package devserver
 
 import (
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 )
 
 func TestGenerateWranglerToml(t *testing.T) {
+	t.Chdir(t.TempDir())
 	cfg := &AerostackConfig{
 		Name:              "test-app",
 		CompatibilityDate: "2024-01-01",
@@ -54,6 +56,133 @@ func TestGenerateWranglerToml(t *testing.T) {
 	}
 }
 
+func TestGenerateWranglerTomlForService_Bindings(t *testing.T) {
+	t.Chdir(t.TempDir())
+	cfg := &AerostackConfig{Name: "shop", CompatibilityDate: "2024-01-01"}
+	svc := Service{Name: "billing", Main: "src/billing.ts", Bindings: []ServiceBinding{
+		{Binding: "AUTH", Service: "auth"},
+		{Binding: "API", Service: MainWorker},
+	}}
+	outputPath := filepath.Join(t.TempDir(), "wrangler-billing.toml")
+	if err := GenerateWranglerTomlForService(cfg, svc, outputPath); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := os.ReadFile(outputPath)
+	content := string(data)
+	if !strings.Contains(content, `name = "shop-billing"`) {
+		t.Errorf("worker name missing:\n%s", content)
+	}
+	if !strings.Contains(content, "[[services]]\nbinding = \"AUTH\"\nservice = \"shop-auth\"") {
+		t.Errorf("AUTH binding should target the auth worker:\n%s", content)
+	}
+	if !strings.Contains(content, "[[services]]\nbinding = \"API\"\nservice = \"shop\"") {
+		t.Errorf("API binding should target the main worker:\n%s", content)
+	}
+}
+
+func TestGenerateWranglerToml_R2Buckets(t *testing.T) {
+	t.Chdir(t.TempDir())
+	cfg := &AerostackConfig{
+		Name:              "shop",
+		CompatibilityDate: "2024-01-01",
+		R2Buckets:         []R2Bucket{{Binding: "UPLOADS", BucketName: "local-uploads"}},
+		EnvOverrides: map[string]EnvOverride{
+			"production": {R2Buckets: []R2Bucket{{Binding: "UPLOADS", BucketName: "shop-uploads", PreviewBucketName: "shop-uploads-dev"}}},
+		},
+	}
+	outputPath := filepath.Join(t.TempDir(), "wrangler.toml")
+	if err := GenerateWranglerToml(cfg, outputPath); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := os.ReadFile(outputPath)
+	content := string(data)
+	if !strings.Contains(content, "[[r2_buckets]]\nbinding = \"UPLOADS\"\nbucket_name = \"local-uploads\"") {
+		t.Errorf("local R2 bucket missing:\n%s", content)
+	}
+	if !strings.Contains(content, "[[env.production.r2_buckets]]\nbinding = \"UPLOADS\"\nbucket_name = \"shop-uploads\"\npreview_bucket_name = \"shop-uploads-dev\"") {
+		t.Errorf("production R2 bucket missing:\n%s", content)
+	}
+}
+
+func TestGenerateWranglerTomlWithOptions_Standalone(t *testing.T) {
+	cfg := &AerostackConfig{
+		Name:              "shop",
+		CompatibilityDate: "2024-01-01",
+		D1Databases:       []D1Database{{Binding: "DB", DatabaseName: "shop", DatabaseID: "d1-real"}},
+		PostgresDatabases: []PostgresDatabase{{Binding: "PG", ConnectionString: "postgres://localhost/db"}},
+		Services:          []Service{{Name: "billing", Main: "src/billing.ts"}},
+	}
+	dir := t.TempDir()
+	t.Chdir(dir)
+	opts := WranglerTomlOptions{BuildCommand: "node scripts/build.mjs", Standalone: true}
+	mainPath := filepath.Join(dir, "wrangler.toml")
+	if err := GenerateWranglerTomlWithOptions(cfg, mainPath, opts); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := os.ReadFile(mainPath)
+	content := string(data)
+	for _, want := range []string{
+		`command = "node scripts/build.mjs"`,
+		`database_id = "d1-real"`,
+		"# [[hyperdrive]]\n# binding = \"PG\"",
+		"# [[env.production.hyperdrive]]",
+		"# Deploy: npx wrangler deploy --env",
+	} {
+		if !strings.Contains(content, want) {
+			t.Errorf("missing %q:\n%s", want, content)
+		}
+	}
+	if strings.Contains(content, "local-hyperdrive") {
+		t.Errorf("standalone config should not bind the local Hyperdrive stub:\n%s", content)
+	}
+
+	svcPath := filepath.Join(dir, "wrangler.billing.toml")
+	if err := GenerateWranglerTomlForServiceWithOptions(cfg, cfg.Services[0], svcPath, opts); err != nil {
+		t.Fatal(err)
+	}
+	data, _ = os.ReadFile(svcPath)
+	if !strings.Contains(string(data), "dist/billing.js") || !strings.Contains(string(data), `command = "node scripts/build.mjs"`) {
+		t.Errorf("service config:\n%s", data)
+	}
+}
+
+func TestRootRelative(t *testing.T) {
+	cases := []struct{ output, want string }{
+		{"wrangler.toml", "dist/worker.js"},
+		{filepath.Join(".aerostack", "wrangler.toml"), "../dist/worker.js"},
+		{filepath.Join("a", "b", "wrangler.toml"), "../../dist/worker.js"},
+	}
+	for _, c := range cases {
+		if got := rootRelative(c.output, "dist/worker.js"); got != c.want {
+			t.Errorf("rootRelative(%q) = %s, want %s", c.output, got, c.want)
+		}
+	}
+}
+
+func TestGenerateBuildScript(t *testing.T) {
+	cfg := &AerostackConfig{CompatibilityFlags: []string{"nodejs_compat"}}
+	script, err := GenerateBuildScript(cfg, []BuildScriptWorker{{Entry: "src/index.ts", Outfile: "dist/worker.js"}}, "scripts/node-mocks.cjs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{`"fs": "./scripts/node-mocks.cjs"`, `"path": "node:path"`, `"platform": "node"`, `"entry": "src/index.ts"`, "createRequire"} {
+		if !strings.Contains(script, want) {
+			t.Errorf("missing %q:\n%s", want, script)
+		}
+	}
+
+	// Same Node handling without the flag, matching BuildWorker
+	script, _ = GenerateBuildScript(&AerostackConfig{}, nil, "scripts/node-mocks.cjs")
+	if !strings.Contains(script, `"fs": "./scripts/node-mocks.cjs"`) || !strings.Contains(script, "createRequire") {
+		t.Errorf("node mocks should be aliased without nodejs_compat:\n%s", script)
+	}
+
+	script, _ = GenerateBuildScript(&AerostackConfig{CompatibilityFlags: []string{"nodejs_compat_v2"}}, nil, "scripts/node-mocks.cjs")
+	if !strings.Contains(script, `"platform": "browser"`) {
+		t.Errorf("nodejs_compat_v2 should keep the browser platform:\n%s", script)
+	}
+}
+
 func TestParseAerostackToml(t *testing.T) {
 	content := `
 name = "test-app"
@@ -0,0 +1,170 @@
+package devserver
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"hash/fnv"
+	"os"
+	"strings"
+	"time"
+)
+
+// DefaultMigrationLockTimeout is how long apply and rollback wait for another run to finish.
+const DefaultMigrationLockTimeout = 5 * time.Minute
+
+// migrationLockPoll is how often a waiting run retries the migration lock.
+var migrationLockPoll = 500 * time.Millisecond
+
+// migrationLockKey is the session advisory lock that serialises runs against one schema's
+// migrations, keyed on the tracking table's qualified name.
+func migrationLockKey(pg PostgresDatabase) int64 {
+	h := fnv.New64a()
+	h.Write([]byte(pg.SchemaName() + "._aerostack_migrations"))
+	return int64(h.Sum64())
+}
+
+// PostgresMigrateOptions controls how Postgres migrations are applied or rolled back.
+type PostgresMigrateOptions struct {
+	// LockTimeout is how long to wait for the migration lock; zero fails at once when it is held.
+	LockTimeout time.Duration
+	// SingleTransaction runs the whole batch in one transaction: all of it or none of it.
+	SingleTransaction bool
+	// OnWait is called once, with the current holder, when the lock is busy.
+	OnWait func(holder *MigrationLockHolder)
+}
+
+// MigrationLockHolder is the Postgres session holding the migration lock.
+type MigrationLockHolder struct {
+	PID         int
+	Application string // application_name; aerostack sets "aerostack migrate on <host> (pid N)"
+	User        string
+	ClientAddr  string
+	Since       time.Time // when the holder's current transaction or session started
+}
+
+func (h *MigrationLockHolder) String() string {
+	if h == nil {
+		return "another session"
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "backend pid %d", h.PID)
+	if h.Application != "" {
+		fmt.Fprintf(&b, " (%s)", h.Application)
+	}
+	if h.User != "" {
+		fmt.Fprintf(&b, " as %s", h.User)
+	}
+	if h.ClientAddr != "" {
+		fmt.Fprintf(&b, " from %s", h.ClientAddr)
+	}
+	if !h.Since.IsZero() {
+		fmt.Fprintf(&b, ", connected %s ago", time.Since(h.Since).Round(time.Second))
+	}
+	return b.String()
+}
+
+// MigrationLockedError is returned when the migration lock could not be taken in time.
+type MigrationLockedError struct {
+	Binding string
+	Holder  *MigrationLockHolder
+	Waited  time.Duration
+}
+
+func (e *MigrationLockedError) Error() string {
+	return fmt.Sprintf("migrations for %s are locked by %s (waited %s). Try again once it finishes, or raise --lock-timeout",
+		e.Binding, e.Holder, e.Waited.Round(time.Second))
+}
+
+// withMigrationLock runs fn on a dedicated connection holding the migration advisory lock,
+// after making sure the binding's _aerostack_migrations exists. The lock is session-level,
+// so it covers every transaction fn runs and is released if the process dies. Bindings with
+// their own schema get it first on the search_path, so migrations create their tables there.
+func withMigrationLock(pg PostgresDatabase, opts PostgresMigrateOptions, fn func(conn *sql.Conn) error) error {
+	binding := pg.Binding
+	db, err := connectPostgres(pg.ConnectionString, binding)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	ctx := context.Background()
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to connect to %s: %w", binding, err)
+	}
+	defer conn.Close()
+
+	// Name the session so a waiting run can say who holds the lock
+	if _, err := conn.ExecContext(ctx, "SELECT set_config('application_name', $1, false)", migrationAppName()); err != nil {
+		return fmt.Errorf("failed to connect to %s: %w", binding, err)
+	}
+
+	key := migrationLockKey(pg)
+	start := time.Now()
+	waited := false
+	for {
+		var locked bool
+		if err := conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock($1)", key).Scan(&locked); err != nil {
+			return fmt.Errorf("failed to take the migration lock: %w", err)
+		}
+		if locked {
+			break
+		}
+		holder := migrationLockHolder(ctx, conn, key)
+		elapsed := time.Since(start)
+		if elapsed >= opts.LockTimeout {
+			return &MigrationLockedError{Binding: binding, Holder: holder, Waited: elapsed}
+		}
+		if !waited && opts.OnWait != nil {
+			opts.OnWait(holder)
+		}
+		waited = true
+		time.Sleep(min(migrationLockPoll, opts.LockTimeout-elapsed))
+	}
+	defer conn.ExecContext(ctx, "SELECT pg_advisory_unlock($1)", key)
+
+	// Created under the lock: concurrent CREATE TABLE IF NOT EXISTS can still collide
+	if err := ensureMigrationsTable(conn, pg); err != nil {
+		return err
+	}
+	if pg.SchemaName() != "public" {
+		if _, err := conn.ExecContext(ctx, "SET search_path TO "+quoteIdent(pg.SchemaName())+", public"); err != nil {
+			return fmt.Errorf("failed to use schema %s: %w", pg.SchemaName(), err)
+		}
+	}
+	return fn(conn)
+}
+
+// migrationLockHolder looks up the session holding the lock; nil when it can't be seen
+// (e.g. the holder just released it, or pg_stat_activity hides other users' sessions).
+func migrationLockHolder(ctx context.Context, conn *sql.Conn, lockKey int64) *MigrationLockHolder {
+	// A bigint advisory key shows up in pg_locks split into classid (high) and objid (low)
+	key := uint64(lockKey)
+	var h MigrationLockHolder
+	var since sql.NullTime
+	err := conn.QueryRowContext(ctx, `
+SELECT a.pid, COALESCE(a.application_name, ''), COALESCE(a.usename::text, ''),
+       COALESCE(host(a.client_addr), ''), a.backend_start
+FROM pg_locks l JOIN pg_stat_activity a ON a.pid = l.pid
+WHERE l.locktype = 'advisory' AND l.granted AND l.objsubid = 1
+  AND l.classid::bigint = $1 AND l.objid::bigint = $2
+LIMIT 1`, int64(key>>32), int64(key&0xffffffff)).Scan(&h.PID, &h.Application, &h.User, &h.ClientAddr, &since)
+	if err != nil {
+		return nil
+	}
+	h.Since = since.Time
+	return &h
+}
+
+func migrationAppName() string {
+	host, _ := os.Hostname()
+	if host == "" {
+		host = "unknown host"
+	}
+	// application_name is capped at 63 bytes by Postgres
+	name := fmt.Sprintf("aerostack migrate on %s (pid %d)", host, os.Getpid())
+	if len(name) > 63 {
+		name = name[:63]
+	}
+	return name
+}
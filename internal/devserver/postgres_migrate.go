@@ -1,90 +1,508 @@
 package devserver
 
 import (
+	"context"
+	"crypto/sha256"
 	"database/sql"
+	"encoding/hex"
 	"fmt"
 	"os"
+	"path/filepath"
 	"sort"
 	"strings"
+	"time"
 
 	_ "github.com/lib/pq"
 )
 
-const migrationsTable = `CREATE TABLE IF NOT EXISTS _aerostack_migrations (
-	name TEXT PRIMARY KEY,
-	applied_at TIMESTAMPTZ DEFAULT NOW()
-);`
+// PostgresMigrationsDir holds the Postgres migrations (D1 uses migrations/).
+const PostgresMigrationsDir = "migrations_postgres"
 
-// ApplyPostgresMigrations applies migrations from migrations_postgres/ to the given Postgres connection.
-// Uses _aerostack_migrations table to track applied migrations.
-func ApplyPostgresMigrations(connStr string, binding string) (int, error) {
-	if strings.Contains(connStr, "$") {
-		return 0, fmt.Errorf("connection string has unresolved env vars for binding %q", binding)
+// downMarker separates the up and down SQL inside a single migration file.
+const downMarker = "-- +down"
+
+// upMarker optionally opens the up section; it is stripped so it doesn't affect the checksum.
+const upMarker = "-- +up"
+
+// Migration states reported by PostgresMigrationStatus.
+const (
+	MigrationApplied  = "applied"
+	MigrationPending  = "pending"
+	MigrationModified = "modified" // applied, but the file changed since
+	MigrationMissing  = "missing"  // applied, but the file is gone
+)
+
+// PostgresMigration is one migration file. Down comes from a paired <name>.down.sql or the
+// file's "-- +down" section; it is empty when the migration can't be rolled back.
+type PostgresMigration struct {
+	Name     string
+	Up       string
+	Down     string
+	Checksum string // SHA-256 of Up
+}
+
+// MigrationState is a migration as seen from both the binding's directory and its
+// _aerostack_migrations rows.
+type MigrationState struct {
+	Name      string
+	Status    string
+	AppliedAt time.Time // zero unless applied
+}
+
+type appliedMigration struct {
+	Name      string
+	Checksum  string // empty for rows recorded before checksums were stored
+	AppliedAt time.Time
+}
+
+// LoadPostgresMigrations reads dir's *.sql files in name order. A missing dir is not an error.
+func LoadPostgresMigrations(dir string) ([]PostgresMigration, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read %s: %w", dir, err)
+	}
+
+	var migrations []PostgresMigration
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !strings.HasSuffix(name, ".sql") || strings.HasSuffix(name, ".down.sql") {
+			continue
+		}
+		content, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", name, err)
+		}
+		up, down := splitMigration(string(content))
+		if paired, err := os.ReadFile(filepath.Join(dir, strings.TrimSuffix(name, ".sql")+".down.sql")); err == nil {
+			if down != "" {
+				return nil, fmt.Errorf("%s has both a %s section and a .down.sql file", name, downMarker)
+			}
+			down = string(paired)
+		}
+		migrations = append(migrations, PostgresMigration{Name: name, Up: up, Down: down, Checksum: migrationChecksum(up)})
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Name < migrations[j].Name })
+	return migrations, nil
+}
+
+// splitMigration splits a file at its "-- +down" line. Without one, everything is up.
+func splitMigration(content string) (up, down string) {
+	var upLines, downLines []string
+	inDown := false
+	for _, line := range strings.Split(content, "\n") {
+		switch marker := strings.TrimSpace(line); {
+		case !inDown && strings.EqualFold(marker, downMarker):
+			inDown = true
+			continue
+		case !inDown && strings.EqualFold(marker, upMarker):
+			continue
+		}
+		if inDown {
+			downLines = append(downLines, line)
+		} else {
+			upLines = append(upLines, line)
+		}
 	}
+	return strings.Join(upLines, "\n"), strings.TrimSpace(strings.Join(downLines, "\n"))
+}
+
+func migrationChecksum(up string) string {
+	sum := sha256.Sum256([]byte(strings.TrimSpace(up)))
+	return hex.EncodeToString(sum[:])
+}
 
+// migrationStates merges the files on disk with the applied rows. Applied migrations whose
+// file is gone are reported as missing, in name order with the rest.
+func migrationStates(files []PostgresMigration, applied []appliedMigration) []MigrationState {
+	byName := make(map[string]appliedMigration, len(applied))
+	for _, a := range applied {
+		byName[a.Name] = a
+	}
+	var states []MigrationState
+	onDisk := make(map[string]bool, len(files))
+	for _, f := range files {
+		onDisk[f.Name] = true
+		a, ok := byName[f.Name]
+		switch {
+		case !ok:
+			states = append(states, MigrationState{Name: f.Name, Status: MigrationPending})
+		case a.Checksum != "" && a.Checksum != f.Checksum:
+			states = append(states, MigrationState{Name: f.Name, Status: MigrationModified, AppliedAt: a.AppliedAt})
+		default:
+			states = append(states, MigrationState{Name: f.Name, Status: MigrationApplied, AppliedAt: a.AppliedAt})
+		}
+	}
+	for _, a := range applied {
+		if !onDisk[a.Name] {
+			states = append(states, MigrationState{Name: a.Name, Status: MigrationMissing, AppliedAt: a.AppliedAt})
+		}
+	}
+	sort.SliceStable(states, func(i, j int) bool { return states[i].Name < states[j].Name })
+	return states
+}
+
+// modifiedError reports applied migrations whose contents changed, which must be fixed
+// (restore the file or add a new migration) before anything else runs.
+func modifiedError(states []MigrationState) error {
+	var modified []string
+	for _, s := range states {
+		if s.Status == MigrationModified {
+			modified = append(modified, s.Name)
+		}
+	}
+	if len(modified) == 0 {
+		return nil
+	}
+	return fmt.Errorf("refusing to run: applied migration(s) changed since they were applied: %s\n"+
+		"Restore the original file(s) and put the change in a new migration", strings.Join(modified, ", "))
+}
+
+// connectPostgres opens a Postgres connection pool for binding.
+func connectPostgres(connStr, binding string) (*sql.DB, error) {
+	if strings.Contains(connStr, "$") {
+		return nil, fmt.Errorf("connection string has unresolved env vars for binding %q", binding)
+	}
 	db, err := sql.Open("postgres", connStr)
 	if err != nil {
-		return 0, fmt.Errorf("failed to connect: %w", err)
+		return nil, fmt.Errorf("failed to connect: %w", err)
 	}
-	defer db.Close()
+	return db, nil
+}
+
+// migrationConn is satisfied by *sql.DB and the locked *sql.Conn of apply and rollback.
+type migrationConn interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	BeginTx(ctx context.Context, opts *sql.TxOptions) (*sql.Tx, error)
+}
+
+// migrationsTable is the binding's tracking table, in the binding's schema. Rows are keyed
+// by binding, so bindings sharing a database and schema keep separate histories.
+func migrationsTable(pg PostgresDatabase) string {
+	return quoteIdent(pg.SchemaName()) + "._aerostack_migrations"
+}
+
+// bindingFilter matches the binding's rows. Rows recorded before tracking was per binding
+// have an empty binding and belong to whichever bindings use migrations_postgres/.
+func bindingFilter(pg PostgresDatabase, arg int) string {
+	if pg.MigrationsPath() == PostgresMigrationsDir {
+		return fmt.Sprintf("binding IN ($%d, '')", arg)
+	}
+	return fmt.Sprintf("binding = $%d", arg)
+}
+
+// ensureMigrationsTable creates the binding's schema and tracking table, and upgrades
+// tables from before per-binding tracking (no binding column, keyed on name alone).
+func ensureMigrationsTable(db migrationConn, pg PostgresDatabase) error {
+	ctx := context.Background()
+	table := migrationsTable(pg)
+	if pg.SchemaName() != "public" {
+		if _, err := db.ExecContext(ctx, "CREATE SCHEMA IF NOT EXISTS "+quoteIdent(pg.SchemaName())); err != nil {
+			return fmt.Errorf("failed to create schema %s: %w", pg.SchemaName(), err)
+		}
+	}
+	for _, stmt := range []string{
+		"CREATE TABLE IF NOT EXISTS " + table + ` (
+	binding TEXT NOT NULL DEFAULT '',
+	name TEXT NOT NULL,
+	applied_at TIMESTAMPTZ DEFAULT NOW(),
+	checksum TEXT,
+	PRIMARY KEY (binding, name)
+)`,
+		"ALTER TABLE " + table + " ADD COLUMN IF NOT EXISTS checksum TEXT",
+		"ALTER TABLE " + table + " ADD COLUMN IF NOT EXISTS binding TEXT NOT NULL DEFAULT ''",
+	} {
+		if _, err := db.ExecContext(ctx, stmt); err != nil {
+			return fmt.Errorf("failed to create migrations table: %w", err)
+		}
+	}
+
+	var pkey string
+	var pkeyColumns int
+	err := db.QueryRowContext(ctx, "SELECT conname, array_length(conkey, 1) FROM pg_constraint WHERE conrelid = $1::regclass AND contype = 'p'", table).Scan(&pkey, &pkeyColumns)
+	if err != nil && err != sql.ErrNoRows {
+		return fmt.Errorf("failed to inspect migrations table: %w", err)
+	}
+	if pkeyColumns < 2 {
+		upgrade := "ALTER TABLE " + table + " ADD PRIMARY KEY (binding, name)"
+		if pkey != "" {
+			upgrade = "ALTER TABLE " + table + " DROP CONSTRAINT " + quoteIdent(pkey) + ", ADD PRIMARY KEY (binding, name)"
+		}
+		if _, err := db.ExecContext(ctx, upgrade); err != nil {
+			return fmt.Errorf("failed to upgrade migrations table: %w", err)
+		}
+	}
+	return nil
+}
 
-	// Ensure migrations table exists
-	if _, err := db.Exec(migrationsTable); err != nil {
-		return 0, fmt.Errorf("failed to create migrations table: %w", err)
+func loadAppliedMigrations(db migrationConn, pg PostgresDatabase) ([]appliedMigration, error) {
+	query := "SELECT name, COALESCE(checksum, ''), applied_at FROM " + migrationsTable(pg) + " WHERE " + bindingFilter(pg, 1) + " ORDER BY name"
+	rows, err := db.QueryContext(context.Background(), query, pg.Binding)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read _aerostack_migrations: %w", err)
+	}
+	defer rows.Close()
+	var applied []appliedMigration
+	for rows.Next() {
+		var a appliedMigration
+		if err := rows.Scan(&a.Name, &a.Checksum, &a.AppliedAt); err != nil {
+			return nil, err
+		}
+		applied = append(applied, a)
 	}
+	return applied, rows.Err()
+}
 
-	entries, err := os.ReadDir("migrations_postgres")
+// readAppliedMigrations reads the binding's applied migrations without creating or upgrading
+// the tracking table: no table yet means nothing is applied, and a table from before
+// checksums or per-binding tracking is read as it is.
+func readAppliedMigrations(db migrationConn, pg PostgresDatabase) ([]appliedMigration, error) {
+	ctx := context.Background()
+	rows, err := db.QueryContext(ctx, "SELECT column_name FROM information_schema.columns WHERE table_schema = $1 AND table_name = '_aerostack_migrations'", pg.SchemaName())
 	if err != nil {
-		if os.IsNotExist(err) {
-			return 0, nil // No Postgres migrations folder
+		return nil, fmt.Errorf("failed to inspect _aerostack_migrations: %w", err)
+	}
+	columns := map[string]bool{}
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			rows.Close()
+			return nil, err
 		}
-		return 0, fmt.Errorf("failed to read migrations_postgres: %w", err)
+		columns[name] = true
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
 	}
 
-	var files []string
-	for _, e := range entries {
-		if !e.IsDir() && strings.HasSuffix(e.Name(), ".sql") {
-			files = append(files, e.Name())
+	switch {
+	case len(columns) == 0:
+		return nil, nil
+	case columns["binding"] && columns["checksum"]:
+		return loadAppliedMigrations(db, pg)
+	}
+
+	checksum := "''"
+	if columns["checksum"] {
+		checksum = "COALESCE(checksum, '')"
+	}
+	filter, args := "TRUE", []any{}
+	if columns["binding"] {
+		filter, args = bindingFilter(pg, 1), append(args, pg.Binding)
+	} else if pg.MigrationsPath() != PostgresMigrationsDir {
+		// Rows without a binding belong to migrations_postgres/ only
+		return nil, nil
+	}
+	query := "SELECT name, " + checksum + ", applied_at FROM " + migrationsTable(pg) + " WHERE " + filter + " ORDER BY name"
+	applied, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read _aerostack_migrations: %w", err)
+	}
+	defer applied.Close()
+	var out []appliedMigration
+	for applied.Next() {
+		var a appliedMigration
+		if err := applied.Scan(&a.Name, &a.Checksum, &a.AppliedAt); err != nil {
+			return nil, err
 		}
+		out = append(out, a)
 	}
-	sort.Strings(files)
+	return out, applied.Err()
+}
 
-	applied := 0
-	for _, f := range files {
-		var exists bool
-		err := db.QueryRow("SELECT EXISTS(SELECT 1 FROM _aerostack_migrations WHERE name = $1)", f).Scan(&exists)
+// PostgresMigrationStatus lists every migration in the binding's migrations directory and
+// every applied one, with its status (applied, pending, modified or missing). It only reads:
+// the tracking table is neither created nor upgraded, so every migration is pending until
+// the first apply.
+func PostgresMigrationStatus(pg PostgresDatabase) ([]MigrationState, error) {
+	files, err := LoadPostgresMigrations(pg.MigrationsPath())
+	if err != nil {
+		return nil, err
+	}
+	db, err := connectPostgres(pg.ConnectionString, pg.Binding)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+	applied, err := readAppliedMigrations(db, pg)
+	if err != nil {
+		return nil, err
+	}
+	return migrationStates(files, applied), nil
+}
+
+// ApplyPostgresMigrations applies the binding's migrations (migrations_dir, else
+// migrations_postgres/) in its schema. The binding's _aerostack_migrations rows track applied
+// migrations and their checksums; it refuses to run when an applied migration's file has
+// changed. The whole run holds the migration advisory lock, so concurrent runs against one
+// database apply each file once.
+func ApplyPostgresMigrations(pg PostgresDatabase, opts PostgresMigrateOptions) (int, error) {
+	files, err := LoadPostgresMigrations(pg.MigrationsPath())
+	if err != nil {
+		return 0, err
+	}
+	table := migrationsTable(pg)
+	count := 0
+	err = withMigrationLock(pg, opts, func(conn *sql.Conn) error {
+		applied, err := loadAppliedMigrations(conn, pg)
 		if err != nil {
-			return applied, fmt.Errorf("failed to check migration %s: %w", f, err)
+			return err
 		}
-		if exists {
-			continue
+		if err := modifiedError(migrationStates(files, applied)); err != nil {
+			return err
 		}
 
-		content, err := os.ReadFile("migrations_postgres/" + f)
-		if err != nil {
-			return applied, fmt.Errorf("failed to read %s: %w", f, err)
+		// Rows recorded before checksums were stored adopt the file's current checksum
+		recorded := make(map[string]bool, len(applied))
+		for _, a := range applied {
+			recorded[a.Name] = true
+			if a.Checksum != "" {
+				continue
+			}
+			for _, f := range files {
+				if f.Name == a.Name {
+					update := "UPDATE " + table + " SET checksum = $1 WHERE name = $2 AND " + bindingFilter(pg, 3)
+					if _, err := conn.ExecContext(context.Background(), update, f.Checksum, f.Name, pg.Binding); err != nil {
+						return fmt.Errorf("failed to record checksum for %s: %w", f.Name, err)
+					}
+				}
+			}
 		}
 
-		tx, err := db.Begin()
-		if err != nil {
-			return applied, fmt.Errorf("failed to begin tx for %s: %w", f, err)
+		var pending []PostgresMigration
+		for _, f := range files {
+			if !recorded[f.Name] {
+				pending = append(pending, f)
+			}
 		}
+		return runMigrations(conn, pending, opts.SingleTransaction, func(tx *sql.Tx, f PostgresMigration) error {
+			if _, err := tx.Exec(f.Up); err != nil {
+				return fmt.Errorf("migration %s failed: %w", f.Name, err)
+			}
+			if _, err := tx.Exec("INSERT INTO "+table+" (binding, name, checksum) VALUES ($1, $2, $3)", pg.Binding, f.Name, f.Checksum); err != nil {
+				return fmt.Errorf("failed to record migration %s: %w", f.Name, err)
+			}
+			return nil
+		}, func() { count++ })
+	})
+	return count, err
+}
 
-		if _, err := tx.Exec(string(content)); err != nil {
-			tx.Rollback()
-			return applied, fmt.Errorf("migration %s failed: %w", f, err)
+// runMigrations runs step for each migration, each in its own transaction or, with single,
+// all in one. done is called for every migration that is committed.
+func runMigrations(conn migrationConn, migrations []PostgresMigration, single bool, step func(tx *sql.Tx, m PostgresMigration) error, done func()) error {
+	ctx := context.Background()
+	if single {
+		if len(migrations) == 0 {
+			return nil
+		}
+		tx, err := conn.BeginTx(ctx, nil)
+		if err != nil {
+			return fmt.Errorf("failed to begin tx: %w", err)
+		}
+		for _, m := range migrations {
+			if err := step(tx, m); err != nil {
+				tx.Rollback()
+				return fmt.Errorf("%w\nThe batch ran in one transaction, so none of its %d migration(s) were applied", err, len(migrations))
+			}
+		}
+		if err := tx.Commit(); err != nil {
+			return fmt.Errorf("failed to commit the batch: %w", err)
+		}
+		for range migrations {
+			done()
 		}
+		return nil
+	}
 
-		if _, err := tx.Exec("INSERT INTO _aerostack_migrations (name) VALUES ($1)", f); err != nil {
+	for _, m := range migrations {
+		tx, err := conn.BeginTx(ctx, nil)
+		if err != nil {
+			return fmt.Errorf("failed to begin tx for %s: %w", m.Name, err)
+		}
+		if err := step(tx, m); err != nil {
 			tx.Rollback()
-			return applied, fmt.Errorf("failed to record migration %s: %w", f, err)
+			return err
 		}
-
 		if err := tx.Commit(); err != nil {
-			return applied, fmt.Errorf("failed to commit %s: %w", f, err)
+			return fmt.Errorf("failed to commit %s: %w", m.Name, err)
 		}
-		applied++
+		done()
 	}
+	return nil
+}
 
-	return applied, nil
+// RollbackPostgresMigrations reverts the binding's last steps applied migrations, newest
+// first, using their down SQL. Every migration to revert is checked up front: it must still
+// exist, be unmodified and have a down section, so a rollback never stops halfway for a
+// missing file. Like apply, it holds the migration lock for the whole run.
+func RollbackPostgresMigrations(pg PostgresDatabase, steps int, opts PostgresMigrateOptions) ([]string, error) {
+	if steps < 1 {
+		return nil, fmt.Errorf("--steps must be at least 1")
+	}
+	files, err := LoadPostgresMigrations(pg.MigrationsPath())
+	if err != nil {
+		return nil, err
+	}
+
+	var reverted []string
+	err = withMigrationLock(pg, opts, func(conn *sql.Conn) error {
+		applied, err := loadAppliedMigrations(conn, pg)
+		if err != nil {
+			return err
+		}
+		targets, err := rollbackPlan(files, applied, steps)
+		if err != nil {
+			return err
+		}
+		i := 0
+		return runMigrations(conn, targets, opts.SingleTransaction, func(tx *sql.Tx, m PostgresMigration) error {
+			if _, err := tx.Exec(m.Down); err != nil {
+				return fmt.Errorf("rollback of %s failed: %w", m.Name, err)
+			}
+			if _, err := tx.Exec("DELETE FROM "+migrationsTable(pg)+" WHERE name = $1 AND "+bindingFilter(pg, 2), m.Name, pg.Binding); err != nil {
+				return fmt.Errorf("failed to unrecord migration %s: %w", m.Name, err)
+			}
+			return nil
+		}, func() {
+			reverted = append(reverted, targets[i].Name)
+			i++
+		})
+	})
+	return reverted, err
+}
+
+// rollbackPlan picks the last steps applied migrations (by name, newest first) and checks each
+// can be reverted.
+func rollbackPlan(files []PostgresMigration, applied []appliedMigration, steps int) ([]PostgresMigration, error) {
+	byName := make(map[string]PostgresMigration, len(files))
+	for _, f := range files {
+		byName[f.Name] = f
+	}
+	sorted := append([]appliedMigration(nil), applied...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Name > sorted[j].Name })
+	if steps > len(sorted) {
+		steps = len(sorted)
+	}
+
+	var plan []PostgresMigration
+	for _, a := range sorted[:steps] {
+		f, ok := byName[a.Name]
+		switch {
+		case !ok:
+			return nil, fmt.Errorf("cannot roll back %s: the migration file is missing", a.Name)
+		case a.Checksum != "" && a.Checksum != f.Checksum:
+			return nil, fmt.Errorf("cannot roll back %s: the file changed since it was applied", a.Name)
+		case strings.TrimSpace(f.Down) == "":
+			return nil, fmt.Errorf("cannot roll back %s: add a %s section or a %s file",
+				a.Name, downMarker, strings.TrimSuffix(a.Name, ".sql")+".down.sql")
+		}
+		plan = append(plan, f)
+	}
+	return plan, nil
 }
@@ -0,0 +1,157 @@
+package devserver
+
+import (
+	"database/sql"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLoadPostgresMigrations_DownSections(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"001_users.sql":      "-- +up\nCREATE TABLE users (id INT);\n\n-- +down\nDROP TABLE users;\n",
+		"002_posts.sql":      "CREATE TABLE posts (id INT);\n",
+		"002_posts.down.sql": "DROP TABLE posts;\n",
+		"003_seed.sql":       "INSERT INTO users VALUES (1);\n",
+		"notes.txt":          "ignored",
+	})
+
+	migrations, err := LoadPostgresMigrations(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(migrations) != 3 {
+		t.Fatalf("got %d migrations: %+v", len(migrations), migrations)
+	}
+	if strings.Contains(migrations[0].Up, "DROP") || migrations[0].Down != "DROP TABLE users;" {
+		t.Errorf("inline sections = %q / %q", migrations[0].Up, migrations[0].Down)
+	}
+	if migrations[1].Down != "DROP TABLE posts;\n" {
+		t.Errorf("paired down = %q", migrations[1].Down)
+	}
+	if migrations[2].Down != "" {
+		t.Errorf("003 should have no down, got %q", migrations[2].Down)
+	}
+
+	// Editing only the down section must not change the checksum of what was applied
+	writeFiles(t, dir, map[string]string{"001_users.sql": "-- +up\nCREATE TABLE users (id INT);\n\n-- +down\nDROP TABLE IF EXISTS users;\n"})
+	again, _ := LoadPostgresMigrations(dir)
+	if again[0].Checksum != migrations[0].Checksum {
+		t.Errorf("checksum changed after editing the down section")
+	}
+}
+
+func TestMigrationStates(t *testing.T) {
+	files := []PostgresMigration{
+		{Name: "001.sql", Checksum: "a"},
+		{Name: "002.sql", Checksum: "b"},
+		{Name: "004.sql", Checksum: "d"},
+		{Name: "005.sql", Checksum: "e"},
+	}
+	at := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	applied := []appliedMigration{
+		{Name: "001.sql", Checksum: "a", AppliedAt: at},
+		{Name: "002.sql", Checksum: "changed", AppliedAt: at},
+		{Name: "003.sql", Checksum: "c", AppliedAt: at},
+		{Name: "004.sql", AppliedAt: at}, // recorded before checksums
+	}
+
+	states := migrationStates(files, applied)
+	want := []string{
+		"001.sql " + MigrationApplied,
+		"002.sql " + MigrationModified,
+		"003.sql " + MigrationMissing,
+		"004.sql " + MigrationApplied,
+		"005.sql " + MigrationPending,
+	}
+	if len(states) != len(want) {
+		t.Fatalf("states = %+v", states)
+	}
+	for i, s := range states {
+		if got := s.Name + " " + s.Status; got != want[i] {
+			t.Errorf("state[%d] = %s, want %s", i, got, want[i])
+		}
+	}
+	if err := modifiedError(states); err == nil || !strings.Contains(err.Error(), "002.sql") {
+		t.Errorf("modifiedError = %v", err)
+	}
+}
+
+func TestRollbackPlan(t *testing.T) {
+	files := []PostgresMigration{
+		{Name: "001.sql", Checksum: "a", Down: "DROP TABLE a;"},
+		{Name: "002.sql", Checksum: "b", Down: "DROP TABLE b;"},
+		{Name: "003.sql", Checksum: "c"},
+	}
+	applied := []appliedMigration{{Name: "001.sql", Checksum: "a"}, {Name: "002.sql", Checksum: "b"}}
+
+	plan, err := rollbackPlan(files, applied, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(plan) != 2 || plan[0].Name != "002.sql" || plan[1].Name != "001.sql" {
+		t.Errorf("plan = %+v", plan)
+	}
+
+	applied = append(applied, appliedMigration{Name: "003.sql", Checksum: "c"})
+	if _, err := rollbackPlan(files, applied, 2); err == nil || !strings.Contains(err.Error(), "003.down.sql") {
+		t.Errorf("expected missing down error, got %v", err)
+	}
+
+	applied[1].Checksum = "edited"
+	if _, err := rollbackPlan(files[:2], applied[:2], 1); err == nil || !strings.Contains(err.Error(), "changed") {
+		t.Errorf("expected modified error, got %v", err)
+	}
+}
+
+func TestRunMigrations_SingleTransaction(t *testing.T) {
+	migrations := []PostgresMigration{
+		{Name: "001_users.sql", Up: "CREATE TABLE users (id INTEGER)"},
+		{Name: "002_posts.sql", Up: "CREATE TABLE posts (id INTEGER)"},
+		{Name: "003_broken.sql", Up: "CREATE TABLE nope ("},
+	}
+	step := func(tx *sql.Tx, m PostgresMigration) error {
+		_, err := tx.Exec(m.Up)
+		return err
+	}
+	for _, single := range []bool{false, true} {
+		conn, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "test.db"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer conn.Close()
+		done := 0
+		err = runMigrations(conn, migrations, single, step, func() { done++ })
+		if err == nil {
+			t.Fatalf("single=%v: expected the broken migration to fail", single)
+		}
+		tables, _ := queryStrings(conn, "SELECT name FROM sqlite_master WHERE type = 'table' ORDER BY name")
+		want := "posts,users" // each migration commits on its own
+		if single {
+			want = "" // the batch is all or nothing
+		}
+		if strings.Join(tables, ",") != want || (single && done != 0) || (!single && done != 2) {
+			t.Errorf("single=%v: tables = %v, done = %d, err = %v", single, tables, done, err)
+		}
+	}
+}
+
+func TestMigrationLockedError(t *testing.T) {
+	err := &MigrationLockedError{
+		Binding: "PG",
+		Holder:  &MigrationLockHolder{PID: 4242, Application: "aerostack migrate on ci-runner (pid 17)", User: "app", ClientAddr: "10.0.0.7"},
+		Waited:  30 * time.Second,
+	}
+	want := "migrations for PG are locked by backend pid 4242 (aerostack migrate on ci-runner (pid 17)) as app from 10.0.0.7 (waited 30s)"
+	if !strings.HasPrefix(err.Error(), want) {
+		t.Errorf("error = %q", err.Error())
+	}
+	if got := (&MigrationLockedError{Binding: "PG"}).Error(); !strings.Contains(got, "locked by another session") {
+		t.Errorf("error without a visible holder = %q", got)
+	}
+	if len(migrationAppName()) > 63 {
+		t.Errorf("application_name %q is longer than Postgres allows", migrationAppName())
+	}
+}
@@ -0,0 +1,266 @@
+package devserver
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/pelletier/go-toml/v2"
+)
+
+// aerostackToml is the typed schema for aerostack.toml.
+// Every key the CLI understands is listed here; anything else is rejected in strict mode
+// so a typo surfaces as an error instead of silently falling back to a default.
+type aerostackToml struct {
+	Name               string         `toml:"name"`
+	ProjectSlug        string         `toml:"project_slug"`
+	Slug               string         `toml:"slug"`
+	ProjectID          string         `toml:"project_id"`
+	APIKey             string         `toml:"api_key"` // read by credentials.GetAPIKey
+	Main               string         `toml:"main"`
+	CompatibilityDate  string         `toml:"compatibility_date"`
+	CompatibilityFlags []string       `toml:"compatibility_flags"`
+	Description        string         `toml:"description"`
+	Category           string         `toml:"category"`
+	Tags               []string       `toml:"tags"`
+	AI                 bool           `toml:"ai"`
+	Commands           commandsToml   `toml:"commands"`
+	Build              buildToml      `toml:"build"`
+	D1Databases        []d1Toml       `toml:"d1_databases"`
+	PostgresDatabases  []postgresToml `toml:"postgres_databases"`
+	Services           []serviceToml  `toml:"services"`
+	KVNamespaces       []kvToml       `toml:"kv_namespaces"`
+	R2Buckets          []r2Toml       `toml:"r2_buckets"`
+	Queues             queuesToml     `toml:"queues"`
+	Vars               map[string]any `toml:"vars"`
+}
+
+// aerostackTomlWithEnvs is used when `env` is a table of [env.<name>] overrides.
+type aerostackTomlWithEnvs struct {
+	aerostackToml
+	Env map[string]envToml `toml:"env"`
+}
+
+// aerostackTomlWithSecrets is used when `env` is the list of required secret names
+// (env = ["KEY1", "KEY2"]), as written by MCP servers and skills.
+type aerostackTomlWithSecrets struct {
+	aerostackToml
+	Env []string `toml:"env"`
+}
+
+type commandsToml struct {
+	Build  string `toml:"build"`
+	Dev    string `toml:"dev"`
+	Deploy string `toml:"deploy"`
+}
+
+type buildToml struct {
+	MaxBundleKB int `toml:"max_bundle_kb"`
+}
+
+type d1Toml struct {
+	Binding      string `toml:"binding"`
+	DatabaseName string `toml:"database_name"`
+	DatabaseID   string `toml:"database_id"`
+}
+
+type postgresToml struct {
+	Binding          string `toml:"binding"`
+	ConnectionString string `toml:"connection_string"`
+	Schema           string `toml:"schema"`
+	SchemaFile       string `toml:"schema_file"`
+	MigrationsDir    string `toml:"migrations_dir"`
+	PoolSize         int    `toml:"pool_size"`
+	NeonProjectID    string `toml:"neon_project_id"`
+}
+
+type serviceToml struct {
+	Name     string               `toml:"name"`
+	Main     string               `toml:"main"`
+	Bindings []serviceBindingToml `toml:"bindings"`
+}
+
+type serviceBindingToml struct {
+	Binding string `toml:"binding"`
+	Service string `toml:"service"`
+}
+
+type kvToml struct {
+	Binding   string `toml:"binding"`
+	ID        string `toml:"id"`
+	PreviewID string `toml:"preview_id"`
+}
+
+type r2Toml struct {
+	Binding           string `toml:"binding"`
+	BucketName        string `toml:"bucket_name"`
+	PreviewBucketName string `toml:"preview_bucket_name"`
+}
+
+type queuesToml struct {
+	Producers []queueToml `toml:"producers"`
+}
+
+type queueToml struct {
+	Binding string `toml:"binding"`
+	Queue   string `toml:"queue"`
+}
+
+// envToml holds the overrides accepted inside an [env.<name>] table.
+type envToml struct {
+	APIKey             string         `toml:"api_key"`
+	CompatibilityFlags []string       `toml:"compatibility_flags"`
+	D1Databases        []d1Toml       `toml:"d1_databases"`
+	PostgresDatabases  []postgresToml `toml:"postgres_databases"`
+	KVNamespaces       []kvToml       `toml:"kv_namespaces"`
+	R2Buckets          []r2Toml       `toml:"r2_buckets"`
+	Queues             queuesToml     `toml:"queues"`
+	Vars               map[string]any `toml:"vars"`
+}
+
+// ConfigError is a single problem found in aerostack.toml.
+// Line and Column are 1-based; zero means the position is unknown.
+type ConfigError struct {
+	File    string
+	Line    int
+	Column  int
+	Key     string
+	Code    string // unknown-key, invalid-toml, missing-field, invalid-value
+	Message string
+}
+
+func (e ConfigError) Error() string {
+	loc := e.File
+	if e.Line > 0 {
+		loc = fmt.Sprintf("%s:%d:%d", e.File, e.Line, e.Column)
+	}
+	if e.Key != "" {
+		return fmt.Sprintf("%s: %s: %s", loc, e.Key, e.Message)
+	}
+	return fmt.Sprintf("%s: %s", loc, e.Message)
+}
+
+// ConfigErrors collects every problem found while parsing, so they can all be fixed in one pass.
+type ConfigErrors []ConfigError
+
+func (e ConfigErrors) Error() string {
+	msgs := make([]string, len(e))
+	for i, ce := range e {
+		msgs[i] = ce.Error()
+	}
+	return strings.Join(msgs, "\n")
+}
+
+// aerostackTomlFile is a decoded aerostack.toml with `env` resolved to whichever form it used.
+type aerostackTomlFile struct {
+	aerostackToml
+	Secrets []string
+	Envs    map[string]envToml
+}
+
+// decodeAerostackToml decodes data into the typed schema. In strict mode unknown keys are errors.
+// Decode failures are returned as ConfigErrors carrying the line and column of the offending key.
+func decodeAerostackToml(file string, data []byte, strict bool) (*aerostackTomlFile, error) {
+	// `env` is either a list of secret names or a table of per-environment overrides.
+	// Peek at its shape first so the strict decode can use the matching schema.
+	var shape struct {
+		Env any `toml:"env"`
+	}
+	if err := toml.Unmarshal(data, &shape); err != nil {
+		return nil, tomlDecodeErrors(file, err)
+	}
+
+	switch shape.Env.(type) {
+	case []any:
+		var doc aerostackTomlWithSecrets
+		if err := newTomlDecoder(data, strict).Decode(&doc); err != nil {
+			return nil, tomlDecodeErrors(file, err)
+		}
+		return &aerostackTomlFile{aerostackToml: doc.aerostackToml, Secrets: doc.Env}, nil
+	default:
+		var doc aerostackTomlWithEnvs
+		if err := newTomlDecoder(data, strict).Decode(&doc); err != nil {
+			return nil, tomlDecodeErrors(file, err)
+		}
+		return &aerostackTomlFile{aerostackToml: doc.aerostackToml, Envs: doc.Env}, nil
+	}
+}
+
+func newTomlDecoder(data []byte, strict bool) *toml.Decoder {
+	dec := toml.NewDecoder(bytes.NewReader(data))
+	if strict {
+		dec.DisallowUnknownFields()
+	}
+	return dec
+}
+
+// tomlDecodeErrors converts go-toml errors into ConfigErrors with positions.
+func tomlDecodeErrors(file string, err error) error {
+	var strictErr *toml.StrictMissingError
+	if errors.As(err, &strictErr) {
+		var errs ConfigErrors
+		for _, de := range strictErr.Errors {
+			row, col := de.Position()
+			errs = append(errs, ConfigError{
+				File:    file,
+				Line:    row,
+				Column:  col,
+				Key:     strings.Join(de.Key(), "."),
+				Code:    "unknown-key",
+				Message: "unknown key",
+			})
+		}
+		return errs
+	}
+	var decodeErr *toml.DecodeError
+	if errors.As(err, &decodeErr) {
+		row, col := decodeErr.Position()
+		return ConfigErrors{{
+			File:    file,
+			Line:    row,
+			Column:  col,
+			Key:     strings.Join(decodeErr.Key(), "."),
+			Code:    "invalid-toml",
+			Message: strings.TrimPrefix(decodeErr.Error(), "toml: "),
+		}}
+	}
+	return ConfigErrors{{File: file, Code: "invalid-toml", Message: err.Error()}}
+}
+
+// arrayTableLine returns the 1-based line of the idx-th [[header]] in data, or 0 if not found.
+// Used to point validation errors (e.g. a missing binding) at the block that caused them.
+func arrayTableLine(data []byte, header string, idx int) int {
+	want := "[[" + header + "]]"
+	seen := 0
+	line := 0
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if i := strings.Index(text, "#"); i >= 0 {
+			text = strings.TrimSpace(text[:i])
+		}
+		if strings.ReplaceAll(text, " ", "") != want {
+			continue
+		}
+		if seen == idx {
+			return line
+		}
+		seen++
+	}
+	return 0
+}
+
+// tomlVarString renders a [vars] value the way wrangler would see it.
+func tomlVarString(v any) (string, bool) {
+	switch val := v.(type) {
+	case string:
+		return val, true
+	case bool, int64, float64:
+		return fmt.Sprint(val), true
+	default:
+		return "", false
+	}
+}
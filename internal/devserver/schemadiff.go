@@ -0,0 +1,666 @@
+package devserver
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// SQL dialects DiffSchemas can emit.
+const (
+	DialectSQLite   = "sqlite" // D1
+	DialectPostgres = "postgres"
+)
+
+// rebuildPrefix names the temporary table a SQLite table rebuild copies into.
+const rebuildPrefix = "_aerostack_new_"
+
+// SchemaChange is one step of a schema diff: a summary and the statements that perform it.
+type SchemaChange struct {
+	Table   string
+	Summary string
+	SQL     []string
+	// Down reverts SQL where that is mechanical (new tables and columns, nullability); it is
+	// empty for changes that can't be undone without the lost data.
+	Down []string
+	// Destructive changes can lose data (dropped tables or columns, type changes) and
+	// need confirmation before a migration is written.
+	Destructive bool
+}
+
+// DesiredSchema is a declarative schema file: the tables it creates and their original DDL,
+// which is reused so defaults, checks and foreign keys survive into the migration.
+type DesiredSchema struct {
+	Tables  []TableSchema
+	creates map[string]string   // lower-case table name → CREATE TABLE statement
+	indexes map[string][]string // lower-case table name → CREATE INDEX statements
+	byIndex map[string]string   // lower-case index name → CREATE INDEX statement
+}
+
+var (
+	identPattern  = "(\"(?:[^\"]|\"\")+\"|`[^`]+`|\\[[^\\]]+\\]|[\\w.]+)"
+	createTableRe = regexp.MustCompile(`(?is)^CREATE\s+(?:TEMP\w*\s+)?TABLE\s+(?:IF\s+NOT\s+EXISTS\s+)?` + identPattern)
+	createIndexRe = regexp.MustCompile(`(?is)^CREATE\s+(?:UNIQUE\s+)?INDEX\s+(?:CONCURRENTLY\s+)?(?:IF\s+NOT\s+EXISTS\s+)?` + identPattern + `\s+ON\s+(?:ONLY\s+)?` + identPattern)
+)
+
+// LoadDesiredSchemaSQLite loads a schema file into an in-memory SQLite database and
+// introspects the result.
+func LoadDesiredSchemaSQLite(path string) (*DesiredSchema, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read schema file: %w", err)
+	}
+	conn, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	// Every pooled connection would be a separate in-memory database
+	conn.SetMaxOpenConns(1)
+	if _, err := conn.Exec(string(content)); err != nil {
+		return nil, fmt.Errorf("%s is not valid SQLite: %w", path, err)
+	}
+	tables, err := introspectSQLite(conn, "")
+	if err != nil {
+		return nil, err
+	}
+	return newDesiredSchema(string(content), tables), nil
+}
+
+// LoadDesiredSchemaPostgres runs a schema file inside a scratch schema of the target database,
+// in a transaction that is always rolled back, and introspects the result.
+func LoadDesiredSchemaPostgres(connStr, path string) (*DesiredSchema, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read schema file: %w", err)
+	}
+	db, err := sql.Open("postgres", connStr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to Postgres: %w", err)
+	}
+	defer db.Close()
+
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to Postgres: %w", err)
+	}
+	defer tx.Rollback()
+	scratch := fmt.Sprintf("_aerostack_diff_%d", time.Now().UnixNano())
+	if _, err := tx.Exec("CREATE SCHEMA " + scratch); err != nil {
+		return nil, fmt.Errorf("failed to create scratch schema: %w", err)
+	}
+	if _, err := tx.Exec("SET LOCAL search_path TO " + scratch); err != nil {
+		return nil, err
+	}
+	if _, err := tx.Exec(string(content)); err != nil {
+		return nil, fmt.Errorf("%s is not valid Postgres: %w", path, err)
+	}
+	tables, err := introspectPostgresSchema(tx, scratch, "")
+	if err != nil {
+		return nil, err
+	}
+	return newDesiredSchema(string(content), tables), nil
+}
+
+func newDesiredSchema(content string, tables []TableSchema) *DesiredSchema {
+	d := &DesiredSchema{Tables: tables, creates: map[string]string{}, indexes: map[string][]string{}, byIndex: map[string]string{}}
+	// Keep the file's order so tables referenced by foreign keys are created first
+	order := map[string]int{}
+	for i, stmt := range splitSQLStatements(content) {
+		if m := createTableRe.FindStringSubmatch(stmt); m != nil {
+			name := strings.ToLower(unquoteIdent(m[1]))
+			d.creates[name] = stmt
+			order[name] = i
+		} else if m := createIndexRe.FindStringSubmatch(stmt); m != nil {
+			table := strings.ToLower(unquoteIdent(m[2]))
+			d.indexes[table] = append(d.indexes[table], stmt)
+			d.byIndex[strings.ToLower(unquoteIdent(m[1]))] = stmt
+		}
+	}
+	sort.SliceStable(d.Tables, func(i, j int) bool {
+		return order[strings.ToLower(d.Tables[i].Name)] < order[strings.ToLower(d.Tables[j].Name)]
+	})
+	return d
+}
+
+// CurrentSchemaD1Local introspects a local D1 database; one that doesn't exist yet is empty.
+func CurrentSchemaD1Local(db D1Database) ([]TableSchema, error) {
+	if _, exists := LocalD1Path(db); !exists {
+		return nil, nil
+	}
+	return IntrospectD1Local(db, "")
+}
+
+// isTrackingTable reports tables owned by migration tooling, which a diff must never touch.
+func isTrackingTable(name string) bool {
+	name = strings.ToLower(name)
+	return name == "d1_migrations" || strings.HasPrefix(name, "_aerostack_") || strings.HasPrefix(name, "_cf_") || strings.HasPrefix(name, "sqlite_")
+}
+
+// DiffSchemas returns the changes that turn current into desired, in the given dialect:
+// new tables first (in schema file order), then changed tables and their indexes, then
+// dropped tables.
+func DiffSchemas(current []TableSchema, desired *DesiredSchema, dialect string) []SchemaChange {
+	cur := map[string]TableSchema{}
+	for _, t := range current {
+		if !isTrackingTable(t.Name) {
+			cur[strings.ToLower(t.Name)] = t
+		}
+	}
+	want := map[string]bool{}
+
+	var creates, alters, drops []SchemaChange
+	for _, t := range desired.Tables {
+		key := strings.ToLower(t.Name)
+		if isTrackingTable(t.Name) {
+			continue
+		}
+		want[key] = true
+		existing, ok := cur[key]
+		if !ok {
+			stmts := []string{desired.createSQL(t, dialect)}
+			stmts = append(stmts, desired.indexSQL(t.Name)...)
+			creates = append(creates, SchemaChange{
+				Table:   t.Name,
+				Summary: "create table " + t.Name,
+				SQL:     stmts,
+				Down:    []string{"DROP TABLE " + quoteIdent(t.Name) + ";"},
+			})
+			continue
+		}
+		alters = append(alters, desired.diffTable(existing, t, dialect)...)
+	}
+
+	var dropped []string
+	for key, t := range cur {
+		if !want[key] {
+			dropped = append(dropped, t.Name)
+		}
+	}
+	sort.Strings(dropped)
+	for _, name := range dropped {
+		drops = append(drops, SchemaChange{
+			Table:       name,
+			Summary:     "drop table " + name,
+			SQL:         []string{"DROP TABLE " + quoteIdent(name) + ";"},
+			Destructive: true,
+		})
+	}
+
+	return append(append(creates, alters...), drops...)
+}
+
+// SchemaMigration renders changes as a migration file; Postgres files get up/down sections.
+// When any change has no Down, the down section is left out entirely so 'db migrate
+// rollback' refuses the migration instead of reverting half of it.
+func SchemaMigration(changes []SchemaChange, dialect string) string {
+	var b strings.Builder
+	if dialect == DialectPostgres {
+		b.WriteString(upMarker + "\n")
+	}
+	var irreversible []string
+	for _, c := range changes {
+		fmt.Fprintf(&b, "-- %s", c.Summary)
+		if c.Destructive {
+			b.WriteString(" (destructive)")
+		}
+		b.WriteString("\n")
+		for _, stmt := range c.SQL {
+			b.WriteString(stmt + "\n")
+		}
+		b.WriteString("\n")
+		if len(c.Down) == 0 {
+			irreversible = append(irreversible, c.Summary)
+		}
+	}
+	if dialect != DialectPostgres {
+		return b.String()
+	}
+
+	if len(irreversible) > 0 {
+		fmt.Fprintf(&b, "-- Not reversible (%s), so this migration has no down section.\n", strings.Join(irreversible, ", "))
+		return b.String()
+	}
+	b.WriteString(downMarker + "\n")
+	// Undo in reverse order
+	for i := len(changes) - 1; i >= 0; i-- {
+		for _, stmt := range changes[i].Down {
+			b.WriteString(stmt + "\n")
+		}
+	}
+	return b.String()
+}
+
+func (d *DesiredSchema) diffTable(cur, want TableSchema, dialect string) []SchemaChange {
+	curCols := map[string]ColumnSchema{}
+	for _, c := range cur.Columns {
+		curCols[strings.ToLower(c.Name)] = c
+	}
+	wantCols := map[string]bool{}
+
+	var added, changed []ColumnSchema
+	var dropped []string
+	nullOnly := map[string]bool{}
+	for _, c := range want.Columns {
+		key := strings.ToLower(c.Name)
+		wantCols[key] = true
+		old, ok := curCols[key]
+		switch {
+		case !ok:
+			added = append(added, c)
+		case normalizeSQLType(old.SQLType) != normalizeSQLType(c.SQLType):
+			changed = append(changed, c)
+		case old.IsNullable != c.IsNullable:
+			changed = append(changed, c)
+			nullOnly[key] = true
+		}
+	}
+	for _, c := range cur.Columns {
+		if !wantCols[strings.ToLower(c.Name)] {
+			dropped = append(dropped, c.Name)
+		}
+	}
+	pkChanged := strings.Join(primaryKey(cur), ",") != strings.Join(primaryKey(want), ",")
+
+	if dialect == DialectSQLite {
+		rebuild := len(changed) > 0 || len(dropped) > 0 || pkChanged
+		for _, c := range added {
+			if !canAddColumnSQLite(d.columnDef(want.Name, c, dialect)) {
+				rebuild = true
+			}
+		}
+		if rebuild {
+			return []SchemaChange{d.rebuildSQLite(cur, want, added, changed, dropped, nullOnly)}
+		}
+	}
+
+	changes := d.diffIndexes(cur, want)
+	table := quoteIdent(want.Name)
+	for _, c := range added {
+		changes = append(changes, SchemaChange{
+			Table:   want.Name,
+			Summary: fmt.Sprintf("add column %s.%s", want.Name, c.Name),
+			SQL:     []string{fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s;", table, d.columnDef(want.Name, c, dialect))},
+			Down:    []string{fmt.Sprintf("ALTER TABLE %s DROP COLUMN %s;", table, quoteIdent(c.Name))},
+		})
+	}
+	// Only Postgres reaches here with changed or dropped columns; SQLite rebuilds the table
+	for _, c := range changed {
+		col := quoteIdent(c.Name)
+		if !nullOnly[strings.ToLower(c.Name)] {
+			changes = append(changes, SchemaChange{
+				Table:       want.Name,
+				Summary:     fmt.Sprintf("change type of %s.%s to %s", want.Name, c.Name, c.SQLType),
+				SQL:         []string{fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s TYPE %s USING %s::%s;", table, col, c.SQLType, col, c.SQLType)},
+				Destructive: true,
+			})
+		}
+		if curCols[strings.ToLower(c.Name)].IsNullable != c.IsNullable {
+			action, undo, summary := "SET NOT NULL", "DROP NOT NULL", "make %s.%s NOT NULL"
+			if c.IsNullable {
+				action, undo, summary = "DROP NOT NULL", "SET NOT NULL", "make %s.%s nullable"
+			}
+			changes = append(changes, SchemaChange{
+				Table:   want.Name,
+				Summary: fmt.Sprintf(summary, want.Name, c.Name),
+				SQL:     []string{fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s %s;", table, col, action)},
+				Down:    []string{fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s %s;", table, col, undo)},
+			})
+		}
+	}
+	for _, name := range dropped {
+		changes = append(changes, SchemaChange{
+			Table:       want.Name,
+			Summary:     fmt.Sprintf("drop column %s.%s", want.Name, name),
+			SQL:         []string{fmt.Sprintf("ALTER TABLE %s DROP COLUMN %s;", table, quoteIdent(name))},
+			Destructive: true,
+		})
+	}
+	if pkChanged {
+		stmts := []string{fmt.Sprintf("ALTER TABLE %s DROP CONSTRAINT IF EXISTS %s;", table, quoteIdent(want.Name+"_pkey"))}
+		if pk := primaryKey(want); len(pk) > 0 {
+			quoted := make([]string, len(pk))
+			for i, c := range pk {
+				quoted[i] = quoteIdent(c)
+			}
+			stmts = append(stmts, fmt.Sprintf("ALTER TABLE %s ADD PRIMARY KEY (%s);", table, strings.Join(quoted, ", ")))
+		}
+		changes = append(changes, SchemaChange{Table: want.Name, Summary: "change primary key of " + want.Name, SQL: stmts})
+	}
+	return changes
+}
+
+// diffIndexes creates, drops and recreates the CREATE INDEX indexes of a table that isn't
+// rebuilt. Indexes behind UNIQUE constraints belong to the table definition and are skipped.
+func (d *DesiredSchema) diffIndexes(cur, want TableSchema) []SchemaChange {
+	curIdx := map[string]IndexSchema{}
+	for _, idx := range cur.Indexes {
+		if !idx.Constraint {
+			curIdx[strings.ToLower(idx.Name)] = idx
+		}
+	}
+	wantIdx := map[string]bool{}
+
+	var changes []SchemaChange
+	for _, idx := range want.Indexes {
+		key := strings.ToLower(idx.Name)
+		if idx.Constraint {
+			continue
+		}
+		wantIdx[key] = true
+		stmt, ok := d.byIndex[key]
+		if !ok {
+			continue
+		}
+		old, exists := curIdx[key]
+		switch {
+		case !exists:
+			changes = append(changes, SchemaChange{
+				Table:   want.Name,
+				Summary: fmt.Sprintf("create index %s on %s", idx.Name, want.Name),
+				SQL:     []string{stmt + ";"},
+				Down:    []string{"DROP INDEX " + quoteIdent(idx.Name) + ";"},
+			})
+		case old.Unique != idx.Unique || strings.Join(old.Columns, ",") != strings.Join(idx.Columns, ","):
+			changes = append(changes, SchemaChange{
+				Table:   want.Name,
+				Summary: fmt.Sprintf("recreate index %s on %s", idx.Name, want.Name),
+				SQL:     []string{"DROP INDEX " + quoteIdent(idx.Name) + ";", stmt + ";"},
+				Down:    append([]string{"DROP INDEX " + quoteIdent(idx.Name) + ";"}, indexDown(want.Name, old)...),
+			})
+		}
+	}
+
+	var dropped []string
+	for key, idx := range curIdx {
+		if !wantIdx[key] {
+			dropped = append(dropped, idx.Name)
+		}
+	}
+	sort.Strings(dropped)
+	for _, name := range dropped {
+		changes = append(changes, SchemaChange{
+			Table:   want.Name,
+			Summary: fmt.Sprintf("drop index %s", name),
+			SQL:     []string{"DROP INDEX " + quoteIdent(name) + ";"},
+			Down:    indexDown(want.Name, curIdx[strings.ToLower(name)]),
+		})
+	}
+	return changes
+}
+
+// indexDown recreates an index from its introspected columns; nil for expression indexes,
+// whose definition isn't known.
+func indexDown(table string, idx IndexSchema) []string {
+	if len(idx.Columns) == 0 {
+		return nil
+	}
+	quoted := make([]string, len(idx.Columns))
+	for i, c := range idx.Columns {
+		quoted[i] = quoteIdent(c)
+	}
+	create := "CREATE INDEX"
+	if idx.Unique {
+		create = "CREATE UNIQUE INDEX"
+	}
+	return []string{fmt.Sprintf("%s %s ON %s (%s);", create, quoteIdent(idx.Name), quoteIdent(table), strings.Join(quoted, ", "))}
+}
+
+// rebuildSQLite recreates a table SQLite can't ALTER in place: create the new shape, copy the
+// shared columns, drop the old table and rename (the documented 12-step procedure).
+func (d *DesiredSchema) rebuildSQLite(cur, want TableSchema, added, changed []ColumnSchema, dropped []string, nullOnly map[string]bool) SchemaChange {
+	tmp := rebuildPrefix + want.Name
+	create := d.createSQL(want, DialectSQLite)
+	if m := createTableRe.FindStringSubmatchIndex(create); m != nil {
+		create = "CREATE TABLE " + quoteIdent(tmp) + create[m[3]:]
+	}
+
+	curCols := map[string]bool{}
+	for _, c := range cur.Columns {
+		curCols[strings.ToLower(c.Name)] = true
+	}
+	var shared []string
+	for _, c := range want.Columns {
+		if curCols[strings.ToLower(c.Name)] {
+			shared = append(shared, quoteIdent(c.Name))
+		}
+	}
+	cols := strings.Join(shared, ", ")
+
+	stmts := []string{
+		"PRAGMA defer_foreign_keys = on;",
+		create,
+		fmt.Sprintf("INSERT INTO %s (%s) SELECT %s FROM %s;", quoteIdent(tmp), cols, cols, quoteIdent(want.Name)),
+		"DROP TABLE " + quoteIdent(want.Name) + ";",
+		fmt.Sprintf("ALTER TABLE %s RENAME TO %s;", quoteIdent(tmp), quoteIdent(want.Name)),
+	}
+	stmts = append(stmts, d.indexSQL(want.Name)...)
+
+	var why []string
+	destructive := len(dropped) > 0
+	for _, c := range added {
+		why = append(why, "add "+c.Name)
+	}
+	for _, c := range changed {
+		if nullOnly[strings.ToLower(c.Name)] {
+			why = append(why, "nullability of "+c.Name)
+		} else {
+			why = append(why, "type of "+c.Name)
+			destructive = true
+		}
+	}
+	for _, name := range dropped {
+		why = append(why, "drop "+name)
+	}
+	if len(why) == 0 {
+		why = append(why, "primary key")
+	}
+	return SchemaChange{
+		Table:       want.Name,
+		Summary:     fmt.Sprintf("rebuild table %s (%s)", want.Name, strings.Join(why, ", ")),
+		SQL:         stmts,
+		Destructive: destructive,
+	}
+}
+
+// createSQL returns the table's CREATE TABLE from the schema file, or one built from its columns.
+func (d *DesiredSchema) createSQL(t TableSchema, dialect string) string {
+	if stmt, ok := d.creates[strings.ToLower(t.Name)]; ok {
+		return stmt + ";"
+	}
+	defs := make([]string, len(t.Columns))
+	for i, c := range t.Columns {
+		defs[i] = "  " + d.columnDef(t.Name, c, dialect)
+	}
+	if pk := primaryKey(t); len(pk) > 0 {
+		quoted := make([]string, len(pk))
+		for i, c := range pk {
+			quoted[i] = quoteIdent(c)
+		}
+		defs = append(defs, "  PRIMARY KEY ("+strings.Join(quoted, ", ")+")")
+	}
+	return fmt.Sprintf("CREATE TABLE %s (\n%s\n);", quoteIdent(t.Name), strings.Join(defs, ",\n"))
+}
+
+func (d *DesiredSchema) indexSQL(table string) []string {
+	var stmts []string
+	for _, stmt := range d.indexes[strings.ToLower(table)] {
+		stmts = append(stmts, stmt+";")
+	}
+	return stmts
+}
+
+// columnDef returns a column's definition as written in the schema file, so ADD COLUMN keeps
+// its DEFAULT, CHECK and REFERENCES clauses; it falls back to name, type and nullability.
+func (d *DesiredSchema) columnDef(table string, c ColumnSchema, dialect string) string {
+	if stmt, ok := d.creates[strings.ToLower(table)]; ok {
+		if def, ok := columnDefs(stmt)[strings.ToLower(c.Name)]; ok {
+			return def
+		}
+	}
+	def := quoteIdent(c.Name) + " " + c.SQLType
+	if !c.IsNullable {
+		def += " NOT NULL"
+	}
+	return def
+}
+
+// canAddColumnSQLite reports whether SQLite's ALTER TABLE ADD COLUMN accepts a definition.
+func canAddColumnSQLite(def string) bool {
+	upper := strings.ToUpper(def)
+	if strings.Contains(upper, "PRIMARY KEY") || strings.Contains(upper, "UNIQUE") {
+		return false
+	}
+	if strings.Contains(upper, "DEFAULT CURRENT_") || strings.Contains(upper, "DEFAULT (") {
+		return false
+	}
+	if strings.Contains(upper, "NOT NULL") && !strings.Contains(upper, "DEFAULT") {
+		return false
+	}
+	return true
+}
+
+func primaryKey(t TableSchema) []string {
+	var pk []string
+	for _, c := range t.Columns {
+		if c.IsPrimary {
+			pk = append(pk, strings.ToLower(c.Name))
+		}
+	}
+	return pk
+}
+
+func normalizeSQLType(t string) string {
+	return strings.Join(strings.Fields(strings.ToUpper(t)), " ")
+}
+
+// columnDefs maps lower-case column names to their definitions in a CREATE TABLE statement.
+func columnDefs(createSQL string) map[string]string {
+	open := strings.Index(createSQL, "(")
+	close := strings.LastIndex(createSQL, ")")
+	defs := map[string]string{}
+	if open < 0 || close < open {
+		return defs
+	}
+	for _, part := range splitTopLevel(createSQL[open+1 : close]) {
+		part = strings.TrimSpace(part)
+		fields := strings.Fields(part)
+		if len(fields) == 0 {
+			continue
+		}
+		switch strings.ToUpper(fields[0]) {
+		case "CONSTRAINT", "PRIMARY", "UNIQUE", "CHECK", "FOREIGN", "EXCLUDE", "LIKE":
+			continue
+		}
+		defs[strings.ToLower(unquoteIdent(fields[0]))] = part
+	}
+	return defs
+}
+
+// splitTopLevel splits on commas outside parentheses and quotes.
+func splitTopLevel(s string) []string {
+	var parts []string
+	depth, start := 0, 0
+	var quote byte
+	for i := 0; i < len(s); i++ {
+		ch := s[i]
+		switch {
+		case quote != 0:
+			if ch == quote {
+				quote = 0
+			}
+		case ch == '\'' || ch == '"' || ch == '`':
+			quote = ch
+		case ch == '(':
+			depth++
+		case ch == ')':
+			depth--
+		case ch == ',' && depth == 0:
+			parts = append(parts, s[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, s[start:])
+}
+
+// splitSQLStatements splits a SQL file into statements, dropping comments and trailing
+// semicolons. Quotes and Postgres dollar-quoted bodies are respected.
+func splitSQLStatements(content string) []string {
+	var stmts []string
+	var cur strings.Builder
+	flush := func() {
+		if stmt := strings.TrimSpace(cur.String()); stmt != "" {
+			stmts = append(stmts, stmt)
+		}
+		cur.Reset()
+	}
+	for i := 0; i < len(content); i++ {
+		ch := content[i]
+		switch {
+		case ch == '-' && strings.HasPrefix(content[i:], "--"):
+			end := strings.IndexByte(content[i:], '\n')
+			if end < 0 {
+				i = len(content)
+			} else {
+				i += end
+			}
+			cur.WriteByte('\n')
+		case ch == '/' && strings.HasPrefix(content[i:], "/*"):
+			end := strings.Index(content[i+2:], "*/")
+			if end < 0 {
+				i = len(content)
+			} else {
+				i += end + 3
+			}
+			cur.WriteByte(' ')
+		case ch == '\'' || ch == '"' || ch == '`':
+			end := strings.IndexByte(content[i+1:], ch)
+			if end < 0 {
+				end = len(content) - i - 1
+			}
+			cur.WriteString(content[i : i+end+2])
+			i += end + 1
+		case ch == '$':
+			if tag := dollarTagRe.FindString(content[i:]); tag != "" {
+				end := strings.Index(content[i+len(tag):], tag)
+				if end < 0 {
+					end = len(content) - i - len(tag)
+				} else {
+					end += len(tag)
+				}
+				cur.WriteString(content[i : i+len(tag)+end])
+				i += len(tag) + end - 1
+				continue
+			}
+			cur.WriteByte(ch)
+		case ch == ';':
+			flush()
+		default:
+			cur.WriteByte(ch)
+		}
+	}
+	flush()
+	return stmts
+}
+
+var dollarTagRe = regexp.MustCompile(`^\$[A-Za-z_]*\$`)
+
+// unquoteIdent strips identifier quotes and any schema prefix: "public"."users" → users.
+func unquoteIdent(s string) string {
+	if i := strings.LastIndex(s, "."); i >= 0 && !strings.HasPrefix(s, `"`) {
+		s = s[i+1:]
+	}
+	if len(s) >= 2 {
+		switch {
+		case s[0] == '"' && s[len(s)-1] == '"':
+			return strings.ReplaceAll(s[1:len(s)-1], `""`, `"`)
+		case s[0] == '`' && s[len(s)-1] == '`', s[0] == '[' && s[len(s)-1] == ']':
+			return s[1 : len(s)-1]
+		}
+	}
+	return s
+}
@@ -0,0 +1,261 @@
+package devserver
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSplitSQLStatements(t *testing.T) {
+	content := `-- users
+CREATE TABLE users (id INTEGER PRIMARY KEY, note TEXT DEFAULT 'a;b');
+/* block; comment */
+CREATE FUNCTION touch() RETURNS trigger AS $$
+BEGIN NEW.updated_at = now(); RETURN NEW; END;
+$$ LANGUAGE plpgsql;
+CREATE INDEX idx ON users(note)`
+	stmts := splitSQLStatements(content)
+	if len(stmts) != 3 {
+		t.Fatalf("got %d statements: %q", len(stmts), stmts)
+	}
+	if !strings.Contains(stmts[0], "'a;b'") {
+		t.Errorf("quoted semicolon split the statement: %q", stmts[0])
+	}
+	if !strings.Contains(stmts[1], "RETURN NEW; END;") {
+		t.Errorf("dollar-quoted body split the statement: %q", stmts[1])
+	}
+}
+
+func TestDiffSchemas_SQLiteRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+	writeFiles(t, dir, map[string]string{
+		"migrations/0001_init.sql": `CREATE TABLE users (id INTEGER PRIMARY KEY, email TEXT NOT NULL, legacy TEXT);
+CREATE TABLE old_sessions (id INTEGER PRIMARY KEY);
+INSERT INTO users (id, email, legacy) VALUES (1, 'a@example.com', 'x');`,
+		"schema.sql": `CREATE TABLE users (
+  id INTEGER PRIMARY KEY,
+  email TEXT NOT NULL,
+  nickname TEXT,
+  created_at TEXT NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE TABLE posts (
+  id INTEGER PRIMARY KEY,
+  user_id INTEGER NOT NULL REFERENCES users(id),
+  title TEXT NOT NULL
+);
+CREATE INDEX idx_posts_user ON posts(user_id);`,
+	})
+	db := D1Database{Binding: "DB", DatabaseName: "local-db", DatabaseID: "aerostack-local"}
+	if _, err := ApplyD1MigrationsLocal(db, D1MigrationsDir); err != nil {
+		t.Fatal(err)
+	}
+
+	desired, err := LoadDesiredSchemaSQLite("schema.sql")
+	if err != nil {
+		t.Fatal(err)
+	}
+	current, err := CurrentSchemaD1Local(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	changes := DiffSchemas(current, desired, DialectSQLite)
+
+	var summaries []string
+	for _, c := range changes {
+		summaries = append(summaries, c.Summary)
+	}
+	want := []string{
+		"create table posts",
+		"rebuild table users (add nickname, add created_at, drop legacy)",
+		"drop table old_sessions",
+	}
+	if strings.Join(summaries, "|") != strings.Join(want, "|") {
+		t.Fatalf("summaries = %q, want %q", summaries, want)
+	}
+	if changes[0].Destructive || !changes[1].Destructive || !changes[2].Destructive {
+		t.Errorf("destructive flags = %v %v %v", changes[0].Destructive, changes[1].Destructive, changes[2].Destructive)
+	}
+
+	// Applying the generated migration leaves nothing to diff and keeps the data
+	var sql []string
+	for _, c := range changes {
+		sql = append(sql, c.SQL...)
+	}
+	writeFiles(t, dir, map[string]string{"migrations/0002_diff.sql": strings.Join(sql, "\n")})
+	if _, err := ApplyD1MigrationsLocal(db, D1MigrationsDir); err != nil {
+		t.Fatal(err)
+	}
+	current, err = CurrentSchemaD1Local(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again := DiffSchemas(current, desired, DialectSQLite); len(again) != 0 {
+		t.Errorf("second diff = %+v", again)
+	}
+	conn, err := OpenLocalD1(db, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	var email string
+	if err := conn.QueryRow("SELECT email FROM users WHERE id = 1").Scan(&email); err != nil || email != "a@example.com" {
+		t.Errorf("email = %q, %v", email, err)
+	}
+}
+
+func TestDiffSchemas_SQLiteAddColumn(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{"schema.sql": "CREATE TABLE users (id INTEGER PRIMARY KEY, bio TEXT DEFAULT '');"})
+	desired, err := LoadDesiredSchemaSQLite(filepath.Join(dir, "schema.sql"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	current := []TableSchema{{Name: "users", Columns: []ColumnSchema{{Name: "id", SQLType: "INTEGER", IsPrimary: true, IsNullable: true}}}}
+
+	changes := DiffSchemas(current, desired, DialectSQLite)
+	if len(changes) != 1 || changes[0].Destructive {
+		t.Fatalf("changes = %+v", changes)
+	}
+	if got := changes[0].SQL[0]; got != `ALTER TABLE "users" ADD COLUMN bio TEXT DEFAULT '';` {
+		t.Errorf("SQL = %s", got)
+	}
+}
+
+func TestDiffSchemas_Postgres(t *testing.T) {
+	desired := newDesiredSchema(`CREATE TABLE users (
+  id SERIAL PRIMARY KEY,
+  email VARCHAR(320) NOT NULL,
+  age BIGINT,
+  plan TEXT NOT NULL DEFAULT 'free'
+);`, []TableSchema{{Name: "users", Columns: []ColumnSchema{
+		{Name: "id", SQLType: "integer", IsPrimary: true},
+		{Name: "email", SQLType: "character varying(320)"},
+		{Name: "age", SQLType: "bigint", IsNullable: true},
+		{Name: "plan", SQLType: "text"},
+	}}})
+	current := []TableSchema{
+		{Name: "users", Columns: []ColumnSchema{
+			{Name: "id", SQLType: "integer", IsPrimary: true},
+			{Name: "email", SQLType: "character varying(320)", IsNullable: true},
+			{Name: "age", SQLType: "integer", IsNullable: true},
+			{Name: "nickname", SQLType: "text", IsNullable: true},
+		}},
+		{Name: "_aerostack_migrations", Columns: []ColumnSchema{{Name: "name", SQLType: "text"}}},
+	}
+
+	changes := DiffSchemas(current, desired, DialectPostgres)
+	var got []string
+	for _, c := range changes {
+		got = append(got, c.SQL...)
+	}
+	want := []string{
+		`ALTER TABLE "users" ADD COLUMN plan TEXT NOT NULL DEFAULT 'free';`,
+		`ALTER TABLE "users" ALTER COLUMN "email" SET NOT NULL;`,
+		`ALTER TABLE "users" ALTER COLUMN "age" TYPE bigint USING "age"::bigint;`,
+		`ALTER TABLE "users" DROP COLUMN "nickname";`,
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("SQL =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+	destructive := 0
+	for _, c := range changes {
+		if c.Destructive {
+			destructive++
+		}
+	}
+	if destructive != 2 {
+		t.Errorf("destructive = %d, want 2 (type change and dropped column)", destructive)
+	}
+	if changes[0].Down[0] != `ALTER TABLE "users" DROP COLUMN "plan";` {
+		t.Errorf("down = %v", changes[0].Down)
+	}
+}
+
+func TestDiffSchemas_Indexes(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+	writeFiles(t, dir, map[string]string{
+		"migrations/0001_init.sql": `CREATE TABLE posts (id INTEGER PRIMARY KEY, user_id INTEGER, slug TEXT UNIQUE, title TEXT);
+CREATE INDEX idx_posts_title ON posts(title);
+CREATE INDEX idx_posts_user ON posts(user_id);`,
+		"schema.sql": `CREATE TABLE posts (id INTEGER PRIMARY KEY, user_id INTEGER, slug TEXT UNIQUE, title TEXT);
+CREATE INDEX idx_posts_user ON posts(user_id, id);
+CREATE UNIQUE INDEX idx_posts_title_user ON posts(title, user_id);`,
+	})
+	db := D1Database{Binding: "DB", DatabaseName: "local-db", DatabaseID: "aerostack-local"}
+	if _, err := ApplyD1MigrationsLocal(db, D1MigrationsDir); err != nil {
+		t.Fatal(err)
+	}
+	desired, err := LoadDesiredSchemaSQLite("schema.sql")
+	if err != nil {
+		t.Fatal(err)
+	}
+	current, err := CurrentSchemaD1Local(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	changes := DiffSchemas(current, desired, DialectSQLite)
+
+	var summaries, sql []string
+	for _, c := range changes {
+		summaries = append(summaries, c.Summary)
+		sql = append(sql, c.SQL...)
+		if c.Destructive {
+			t.Errorf("%s should not be destructive", c.Summary)
+		}
+	}
+	want := []string{
+		"create index idx_posts_title_user on posts",
+		"recreate index idx_posts_user on posts",
+		"drop index idx_posts_title",
+	}
+	if strings.Join(summaries, "|") != strings.Join(want, "|") {
+		t.Fatalf("summaries = %q, want %q", summaries, want)
+	}
+
+	writeFiles(t, dir, map[string]string{"migrations/0002_diff.sql": strings.Join(sql, "\n")})
+	if _, err := ApplyD1MigrationsLocal(db, D1MigrationsDir); err != nil {
+		t.Fatal(err)
+	}
+	if current, err = CurrentSchemaD1Local(db); err != nil {
+		t.Fatal(err)
+	}
+	if again := DiffSchemas(current, desired, DialectSQLite); len(again) != 0 {
+		t.Errorf("second diff = %+v", again)
+	}
+}
+
+func TestSchemaMigration_IrreversibleHasNoDown(t *testing.T) {
+	desired := newDesiredSchema(`CREATE TABLE users (id INTEGER PRIMARY KEY);`,
+		[]TableSchema{{Name: "users", Columns: []ColumnSchema{{Name: "id", SQLType: "integer", IsPrimary: true}}}})
+	current := []TableSchema{
+		{Name: "users", Columns: []ColumnSchema{{Name: "id", SQLType: "integer", IsPrimary: true}}},
+		{Name: "posts", Columns: []ColumnSchema{{Name: "id", SQLType: "integer", IsPrimary: true}}},
+	}
+	content := SchemaMigration(DiffSchemas(current, desired, DialectPostgres), DialectPostgres)
+	if strings.Contains(content, downMarker) || strings.Contains(content, "TODO") {
+		t.Fatalf("irreversible migration should have no down section:\n%s", content)
+	}
+
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{"001_drop_posts.sql": content})
+	files, err := LoadPostgresMigrations(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	applied := []appliedMigration{{Name: files[0].Name, Checksum: files[0].Checksum}}
+	if _, err := rollbackPlan(files, applied, 1); err == nil {
+		t.Errorf("rolling back a DROP TABLE migration should fail")
+	}
+}
+
+func TestSchemaMigration_ReversibleHasDown(t *testing.T) {
+	desired := newDesiredSchema(`CREATE TABLE users (id INTEGER PRIMARY KEY);`,
+		[]TableSchema{{Name: "users", Columns: []ColumnSchema{{Name: "id", SQLType: "integer", IsPrimary: true}}}})
+	content := SchemaMigration(DiffSchemas(nil, desired, DialectPostgres), DialectPostgres)
+	_, down := splitMigration(content)
+	if down != `DROP TABLE "users";` {
+		t.Errorf("down = %q\n%s", down, content)
+	}
+}
@@ -0,0 +1,751 @@
+package devserver
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SeedsDir holds the fixtures 'aerostack store seed' applies: *.sql files run as-is and
+// *.json files of rows, in file name order.
+const SeedsDir = "seeds"
+
+// SeedOptions controls what a seed run writes.
+type SeedOptions struct {
+	Dir    string   // fixtures directory; empty applies no fixtures
+	Fake   int      // rows of generated data per table, after the fixtures
+	Seed   int64    // the same seed generates the same data
+	Tables []string // restrict generated data to these tables (default: all)
+	Reset  bool     // empty every table (and reset its ids) first
+}
+
+// SeedReport is what one step of a seed run wrote.
+type SeedReport struct {
+	Source string // fixture file name, or "generated"
+	Table  string // empty for SQL fixtures
+	Rows   int    // rows inserted; rows affected for SQL fixtures
+}
+
+var seedPrefixRe = regexp.MustCompile(`^\d+[_-]`)
+
+// SeedFiles lists dir's *.sql and *.json fixtures in the order they are applied.
+func SeedFiles(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read %s: %w", dir, err)
+	}
+	var files []string
+	for _, e := range entries {
+		if ext := filepath.Ext(e.Name()); !e.IsDir() && (ext == ".sql" || ext == ".json") {
+			files = append(files, e.Name())
+		}
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// SeedD1Local seeds a local D1 database in one transaction.
+func SeedD1Local(db D1Database, opts SeedOptions) ([]SeedReport, error) {
+	conn, err := OpenLocalD1(db, false)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	s, err := newSQLiteSeeder(conn, db.Binding)
+	if err != nil {
+		return nil, err
+	}
+	return s.seed(opts)
+}
+
+// SeedPostgres seeds a Postgres binding in one transaction. Only tables in the binding's
+// schema are seeded or reset, so other bindings sharing the database keep their data.
+func SeedPostgres(pg PostgresDatabase, opts SeedOptions) ([]SeedReport, error) {
+	s, err := newPostgresSeeder(pg.ConnectionString, pg.Binding, pg.SchemaName())
+	if err != nil {
+		return nil, err
+	}
+	defer s.conn.Close()
+	return s.seed(opts)
+}
+
+// seeder writes fixtures, generated rows and restored dumps inside one transaction.
+type seeder struct {
+	conn      *sql.DB
+	tx        *sql.Tx
+	dialect   string
+	tables    []TableSchema   // parents before children
+	identity  map[string]bool // schema.table.column filled from a sequence without a default (Postgres)
+	generated map[string]bool // schema.table.column computed by the database (Postgres)
+	restoring bool            // keep explicit values for identity columns
+	schema    string          // non-public Postgres schema unqualified SQL resolves to first
+}
+
+func newSQLiteSeeder(conn *sql.DB, binding string) (*seeder, error) {
+	tables, err := introspectSQLite(conn, binding)
+	if err != nil {
+		return nil, err
+	}
+	return &seeder{conn: conn, dialect: DialectSQLite, tables: userTables(tables)}, nil
+}
+
+// newPostgresSeeder connects to Postgres and works on the tables of schemas (every user
+// schema when none are given); the caller closes s.conn.
+func newPostgresSeeder(connStr, binding string, schemas ...string) (*seeder, error) {
+	if strings.Contains(connStr, "$") {
+		return nil, fmt.Errorf("connection string has unresolved env vars for binding %q", binding)
+	}
+	tables, err := IntrospectPostgres(connStr, binding, schemas...)
+	if err != nil {
+		return nil, err
+	}
+	conn, err := sql.Open("postgres", connStr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect: %w", err)
+	}
+	s := &seeder{conn: conn, dialect: DialectPostgres, tables: userTables(tables), identity: map[string]bool{}, generated: map[string]bool{}}
+	if len(schemas) == 1 && schemas[0] != "public" {
+		s.schema = schemas[0]
+	}
+
+	// Identity and generated columns have no default expression but reject explicit values
+	rows, err := conn.Query(`
+		SELECT n.nspname, c.relname, a.attname, a.attgenerated <> ''
+		FROM pg_attribute a
+		JOIN pg_class c ON c.oid = a.attrelid
+		JOIN pg_namespace n ON n.oid = c.relnamespace
+		WHERE a.attnum > 0 AND NOT a.attisdropped AND (a.attidentity <> '' OR a.attgenerated <> '')
+		AND n.nspname NOT IN ('pg_catalog', 'information_schema')
+	`)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to read identity columns: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var schema, table, column string
+		var generated bool
+		if err := rows.Scan(&schema, &table, &column, &generated); err != nil {
+			conn.Close()
+			return nil, err
+		}
+		if generated {
+			s.generated[schema+"."+table+"."+column] = true
+		} else {
+			s.identity[schema+"."+table+"."+column] = true
+		}
+	}
+	if err := rows.Err(); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+func userTables(tables []TableSchema) []TableSchema {
+	var out []TableSchema
+	for _, t := range tables {
+		if !isTrackingTable(t.Name) {
+			out = append(out, t)
+		}
+	}
+	return seedOrder(out)
+}
+
+func (s *seeder) seed(opts SeedOptions) ([]SeedReport, error) {
+	files, err := SeedFiles(opts.Dir)
+	if err != nil {
+		return nil, err
+	}
+	if opts.Dir != "" && len(files) == 0 && opts.Fake == 0 && !opts.Reset {
+		return nil, fmt.Errorf("no *.sql or *.json fixtures in %s/ and no generated rows requested", opts.Dir)
+	}
+	for _, name := range opts.Tables {
+		if _, ok := s.table(name); !ok {
+			return nil, fmt.Errorf("no table %q to generate rows for", name)
+		}
+	}
+	var reports []SeedReport
+	err = s.inTx(func() error {
+		reports, err = s.run(files, opts)
+		return err
+	})
+	return reports, err
+}
+
+// inTx runs fn in a transaction on s.tx, committing only when it succeeds.
+func (s *seeder) inTx(fn func() error) error {
+	tx, err := s.conn.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	s.tx = tx
+	defer func() { s.tx = nil }()
+	if s.dialect == DialectSQLite {
+		// Rows may reference rows inserted later on; check foreign keys at commit
+		if _, err := tx.Exec("PRAGMA defer_foreign_keys = ON"); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	if s.schema != "" {
+		// SQL fixtures name tables unqualified, as the binding's migrations did
+		if _, err := tx.Exec("SET LOCAL search_path TO " + quoteIdent(s.schema) + ", public"); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	if err := fn(); err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit: %w", err)
+	}
+	return nil
+}
+
+func (s *seeder) run(files []string, opts SeedOptions) ([]SeedReport, error) {
+	if opts.Reset {
+		if err := s.reset(nil); err != nil {
+			return nil, err
+		}
+	}
+
+	var reports []SeedReport
+	for _, f := range files {
+		content, err := os.ReadFile(filepath.Join(opts.Dir, f))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", f, err)
+		}
+		if filepath.Ext(f) == ".sql" {
+			res, err := s.tx.Exec(string(content))
+			if err != nil {
+				return nil, fmt.Errorf("seed %s failed: %w", f, err)
+			}
+			n, _ := res.RowsAffected()
+			reports = append(reports, SeedReport{Source: f, Rows: int(n)})
+			continue
+		}
+		r, err := s.applyJSON(f, content)
+		if err != nil {
+			return nil, fmt.Errorf("seed %s failed: %w", f, err)
+		}
+		reports = append(reports, r...)
+	}
+
+	if opts.Fake > 0 {
+		rng := rand.New(rand.NewPCG(uint64(opts.Seed), 0))
+		for _, t := range s.tables {
+			if len(opts.Tables) > 0 && !containsTable(opts.Tables, t) {
+				continue
+			}
+			if err := s.generate(t, opts.Fake, rng); err != nil {
+				return nil, fmt.Errorf("generating %s failed: %w", t.Name, err)
+			}
+			reports = append(reports, SeedReport{Source: "generated", Table: tableLabel(t), Rows: opts.Fake})
+		}
+	}
+	return reports, nil
+}
+
+// reset empties the given tables (default: all), children first, and restarts their id
+// sequences.
+func (s *seeder) reset(only []string) error {
+	var tables []TableSchema
+	for _, t := range s.tables {
+		if len(only) == 0 || containsTable(only, t) {
+			tables = append(tables, t)
+		}
+	}
+	if len(tables) == 0 {
+		return nil
+	}
+	if s.dialect == DialectPostgres {
+		names := make([]string, len(tables))
+		for i, t := range tables {
+			names[i] = s.quoteTable(t)
+		}
+		if _, err := s.tx.Exec("TRUNCATE TABLE " + strings.Join(names, ", ") + " RESTART IDENTITY CASCADE"); err != nil {
+			return fmt.Errorf("failed to truncate tables: %w", err)
+		}
+		return nil
+	}
+	for i := len(tables) - 1; i >= 0; i-- {
+		if _, err := s.tx.Exec("DELETE FROM " + s.quoteTable(tables[i])); err != nil {
+			return fmt.Errorf("failed to empty %s: %w", tables[i].Name, err)
+		}
+	}
+	var seq int
+	if err := s.tx.QueryRow("SELECT COUNT(*) FROM sqlite_master WHERE name = 'sqlite_sequence'").Scan(&seq); err != nil {
+		return err
+	}
+	if seq > 0 {
+		if _, err := s.tx.Exec("DELETE FROM sqlite_sequence"); err != nil {
+			return fmt.Errorf("failed to reset AUTOINCREMENT counters: %w", err)
+		}
+	}
+	return nil
+}
+
+// applyJSON inserts a JSON fixture: an object mapping table names to arrays of rows, or a bare
+// array of rows for the table named by the file (01_users.json → users).
+func (s *seeder) applyJSON(file string, content []byte) ([]SeedReport, error) {
+	dec := json.NewDecoder(bytes.NewReader(content))
+	dec.UseNumber()
+	var raw any
+	if err := dec.Decode(&raw); err != nil {
+		return nil, fmt.Errorf("invalid JSON: %w", err)
+	}
+
+	fixtures := map[string][]map[string]any{}
+	switch v := raw.(type) {
+	case []any:
+		name := seedPrefixRe.ReplaceAllString(strings.TrimSuffix(file, ".json"), "")
+		rows, err := fixtureRows(name, v)
+		if err != nil {
+			return nil, err
+		}
+		fixtures[name] = rows
+	case map[string]any:
+		for name, val := range v {
+			list, ok := val.([]any)
+			if !ok {
+				return nil, fmt.Errorf("%q must be an array of rows", name)
+			}
+			rows, err := fixtureRows(name, list)
+			if err != nil {
+				return nil, err
+			}
+			fixtures[name] = rows
+		}
+	default:
+		return nil, fmt.Errorf("expected an object of tables or an array of rows")
+	}
+
+	// Insert parents before children whatever order the file lists them in
+	var reports []SeedReport
+	var unknown []string
+	for name := range fixtures {
+		if _, ok := s.table(name); !ok {
+			unknown = append(unknown, name)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return nil, fmt.Errorf("no table %s in the database. Run 'aerostack db migrate apply' first", strings.Join(unknown, ", "))
+	}
+	for _, t := range s.tables {
+		for name, rows := range fixtures {
+			if match, _ := s.table(name); match.Name != t.Name || match.Schema != t.Schema {
+				continue
+			}
+			for i, row := range rows {
+				if err := s.insert(t, row); err != nil {
+					return nil, fmt.Errorf("%s row %d: %w", name, i+1, err)
+				}
+			}
+			reports = append(reports, SeedReport{Source: file, Table: tableLabel(t), Rows: len(rows)})
+		}
+	}
+	return reports, nil
+}
+
+func fixtureRows(table string, list []any) ([]map[string]any, error) {
+	rows := make([]map[string]any, len(list))
+	for i, item := range list {
+		row, ok := item.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("%s row %d is not an object", table, i+1)
+		}
+		rows[i] = row
+	}
+	return rows, nil
+}
+
+// insert writes one row, checking its keys against the table's columns.
+func (s *seeder) insert(t TableSchema, row map[string]any) error {
+	if len(row) == 0 {
+		_, err := s.tx.Exec("INSERT INTO " + s.quoteTable(t) + " DEFAULT VALUES")
+		return err
+	}
+	names := make([]string, 0, len(row))
+	for name := range row {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	cols := make([]string, len(names))
+	marks := make([]string, len(names))
+	args := make([]any, len(names))
+	for i, name := range names {
+		if _, ok := findColumn(t, name); !ok {
+			return fmt.Errorf("no column %q in %s", name, t.Name)
+		}
+		cols[i] = quoteIdent(name)
+		marks[i] = s.placeholder(i + 1)
+		args[i] = sqlValue(row[name])
+	}
+	overriding := ""
+	if s.restoring && s.dialect == DialectPostgres {
+		overriding = " OVERRIDING SYSTEM VALUE"
+	}
+	_, err := s.tx.Exec(fmt.Sprintf("INSERT INTO %s (%s)%s VALUES (%s)", s.quoteTable(t), strings.Join(cols, ", "), overriding, strings.Join(marks, ", ")), args...)
+	return err
+}
+
+// sqlValue converts a decoded JSON value into a driver argument; objects and arrays are
+// stored as JSON text, and {"$base64": "..."} as binary.
+func sqlValue(v any) any {
+	if m, ok := v.(map[string]any); ok && len(m) == 1 {
+		if b64, ok := m["$base64"].(string); ok {
+			if b, err := base64.StdEncoding.DecodeString(b64); err == nil {
+				return b
+			}
+		}
+	}
+	switch val := v.(type) {
+	case json.Number:
+		if n, err := val.Int64(); err == nil {
+			return n
+		}
+		f, _ := val.Float64()
+		return f
+	case map[string]any, []any:
+		b, _ := json.Marshal(val)
+		return string(b)
+	default:
+		return val
+	}
+}
+
+// generate inserts n rows of fake data, choosing foreign keys from rows already in the
+// referenced table.
+func (s *seeder) generate(t TableSchema, n int, rng *rand.Rand) error {
+	var start int
+	if err := s.tx.QueryRow("SELECT COUNT(*) FROM " + s.quoteTable(t)).Scan(&start); err != nil {
+		return err
+	}
+
+	parents := map[int][][]any{}
+	for i, fk := range t.ForeignKeys {
+		ref, ok := findTable(s.tables, t, fk)
+		if !ok {
+			continue
+		}
+		keys, err := s.parentKeys(ref, fk.RefColumns)
+		if err != nil {
+			return err
+		}
+		if len(keys) == 0 && !allNullable(t, fk.Columns) && !(ref.Name == t.Name && ref.Schema == t.Schema) {
+			return fmt.Errorf("%s references %s, which has no rows. Seed %s too", t.Name, ref.Name, ref.Name)
+		}
+		parents[i] = keys
+	}
+
+	for row := 1; row <= n; row++ {
+		values := map[string]any{}
+		for _, col := range t.Columns {
+			if key := t.Schema + "." + t.Name + "." + col.Name; hasGeneratedValue(t, col) || s.identity[key] || s.generated[key] {
+				continue
+			}
+			values[col.Name] = fakeValue(t, col, start+row, rng)
+		}
+		for i, fk := range t.ForeignKeys {
+			keys, ok := parents[i]
+			if !ok {
+				continue
+			}
+			var pick []any
+			if len(keys) > 0 {
+				pick = keys[rng.IntN(len(keys))]
+			}
+			for j, col := range fk.Columns {
+				if pick == nil {
+					values[col] = nil
+				} else {
+					values[col] = pick[j]
+				}
+			}
+		}
+		if err := s.insert(t, values); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// parentKeys reads the referenced columns of every row in a parent table.
+func (s *seeder) parentKeys(t TableSchema, columns []string) ([][]any, error) {
+	quoted := make([]string, len(columns))
+	for i, c := range columns {
+		quoted[i] = quoteIdent(c)
+	}
+	list := strings.Join(quoted, ", ")
+	rows, err := s.tx.Query(fmt.Sprintf("SELECT %s FROM %s ORDER BY %s LIMIT 1000", list, s.quoteTable(t), list))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s keys: %w", t.Name, err)
+	}
+	defer rows.Close()
+	var keys [][]any
+	for rows.Next() {
+		vals := make([]any, len(columns))
+		ptrs := make([]any, len(columns))
+		for i := range vals {
+			ptrs[i] = &vals[i]
+		}
+		if err := rows.Scan(ptrs...); err != nil {
+			return nil, err
+		}
+		keys = append(keys, vals)
+	}
+	return keys, rows.Err()
+}
+
+func (s *seeder) table(name string) (TableSchema, bool) {
+	for _, t := range s.tables {
+		if strings.EqualFold(name, t.Name) && (t.Schema == "" || t.Schema == "public") || strings.EqualFold(name, t.Schema+"."+t.Name) {
+			return t, true
+		}
+	}
+	return TableSchema{}, false
+}
+
+func (s *seeder) quoteTable(t TableSchema) string {
+	if t.Schema != "" {
+		return quoteIdent(t.Schema) + "." + quoteIdent(t.Name)
+	}
+	return quoteIdent(t.Name)
+}
+
+func (s *seeder) placeholder(n int) string {
+	if s.dialect == DialectPostgres {
+		return "$" + strconv.Itoa(n)
+	}
+	return "?"
+}
+
+func containsTable(names []string, t TableSchema) bool {
+	for _, name := range names {
+		if strings.EqualFold(name, t.Name) || strings.EqualFold(name, t.Schema+"."+t.Name) {
+			return true
+		}
+	}
+	return false
+}
+
+func tableLabel(t TableSchema) string {
+	if t.Schema != "" && t.Schema != "public" {
+		return t.Schema + "." + t.Name
+	}
+	return t.Name
+}
+
+func findColumn(t TableSchema, name string) (ColumnSchema, bool) {
+	for _, c := range t.Columns {
+		if strings.EqualFold(c.Name, name) {
+			return c, true
+		}
+	}
+	return ColumnSchema{}, false
+}
+
+func allNullable(t TableSchema, columns []string) bool {
+	for _, name := range columns {
+		if c, ok := findColumn(t, name); !ok || !c.IsNullable {
+			return false
+		}
+	}
+	return true
+}
+
+// seedOrder sorts tables so every table comes after the tables it references. Tables in a
+// reference cycle keep their original order.
+func seedOrder(tables []TableSchema) []TableSchema {
+	sortSchemas(tables)
+	var ordered []TableSchema
+	done := map[int]bool{}
+	for len(ordered) < len(tables) {
+		progress := false
+		for i, t := range tables {
+			if done[i] {
+				continue
+			}
+			ready := true
+			for _, fk := range t.ForeignKeys {
+				for j, ref := range tables {
+					if !done[j] && j != i && strings.EqualFold(ref.Name, fk.RefTable) && (fk.RefSchema == "" || fk.RefSchema == ref.Schema) {
+						ready = false
+					}
+				}
+			}
+			if ready {
+				ordered = append(ordered, t)
+				done[i] = true
+				progress = true
+			}
+		}
+		if !progress {
+			for i, t := range tables {
+				if !done[i] {
+					ordered = append(ordered, t)
+					done[i] = true
+				}
+			}
+		}
+	}
+	return ordered
+}
+
+// ─── Fake data ──────────────────────────────────────────────────
+
+var (
+	fakeFirstNames = []string{"Ada", "Grace", "Alan", "Linus", "Margaret", "Dennis", "Barbara", "Ken", "Radia", "Edsger", "Frances", "Tim"}
+	fakeLastNames  = []string{"Lovelace", "Hopper", "Turing", "Torvalds", "Hamilton", "Ritchie", "Liskov", "Thompson", "Perlman", "Dijkstra", "Allen", "Berners-Lee"}
+	fakeWords      = []string{"alpha", "bright", "cloud", "delta", "edge", "forest", "garden", "harbor", "island", "journey", "kernel", "lumen", "meadow", "north", "orbit", "pixel", "quartz", "river", "summit", "tide"}
+	fakeCities     = []string{"Lisbon", "Osaka", "Toronto", "Nairobi", "Berlin", "Austin", "Melbourne", "Bogotá"}
+	fakeCountries  = []string{"PT", "JP", "CA", "KE", "DE", "US", "AU", "CO"}
+	fakeEpoch      = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+)
+
+// fakeValue makes up a value for a column from its type and name. n is the row's position in
+// the table, so primary and unique columns stay unique.
+func fakeValue(t TableSchema, col ColumnSchema, n int, rng *rand.Rand) any {
+	if len(col.EnumValues) > 0 {
+		return col.EnumValues[rng.IntN(len(col.EnumValues))]
+	}
+	typ := strings.ToLower(baseType(col.SQLType))
+	name := strings.ToLower(col.Name)
+	unique := col.IsPrimary || col.IsUnique || uniqueIndexed(t, col.Name)
+
+	switch {
+	case strings.HasSuffix(col.SQLType, "[]"):
+		return "{}"
+	case strings.Contains(typ, "bool"):
+		return rng.IntN(2) == 1
+	case isIntegerType(typ):
+		switch {
+		case unique:
+			return n
+		case strings.Contains(name, "age"):
+			return 18 + rng.IntN(60)
+		default:
+			return rng.IntN(1000)
+		}
+	case strings.Contains(typ, "real"), strings.Contains(typ, "float"), strings.Contains(typ, "double"),
+		strings.Contains(typ, "numeric"), strings.Contains(typ, "decimal"), strings.Contains(typ, "money"):
+		return math.Round(rng.Float64()*100000) / 100
+	case typ == "uuid":
+		b := make([]byte, 16)
+		for i := range b {
+			b[i] = byte(rng.IntN(256))
+		}
+		b[6], b[8] = b[6]&0x0f|0x40, b[8]&0x3f|0x80
+		h := hex.EncodeToString(b)
+		return h[:8] + "-" + h[8:12] + "-" + h[12:16] + "-" + h[16:20] + "-" + h[20:]
+	case strings.Contains(typ, "json"):
+		return "{}"
+	case strings.Contains(typ, "blob"), typ == "bytea":
+		return []byte(fmt.Sprintf("%s-%d", col.Name, n))
+	case typ == "date":
+		return fakeTime(rng).Format("2006-01-02")
+	case strings.HasPrefix(typ, "time ") || typ == "time":
+		return fakeTime(rng).Format("15:04:05")
+	case strings.Contains(typ, "timestamp"), strings.Contains(typ, "datetime"),
+		strings.HasSuffix(name, "_at"), strings.HasSuffix(name, "_date"):
+		return fakeTime(rng).Format("2006-01-02 15:04:05")
+	}
+
+	s := fakeText(name, n, rng)
+	if unique && !strings.Contains(s, strconv.Itoa(n)) {
+		s += "-" + strconv.Itoa(n)
+	}
+	if m := typeLenRe.FindStringSubmatch(col.SQLType); m != nil && strings.Contains(strings.ToUpper(col.SQLType), "CHAR") {
+		if max, _ := strconv.Atoi(m[1]); max > 0 && len(s) > max {
+			s = s[:max]
+		}
+	}
+	return s
+}
+
+func fakeText(name string, n int, rng *rand.Rand) string {
+	first := fakeFirstNames[rng.IntN(len(fakeFirstNames))]
+	last := fakeLastNames[rng.IntN(len(fakeLastNames))]
+	switch {
+	case strings.Contains(name, "email"):
+		return strings.ToLower(fmt.Sprintf("%s.%s%d@example.com", first, strings.ReplaceAll(last, "-", ""), n))
+	case strings.Contains(name, "first") && strings.Contains(name, "name"):
+		return first
+	case strings.Contains(name, "last") && strings.Contains(name, "name"), name == "surname":
+		return last
+	case name == "username", name == "handle", name == "login":
+		return strings.ToLower(first) + strconv.Itoa(n)
+	case strings.Contains(name, "name"):
+		return first + " " + last
+	case strings.Contains(name, "slug"):
+		return fakeWords[rng.IntN(len(fakeWords))] + "-" + fakeWords[rng.IntN(len(fakeWords))] + "-" + strconv.Itoa(n)
+	case strings.Contains(name, "url"), strings.Contains(name, "website"), strings.Contains(name, "link"):
+		return fmt.Sprintf("https://example.com/%s/%d", fakeWords[rng.IntN(len(fakeWords))], n)
+	case strings.Contains(name, "phone"):
+		return fmt.Sprintf("+1-555-01%02d", rng.IntN(100))
+	case strings.Contains(name, "city"):
+		return fakeCities[rng.IntN(len(fakeCities))]
+	case strings.Contains(name, "country"):
+		return fakeCountries[rng.IntN(len(fakeCountries))]
+	case strings.Contains(name, "color"), strings.Contains(name, "colour"):
+		return fmt.Sprintf("#%06x", rng.IntN(0x1000000))
+	case strings.Contains(name, "password"), strings.Contains(name, "hash"), strings.Contains(name, "token"), strings.Contains(name, "secret"):
+		b := make([]byte, 16)
+		for i := range b {
+			b[i] = byte(rng.IntN(256))
+		}
+		return hex.EncodeToString(b)
+	case strings.Contains(name, "title"), strings.Contains(name, "subject"):
+		return fakeSentence(rng, 3, false)
+	case strings.Contains(name, "desc"), strings.Contains(name, "body"), strings.Contains(name, "content"),
+		strings.Contains(name, "bio"), strings.Contains(name, "summary"), strings.Contains(name, "note"), name == "text":
+		return fakeSentence(rng, 8+rng.IntN(8), true)
+	default:
+		return fmt.Sprintf("%s %d", name, n)
+	}
+}
+
+func fakeSentence(rng *rand.Rand, words int, period bool) string {
+	w := make([]string, words)
+	for i := range w {
+		w[i] = fakeWords[rng.IntN(len(fakeWords))]
+	}
+	s := strings.ToUpper(w[0][:1]) + strings.Join(w, " ")[1:]
+	if period {
+		s += "."
+	}
+	return s
+}
+
+func fakeTime(rng *rand.Rand) time.Time {
+	return fakeEpoch.Add(time.Duration(rng.IntN(365*24*3600)) * time.Second)
+}
+
+func uniqueIndexed(t TableSchema, column string) bool {
+	for _, idx := range t.Indexes {
+		if idx.Unique && len(idx.Columns) == 1 && strings.EqualFold(idx.Columns[0], column) {
+			return true
+		}
+	}
+	return false
+}
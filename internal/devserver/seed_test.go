@@ -0,0 +1,209 @@
+package devserver
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func seedTestDB(t *testing.T, files map[string]string) D1Database {
+	t.Helper()
+	dir := t.TempDir()
+	t.Chdir(dir)
+	files["migrations/0001_init.sql"] = `
+CREATE TABLE users (id INTEGER PRIMARY KEY AUTOINCREMENT, email TEXT NOT NULL UNIQUE, name TEXT, role TEXT NOT NULL CHECK (role IN ('admin', 'member')), created_at TEXT);
+CREATE TABLE posts (id INTEGER PRIMARY KEY, user_id INTEGER NOT NULL REFERENCES users(id), title VARCHAR(12) NOT NULL, published BOOLEAN NOT NULL DEFAULT 0);`
+	writeFiles(t, dir, files)
+	db := D1Database{Binding: "DB", DatabaseName: "local-db", DatabaseID: "aerostack-local"}
+	if _, err := ApplyD1MigrationsLocal(db, D1MigrationsDir); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func queryD1(t *testing.T, db D1Database, query string) []string {
+	t.Helper()
+	conn, err := OpenLocalD1(db, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	out, err := queryStrings(conn, query)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return out
+}
+
+func TestSeedD1Local_Fixtures(t *testing.T) {
+	db := seedTestDB(t, map[string]string{
+		// Children listed first: the seeder still inserts users before posts
+		"seeds/01_blog.json": `{
+			"posts": [{"id": 10, "user_id": 1, "title": "Hello"}],
+			"users": [{"id": 1, "email": "ada@example.com", "role": "admin"}]
+		}`,
+		"seeds/02_users.json": `[{"email": "grace@example.com", "role": "member"}]`,
+		"seeds/03_extra.sql":  "UPDATE users SET name = 'Ada' WHERE id = 1;",
+	})
+
+	reports, err := SeedD1Local(db, SeedOptions{Dir: SeedsDir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, r := range reports {
+		got = append(got, r.Source+":"+r.Table)
+	}
+	if strings.Join(got, ",") != "01_blog.json:users,01_blog.json:posts,02_users.json:users,03_extra.sql:" {
+		t.Errorf("reports = %v", got)
+	}
+	if names := queryD1(t, db, "SELECT COALESCE(name, email) FROM users ORDER BY id"); strings.Join(names, ",") != "Ada,grace@example.com" {
+		t.Errorf("users = %v", names)
+	}
+
+	// A bad fixture rolls the whole run back
+	writeFiles(t, ".", map[string]string{"seeds/04_bad.json": `{"users": [{"email": "x@example.com", "nope": 1, "role": "admin"}]}`})
+	if _, err := SeedD1Local(db, SeedOptions{Dir: SeedsDir, Reset: true}); err == nil || !strings.Contains(err.Error(), `no column "nope"`) {
+		t.Fatalf("err = %v", err)
+	}
+	if n := queryD1(t, db, "SELECT COUNT(*) FROM users"); n[0] != "2" {
+		t.Errorf("failed seed was not rolled back: %s users", n[0])
+	}
+}
+
+func TestSeedD1Local_Fake(t *testing.T) {
+	db := seedTestDB(t, map[string]string{})
+
+	if _, err := SeedD1Local(db, SeedOptions{Fake: 5, Seed: 42, Tables: []string{"posts"}}); err == nil {
+		t.Error("expected an error generating posts without users")
+	}
+	if _, err := SeedD1Local(db, SeedOptions{Fake: 5, Seed: 42}); err != nil {
+		t.Fatal(err)
+	}
+	first := queryD1(t, db, "SELECT email || role || COALESCE(created_at, '') FROM users ORDER BY id")
+	posts := queryD1(t, db, "SELECT user_id || ':' || title FROM posts ORDER BY id")
+	if len(first) != 5 || len(posts) != 5 {
+		t.Fatalf("users = %v, posts = %v", first, posts)
+	}
+	for _, p := range posts {
+		if title := p[strings.Index(p, ":")+1:]; len(title) > 12 {
+			t.Errorf("title %q exceeds VARCHAR(12)", title)
+		}
+	}
+	for _, u := range first {
+		if !strings.Contains(u, "@example.com") || !(strings.Contains(u, "admin") || strings.Contains(u, "member")) {
+			t.Errorf("unexpected user %q", u)
+		}
+	}
+
+	// Reset and the same seed reproduce the same rows, with ids starting over
+	if _, err := SeedD1Local(db, SeedOptions{Fake: 5, Seed: 42, Reset: true}); err != nil {
+		t.Fatal(err)
+	}
+	again := queryD1(t, db, "SELECT email || role || COALESCE(created_at, '') FROM users ORDER BY id")
+	if strings.Join(again, "\n") != strings.Join(first, "\n") {
+		t.Errorf("same seed gave different data:\n%v\n%v", first, again)
+	}
+	if ids := queryD1(t, db, "SELECT MIN(id) FROM users"); ids[0] != "1" {
+		t.Errorf("ids not reset, min id = %s", ids[0])
+	}
+}
+
+func TestSeedOrder(t *testing.T) {
+	tables := []TableSchema{
+		{Name: "comments", ForeignKeys: []ForeignKey{{RefTable: "posts"}, {RefTable: "users"}}},
+		{Name: "posts", ForeignKeys: []ForeignKey{{RefTable: "users"}}},
+		{Name: "users", ForeignKeys: []ForeignKey{{RefTable: "users"}}},
+	}
+	var names []string
+	for _, t := range seedOrder(tables) {
+		names = append(names, t.Name)
+	}
+	if strings.Join(names, ",") != "users,posts,comments" {
+		t.Errorf("order = %v", names)
+	}
+}
+
+// testPostgres opens the database in AEROSTACK_TEST_POSTGRES_URL, skipping tests that need a
+// live Postgres when it isn't set. It returns the connection string and a connection.
+func testPostgres(t *testing.T) (string, *sql.DB) {
+	t.Helper()
+	connStr := os.Getenv("AEROSTACK_TEST_POSTGRES_URL")
+	if connStr == "" {
+		t.Skip("AEROSTACK_TEST_POSTGRES_URL not set")
+	}
+	conn, err := sql.Open("postgres", connStr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return connStr, conn
+}
+
+// testPostgresSchemas creates schemas that each hold an accounts table with one row, and
+// drops them when the test ends.
+func testPostgresSchemas(t *testing.T, conn *sql.DB, names ...string) []string {
+	t.Helper()
+	suffix := time.Now().UnixNano()
+	schemas := make([]string, len(names))
+	for i, name := range names {
+		schema := fmt.Sprintf("test_%s_%d", name, suffix)
+		schemas[i] = schema
+		t.Cleanup(func() { conn.Exec("DROP SCHEMA IF EXISTS " + schema + " CASCADE") })
+		if _, err := conn.Exec(fmt.Sprintf(`CREATE SCHEMA %[1]s;
+CREATE TABLE %[1]s.accounts (id SERIAL PRIMARY KEY, name TEXT NOT NULL);
+INSERT INTO %[1]s.accounts (name) VALUES ('keep');`, schema)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return schemas
+}
+
+func countRows(t *testing.T, conn *sql.DB, table string) int {
+	t.Helper()
+	var n int
+	if err := conn.QueryRow("SELECT COUNT(*) FROM " + table).Scan(&n); err != nil {
+		t.Fatal(err)
+	}
+	return n
+}
+
+func TestSeedPostgres_ResetStaysInBindingSchema(t *testing.T) {
+	connStr, conn := testPostgres(t)
+	schemas := testPostgresSchemas(t, conn, "billing", "shop")
+	billing, shop := schemas[0], schemas[1]
+
+	pg := PostgresDatabase{Binding: "BILLING", ConnectionString: connStr, Schema: billing}
+	if _, err := SeedPostgres(pg, SeedOptions{Reset: true}); err != nil {
+		t.Fatal(err)
+	}
+	if n := countRows(t, conn, billing+".accounts"); n != 0 {
+		t.Errorf("%s.accounts has %d rows after --reset, want 0", billing, n)
+	}
+	if n := countRows(t, conn, shop+".accounts"); n != 1 {
+		t.Errorf("%s.accounts has %d rows, want 1: another binding's schema was reset", shop, n)
+	}
+}
+
+func TestSeedPostgres_SQLFixtureUsesBindingSchema(t *testing.T) {
+	connStr, conn := testPostgres(t)
+	schemas := testPostgresSchemas(t, conn, "billing", "shop")
+	billing, shop := schemas[0], schemas[1]
+
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{"accounts.sql": "INSERT INTO accounts (name) VALUES ('seeded');"})
+
+	pg := PostgresDatabase{Binding: "BILLING", ConnectionString: connStr, Schema: billing}
+	if _, err := SeedPostgres(pg, SeedOptions{Dir: dir}); err != nil {
+		t.Fatal(err)
+	}
+	if n := countRows(t, conn, billing+".accounts"); n != 2 {
+		t.Errorf("%s.accounts has %d rows, want 2", billing, n)
+	}
+	if n := countRows(t, conn, shop+".accounts"); n != 1 {
+		t.Errorf("%s.accounts has %d rows, want 1", shop, n)
+	}
+}
@@ -0,0 +1,214 @@
+package devserver
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// ShellSession is an open connection for 'aerostack db shell'. Every statement runs on the
+// same connection, so BEGIN/COMMIT, SET and temporary tables typed at the prompt carry over.
+type ShellSession struct {
+	conn    *sql.DB
+	dialect string
+	binding string
+	connStr string // Postgres only, for introspection
+	schema  string // Postgres only: the binding's schema
+}
+
+// QueryResult is the outcome of one statement: rows for queries, a count for the rest.
+type QueryResult struct {
+	Columns      []string
+	Rows         [][]any
+	RowsAffected int64
+}
+
+// OpenShellD1Local opens a local D1 database's SQLite file.
+func OpenShellD1Local(db D1Database) (*ShellSession, error) {
+	conn, err := OpenLocalD1(db, false)
+	if err != nil {
+		return nil, err
+	}
+	return newShellSession(conn, DialectSQLite, db.Binding, ""), nil
+}
+
+// OpenShellD1Remote opens a deployed D1 database through the D1 HTTP API.
+func OpenShellD1Remote(db D1Database) (*ShellSession, error) {
+	conn, err := OpenRemoteD1(db)
+	if err != nil {
+		return nil, err
+	}
+	return newShellSession(conn, DialectSQLite, db.Binding, ""), nil
+}
+
+// OpenShellPostgres connects to a Postgres binding. Unqualified names resolve to the
+// binding's schema first, as they do in its migrations.
+func OpenShellPostgres(pg PostgresDatabase) (*ShellSession, error) {
+	if strings.Contains(pg.ConnectionString, "$") {
+		return nil, fmt.Errorf("connection string has unresolved env vars for binding %q", pg.Binding)
+	}
+	conn, err := sql.Open("postgres", pg.ConnectionString)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect: %w", err)
+	}
+	if err := conn.Ping(); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to connect to %s: %w", pg.Binding, err)
+	}
+	s := newShellSession(conn, DialectPostgres, pg.Binding, pg.ConnectionString)
+	s.schema = pg.SchemaName()
+	if s.schema != "public" {
+		if _, err := conn.Exec("SET search_path TO " + quoteIdent(s.schema) + ", public"); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("failed to set search_path: %w", err)
+		}
+	}
+	return s, nil
+}
+
+// newShellSession limits the pool to one connection that is never recycled: session state
+// lives on the connection, and a second one would silently lose it.
+func newShellSession(conn *sql.DB, dialect, binding, connStr string) *ShellSession {
+	conn.SetMaxOpenConns(1)
+	conn.SetMaxIdleConns(1)
+	conn.SetConnMaxLifetime(0)
+	conn.SetConnMaxIdleTime(0)
+	return &ShellSession{conn: conn, dialect: dialect, binding: binding, connStr: connStr}
+}
+
+// Dialect is DialectSQLite or DialectPostgres.
+func (s *ShellSession) Dialect() string { return s.dialect }
+
+// Close closes the connection.
+func (s *ShellSession) Close() error { return s.conn.Close() }
+
+// Run executes one statement. Statements that return rows (SELECT, WITH, PRAGMA, EXPLAIN,
+// SHOW, VALUES or anything with RETURNING) are queried; the rest report rows affected.
+func (s *ShellSession) Run(stmt string) (*QueryResult, error) {
+	if !returnsRows(stmt) {
+		res, err := s.conn.Exec(stmt)
+		if err != nil {
+			return nil, err
+		}
+		n, _ := res.RowsAffected()
+		return &QueryResult{RowsAffected: n}, nil
+	}
+
+	rows, err := s.conn.Query(stmt)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	cols, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+	result := &QueryResult{Columns: cols}
+	for rows.Next() {
+		vals := make([]any, len(cols))
+		ptrs := make([]any, len(cols))
+		for i := range vals {
+			ptrs[i] = &vals[i]
+		}
+		if err := rows.Scan(ptrs...); err != nil {
+			return nil, err
+		}
+		result.Rows = append(result.Rows, vals)
+	}
+	return result, rows.Err()
+}
+
+// Tables introspects the database for the \dt and \d meta-commands.
+func (s *ShellSession) Tables() ([]TableSchema, error) {
+	if s.dialect == DialectPostgres {
+		return IntrospectPostgres(s.connStr, s.binding, s.schema)
+	}
+	return introspectSQLite(s.conn, s.binding)
+}
+
+func returnsRows(stmt string) bool {
+	fields := strings.Fields(strings.ToUpper(stripLeadingComments(stmt)))
+	if len(fields) == 0 {
+		return false
+	}
+	switch fields[0] {
+	case "SELECT", "WITH", "PRAGMA", "EXPLAIN", "SHOW", "VALUES", "TABLE":
+		return true
+	}
+	for _, f := range fields {
+		if f == "RETURNING" {
+			return true
+		}
+	}
+	return false
+}
+
+func stripLeadingComments(stmt string) string {
+	for {
+		stmt = strings.TrimSpace(stmt)
+		switch {
+		case strings.HasPrefix(stmt, "--"):
+			if i := strings.Index(stmt, "\n"); i >= 0 {
+				stmt = stmt[i+1:]
+			} else {
+				return ""
+			}
+		case strings.HasPrefix(stmt, "/*"):
+			if i := strings.Index(stmt, "*/"); i >= 0 {
+				stmt = stmt[i+2:]
+			} else {
+				return ""
+			}
+		default:
+			return stmt
+		}
+	}
+}
+
+// StatementComplete reports whether buf holds one or more whole statements: its last
+// character outside quotes and comments is a semicolon.
+func StatementComplete(buf string) bool {
+	last := byte(0)
+	for i := 0; i < len(buf); i++ {
+		ch := buf[i]
+		switch {
+		case ch == '-' && strings.HasPrefix(buf[i:], "--"):
+			end := strings.IndexByte(buf[i:], '\n')
+			if end < 0 {
+				return last == ';'
+			}
+			i += end
+		case ch == '/' && strings.HasPrefix(buf[i:], "/*"):
+			end := strings.Index(buf[i+2:], "*/")
+			if end < 0 {
+				return false
+			}
+			i += end + 3
+		case ch == '\'' || ch == '"' || ch == '`':
+			end := strings.IndexByte(buf[i+1:], ch)
+			if end < 0 {
+				return false
+			}
+			i += end + 1
+			last = ch
+		case ch == '$':
+			if tag := dollarTagRe.FindString(buf[i:]); tag != "" {
+				end := strings.Index(buf[i+len(tag):], tag)
+				if end < 0 {
+					return false
+				}
+				i += 2*len(tag) + end - 1
+			}
+			last = ch
+		case ch == ' ' || ch == '\t' || ch == '\n' || ch == '\r':
+		default:
+			last = ch
+		}
+	}
+	return last == ';'
+}
+
+// SplitStatements splits complete input into statements for Run.
+func SplitStatements(buf string) []string {
+	return splitSQLStatements(buf)
+}
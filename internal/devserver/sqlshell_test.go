@@ -0,0 +1,130 @@
+package devserver
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestStatementComplete(t *testing.T) {
+	for buf, want := range map[string]bool{
+		"SELECT 1;":                           true,
+		"SELECT 1":                            false,
+		"SELECT 1;  \n":                       true,
+		"SELECT ';'":                          false,
+		"SELECT 'it''s';":                     true,
+		"SELECT 1; -- done":                   true,
+		"SELECT 1 -- not yet;":                false,
+		"SELECT 1 /* ; */":                    false,
+		"CREATE FUNCTION f() AS $$ SELECT 1;": false,
+		"CREATE FUNCTION f() AS $$ SELECT 1; $$ LANGUAGE sql;": true,
+		"SELECT $1;": true,
+	} {
+		if got := StatementComplete(buf); got != want {
+			t.Errorf("StatementComplete(%q) = %v, want %v", buf, got, want)
+		}
+	}
+}
+
+func TestShellSession_Run(t *testing.T) {
+	db := seedTestDB(t, map[string]string{})
+	s, err := OpenShellD1Local(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	res, err := s.Run("INSERT INTO users (email, role) VALUES ('ada@example.com', 'admin'), ('grace@example.com', 'member')")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Columns != nil || res.RowsAffected != 2 {
+		t.Errorf("insert result = %+v", res)
+	}
+
+	res, err = s.Run("-- newest first\nSELECT id, email, name FROM users ORDER BY id DESC")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Columns) != 3 || len(res.Rows) != 2 || res.Rows[0][1] != "grace@example.com" || res.Rows[0][2] != nil {
+		t.Errorf("select result = %+v", res)
+	}
+
+	res, err = s.Run("UPDATE users SET name = 'Ada' WHERE id = 1 RETURNING name")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Rows) != 1 || res.Rows[0][0] != "Ada" {
+		t.Errorf("returning result = %+v", res)
+	}
+
+	tables, err := s.Tables()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, table := range tables {
+		names = append(names, table.Name)
+	}
+	if !slices.Contains(names, "users") || !slices.Contains(names, "posts") {
+		t.Errorf("tables = %v", names)
+	}
+}
+
+func TestShellSession_KeepsSessionState(t *testing.T) {
+	db := seedTestDB(t, map[string]string{})
+	s, err := OpenShellD1Local(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	if n := s.conn.Stats().MaxOpenConnections; n != 1 {
+		t.Fatalf("MaxOpenConnections = %d, want the session pinned to one connection", n)
+	}
+
+	for _, stmt := range []string{
+		"CREATE TEMP TABLE scratch (id INTEGER)",
+		"INSERT INTO scratch VALUES (1)",
+		"BEGIN",
+		"INSERT INTO users (email, role) VALUES ('ada@example.com', 'admin')",
+		"ROLLBACK",
+	} {
+		if _, err := s.Run(stmt); err != nil {
+			t.Fatalf("%s: %v", stmt, err)
+		}
+	}
+	res, err := s.Run("SELECT (SELECT COUNT(*) FROM scratch), (SELECT COUNT(*) FROM users)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Rows[0][0] != int64(1) || res.Rows[0][1] != int64(0) {
+		t.Errorf("scratch, users = %v, want the temp table kept and the insert rolled back", res.Rows[0])
+	}
+}
+
+func TestShellSession_PostgresUsesBindingSchema(t *testing.T) {
+	connStr, conn := testPostgres(t)
+	schemas := testPostgresSchemas(t, conn, "billing", "shop")
+	billing := schemas[0]
+
+	s, err := OpenShellPostgres(PostgresDatabase{Binding: "BILLING", ConnectionString: connStr, Schema: billing})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	if _, err := s.Run("INSERT INTO accounts (name) VALUES ('shell')"); err != nil {
+		t.Fatal(err)
+	}
+	if n := countRows(t, conn, billing+".accounts"); n != 2 {
+		t.Errorf("%s.accounts has %d rows, want 2", billing, n)
+	}
+
+	tables, err := s.Tables()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tbl := range tables {
+		if tbl.Schema != billing {
+			t.Errorf("Tables() listed %s.%s outside the binding's schema", tbl.Schema, tbl.Name)
+		}
+	}
+}
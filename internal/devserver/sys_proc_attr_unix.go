@@ -20,3 +20,11 @@ func KillProcessGroup(p *os.Process) {
 		_ = syscall.Kill(-p.Pid, syscall.SIGKILL)
 	}
 }
+
+// TerminateProcessGroup asks the whole process group to exit (SIGTERM) so wrangler can
+// flush local state; follow up with KillProcessGroup if it doesn't.
+func TerminateProcessGroup(p *os.Process) {
+	if p != nil {
+		_ = syscall.Kill(-p.Pid, syscall.SIGTERM)
+	}
+}
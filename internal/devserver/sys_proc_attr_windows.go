@@ -17,3 +17,10 @@ func KillProcessGroup(p *os.Process) {
 		_ = p.Kill()
 	}
 }
+
+// TerminateProcessGroup has no graceful equivalent on Windows; the process is killed.
+func TerminateProcessGroup(p *os.Process) {
+	if p != nil {
+		_ = p.Kill()
+	}
+}
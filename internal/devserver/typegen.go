@@ -0,0 +1,964 @@
+package devserver
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/aerostackdev/cli/internal/api"
+)
+
+// Output formats of 'aerostack generate types'.
+const (
+	TypeFormatInterfaces = "interfaces" // plain TypeScript interfaces
+	TypeFormatZod        = "zod"        // Zod validators with inferred types
+	TypeFormatDrizzle    = "drizzle"    // Drizzle sqliteTable/pgTable definitions
+)
+
+// TypeFormats lists the formats GenerateTypes accepts.
+var TypeFormats = []string{TypeFormatInterfaces, TypeFormatZod, TypeFormatDrizzle}
+
+const generatedHeader = "// Generated by Aerostack CLI. Do not edit manually.\n\n"
+
+var (
+	jsIdentRe = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$]*$`)
+	typeLenRe = regexp.MustCompile(`\((\d+)\)`)
+	typeNumRe = regexp.MustCompile(`\((\d+),\s*(\d+)\)`)
+)
+
+// GenerateTypes renders schemas and project metadata in one of TypeFormats. Migration
+// tracking tables are left out.
+func GenerateTypes(schemas []TableSchema, meta *api.ProjectMetadata, format string) (string, error) {
+	schemas = UserTables(schemas)
+	switch format {
+	case TypeFormatInterfaces, "":
+		return GenerateTypeScript(schemas, meta), nil
+	case TypeFormatZod:
+		return generateZod(schemas, meta), nil
+	case TypeFormatDrizzle:
+		return generateDrizzle(schemas, meta), nil
+	default:
+		return "", fmt.Errorf("unknown format %q (expected %s)", format, strings.Join(TypeFormats, ", "))
+	}
+}
+
+// GenerateBindingTypes renders a Bindings interface for the worker's env object from
+// aerostack.toml, typed with the globals of @cloudflare/workers-types. It is empty when the
+// config declares no bindings.
+func GenerateBindingTypes(cfg *AerostackConfig) string {
+	type field struct{ name, typ string }
+	var fields []field
+	seen := map[string]bool{}
+	add := func(name, typ string) {
+		if !seen[name] {
+			seen[name] = true
+			fields = append(fields, field{name, typ})
+		}
+	}
+	for _, db := range cfg.D1Databases {
+		add(db.Binding, "D1Database")
+	}
+	for _, pg := range cfg.PostgresDatabases {
+		add(pg.Binding, "Hyperdrive")
+	}
+	for _, ns := range cfg.KVNamespaces {
+		add(ns.Binding, "KVNamespace")
+	}
+	for _, b := range cfg.R2Buckets {
+		add(b.Binding, "R2Bucket")
+	}
+	for _, q := range cfg.Queues {
+		add(q.Binding, "Queue")
+	}
+	for _, svc := range cfg.Services {
+		add(strings.ToUpper(svc.Name), "Fetcher")
+	}
+	if cfg.AI {
+		add("AI", "Ai")
+	}
+	for _, k := range sortedVarKeys(cfg.Vars) {
+		add(k, "string")
+	}
+	for _, secret := range cfg.EnvVars {
+		add(secret, "string")
+	}
+	if len(fields) == 0 {
+		return ""
+	}
+
+	var sb strings.Builder
+	sb.WriteString("// Worker bindings from aerostack.toml; types come from @cloudflare/workers-types\n")
+	sb.WriteString("export interface Bindings {\n")
+	for _, f := range fields {
+		name := f.name
+		if !jsIdentRe.MatchString(name) {
+			name = tsString(name)
+		}
+		sb.WriteString(fmt.Sprintf("  %s: %s;\n", name, f.typ))
+	}
+	sb.WriteString("}\n")
+	return sb.String()
+}
+
+// tsColumnType is a column's TypeScript type; enum columns become a union of their values.
+func tsColumnType(col ColumnSchema) string {
+	if len(col.EnumValues) > 0 {
+		return tsUnion(col.EnumValues)
+	}
+	return col.Type
+}
+
+func tsUnion(values []string) string {
+	return strings.Join(tsStrings(values), " | ")
+}
+
+// tsStringList renders values as the elements of a TypeScript array literal.
+func tsStringList(values []string) string {
+	return strings.Join(tsStrings(values), ", ")
+}
+
+func tsStrings(values []string) []string {
+	quoted := make([]string, len(values))
+	for i, v := range values {
+		quoted[i] = tsString(v)
+	}
+	return quoted
+}
+
+// tsString quotes a string for TypeScript source, single-quoted like the templates.
+func tsString(s string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`, "\n", `\n`).Replace(s) + "'"
+}
+
+// jsKey returns name as an object key, quoting it when it isn't a plain identifier.
+func jsKey(name string) string {
+	if jsIdentRe.MatchString(name) {
+		return name
+	}
+	return tsString(name)
+}
+
+// jsProp accesses a property: t.name or t['first name'].
+func jsProp(obj, name string) string {
+	if jsIdentRe.MatchString(name) {
+		return obj + "." + name
+	}
+	return obj + "[" + tsString(name) + "]"
+}
+
+func toCamelCase(s string) string {
+	return lowerFirst(toPascalCase(s))
+}
+
+func lowerFirst(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToLower(s[:1]) + s[1:]
+}
+
+// isIntegerType reports SQL integer types: INTEGER, BIGINT, int4, smallint...
+func isIntegerType(sqlType string) bool {
+	t := strings.ToUpper(sqlType)
+	return strings.Contains(t, "INT") && !strings.Contains(t, "INTERVAL") && !strings.Contains(t, "POINT")
+}
+
+// hasGeneratedValue reports columns the database fills in on insert: defaults, sequences and
+// SQLite's INTEGER PRIMARY KEY rowid alias.
+func hasGeneratedValue(table TableSchema, col ColumnSchema) bool {
+	if col.Default != "" {
+		return true
+	}
+	return table.Dialect == DialectSQLite && col.IsPrimary && strings.EqualFold(col.SQLType, "INTEGER") && len(primaryKey(table)) == 1
+}
+
+// nullableColumn reports whether a column's value can be null. Primary keys never are, even
+// where SQLite's PRAGMA reports notnull=0 for them; drizzle sees them the same way.
+func nullableColumn(col ColumnSchema) bool {
+	return col.IsNullable && !col.IsPrimary
+}
+
+// ─── Zod ────────────────────────────────────────────────────────
+
+func generateZod(schemas []TableSchema, meta *api.ProjectMetadata) string {
+	var sb strings.Builder
+	sb.WriteString(generatedHeader)
+	sb.WriteString("import { z } from 'zod';\n\n")
+
+	sortSchemas(schemas)
+	for _, table := range schemas {
+		name := tableTypeName(table)
+		var optional []string
+		sb.WriteString(fmt.Sprintf("export const %sSchema = z.object({\n", name))
+		for _, col := range table.Columns {
+			sb.WriteString(fmt.Sprintf("  %s: %s,\n", jsKey(col.Name), zodColumn(col)))
+			if nullableColumn(col) || hasGeneratedValue(table, col) {
+				optional = append(optional, fmt.Sprintf("%s: true", jsKey(col.Name)))
+			}
+		}
+		sb.WriteString("});\n")
+		// Inserts may leave out nullable columns and the ones the database fills in
+		insert := name + "Schema"
+		if len(optional) > 0 {
+			insert = fmt.Sprintf("%sSchema.partial({ %s })", name, strings.Join(optional, ", "))
+		}
+		sb.WriteString(fmt.Sprintf("export const New%sSchema = %s;\n", name, insert))
+		sb.WriteString(fmt.Sprintf("export type %s = z.infer<typeof %sSchema>;\n", name, name))
+		sb.WriteString(fmt.Sprintf("export type New%s = z.infer<typeof New%sSchema>;\n\n", name, name))
+	}
+
+	if meta != nil {
+		for _, col := range meta.Collections {
+			name := collectionTypeName(col.Slug)
+			data := "z.any()"
+			if fields, ok := parseCollectionSchema(col.Schema); ok {
+				data = zodObject(fields, "")
+			}
+			sb.WriteString(fmt.Sprintf("export const %sSchema = z.object({\n", name))
+			sb.WriteString("  id: z.string(),\n")
+			sb.WriteString("  slug: z.string(),\n")
+			sb.WriteString(fmt.Sprintf("  data: %s,\n", strings.ReplaceAll(data, "\n", "\n  ")))
+			sb.WriteString("  created_at: z.number(),\n")
+			sb.WriteString("});\n")
+			sb.WriteString(fmt.Sprintf("export type %s = z.infer<typeof %sSchema>;\n\n", name, name))
+		}
+	}
+
+	writeProjectSchema(&sb, schemas, meta)
+	return sb.String()
+}
+
+func zodColumn(col ColumnSchema) string {
+	var z string
+	switch {
+	case len(col.EnumValues) > 0:
+		z = zodEnum(col.EnumValues)
+	case col.Type == "number":
+		z = "z.number()"
+		if isIntegerType(col.SQLType) {
+			z += ".int()"
+		}
+	case col.Type == "string":
+		z = "z.string()"
+		if strings.EqualFold(baseType(col.SQLType), "uuid") {
+			z += ".uuid()"
+		} else if m := typeLenRe.FindStringSubmatch(col.SQLType); m != nil && strings.Contains(strings.ToUpper(col.SQLType), "CHAR") {
+			z += ".max(" + m[1] + ")"
+		}
+	case col.Type == "boolean":
+		z = "z.boolean()"
+	case col.Type == "Uint8Array":
+		z = "z.instanceof(Uint8Array)"
+	default:
+		z = "z.unknown()"
+	}
+	if nullableColumn(col) {
+		z += ".nullable()"
+	}
+	return z
+}
+
+func zodEnum(values []string) string {
+	return "z.enum([" + tsStringList(values) + "])"
+}
+
+// ─── Drizzle ────────────────────────────────────────────────────
+
+// drizzleImports collects the builders a generated file uses, per module.
+type drizzleImports map[string]map[string]bool
+
+func (d drizzleImports) add(module string, names ...string) {
+	if d[module] == nil {
+		d[module] = map[string]bool{}
+	}
+	for _, n := range names {
+		d[module][n] = true
+	}
+}
+
+func (d drizzleImports) String() string {
+	modules := make([]string, 0, len(d))
+	for m := range d {
+		modules = append(modules, m)
+	}
+	sort.Strings(modules)
+	var sb strings.Builder
+	for _, m := range modules {
+		names := make([]string, 0, len(d[m]))
+		for n := range d[m] {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		sb.WriteString(fmt.Sprintf("import { %s } from '%s';\n", strings.Join(names, ", "), m))
+	}
+	return sb.String()
+}
+
+func drizzleModule(dialect string) string {
+	if dialect == DialectPostgres {
+		return "drizzle-orm/pg-core"
+	}
+	return "drizzle-orm/sqlite-core"
+}
+
+// drizzleTableVar is the exported table constant: dbUsers.
+func drizzleTableVar(table TableSchema) string {
+	return lowerFirst(tableTypeName(table))
+}
+
+// drizzleSchemaVar is the pgSchema constant for a non-public Postgres schema: pgBillingSchema.
+func drizzleSchemaVar(binding, schema string) string {
+	return toCamelCase(binding+"_"+schema) + "Schema"
+}
+
+// pgEnumType splits a column's enum type into schema and name. Only Postgres enum types
+// qualify; text columns with a CHECK (col IN (...)) use the builders' enum option instead.
+func pgEnumType(col ColumnSchema) (schema, name string, ok bool) {
+	if len(col.EnumValues) == 0 {
+		return "", "", false
+	}
+	t := strings.TrimSuffix(col.SQLType, "[]")
+	if _, builtin := pgBuilders[baseType(strings.ToLower(t))]; builtin {
+		return "", "", false
+	}
+	parts := strings.Split(t, ".")
+	for i := range parts {
+		parts[i] = strings.Trim(parts[i], `"`)
+	}
+	if len(parts) == 2 {
+		return parts[0], parts[1], true
+	}
+	return "", parts[0], true
+}
+
+func generateDrizzle(schemas []TableSchema, meta *api.ProjectMetadata) string {
+	sortSchemas(schemas)
+	imports := drizzleImports{}
+	var body strings.Builder
+	pgModule := drizzleModule(DialectPostgres)
+
+	// Non-public Postgres schemas and enum types are declared once per binding
+	declared := map[string]bool{}
+	declareSchema := func(binding, schema string) string {
+		v := drizzleSchemaVar(binding, schema)
+		if !declared[v] {
+			declared[v] = true
+			imports.add(pgModule, "pgSchema")
+			body.WriteString(fmt.Sprintf("export const %s = pgSchema(%s);\n\n", v, tsString(schema)))
+		}
+		return v
+	}
+	enums := map[string]string{} // binding + "." + SQL type → variable
+	for _, table := range schemas {
+		if table.Dialect != DialectPostgres {
+			continue
+		}
+		if table.Schema != "" && table.Schema != "public" {
+			declareSchema(table.SourceBinding, table.Schema)
+		}
+		for _, col := range table.Columns {
+			schema, name, ok := pgEnumType(col)
+			key := table.SourceBinding + "." + strings.TrimSuffix(col.SQLType, "[]")
+			if !ok || enums[key] != "" {
+				continue
+			}
+			v := toCamelCase(table.SourceBinding+"_"+schema+"_"+name) + "Enum"
+			enums[key] = v
+			fn := "pgEnum"
+			if schema != "" && schema != "public" {
+				fn = declareSchema(table.SourceBinding, schema) + ".enum"
+			} else {
+				imports.add(pgModule, "pgEnum")
+			}
+			body.WriteString(fmt.Sprintf("export const %s = %s(%s, [%s]);\n\n", v, fn, tsString(name), tsStringList(col.EnumValues)))
+		}
+	}
+
+	for _, table := range schemas {
+		writeDrizzleTable(&body, imports, table, schemas, enums)
+	}
+
+	var sb strings.Builder
+	sb.WriteString(generatedHeader)
+	sb.WriteString(imports.String())
+	sb.WriteString("\n")
+	sb.WriteString(body.String())
+	writeCollectionInterfaces(&sb, meta)
+	writeProjectSchema(&sb, schemas, meta)
+	return sb.String()
+}
+
+func writeDrizzleTable(sb *strings.Builder, imports drizzleImports, table TableSchema, all []TableSchema, enums map[string]string) {
+	module := drizzleModule(table.Dialect)
+	tableFn := "sqliteTable"
+	if table.Dialect == DialectPostgres {
+		tableFn = "pgTable"
+		if table.Schema != "" && table.Schema != "public" {
+			tableFn = drizzleSchemaVar(table.SourceBinding, table.Schema) + ".table"
+		}
+	}
+	if !strings.Contains(tableFn, ".") {
+		imports.add(module, tableFn)
+	}
+	v := drizzleTableVar(table)
+
+	// Single-column foreign keys go on the column; composite ones into the table config
+	refs := map[string]ForeignKey{}
+	var extra []string
+	for _, fk := range table.ForeignKeys {
+		target, ok := findTable(all, table, fk)
+		if !ok || len(fk.Columns) != len(fk.RefColumns) {
+			continue
+		}
+		if len(fk.Columns) == 1 {
+			refs[strings.ToLower(fk.Columns[0])] = fk
+			continue
+		}
+		imports.add(module, "foreignKey")
+		cols := make([]string, len(fk.Columns))
+		refCols := make([]string, len(fk.RefColumns))
+		for i := range fk.Columns {
+			cols[i] = jsProp("t", fk.Columns[i])
+			refCols[i] = jsProp(drizzleTableVar(target), fk.RefColumns[i])
+		}
+		extra = append(extra, fmt.Sprintf("foreignKey({ columns: [%s], foreignColumns: [%s] })%s",
+			strings.Join(cols, ", "), strings.Join(refCols, ", "), drizzleActions(fk, true)))
+	}
+
+	pk := primaryKey(table)
+	if len(pk) > 1 {
+		imports.add(module, "primaryKey")
+		cols := make([]string, 0, len(pk))
+		for _, c := range table.Columns {
+			if c.IsPrimary {
+				cols = append(cols, jsProp("t", c.Name))
+			}
+		}
+		extra = append(extra, fmt.Sprintf("primaryKey({ columns: [%s] })", strings.Join(cols, ", ")))
+	}
+
+	// Single-column UNIQUE constraints become .unique() on the column
+	uniqueCols := map[string]bool{}
+	for _, idx := range table.Indexes {
+		if len(idx.Columns) == 0 {
+			continue
+		}
+		if idx.Constraint && len(idx.Columns) == 1 {
+			uniqueCols[strings.ToLower(idx.Columns[0])] = true
+			continue
+		}
+		cols := make([]string, len(idx.Columns))
+		for i, c := range idx.Columns {
+			cols[i] = jsProp("t", c)
+		}
+		switch {
+		case idx.Constraint:
+			imports.add(module, "unique")
+			name := ""
+			// SQLite names constraint indexes itself; those names can't be created explicitly
+			if !strings.HasPrefix(idx.Name, "sqlite_autoindex_") {
+				name = tsString(idx.Name)
+			}
+			extra = append(extra, fmt.Sprintf("unique(%s).on(%s)", name, strings.Join(cols, ", ")))
+		case idx.Unique:
+			imports.add(module, "uniqueIndex")
+			extra = append(extra, fmt.Sprintf("uniqueIndex(%s).on(%s)", tsString(idx.Name), strings.Join(cols, ", ")))
+		default:
+			imports.add(module, "index")
+			extra = append(extra, fmt.Sprintf("index(%s).on(%s)", tsString(idx.Name), strings.Join(cols, ", ")))
+		}
+	}
+
+	sb.WriteString(fmt.Sprintf("export const %s = %s(%s, {\n", v, tableFn, tsString(table.Name)))
+	for _, col := range table.Columns {
+		builder, comment := drizzleColumn(imports, table, col, enums)
+		if col.IsPrimary && len(pk) == 1 {
+			builder += ".primaryKey()"
+		} else if !col.IsNullable {
+			builder += ".notNull()"
+		}
+		if uniqueCols[strings.ToLower(col.Name)] {
+			builder += ".unique()"
+		}
+		if col.Default != "" && !isSequenceDefault(col.Default) {
+			imports.add("drizzle-orm", "sql")
+			builder += ".default(sql`" + strings.NewReplacer("`", "\\`", "${", "\\${").Replace(col.Default) + "`)"
+		}
+		if fk, ok := refs[strings.ToLower(col.Name)]; ok {
+			target, _ := findTable(all, table, fk)
+			ret := ""
+			if target.Name == table.Name && target.Schema == table.Schema {
+				// A self-reference needs an explicit return type to type-check
+				colType := "AnySQLiteColumn"
+				if table.Dialect == DialectPostgres {
+					colType = "AnyPgColumn"
+				}
+				imports.add(module, colType)
+				ret = ": " + colType
+			}
+			builder += fmt.Sprintf(".references(()%s => %s%s)", ret, jsProp(drizzleTableVar(target), fk.RefColumns[0]), drizzleActions(fk, false))
+		}
+		line := fmt.Sprintf("  %s: %s,", jsKey(col.Name), builder)
+		if comment != "" {
+			line += " // " + comment
+		}
+		sb.WriteString(line + "\n")
+	}
+	if len(extra) > 0 {
+		sb.WriteString("}, (t) => [\n")
+		for _, e := range extra {
+			sb.WriteString("  " + e + ",\n")
+		}
+		sb.WriteString("]);\n")
+	} else {
+		sb.WriteString("});\n")
+	}
+	name := tableTypeName(table)
+	sb.WriteString(fmt.Sprintf("export type %s = typeof %s.$inferSelect;\n", name, v))
+	sb.WriteString(fmt.Sprintf("export type New%s = typeof %s.$inferInsert;\n\n", name, v))
+}
+
+// drizzleActions renders ON DELETE / ON UPDATE as Drizzle options: either the second argument of
+// .references() or chained calls on foreignKey().
+func drizzleActions(fk ForeignKey, chained bool) string {
+	var opts []string
+	var calls string
+	for _, a := range []struct{ key, action string }{{"onDelete", fk.OnDelete}, {"onUpdate", fk.OnUpdate}} {
+		action := strings.ToLower(a.action)
+		if action == "" || action == "no action" {
+			continue
+		}
+		opts = append(opts, fmt.Sprintf("%s: %s", a.key, tsString(action)))
+		calls += fmt.Sprintf(".%s(%s)", a.key, tsString(action))
+	}
+	if chained {
+		return calls
+	}
+	if len(opts) == 0 {
+		return ""
+	}
+	return ", { " + strings.Join(opts, ", ") + " }"
+}
+
+func isSequenceDefault(dflt string) bool {
+	return strings.HasPrefix(strings.ToLower(dflt), "nextval(")
+}
+
+// findTable returns the table a foreign key of from points at, in the same binding.
+func findTable(all []TableSchema, from TableSchema, fk ForeignKey) (TableSchema, bool) {
+	schema := fk.RefSchema
+	if schema == "" {
+		schema = from.Schema
+	}
+	for _, t := range all {
+		if t.SourceBinding == from.SourceBinding && t.Schema == schema && strings.EqualFold(t.Name, fk.RefTable) {
+			return t, true
+		}
+	}
+	return TableSchema{}, false
+}
+
+// pgBuilders maps Postgres base types onto pg-core column builders.
+var pgBuilders = map[string]string{
+	"integer":                     "integer",
+	"bigint":                      "bigint",
+	"smallint":                    "smallint",
+	"text":                        "text",
+	"character varying":           "varchar",
+	"character":                   "char",
+	"boolean":                     "boolean",
+	"uuid":                        "uuid",
+	"json":                        "json",
+	"jsonb":                       "jsonb",
+	"date":                        "date",
+	"timestamp without time zone": "timestamp",
+	"timestamp with time zone":    "timestamp",
+	"time without time zone":      "time",
+	"interval":                    "interval",
+	"numeric":                     "numeric",
+	"real":                        "real",
+	"double precision":            "doublePrecision",
+}
+
+// drizzleColumn returns the column builder for col, and a comment when the type has no exact
+// Drizzle equivalent.
+func drizzleColumn(imports drizzleImports, table TableSchema, col ColumnSchema, enums map[string]string) (string, string) {
+	name := tsString(col.Name)
+	if table.Dialect != DialectPostgres {
+		module := drizzleModule(DialectSQLite)
+		t := strings.ToUpper(col.SQLType)
+		switch {
+		case len(col.EnumValues) > 0:
+			imports.add(module, "text")
+			return fmt.Sprintf("text(%s, { enum: [%s] })", name, tsStringList(col.EnumValues)), ""
+		case strings.Contains(t, "BOOL"):
+			imports.add(module, "integer")
+			return fmt.Sprintf("integer(%s, { mode: 'boolean' })", name), ""
+		case strings.Contains(t, "INT"):
+			imports.add(module, "integer")
+			return fmt.Sprintf("integer(%s)", name), ""
+		case strings.Contains(t, "CHAR"), strings.Contains(t, "CLOB"), strings.Contains(t, "TEXT"), t == "UUID":
+			imports.add(module, "text")
+			return fmt.Sprintf("text(%s)", name), ""
+		case t == "", strings.Contains(t, "BLOB"):
+			imports.add(module, "blob")
+			return fmt.Sprintf("blob(%s)", name), ""
+		case strings.Contains(t, "REAL"), strings.Contains(t, "FLOA"), strings.Contains(t, "DOUB"):
+			imports.add(module, "real")
+			return fmt.Sprintf("real(%s)", name), ""
+		default:
+			imports.add(module, "numeric")
+			return fmt.Sprintf("numeric(%s)", name), ""
+		}
+	}
+
+	module := drizzleModule(DialectPostgres)
+	sqlType := strings.ToLower(col.SQLType)
+	array := ""
+	if strings.HasSuffix(sqlType, "[]") {
+		sqlType = strings.TrimSuffix(sqlType, "[]")
+		array = ".array()"
+	}
+	if v, ok := enums[table.SourceBinding+"."+strings.TrimSuffix(col.SQLType, "[]")]; ok {
+		return fmt.Sprintf("%s(%s)%s", v, name, array), ""
+	}
+	length := ""
+	if m := typeLenRe.FindStringSubmatch(sqlType); m != nil {
+		length = m[1]
+	}
+	serial := isSequenceDefault(col.Default)
+
+	var opts []string
+	comment := ""
+	fn, ok := pgBuilders[baseType(sqlType)]
+	switch {
+	case !ok:
+		fn, comment = "text", col.SQLType+" has no Drizzle builder; mapped as text"
+	case fn == "integer" && serial:
+		fn = "serial"
+	case fn == "bigint":
+		opts = append(opts, "mode: 'number'")
+		if serial {
+			fn = "bigserial"
+		}
+	case fn == "smallint" && serial:
+		fn = "smallserial"
+	case fn == "varchar" || fn == "char":
+		if length != "" {
+			opts = append(opts, "length: "+length)
+		}
+	case baseType(sqlType) == "timestamp with time zone":
+		opts = append(opts, "withTimezone: true")
+	case fn == "numeric":
+		if m := typeNumRe.FindStringSubmatch(sqlType); m != nil {
+			p, _ := strconv.Atoi(m[1])
+			s, _ := strconv.Atoi(m[2])
+			opts = append(opts, fmt.Sprintf("precision: %d, scale: %d", p, s))
+		}
+	}
+	// CHECK (col IN (...)) on a text column narrows it like an enum
+	if len(col.EnumValues) > 0 && (fn == "text" || fn == "varchar" || fn == "char") && comment == "" {
+		opts = append(opts, "enum: ["+tsStringList(col.EnumValues)+"]")
+	}
+	imports.add(module, fn)
+	if len(opts) > 0 {
+		return fmt.Sprintf("%s(%s, { %s })%s", fn, name, strings.Join(opts, ", "), array), comment
+	}
+	return fmt.Sprintf("%s(%s)%s", fn, name, array), comment
+}
+
+// ─── Collections ────────────────────────────────────────────────
+
+func collectionTypeName(slug string) string {
+	return toPascalCase(slug) + "Item"
+}
+
+// writeCollectionInterfaces writes an interface per collection, typing data from the
+// collection's schema when it has one.
+func writeCollectionInterfaces(sb *strings.Builder, meta *api.ProjectMetadata) {
+	if meta == nil {
+		return
+	}
+	for _, col := range meta.Collections {
+		data := "any"
+		if fields, ok := parseCollectionSchema(col.Schema); ok {
+			data = tsObject(fields, "  ")
+		}
+		sb.WriteString(fmt.Sprintf("export interface %s {\n", collectionTypeName(col.Slug)))
+		sb.WriteString("  id: string;\n")
+		sb.WriteString("  slug: string;\n")
+		sb.WriteString(fmt.Sprintf("  data: %s;\n", data))
+		sb.WriteString("  created_at: number;\n")
+		sb.WriteString("}\n\n")
+	}
+}
+
+// fieldType is a collection field's type, parsed from its JSON schema.
+type fieldType struct {
+	Kind   string // string, number, integer, boolean, object, array or unknown
+	Format string // email, url, uuid or date-time for strings
+	Enum   []string
+	Fields []schemaField
+	Items  *fieldType
+}
+
+type schemaField struct {
+	Name     string
+	Required bool
+	Type     fieldType
+}
+
+// parseCollectionSchema reads a collection's Schema JSON. It accepts JSON Schema
+// ({"type": "object", "properties": ...}) and field lists, either bare or under "fields":
+// [{"name": "title", "type": "text", "required": true}]. ok is false when there is no usable schema.
+func parseCollectionSchema(raw *string) ([]schemaField, bool) {
+	if raw == nil || strings.TrimSpace(*raw) == "" {
+		return nil, false
+	}
+	var v any
+	if err := json.Unmarshal([]byte(*raw), &v); err != nil {
+		return nil, false
+	}
+	switch s := v.(type) {
+	case []any:
+		return fieldList(s), true
+	case map[string]any:
+		if _, ok := s["properties"]; ok {
+			return jsonSchemaType(s).Fields, true
+		}
+		if fields, ok := s["fields"].([]any); ok {
+			return fieldList(fields), true
+		}
+	}
+	return nil, false
+}
+
+func jsonSchemaType(m map[string]any) fieldType {
+	kind, _ := m["type"].(string)
+	if kinds, ok := m["type"].([]any); ok {
+		// ["string", "null"]: the first non-null type wins
+		for _, k := range kinds {
+			if ks, _ := k.(string); ks != "null" {
+				kind = ks
+				break
+			}
+		}
+	}
+	ft := fieldType{Kind: kind, Enum: stringValues(m["enum"])}
+	switch kind {
+	case "string":
+		ft.Format, _ = m["format"].(string)
+	case "integer", "number", "boolean":
+	case "object":
+		props, _ := m["properties"].(map[string]any)
+		required := map[string]bool{}
+		for _, r := range stringValues(m["required"]) {
+			required[r] = true
+		}
+		names := make([]string, 0, len(props))
+		for name := range props {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			prop, _ := props[name].(map[string]any)
+			ft.Fields = append(ft.Fields, schemaField{Name: name, Required: required[name], Type: jsonSchemaType(prop)})
+		}
+	case "array":
+		items := fieldType{Kind: "unknown"}
+		if m, ok := m["items"].(map[string]any); ok {
+			items = jsonSchemaType(m)
+		}
+		ft.Items = &items
+	default:
+		if len(ft.Enum) > 0 {
+			ft.Kind = "string"
+		} else {
+			ft.Kind = "unknown"
+		}
+	}
+	return ft
+}
+
+func fieldList(list []any) []schemaField {
+	var fields []schemaField
+	for _, item := range list {
+		m, ok := item.(map[string]any)
+		if !ok {
+			continue
+		}
+		name := firstString(m, "name", "key", "slug", "id")
+		if name == "" {
+			continue
+		}
+		required, _ := m["required"].(bool)
+		fields = append(fields, schemaField{Name: name, Required: required, Type: listFieldType(m)})
+	}
+	return fields
+}
+
+// listFieldType maps the field types of CMS-style field lists onto fieldType.
+func listFieldType(m map[string]any) fieldType {
+	options := stringValues(m["options"])
+	if len(options) == 0 {
+		options = stringValues(m["enum"])
+	}
+	switch strings.ToLower(firstString(m, "type")) {
+	case "number", "float", "decimal":
+		return fieldType{Kind: "number"}
+	case "integer", "int":
+		return fieldType{Kind: "integer"}
+	case "boolean", "bool", "checkbox", "toggle", "switch":
+		return fieldType{Kind: "boolean"}
+	case "email":
+		return fieldType{Kind: "string", Format: "email"}
+	case "url", "link":
+		return fieldType{Kind: "string", Format: "url"}
+	case "uuid":
+		return fieldType{Kind: "string", Format: "uuid"}
+	case "datetime", "date-time", "timestamp":
+		return fieldType{Kind: "string", Format: "date-time"}
+	case "select", "radio", "enum":
+		return fieldType{Kind: "string", Enum: options}
+	case "multiselect", "multi-select", "tags":
+		return fieldType{Kind: "array", Items: &fieldType{Kind: "string", Enum: options}}
+	case "json":
+		return fieldType{Kind: "unknown"}
+	case "object", "group":
+		sub, _ := m["fields"].([]any)
+		return fieldType{Kind: "object", Fields: fieldList(sub)}
+	case "array", "list", "repeater":
+		if sub, ok := m["fields"].([]any); ok {
+			return fieldType{Kind: "array", Items: &fieldType{Kind: "object", Fields: fieldList(sub)}}
+		}
+		items := fieldType{Kind: "unknown"}
+		if im, ok := m["items"].(map[string]any); ok {
+			items = listFieldType(im)
+		}
+		return fieldType{Kind: "array", Items: &items}
+	case "":
+		return fieldType{Kind: "unknown"}
+	default:
+		// text, textarea, richtext, markdown, slug, date, image, file, relation...
+		return fieldType{Kind: "string", Enum: options}
+	}
+}
+
+func firstString(m map[string]any, keys ...string) string {
+	for _, k := range keys {
+		if s, ok := m[k].(string); ok && s != "" {
+			return s
+		}
+	}
+	return ""
+}
+
+// stringValues reads ["a", "b"] or [{"value": "a"}, ...] as strings.
+func stringValues(v any) []string {
+	list, _ := v.([]any)
+	var out []string
+	for _, item := range list {
+		switch x := item.(type) {
+		case string:
+			out = append(out, x)
+		case map[string]any:
+			if s := firstString(x, "value", "name", "label"); s != "" {
+				out = append(out, s)
+			}
+		}
+	}
+	return out
+}
+
+func tsObject(fields []schemaField, indent string) string {
+	if len(fields) == 0 {
+		return "Record<string, unknown>"
+	}
+	var sb strings.Builder
+	sb.WriteString("{\n")
+	for _, f := range fields {
+		opt := ""
+		if !f.Required {
+			opt = "?"
+		}
+		sb.WriteString(fmt.Sprintf("%s  %s%s: %s;\n", indent, jsKey(f.Name), opt, tsFieldType(f.Type, indent+"  ")))
+	}
+	sb.WriteString(indent + "}")
+	return sb.String()
+}
+
+func tsFieldType(ft fieldType, indent string) string {
+	if len(ft.Enum) > 0 {
+		return tsUnion(ft.Enum)
+	}
+	switch ft.Kind {
+	case "string":
+		return "string"
+	case "number", "integer":
+		return "number"
+	case "boolean":
+		return "boolean"
+	case "object":
+		return tsObject(ft.Fields, indent)
+	case "array":
+		item := tsFieldType(*ft.Items, indent)
+		if len(ft.Items.Enum) > 0 || ft.Items.Kind == "object" {
+			return "Array<" + item + ">"
+		}
+		return item + "[]"
+	default:
+		return "unknown"
+	}
+}
+
+func zodObject(fields []schemaField, indent string) string {
+	if len(fields) == 0 {
+		return "z.record(z.string(), z.unknown())"
+	}
+	var sb strings.Builder
+	sb.WriteString("z.object({\n")
+	for _, f := range fields {
+		z := zodFieldType(f.Type, indent+"  ")
+		if !f.Required {
+			z += ".optional()"
+		}
+		sb.WriteString(fmt.Sprintf("%s  %s: %s,\n", indent, jsKey(f.Name), z))
+	}
+	sb.WriteString(indent + "})")
+	return sb.String()
+}
+
+func zodFieldType(ft fieldType, indent string) string {
+	if len(ft.Enum) > 0 {
+		return zodEnum(ft.Enum)
+	}
+	switch ft.Kind {
+	case "string":
+		switch ft.Format {
+		case "email":
+			return "z.string().email()"
+		case "url", "uri":
+			return "z.string().url()"
+		case "uuid":
+			return "z.string().uuid()"
+		case "date-time":
+			return "z.string().datetime({ offset: true })"
+		}
+		return "z.string()"
+	case "number":
+		return "z.number()"
+	case "integer":
+		return "z.number().int()"
+	case "boolean":
+		return "z.boolean()"
+	case "object":
+		return zodObject(ft.Fields, indent)
+	case "array":
+		return "z.array(" + zodFieldType(*ft.Items, indent) + ")"
+	default:
+		return "z.unknown()"
+	}
+}
@@ -0,0 +1,274 @@
+package devserver
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/aerostackdev/cli/internal/api"
+)
+
+func testMetaWithSchema(slug, schema string) *api.ProjectMetadata {
+	meta := &api.ProjectMetadata{}
+	meta.Collections = append(meta.Collections, struct {
+		ID                string  `json:"id"`
+		Name              string  `json:"name"`
+		Slug              string  `json:"slug"`
+		SchemaComponentID string  `json:"schema_component_id"`
+		Schema            *string `json:"schema"`
+	}{Slug: slug, Schema: &schema})
+	return meta
+}
+
+func sqliteBlogSchemas() []TableSchema {
+	return []TableSchema{
+		{
+			Name: "users", SourceBinding: "DB", Dialect: DialectSQLite,
+			Columns: []ColumnSchema{
+				{Name: "id", Type: "number", SQLType: "INTEGER", IsPrimary: true, IsNullable: true},
+				{Name: "email", Type: "string", SQLType: "VARCHAR(320)"},
+				{Name: "role", Type: "string", SQLType: "TEXT", Default: "'member'", EnumValues: []string{"admin", "member"}},
+			},
+			Indexes: []IndexSchema{{Name: "users_email_idx", Columns: []string{"email"}, Unique: true}},
+		},
+		{
+			Name: "posts", SourceBinding: "DB", Dialect: DialectSQLite,
+			Columns: []ColumnSchema{
+				{Name: "id", Type: "number", SQLType: "INTEGER", IsPrimary: true, IsNullable: true},
+				{Name: "user_id", Type: "number", SQLType: "INTEGER"},
+				{Name: "published", Type: "boolean", SQLType: "BOOLEAN", Default: "0"},
+				{Name: "created_at", Type: "string", SQLType: "TEXT", Default: "CURRENT_TIMESTAMP"},
+			},
+			ForeignKeys: []ForeignKey{{Columns: []string{"user_id"}, RefTable: "users", RefColumns: []string{"id"}, OnDelete: "CASCADE"}},
+		},
+	}
+}
+
+func TestGenerateTypes_UnknownFormat(t *testing.T) {
+	if _, err := GenerateTypes(nil, nil, "prisma"); err == nil {
+		t.Error("expected an error for an unknown format")
+	}
+}
+
+func TestGenerateTypeScript_EnumColumn(t *testing.T) {
+	result := GenerateTypeScript(sqliteBlogSchemas(), nil)
+	if !strings.Contains(result, "role: 'admin' | 'member';") {
+		t.Errorf("enum column not rendered as a union:\n%s", result)
+	}
+}
+
+func TestGenerateTypes_Zod(t *testing.T) {
+	result, err := GenerateTypes(sqliteBlogSchemas(), nil, TypeFormatZod)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"import { z } from 'zod';",
+		"export const DbUsersSchema = z.object({",
+		"  id: z.number().int(),",
+		"  email: z.string().max(320),",
+		"  role: z.enum(['admin', 'member']),",
+		"export const NewDbUsersSchema = DbUsersSchema.partial({ id: true, role: true });",
+		"export type DbUsers = z.infer<typeof DbUsersSchema>;",
+		"  published: z.boolean(),",
+		`"DB.users": DbUsers;`,
+	} {
+		if !strings.Contains(result, want) {
+			t.Errorf("missing %q in:\n%s", want, result)
+		}
+	}
+}
+
+func TestGenerateTypes_DrizzleSQLite(t *testing.T) {
+	result, err := GenerateTypes(sqliteBlogSchemas(), nil, TypeFormatDrizzle)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"import { sql } from 'drizzle-orm';",
+		"import { integer, sqliteTable, text, uniqueIndex } from 'drizzle-orm/sqlite-core';",
+		"export const dbPosts = sqliteTable('posts', {",
+		"  id: integer('id').primaryKey(),",
+		"  user_id: integer('user_id').notNull().references(() => dbUsers.id, { onDelete: 'cascade' }),",
+		"  published: integer('published', { mode: 'boolean' }).notNull().default(sql`0`),",
+		"  role: text('role', { enum: ['admin', 'member'] }).notNull().default(sql`'member'`),",
+		"}, (t) => [\n  uniqueIndex('users_email_idx').on(t.email),\n]);",
+		"export type NewDbUsers = typeof dbUsers.$inferInsert;",
+	} {
+		if !strings.Contains(result, want) {
+			t.Errorf("missing %q in:\n%s", want, result)
+		}
+	}
+}
+
+func TestGenerateTypes_DrizzlePostgres(t *testing.T) {
+	schemas := []TableSchema{{
+		Name: "orders", SourceBinding: "PG", Dialect: DialectPostgres,
+		Columns: []ColumnSchema{
+			{Name: "id", Type: "number", SQLType: "integer", IsPrimary: true, Default: "nextval('orders_id_seq'::regclass)"},
+			{Name: "status", Type: "any", SQLType: "order_status", EnumValues: []string{"open", "paid"}},
+			{Name: "total", Type: "number", SQLType: "numeric(10,2)", IsNullable: true},
+			{Name: "tags", Type: "any", SQLType: "text[]", IsNullable: true},
+			{Name: "placed_at", Type: "any", SQLType: "timestamp with time zone", Default: "now()"},
+			{Name: "ip", Type: "any", SQLType: "inet", IsNullable: true},
+		},
+	}}
+	result, err := GenerateTypes(schemas, nil, TypeFormatDrizzle)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"export const pgOrderStatusEnum = pgEnum('order_status', ['open', 'paid']);",
+		"  id: serial('id').primaryKey(),",
+		"  status: pgOrderStatusEnum('status').notNull(),",
+		"  total: numeric('total', { precision: 10, scale: 2 }),",
+		"  tags: text('tags').array(),",
+		"  placed_at: timestamp('placed_at', { withTimezone: true }).notNull().default(sql`now()`),",
+		"  ip: text('ip'), // inet has no Drizzle builder; mapped as text",
+	} {
+		if !strings.Contains(result, want) {
+			t.Errorf("missing %q in:\n%s", want, result)
+		}
+	}
+}
+
+func TestGenerateTypes_CollectionJSONSchema(t *testing.T) {
+	meta := testMetaWithSchema("blog-posts", `{
+		"type": "object",
+		"required": ["title"],
+		"properties": {
+			"title": {"type": "string"},
+			"views": {"type": "integer"},
+			"status": {"type": "string", "enum": ["draft", "live"]},
+			"tags": {"type": "array", "items": {"type": "string"}}
+		}
+	}`)
+
+	ts := GenerateTypeScript(nil, meta)
+	for _, want := range []string{"    title: string;", "    views?: number;", "    status?: 'draft' | 'live';", "    tags?: string[];"} {
+		if !strings.Contains(ts, want) {
+			t.Errorf("missing %q in:\n%s", want, ts)
+		}
+	}
+	if strings.Contains(ts, "data: any") {
+		t.Error("collection with a schema still typed as any")
+	}
+
+	zod, _ := GenerateTypes(nil, meta, TypeFormatZod)
+	for _, want := range []string{"    title: z.string(),", "    views: z.number().int().optional(),", "    tags: z.array(z.string()).optional(),"} {
+		if !strings.Contains(zod, want) {
+			t.Errorf("missing %q in:\n%s", want, zod)
+		}
+	}
+}
+
+func TestGenerateTypes_CollectionFieldList(t *testing.T) {
+	meta := testMetaWithSchema("faq", `[
+		{"name": "question", "type": "text", "required": true},
+		{"name": "category", "type": "select", "options": [{"value": "billing"}, {"value": "account"}]},
+		{"name": "contact", "type": "email"},
+		{"name": "links", "type": "repeater", "fields": [{"name": "url", "type": "url", "required": true}]}
+	]`)
+	ts := GenerateTypeScript(nil, meta)
+	for _, want := range []string{"    question: string;", "    category?: 'billing' | 'account';", "    links?: Array<{\n      url: string;\n    }>;"} {
+		if !strings.Contains(ts, want) {
+			t.Errorf("missing %q in:\n%s", want, ts)
+		}
+	}
+}
+
+func TestParseCollectionSchema_Invalid(t *testing.T) {
+	for _, raw := range []string{"", "not json", `"a string"`, `{"title": "no fields"}`} {
+		if _, ok := parseCollectionSchema(&raw); ok {
+			t.Errorf("parseCollectionSchema(%q) should not be usable", raw)
+		}
+	}
+	if _, ok := parseCollectionSchema(nil); ok {
+		t.Error("nil schema should not be usable")
+	}
+}
+
+func TestGenerateTypes_DrizzleSchemasAndConstraints(t *testing.T) {
+	schemas := []TableSchema{
+		{
+			Name: "invoices", Schema: "billing", SourceBinding: "PG", Dialect: DialectPostgres,
+			Columns: []ColumnSchema{
+				{Name: "id", Type: "string", SQLType: "uuid", IsPrimary: true},
+				{Name: "number", Type: "string", SQLType: "text", IsUnique: true},
+				{Name: "state", Type: "string", SQLType: "billing.invoice_state", EnumValues: []string{"open", "void"}},
+				{Name: "kind", Type: "string", SQLType: "text", EnumValues: []string{"a", "b"}},
+				{Name: "customer_id", Type: "number", SQLType: "integer"},
+			},
+			ForeignKeys: []ForeignKey{{Columns: []string{"customer_id"}, RefSchema: "public", RefTable: "customers", RefColumns: []string{"id"}}},
+			Indexes:     []IndexSchema{{Name: "invoices_number_key", Columns: []string{"number"}, Unique: true, Constraint: true}},
+		},
+		{
+			Name: "customers", Schema: "public", SourceBinding: "PG", Dialect: DialectPostgres,
+			Columns: []ColumnSchema{{Name: "id", Type: "number", SQLType: "integer", IsPrimary: true}},
+		},
+	}
+	result, err := GenerateTypes(schemas, nil, TypeFormatDrizzle)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"export const pgBillingSchema = pgSchema('billing');",
+		"export const pgBillingInvoiceStateEnum = pgBillingSchema.enum('invoice_state', ['open', 'void']);",
+		"export const pgBillingInvoices = pgBillingSchema.table('invoices', {",
+		"  number: text('number').notNull().unique(),",
+		"  state: pgBillingInvoiceStateEnum('state').notNull(),",
+		"  kind: text('kind', { enum: ['a', 'b'] }).notNull(),",
+		"  customer_id: integer('customer_id').notNull().references(() => pgCustomers.id),",
+		"export const pgCustomers = pgTable('customers', {",
+	} {
+		if !strings.Contains(result, want) {
+			t.Errorf("missing %q in:\n%s", want, result)
+		}
+	}
+	if strings.Contains(result, "uniqueIndex") {
+		t.Error("a UNIQUE constraint must not be emitted as a separate index")
+	}
+}
+
+func TestGenerateBindingTypes(t *testing.T) {
+	cfg := &AerostackConfig{
+		D1Databases:  []D1Database{{Binding: "DB"}},
+		KVNamespaces: []KVNamespace{{Binding: "CACHE"}},
+		R2Buckets:    []R2Bucket{{Binding: "UPLOADS", BucketName: "uploads"}},
+		Queues:       []Queue{{Binding: "JOBS"}},
+		AI:           true,
+		Vars:         map[string]string{"my-flag": "on"},
+	}
+	result := GenerateBindingTypes(cfg)
+	for _, want := range []string{
+		"export interface Bindings {",
+		"  DB: D1Database;",
+		"  CACHE: KVNamespace;",
+		"  UPLOADS: R2Bucket;",
+		"  JOBS: Queue;",
+		"  AI: Ai;",
+		"  'my-flag': string;",
+	} {
+		if !strings.Contains(result, want) {
+			t.Errorf("missing %q in:\n%s", want, result)
+		}
+	}
+	if GenerateBindingTypes(&AerostackConfig{}) != "" {
+		t.Error("a config without bindings should generate no interface")
+	}
+}
+
+func TestGenerateTypes_SkipsTrackingTables(t *testing.T) {
+	schemas := append(sqliteBlogSchemas(), TableSchema{
+		Name: "_aerostack_migrations", SourceBinding: "DB", Dialect: DialectSQLite,
+		Columns: []ColumnSchema{{Name: "name", Type: "string", SQLType: "TEXT"}},
+	})
+	for _, format := range TypeFormats {
+		result, err := GenerateTypes(schemas, nil, format)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if strings.Contains(result, "AerostackMigrations") || strings.Contains(result, "_aerostack_migrations") {
+			t.Errorf("%s output includes the migrations tracking table:\n%s", format, result)
+		}
+	}
+}
@@ -0,0 +1,185 @@
+package devserver
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Diagnostic severities
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+)
+
+// Diagnostic is a single finding from ValidateAerostackToml, shaped for editors and pre-commit hooks.
+type Diagnostic struct {
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+	Column   int    `json:"column,omitempty"`
+	Severity string `json:"severity"`
+	Code     string `json:"code"`
+	Message  string `json:"message"`
+}
+
+// ValidateAerostackToml parses the config at path and runs the cross-field checks the schema
+// can't express on its own: duplicate bindings, env overrides for unknown bindings, missing
+// entrypoints and Postgres connection strings that reference undefined env vars.
+// The returned error is only set when the file can't be read; config problems are diagnostics.
+func ValidateAerostackToml(path string) ([]Diagnostic, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	file := filepath.Base(path)
+	root := filepath.Dir(path)
+
+	cfg, err := ParseAerostackToml(path)
+	if err != nil {
+		var cerrs ConfigErrors
+		if !errors.As(err, &cerrs) {
+			return nil, err
+		}
+		var diags []Diagnostic
+		for _, ce := range cerrs {
+			msg := ce.Message
+			if ce.Key != "" {
+				msg = ce.Key + ": " + msg
+			}
+			diags = append(diags, Diagnostic{File: file, Line: ce.Line, Column: ce.Column, Severity: SeverityError, Code: ce.Code, Message: msg})
+		}
+		return diags, nil
+	}
+
+	var diags []Diagnostic
+	report := func(line int, severity, code, format string, args ...any) {
+		diags = append(diags, Diagnostic{File: file, Line: line, Severity: severity, Code: code, Message: fmt.Sprintf(format, args...)})
+	}
+
+	// 1. Binding names must be unique across every binding type: they share the worker's env object.
+	type bindingRef struct {
+		kind string
+		line int
+	}
+	base := bindingsByKind(cfg.D1Databases, cfg.KVNamespaces, cfg.R2Buckets, cfg.Queues, cfg.PostgresDatabases)
+	seen := map[string]bindingRef{}
+	addBinding := func(name, kind string, line int) {
+		if prev, ok := seen[name]; ok {
+			report(line, SeverityError, "duplicate-binding", "binding %q (%s) is already used by %s on line %d", name, kind, prev.kind, prev.line)
+			return
+		}
+		seen[name] = bindingRef{kind: kind, line: line}
+	}
+	for _, kind := range bindingKinds {
+		for i, name := range base[kind] {
+			addBinding(name, kind, arrayTableLine(data, kind, i))
+		}
+	}
+	if cfg.AI {
+		addBinding("AI", "ai", topLevelKeyLine(data, "ai"))
+	}
+
+	// 2. [env.*] overrides must refer to bindings declared at the top level.
+	envNames := make([]string, 0, len(cfg.EnvOverrides))
+	for name := range cfg.EnvOverrides {
+		envNames = append(envNames, name)
+	}
+	sort.Strings(envNames)
+	for _, envName := range envNames {
+		ov := cfg.EnvOverrides[envName]
+		refs := bindingsByKind(ov.D1Databases, ov.KVNamespaces, ov.R2Buckets, ov.Queues, ov.PostgresDatabases)
+		for _, kind := range bindingKinds {
+			for i, name := range refs[kind] {
+				if !containsString(base[kind], name) {
+					report(arrayTableLine(data, "env."+envName+"."+kind, i), SeverityError, "unknown-env-binding",
+						"[env.%s] overrides %s binding %q, which is not declared in [[%s]]", envName, kind, name, kind)
+				}
+			}
+		}
+	}
+
+	// 3. Entrypoints must exist on disk.
+	if _, err := os.Stat(filepath.Join(root, cfg.Main)); err != nil {
+		report(topLevelKeyLine(data, "main"), SeverityError, "missing-entrypoint", "main entrypoint %q not found", cfg.Main)
+	}
+	for i, svc := range cfg.Services {
+		if _, err := os.Stat(filepath.Join(root, svc.Main)); err != nil {
+			report(arrayTableLine(data, "services", i), SeverityError, "missing-service-entrypoint",
+				"service %q entrypoint %q not found", svc.Name, svc.Main)
+		}
+	}
+
+	// 4. Postgres connection strings should not reference undefined env vars.
+	for i, pg := range cfg.PostgresDatabases {
+		for _, name := range unresolvedEnvVars(pg.ConnectionString) {
+			report(arrayTableLine(data, "postgres_databases", i), SeverityWarning, "undefined-env-var",
+				"postgres binding %q references $%s, which is not set in the environment", pg.Binding, name)
+		}
+	}
+	for _, envName := range envNames {
+		for i, pg := range cfg.EnvOverrides[envName].PostgresDatabases {
+			for _, name := range unresolvedEnvVars(pg.ConnectionString) {
+				report(arrayTableLine(data, "env."+envName+".postgres_databases", i), SeverityWarning, "undefined-env-var",
+					"[env.%s] postgres binding %q references $%s, which is not set in the environment", envName, pg.Binding, name)
+			}
+		}
+	}
+
+	sort.SliceStable(diags, func(i, j int) bool { return diags[i].Line < diags[j].Line })
+	return diags, nil
+}
+
+// bindingKinds are the array-table headers that declare bindings, in the order they're checked.
+var bindingKinds = []string{"d1_databases", "kv_namespaces", "r2_buckets", "queues.producers", "postgres_databases"}
+
+// bindingsByKind lists binding names per array-table header, in declaration order.
+func bindingsByKind(d1 []D1Database, kv []KVNamespace, r2 []R2Bucket, queues []Queue, pg []PostgresDatabase) map[string][]string {
+	m := map[string][]string{}
+	for _, db := range d1 {
+		m["d1_databases"] = append(m["d1_databases"], db.Binding)
+	}
+	for _, ns := range kv {
+		m["kv_namespaces"] = append(m["kv_namespaces"], ns.Binding)
+	}
+	for _, b := range r2 {
+		m["r2_buckets"] = append(m["r2_buckets"], b.Binding)
+	}
+	for _, q := range queues {
+		m["queues.producers"] = append(m["queues.producers"], q.Binding)
+	}
+	for _, p := range pg {
+		m["postgres_databases"] = append(m["postgres_databases"], p.Binding)
+	}
+	return m
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// topLevelKeyLine returns the 1-based line of a root-level key (before any table header), or 0.
+func topLevelKeyLine(data []byte, key string) int {
+	line := 0
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(text, "[") {
+			return 0
+		}
+		if k, _, ok := strings.Cut(text, "="); ok && strings.TrimSpace(k) == key {
+			return line
+		}
+	}
+	return 0
+}
@@ -0,0 +1,179 @@
+package devserver
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeProject(t *testing.T, toml string, files ...string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for _, f := range files {
+		p := filepath.Join(dir, f)
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte("export default {}"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	path := filepath.Join(dir, "aerostack.toml")
+	if err := os.WriteFile(path, []byte(toml), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func findDiag(diags []Diagnostic, code string) *Diagnostic {
+	for i := range diags {
+		if diags[i].Code == code {
+			return &diags[i]
+		}
+	}
+	return nil
+}
+
+func TestValidateAerostackToml_Valid(t *testing.T) {
+	path := writeProject(t, `name = "app"
+main = "src/index.ts"
+
+[[d1_databases]]
+binding = "DB"
+
+[[kv_namespaces]]
+binding = "CACHE"
+`, "src/index.ts")
+	diags, err := ValidateAerostackToml(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(diags) != 0 {
+		t.Errorf("expected no diagnostics, got %+v", diags)
+	}
+}
+
+func TestValidateAerostackToml_ParseErrorsBecomeDiagnostics(t *testing.T) {
+	path := writeProject(t, `name = "app"
+mian = "src/index.ts"
+`)
+	diags, err := ValidateAerostackToml(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	d := findDiag(diags, "unknown-key")
+	if d == nil {
+		t.Fatalf("expected unknown-key, got %+v", diags)
+	}
+	if d.Line != 2 || d.Severity != SeverityError || d.File != "aerostack.toml" {
+		t.Errorf("diagnostic = %+v", d)
+	}
+}
+
+func TestValidateAerostackToml_DuplicateBinding(t *testing.T) {
+	path := writeProject(t, `main = "src/index.ts"
+
+[[d1_databases]]
+binding = "DB"
+
+[[kv_namespaces]]
+binding = "DB"
+`, "src/index.ts")
+	diags, _ := ValidateAerostackToml(path)
+	d := findDiag(diags, "duplicate-binding")
+	if d == nil {
+		t.Fatalf("expected duplicate-binding, got %+v", diags)
+	}
+	if d.Line != 6 {
+		t.Errorf("line = %d, want 6", d.Line)
+	}
+}
+
+func TestValidateAerostackToml_UnknownEnvBinding(t *testing.T) {
+	path := writeProject(t, `main = "src/index.ts"
+
+[[d1_databases]]
+binding = "DB"
+
+[[env.staging.d1_databases]]
+binding = "OTHER"
+database_id = "abc"
+`, "src/index.ts")
+	diags, _ := ValidateAerostackToml(path)
+	d := findDiag(diags, "unknown-env-binding")
+	if d == nil {
+		t.Fatalf("expected unknown-env-binding, got %+v", diags)
+	}
+	if d.Line != 6 {
+		t.Errorf("line = %d, want 6", d.Line)
+	}
+}
+
+func TestValidateAerostackToml_MissingEntrypoints(t *testing.T) {
+	path := writeProject(t, `main = "src/missing.ts"
+
+[[services]]
+name = "auth"
+main = "services/auth/index.ts"
+`)
+	diags, _ := ValidateAerostackToml(path)
+	if d := findDiag(diags, "missing-entrypoint"); d == nil || d.Line != 1 {
+		t.Errorf("missing-entrypoint = %+v", d)
+	}
+	if d := findDiag(diags, "missing-service-entrypoint"); d == nil || d.Line != 3 {
+		t.Errorf("missing-service-entrypoint = %+v", d)
+	}
+}
+
+func TestValidateAerostackToml_UndefinedPostgresEnvVar(t *testing.T) {
+	os.Unsetenv("VALIDATE_TEST_PG_URL")
+	path := writeProject(t, `main = "src/index.ts"
+
+[[postgres_databases]]
+binding = "PG"
+connection_string = "$VALIDATE_TEST_PG_URL"
+`, "src/index.ts")
+	diags, _ := ValidateAerostackToml(path)
+	d := findDiag(diags, "undefined-env-var")
+	if d == nil {
+		t.Fatalf("expected undefined-env-var, got %+v", diags)
+	}
+	if d.Severity != SeverityWarning {
+		t.Errorf("severity = %q, want warning", d.Severity)
+	}
+}
+
+func TestValidateAerostackToml_UndefinedEnvPostgresEnvVar(t *testing.T) {
+	os.Unsetenv("VALIDATE_TEST_STAGING_PG_URL")
+	path := writeProject(t, `main = "src/index.ts"
+
+[[postgres_databases]]
+binding = "PG"
+connection_string = "postgres://localhost/app"
+
+[[env.staging.postgres_databases]]
+binding = "PG"
+connection_string = "$VALIDATE_TEST_STAGING_PG_URL"
+`, "src/index.ts")
+	diags, _ := ValidateAerostackToml(path)
+	d := findDiag(diags, "undefined-env-var")
+	if d == nil {
+		t.Fatalf("expected undefined-env-var, got %+v", diags)
+	}
+	if d.Line != 7 {
+		t.Errorf("line = %d, want 7", d.Line)
+	}
+}
+
+func TestValidateAerostackToml_DefaultMainResolvedNextToConfig(t *testing.T) {
+	t.Chdir(t.TempDir())
+	path := writeProject(t, `name = "app"
+`, "src/index.js")
+	diags, err := ValidateAerostackToml(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d := findDiag(diags, "missing-entrypoint"); d != nil {
+		t.Errorf("unexpected %+v", d)
+	}
+}
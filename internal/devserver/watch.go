@@ -0,0 +1,170 @@
+package devserver
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// ConfigWatcher watches the files 'aerostack dev' generates its wrangler configs from
+// (aerostack.toml and .dev.vars) and reports changes after a short debounce, so an editor's
+// write/rename burst triggers a single reload.
+type ConfigWatcher struct {
+	watcher  *fsnotify.Watcher
+	files    map[string]bool
+	debounce time.Duration
+}
+
+// NewConfigWatcher watches the directories containing files. Watching the directory rather
+// than the file itself survives editors that save by renaming a temp file over the original.
+func NewConfigWatcher(files ...string) (*ConfigWatcher, error) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+	w := &ConfigWatcher{
+		watcher:  watcher,
+		files:    map[string]bool{},
+		debounce: 300 * time.Millisecond,
+	}
+	dirs := map[string]bool{}
+	for _, f := range files {
+		f = filepath.Clean(f)
+		w.files[f] = true
+		dirs[filepath.Dir(f)] = true
+	}
+	for dir := range dirs {
+		if err := watcher.Add(dir); err != nil {
+			watcher.Close()
+			return nil, err
+		}
+	}
+	return w, nil
+}
+
+// Start calls onChange with the watched files that changed (sorted), once per debounced burst.
+// onChange runs on the watcher goroutine, so reloads never overlap.
+func (w *ConfigWatcher) Start(onChange func(changed []string)) {
+	go func() {
+		pending := map[string]bool{}
+		var fire <-chan time.Time
+		for {
+			select {
+			case event, ok := <-w.watcher.Events:
+				if !ok {
+					return
+				}
+				if event.Op&(fsnotify.Write|fsnotify.Create|fsnotify.Remove|fsnotify.Rename) == 0 {
+					continue
+				}
+				name := filepath.Clean(event.Name)
+				if !w.files[name] {
+					continue
+				}
+				pending[name] = true
+				fire = time.After(w.debounce)
+			case <-fire:
+				changed := make([]string, 0, len(pending))
+				for name := range pending {
+					changed = append(changed, name)
+				}
+				sort.Strings(changed)
+				pending = map[string]bool{}
+				fire = nil
+				onChange(changed)
+			case err, ok := <-w.watcher.Errors:
+				if !ok {
+					return
+				}
+				log.Println("Watcher error:", err)
+			}
+		}
+	}()
+}
+
+func (w *ConfigWatcher) Close() error {
+	return w.watcher.Close()
+}
+
+// DevWorker is one wrangler dev process in the 'aerostack dev' multi-worker set.
+type DevWorker struct {
+	Name       string
+	ConfigPath string
+	Port       int
+	// Fingerprint is a hash of the generated wrangler config; it changes only when the
+	// worker's effective config does.
+	Fingerprint string
+}
+
+// GenerateDevWorkers writes the wrangler config for the main worker and each [[services]]
+// entry into dir, assigning ports upwards from basePort in declaration order.
+func GenerateDevWorkers(cfg *AerostackConfig, dir string, basePort int) ([]DevWorker, error) {
+	mainPath := filepath.Join(dir, "wrangler.toml")
+	if err := GenerateWranglerToml(cfg, mainPath); err != nil {
+		return nil, fmt.Errorf("failed to generate wrangler config: %w", err)
+	}
+	workers := []DevWorker{{Name: "main", ConfigPath: mainPath, Port: basePort}}
+	for i, svc := range cfg.Services {
+		svcPath := filepath.Join(dir, "wrangler-"+svc.Name+".toml")
+		if err := GenerateWranglerTomlForService(cfg, svc, svcPath); err != nil {
+			return nil, fmt.Errorf("failed to generate wrangler config for %s: %w", svc.Name, err)
+		}
+		workers = append(workers, DevWorker{Name: svc.Name, ConfigPath: svcPath, Port: basePort + i + 1})
+	}
+	for i := range workers {
+		data, err := os.ReadFile(workers[i].ConfigPath)
+		if err != nil {
+			return nil, err
+		}
+		sum := sha256.Sum256(data)
+		workers[i].Fingerprint = hex.EncodeToString(sum[:])
+	}
+	return workers, nil
+}
+
+// WorkerChanges is what a config reload has to do to the running dev workers.
+// Restart and Start hold the new DevWorker; Stop holds the one that is running.
+type WorkerChanges struct {
+	Start   []DevWorker
+	Restart []DevWorker
+	Stop    []DevWorker
+}
+
+func (c WorkerChanges) Empty() bool {
+	return len(c.Start) == 0 && len(c.Restart) == 0 && len(c.Stop) == 0
+}
+
+// DiffDevWorkers compares the running workers with a freshly generated set. A worker is
+// restarted when its config or port changed, or for every worker when restartAll is set
+// (e.g. .dev.vars changed, which all workers share).
+func DiffDevWorkers(running, next []DevWorker, restartAll bool) WorkerChanges {
+	var c WorkerChanges
+	prev := make(map[string]DevWorker, len(running))
+	for _, w := range running {
+		prev[w.Name] = w
+	}
+	seen := make(map[string]bool, len(next))
+	for _, w := range next {
+		seen[w.Name] = true
+		old, ok := prev[w.Name]
+		switch {
+		case !ok:
+			c.Start = append(c.Start, w)
+		case restartAll || old.Fingerprint != w.Fingerprint || old.Port != w.Port:
+			c.Restart = append(c.Restart, w)
+		}
+	}
+	for _, w := range running {
+		if !seen[w.Name] {
+			c.Stop = append(c.Stop, w)
+		}
+	}
+	return c
+}
@@ -0,0 +1,111 @@
+package devserver
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestConfigWatcher_DebouncesAndFilters(t *testing.T) {
+	dir := t.TempDir()
+	tomlPath := filepath.Join(dir, "aerostack.toml")
+	varsPath := filepath.Join(dir, ".dev.vars")
+	writeFiles(t, dir, map[string]string{"aerostack.toml": "name = \"app\"\n"})
+
+	w, err := NewConfigWatcher(tomlPath, varsPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+	w.debounce = 100 * time.Millisecond
+
+	got := make(chan []string, 4)
+	w.Start(func(changed []string) { got <- changed })
+
+	// A burst of writes plus an unrelated file should be one callback
+	for i := 0; i < 3; i++ {
+		if err := os.WriteFile(tomlPath, []byte("name = \"app2\"\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeFiles(t, dir, map[string]string{"src/index.ts": "", "README.md": "x"})
+	if err := os.WriteFile(varsPath, []byte("SECRET=1\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case changed := <-got:
+		if len(changed) != 2 || changed[0] != varsPath || changed[1] != tomlPath {
+			t.Errorf("changed = %v", changed)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no change reported")
+	}
+	select {
+	case changed := <-got:
+		t.Errorf("unexpected second callback: %v", changed)
+	case <-time.After(300 * time.Millisecond):
+	}
+}
+
+func TestGenerateDevWorkers_StableFingerprint(t *testing.T) {
+	dir := t.TempDir()
+	cfg := &AerostackConfig{
+		Name:     "app",
+		Main:     "src/index.ts",
+		Vars:     map[string]string{"A": "1", "B": "2", "C": "3", "D": "4"},
+		Services: []Service{{Name: "auth", Main: "src/auth.ts"}},
+	}
+	first, err := GenerateDevWorkers(cfg, dir, 8788)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(first) != 2 || first[1].Name != "auth" || first[1].Port != 8789 {
+		t.Fatalf("workers = %+v", first)
+	}
+	for i := 0; i < 5; i++ {
+		again, err := GenerateDevWorkers(cfg, dir, 8788)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if c := DiffDevWorkers(first, again, false); !c.Empty() {
+			t.Fatalf("regenerating an unchanged config should not restart anything: %+v", c)
+		}
+	}
+}
+
+func TestDiffDevWorkers(t *testing.T) {
+	running := []DevWorker{
+		{Name: "main", Port: 8788, Fingerprint: "m1"},
+		{Name: "auth", Port: 8789, Fingerprint: "a1"},
+		{Name: "billing", Port: 8790, Fingerprint: "b1"},
+	}
+	next := []DevWorker{
+		{Name: "main", Port: 8788, Fingerprint: "m1"},
+		{Name: "auth", Port: 8789, Fingerprint: "a2"},
+		{Name: "mail", Port: 8790, Fingerprint: "x1"},
+	}
+
+	c := DiffDevWorkers(running, next, false)
+	if len(c.Restart) != 1 || c.Restart[0].Name != "auth" {
+		t.Errorf("restart = %+v", c.Restart)
+	}
+	if len(c.Start) != 1 || c.Start[0].Name != "mail" {
+		t.Errorf("start = %+v", c.Start)
+	}
+	if len(c.Stop) != 1 || c.Stop[0].Name != "billing" {
+		t.Errorf("stop = %+v", c.Stop)
+	}
+
+	c = DiffDevWorkers(running[:2], next[:2], true)
+	if len(c.Restart) != 2 {
+		t.Errorf("restartAll should restart every running worker: %+v", c.Restart)
+	}
+
+	moved := []DevWorker{{Name: "main", Port: 8788, Fingerprint: "m1"}, {Name: "billing", Port: 8789, Fingerprint: "b1"}}
+	c = DiffDevWorkers(running, moved, false)
+	if len(c.Restart) != 1 || c.Restart[0].Name != "billing" || len(c.Stop) != 1 || c.Stop[0].Name != "auth" {
+		t.Errorf("port change should restart: %+v", c)
+	}
+}
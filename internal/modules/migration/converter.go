@@ -3,72 +3,216 @@ package migration
 import (
 	"fmt"
 	"os"
+	"regexp"
+	"sort"
+	"strings"
 
 	"github.com/aerostackdev/cli/internal/devserver"
 )
 
-// ConvertWranglerToAerostack maps the configuration
+// ConvertWranglerToAerostack maps the configuration; Unmapped reports what it leaves out.
 func ConvertWranglerToAerostack(w *WranglerConfig) *devserver.AerostackConfig {
 	cfg := &devserver.AerostackConfig{
-		Name:              w.Name,
-		Main:              w.Main,
-		CompatibilityDate: w.CompatibilityDate,
-		BuildCommand:      "npx esbuild src/index.ts --bundle --outfile=dist/worker.js",
-		DevCommand:       "npx wrangler dev",
-		DeployCommand:     "npx wrangler deploy",
+		Name:               w.Name,
+		Main:               w.Main,
+		CompatibilityDate:  w.CompatibilityDate,
+		CompatibilityFlags: w.CompatibilityFlags,
+		BuildCommand:       "npx esbuild src/index.ts --bundle --outfile=dist/worker.js",
+		DevCommand:         "npx wrangler dev",
+		DeployCommand:      "npx wrangler deploy",
+		D1Databases:        convertD1(w.D1Databases),
+		KVNamespaces:       convertKV(w.KVNamespaces),
+		R2Buckets:          convertR2(w.R2Buckets),
+		Queues:             convertQueues(w.Queues),
+		AI:                 w.AI != nil,
+		Vars:               convertVars(w.Vars),
 	}
 
 	if cfg.CompatibilityDate == "" {
 		cfg.CompatibilityDate = "2024-01-01"
 	}
 
-	// Map D1 Databases
-	for _, d := range w.D1Databases {
-		cfg.D1Databases = append(cfg.D1Databases, devserver.D1Database{
+	// [env.<name>] blocks become env overrides
+	for name, env := range w.Env {
+		if cfg.EnvOverrides == nil {
+			cfg.EnvOverrides = map[string]devserver.EnvOverride{}
+		}
+		cfg.EnvOverrides[name] = devserver.EnvOverride{
+			CompatibilityFlags: env.CompatibilityFlags,
+			D1Databases:        convertD1(env.D1Databases),
+			KVNamespaces:       convertKV(env.KVNamespaces),
+			R2Buckets:          convertR2(env.R2Buckets),
+			Queues:             convertQueues(env.Queues),
+			Vars:               convertVars(env.Vars),
+		}
+	}
+
+	return cfg
+}
+
+func convertD1(in []WranglerD1) []devserver.D1Database {
+	var out []devserver.D1Database
+	for _, d := range in {
+		out = append(out, devserver.D1Database{
 			Binding:      d.Binding,
 			DatabaseName: d.DatabaseName,
 			DatabaseID:   d.DatabaseID,
 		})
 	}
+	return out
+}
 
-	// Map KV Namespaces
-	for _, kv := range w.KVNamespaces {
-		cfg.KVNamespaces = append(cfg.KVNamespaces, devserver.KVNamespace{
-			Binding: kv.Binding,
-			ID:      kv.ID,
+func convertKV(in []WranglerKV) []devserver.KVNamespace {
+	var out []devserver.KVNamespace
+	for _, kv := range in {
+		out = append(out, devserver.KVNamespace{
+			Binding:   kv.Binding,
+			ID:        kv.ID,
+			PreviewID: kv.PreviewID,
 		})
 	}
+	return out
+}
 
-	return cfg
+func convertR2(in []WranglerR2) []devserver.R2Bucket {
+	var out []devserver.R2Bucket
+	for _, b := range in {
+		out = append(out, devserver.R2Bucket{
+			Binding:           b.Binding,
+			BucketName:        b.BucketName,
+			PreviewBucketName: b.PreviewBucketName,
+		})
+	}
+	return out
+}
+
+func convertQueues(in WranglerQueues) []devserver.Queue {
+	var out []devserver.Queue
+	for _, q := range in.Producers {
+		out = append(out, devserver.Queue{Binding: q.Binding, Name: q.Queue})
+	}
+	return out
+}
+
+// convertVars keeps string, number and boolean vars; Unmapped reports the rest.
+func convertVars(in map[string]any) map[string]string {
+	if len(in) == 0 {
+		return nil
+	}
+	out := map[string]string{}
+	for k, v := range in {
+		switch val := v.(type) {
+		case string:
+			out[k] = val
+		case bool, int64, float64, fmt.Stringer: // json.Number is a Stringer
+			out[k] = fmt.Sprint(val)
+		}
+	}
+	return out
 }
 
 // GenerateAerostackToml writes the file
 func GenerateAerostackToml(cfg *devserver.AerostackConfig, path string) error {
-	var b string
-	b += fmt.Sprintf("# Auto-generated by Aerostack Migration Tool\n")
-	b += fmt.Sprintf("name = %q\n", cfg.Name)
-	b += fmt.Sprintf("main = %q\n", cfg.Main)
-	b += fmt.Sprintf("compatibility_date = %q\n\n", cfg.CompatibilityDate)
-	b += fmt.Sprintf("[commands]\n")
-	b += fmt.Sprintf("build = %q\n", cfg.BuildCommand)
-	b += fmt.Sprintf("dev = %q\n", cfg.DevCommand)
-	b += fmt.Sprintf("deploy = %q\n\n", cfg.DeployCommand)
-
-	for _, db := range cfg.D1Databases {
-		b += fmt.Sprintf("[[d1_databases]]\n")
-		b += fmt.Sprintf("binding = %q\n", db.Binding)
-		b += fmt.Sprintf("database_name = %q\n", db.DatabaseName)
-		b += fmt.Sprintf("database_id = %q\n\n", db.DatabaseID)
-	}
-
-	if len(cfg.KVNamespaces) > 0 {
-		b += fmt.Sprintf("# KV Namespaces (from wrangler.toml)\n")
-		for _, kv := range cfg.KVNamespaces {
-			b += fmt.Sprintf("[[kv_namespaces]]\n")
-			b += fmt.Sprintf("binding = %q\n", kv.Binding)
-			b += fmt.Sprintf("id = %q\n\n", kv.ID)
+	var b strings.Builder
+	b.WriteString("# Auto-generated by Aerostack Migration Tool\n")
+	fmt.Fprintf(&b, "name = %q\n", cfg.Name)
+	fmt.Fprintf(&b, "main = %q\n", cfg.Main)
+	fmt.Fprintf(&b, "compatibility_date = %q\n", cfg.CompatibilityDate)
+	if len(cfg.CompatibilityFlags) > 0 {
+		fmt.Fprintf(&b, "compatibility_flags = %s\n", tomlStrings(cfg.CompatibilityFlags))
+	}
+	if cfg.AI {
+		b.WriteString("ai = true\n")
+	}
+	b.WriteString("\n[commands]\n")
+	fmt.Fprintf(&b, "build = %q\n", cfg.BuildCommand)
+	fmt.Fprintf(&b, "dev = %q\n", cfg.DevCommand)
+	fmt.Fprintf(&b, "deploy = %q\n\n", cfg.DeployCommand)
+
+	writeBindings(&b, "", cfg.D1Databases, cfg.KVNamespaces, cfg.R2Buckets, cfg.Queues, cfg.Vars)
+
+	envs := make([]string, 0, len(cfg.EnvOverrides))
+	for name := range cfg.EnvOverrides {
+		envs = append(envs, name)
+	}
+	sort.Strings(envs)
+	for _, name := range envs {
+		env := cfg.EnvOverrides[name]
+		prefix := "env." + tomlKey(name) + "."
+		fmt.Fprintf(&b, "[env.%s]\n", tomlKey(name))
+		if len(env.CompatibilityFlags) > 0 {
+			fmt.Fprintf(&b, "compatibility_flags = %s\n", tomlStrings(env.CompatibilityFlags))
+		}
+		b.WriteString("\n")
+		writeBindings(&b, prefix, env.D1Databases, env.KVNamespaces, env.R2Buckets, env.Queues, env.Vars)
+	}
+
+	return os.WriteFile(path, []byte(b.String()), 0644)
+}
+
+// writeBindings writes binding blocks and vars, under prefix for an [env.<name>] table.
+func writeBindings(b *strings.Builder, prefix string, d1 []devserver.D1Database, kv []devserver.KVNamespace, r2 []devserver.R2Bucket, queues []devserver.Queue, vars map[string]string) {
+	for _, db := range d1 {
+		fmt.Fprintf(b, "[[%sd1_databases]]\n", prefix)
+		fmt.Fprintf(b, "binding = %q\n", db.Binding)
+		fmt.Fprintf(b, "database_name = %q\n", db.DatabaseName)
+		fmt.Fprintf(b, "database_id = %q\n\n", db.DatabaseID)
+	}
+
+	for _, ns := range kv {
+		fmt.Fprintf(b, "[[%skv_namespaces]]\n", prefix)
+		fmt.Fprintf(b, "binding = %q\n", ns.Binding)
+		fmt.Fprintf(b, "id = %q\n", ns.ID)
+		if ns.PreviewID != "" {
+			fmt.Fprintf(b, "preview_id = %q\n", ns.PreviewID)
+		}
+		b.WriteString("\n")
+	}
+
+	for _, bucket := range r2 {
+		fmt.Fprintf(b, "[[%sr2_buckets]]\n", prefix)
+		fmt.Fprintf(b, "binding = %q\n", bucket.Binding)
+		fmt.Fprintf(b, "bucket_name = %q\n", bucket.BucketName)
+		if bucket.PreviewBucketName != "" {
+			fmt.Fprintf(b, "preview_bucket_name = %q\n", bucket.PreviewBucketName)
 		}
+		b.WriteString("\n")
 	}
 
-	return os.WriteFile(path, []byte(b), 0644)
+	for _, q := range queues {
+		fmt.Fprintf(b, "[[%squeues.producers]]\n", prefix)
+		fmt.Fprintf(b, "binding = %q\n", q.Binding)
+		fmt.Fprintf(b, "queue = %q\n\n", q.Name)
+	}
+
+	if len(vars) > 0 {
+		fmt.Fprintf(b, "[%svars]\n", prefix)
+		keys := make([]string, 0, len(vars))
+		for k := range vars {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			fmt.Fprintf(b, "%s = %q\n", tomlKey(k), vars[k])
+		}
+		b.WriteString("\n")
+	}
+}
+
+var bareKeyRe = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// tomlKey quotes keys that aren't valid bare TOML keys.
+func tomlKey(k string) string {
+	if bareKeyRe.MatchString(k) {
+		return k
+	}
+	return fmt.Sprintf("%q", k)
+}
+
+func tomlStrings(list []string) string {
+	quoted := make([]string, len(list))
+	for i, s := range list {
+		quoted[i] = fmt.Sprintf("%q", s)
+	}
+	return "[" + strings.Join(quoted, ", ") + "]"
 }
@@ -87,3 +87,47 @@ func TestGenerateAerostackToml(t *testing.T) {
 		t.Errorf("missing binding: %s", content)
 	}
 }
+
+func TestGenerateAerostackToml_ParsesBack(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "aerostack.toml")
+
+	cfg := ConvertWranglerToAerostack(&WranglerConfig{
+		Name:               "shop",
+		Main:               "src/index.ts",
+		CompatibilityFlags: []string{"nodejs_compat"},
+		KVNamespaces:       []WranglerKV{{Binding: "CACHE", ID: "kv-1", PreviewID: "kv-2"}},
+		R2Buckets:          []WranglerR2{{Binding: "UPLOADS", BucketName: "uploads", PreviewBucketName: "uploads-dev"}},
+		Queues:             WranglerQueues{Producers: []WranglerQueue{{Binding: "JOBS", Queue: "jobs"}}},
+		AI:                 &WranglerAI{Binding: "AI"},
+		Vars:               map[string]any{"API_URL": "https://example.com", "my.var": "x"},
+		Env: map[string]WranglerEnv{
+			"staging": {
+				D1Databases: []WranglerD1{{Binding: "DB", DatabaseName: "db-staging", DatabaseID: "id-2"}},
+				Vars:        map[string]any{"API_URL": "https://staging.example.com"},
+			},
+		},
+	})
+	if err := GenerateAerostackToml(cfg, path); err != nil {
+		t.Fatalf("GenerateAerostackToml: %v", err)
+	}
+
+	parsed, err := devserver.ParseAerostackToml(path)
+	if err != nil {
+		data, _ := os.ReadFile(path)
+		t.Fatalf("ParseAerostackToml: %v\n%s", err, data)
+	}
+	if !parsed.AI || len(parsed.CompatibilityFlags) != 1 || len(parsed.Queues) != 1 {
+		t.Errorf("parsed = %+v", parsed)
+	}
+	if parsed.KVNamespaces[0].PreviewID != "kv-2" || parsed.Vars["my.var"] != "x" {
+		t.Errorf("kv = %+v, vars = %v", parsed.KVNamespaces, parsed.Vars)
+	}
+	if len(parsed.R2Buckets) != 1 || parsed.R2Buckets[0].PreviewBucketName != "uploads-dev" {
+		t.Errorf("r2_buckets = %+v", parsed.R2Buckets)
+	}
+	staging := parsed.EnvOverrides["staging"]
+	if len(staging.D1Databases) != 1 || staging.Vars["API_URL"] != "https://staging.example.com" {
+		t.Errorf("staging = %+v", staging)
+	}
+}
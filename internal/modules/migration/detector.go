@@ -1,50 +1,322 @@
 package migration
 
 import (
+	"bytes"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io/fs"
 	"os"
+	"path/filepath"
+	"slices"
+	"sort"
 
 	"github.com/pelletier/go-toml/v2"
 )
 
-// WranglerConfig represents a subset of wrangler.toml
+// WranglerConfigFiles are the config files wrangler reads, in its order of precedence.
+var WranglerConfigFiles = []string{"wrangler.json", "wrangler.jsonc", "wrangler.toml"}
+
+// WranglerConfig represents the parts of a wrangler config that map onto aerostack.toml.
+// wrangler.toml is decoded through the same JSON schema as wrangler.json(c).
 type WranglerConfig struct {
-	Name               string            `toml:"name"`
-	Main               string            `toml:"main"`
-	CompatibilityDate  string            `toml:"compatibility_date"`
-	CompatibilityFlags []string          `toml:"compatibility_flags"`
-	D1Databases        []WranglerD1      `toml:"d1_databases"`
-	KVNamespaces       []WranglerKV      `toml:"kv_namespaces"`
-	Vars               map[string]string `toml:"vars"`
+	Name               string                 `json:"name"`
+	Main               string                 `json:"main"`
+	CompatibilityDate  string                 `json:"compatibility_date"`
+	CompatibilityFlags []string               `json:"compatibility_flags"`
+	D1Databases        []WranglerD1           `json:"d1_databases"`
+	KVNamespaces       []WranglerKV           `json:"kv_namespaces"`
+	R2Buckets          []WranglerR2           `json:"r2_buckets"`
+	Queues             WranglerQueues         `json:"queues"`
+	AI                 *WranglerAI            `json:"ai"`
+	Vars               map[string]any         `json:"vars"`
+	Env                map[string]WranglerEnv `json:"env"`
+
+	// File is the config file the project was read from
+	File string `json:"-"`
+	raw  map[string]any
+}
+
+// WranglerEnv holds the [env.<name>] settings that map onto aerostack.toml env overrides.
+type WranglerEnv struct {
+	CompatibilityFlags []string       `json:"compatibility_flags"`
+	D1Databases        []WranglerD1   `json:"d1_databases"`
+	KVNamespaces       []WranglerKV   `json:"kv_namespaces"`
+	R2Buckets          []WranglerR2   `json:"r2_buckets"`
+	Queues             WranglerQueues `json:"queues"`
+	Vars               map[string]any `json:"vars"`
 }
 
 type WranglerD1 struct {
-	Binding      string `toml:"binding"`
-	DatabaseName string `toml:"database_name"`
-	DatabaseID   string `toml:"database_id"`
+	Binding      string `json:"binding"`
+	DatabaseName string `json:"database_name"`
+	DatabaseID   string `json:"database_id"`
 }
 
 type WranglerKV struct {
-	Binding string `toml:"binding"`
-	ID      string `toml:"id"`
+	Binding   string `json:"binding"`
+	ID        string `json:"id"`
+	PreviewID string `json:"preview_id"`
 }
 
-// Detect looks for wrangler.toml in the current directory
+type WranglerR2 struct {
+	Binding           string `json:"binding"`
+	BucketName        string `json:"bucket_name"`
+	PreviewBucketName string `json:"preview_bucket_name"`
+}
+
+type WranglerQueues struct {
+	Producers []WranglerQueue `json:"producers"`
+}
+
+type WranglerQueue struct {
+	Binding string `json:"binding"`
+	Queue   string `json:"queue"`
+}
+
+type WranglerAI struct {
+	Binding string `json:"binding"`
+}
+
+// Detect looks for wrangler.json, wrangler.jsonc or wrangler.toml in cwd
 func Detect(cwd string) (*WranglerConfig, error) {
-	tomlPath := fmt.Sprintf("%s/wrangler.toml", cwd)
-	if _, err := os.Stat(tomlPath); os.IsNotExist(err) {
-		return nil, fmt.Errorf("no wrangler.toml found in %s", cwd)
+	for _, name := range WranglerConfigFiles {
+		path := filepath.Join(cwd, name)
+		if _, err := os.Stat(path); errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		return ParseWranglerConfig(path)
 	}
+	return nil, fmt.Errorf("no wrangler.json, wrangler.jsonc or wrangler.toml found in %s", cwd)
+}
 
-	content, err := os.ReadFile(tomlPath)
+// ParseWranglerConfig reads a wrangler config; .json and .jsonc files may use comments and
+// trailing commas, anything else is read as TOML.
+func ParseWranglerConfig(path string) (*WranglerConfig, error) {
+	name := filepath.Base(path)
+	content, err := os.ReadFile(path)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read wrangler.toml: %w", err)
+		return nil, fmt.Errorf("failed to read %s: %w", name, err)
 	}
 
-	var config WranglerConfig
-	if err := toml.Unmarshal(content, &config); err != nil {
-		return nil, fmt.Errorf("failed to parse wrangler.toml: %w", err)
+	var raw map[string]any
+	switch filepath.Ext(path) {
+	case ".json", ".jsonc":
+		data := stripJSONC(content)
+		if err := decodeJSON(data, &raw); err != nil {
+			var syntaxErr *json.SyntaxError
+			if errors.As(err, &syntaxErr) {
+				line := bytes.Count(data[:syntaxErr.Offset], []byte("\n")) + 1
+				return nil, fmt.Errorf("failed to parse %s: line %d: %w", name, line, err)
+			}
+			return nil, fmt.Errorf("failed to parse %s: %w", name, err)
+		}
+	default:
+		if err := toml.Unmarshal(content, &raw); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", name, err)
+		}
 	}
 
+	// Round-trip through JSON so both formats decode into the one schema
+	normalized, err := json.Marshal(raw)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", name, err)
+	}
+	var config WranglerConfig
+	if err := decodeJSON(normalized, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", name, err)
+	}
+	config.File = name
+	config.raw = raw
 	return &config, nil
 }
+
+// decodeJSON keeps numbers as json.Number, so an integer var like 12345678 isn't
+// turned into 1.2345678e+07.
+func decodeJSON(data []byte, v any) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	return dec.Decode(v)
+}
+
+// UnmappedKey is a wrangler setting the migration could not carry over to aerostack.toml.
+type UnmappedKey struct {
+	Key    string // e.g. "durable_objects" or "env.staging.triggers"
+	Reason string
+}
+
+// mappedKeys are the settings ConvertWranglerToAerostack carries over, at the top level
+// and inside [env.<name>].
+var (
+	mappedKeys = map[string]bool{
+		"name": true, "main": true, "compatibility_date": true, "compatibility_flags": true,
+		"d1_databases": true, "kv_namespaces": true, "r2_buckets": true, "queues": true, "ai": true, "vars": true, "env": true,
+	}
+	mappedEnvKeys = map[string]bool{
+		"compatibility_flags": true, "d1_databases": true, "kv_namespaces": true, "r2_buckets": true, "queues": true, "vars": true,
+	}
+)
+
+// unmappedReasons explains the settings that are commonly left behind.
+var unmappedReasons = map[string]string{
+	"durable_objects":           "Durable Object bindings have no aerostack.toml equivalent",
+	"migrations":                "Durable Object migrations have no aerostack.toml equivalent",
+	"services":                  "bindings to other Workers have no equivalent; [[services]] only runs workers from this project",
+	"triggers":                  "cron triggers have no aerostack.toml equivalent",
+	"routes":                    "routes are not configurable in aerostack.toml",
+	"route":                     "routes are not configurable in aerostack.toml",
+	"hyperdrive":                "Hyperdrive bindings have no equivalent; use [[postgres_databases]] with the origin connection string",
+	"ai":                        "Workers AI can only be enabled for the whole project (ai = true)",
+	"vectorize":                 "Vectorize bindings have no aerostack.toml equivalent",
+	"analytics_engine_datasets": "Analytics Engine bindings have no aerostack.toml equivalent",
+	"build":                     "custom builds go in [commands] build",
+}
+
+// ignoredKeys carry no meaning for aerostack and are dropped without a report.
+var ignoredKeys = map[string]bool{"$schema": true}
+
+// Unmapped lists every setting in the parsed file that ConvertWranglerToAerostack drops,
+// so a migrated project doesn't silently lose production bindings.
+func (w *WranglerConfig) Unmapped() []UnmappedKey {
+	var out []UnmappedKey
+	add := func(key, reason string) {
+		out = append(out, UnmappedKey{Key: key, Reason: reason})
+	}
+	unmappedSettings("", w.raw, mappedKeys, add)
+	if envs, ok := w.raw["env"].(map[string]any); ok {
+		for _, name := range sortedKeys(envs) {
+			env, _ := envs[name].(map[string]any)
+			unmappedSettings("env."+name+".", env, mappedEnvKeys, add)
+		}
+	}
+	return out
+}
+
+func unmappedSettings(prefix string, settings map[string]any, mapped map[string]bool, add func(key, reason string)) {
+	for _, k := range sortedKeys(settings) {
+		key := prefix + k
+		if !mapped[k] {
+			if ignoredKeys[k] {
+				continue
+			}
+			reason, ok := unmappedReasons[k]
+			if !ok {
+				reason = "no aerostack.toml equivalent"
+			}
+			add(key, reason)
+			continue
+		}
+
+		switch v := settings[k]; k {
+		case "d1_databases":
+			unmappedEntryKeys(key, v, add, "binding", "database_name", "database_id")
+		case "kv_namespaces":
+			unmappedEntryKeys(key, v, add, "binding", "id", "preview_id")
+		case "r2_buckets":
+			unmappedEntryKeys(key, v, add, "binding", "bucket_name", "preview_bucket_name")
+		case "queues":
+			queues, _ := v.(map[string]any)
+			for _, sub := range sortedKeys(queues) {
+				if sub == "producers" {
+					unmappedEntryKeys(key+".producers", queues[sub], add, "binding", "queue")
+					continue
+				}
+				add(key+"."+sub, "only queue producers are migrated")
+			}
+		case "vars":
+			vars, _ := v.(map[string]any)
+			for _, name := range sortedKeys(vars) {
+				switch vars[name].(type) {
+				case string, bool, json.Number, int64, float64:
+				default:
+					add(key+"."+name, "only string, number and boolean vars are supported")
+				}
+			}
+		case "ai":
+			if ai, _ := v.(map[string]any); ai["binding"] != "AI" {
+				add(key+".binding", "Workers AI is always bound as AI")
+			}
+		}
+	}
+}
+
+// unmappedEntryKeys reports the keys of each binding entry outside the known ones.
+func unmappedEntryKeys(key string, v any, add func(key, reason string), known ...string) {
+	entries, _ := v.([]any)
+	for i, e := range entries {
+		entry, _ := e.(map[string]any)
+		for _, k := range sortedKeys(entry) {
+			if !slices.Contains(known, k) {
+				add(fmt.Sprintf("%s[%d].%s", key, i, k), "no aerostack.toml equivalent")
+			}
+		}
+	}
+}
+
+func sortedKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// stripJSONC turns JSONC into JSON by blanking comments and trailing commas. Bytes are
+// replaced with spaces rather than removed, so syntax error offsets still match the file.
+func stripJSONC(data []byte) []byte {
+	out := bytes.Clone(data)
+	blank := func(from, to int) {
+		for i := from; i < to && i < len(out); i++ {
+			if out[i] != '\n' {
+				out[i] = ' '
+			}
+		}
+	}
+	for i := 0; i < len(out); i++ {
+		switch {
+		case out[i] == '"':
+			i = jsonStringEnd(out, i)
+		case out[i] == '/' && i+1 < len(out) && out[i+1] == '/':
+			end := bytes.IndexByte(out[i:], '\n')
+			if end < 0 {
+				end = len(out) - i
+			}
+			blank(i, i+end)
+			i += end
+		case out[i] == '/' && i+1 < len(out) && out[i+1] == '*':
+			end := bytes.Index(out[i+2:], []byte("*/"))
+			if end < 0 {
+				end = len(out) - i - 4
+			}
+			blank(i, i+end+4)
+			i += end + 3
+		}
+	}
+	// Comments are gone, so a comma followed only by whitespace and a closer is trailing
+	for i := 0; i < len(out); i++ {
+		switch out[i] {
+		case '"':
+			i = jsonStringEnd(out, i)
+		case ',':
+			next := bytes.TrimLeft(out[i+1:], " \t\r\n")
+			if len(next) > 0 && (next[0] == '}' || next[0] == ']') {
+				out[i] = ' '
+			}
+		}
+	}
+	return out
+}
+
+// jsonStringEnd returns the index of the quote closing the string that opens at start.
+func jsonStringEnd(data []byte, start int) int {
+	for i := start + 1; i < len(data); i++ {
+		switch data[i] {
+		case '\\':
+			i++
+		case '"':
+			return i
+		}
+	}
+	return len(data)
+}
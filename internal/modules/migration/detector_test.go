@@ -0,0 +1,138 @@
+package migration
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const wranglerJSONC = `{
+  // Worker entry
+  "$schema": "node_modules/wrangler/config-schema.json",
+  "name": "shop",
+  "main": "src/index.ts",
+  "compatibility_date": "2024-09-23",
+  "compatibility_flags": ["nodejs_compat"],
+  /* bindings */
+  "d1_databases": [
+    { "binding": "DB", "database_name": "shop", "database_id": "d1-1", "migrations_dir": "db/migrations" },
+  ],
+  "kv_namespaces": [{ "binding": "CACHE", "id": "kv-1", "preview_id": "kv-preview" }],
+  "r2_buckets": [{ "binding": "UPLOADS", "bucket_name": "uploads" }],
+  "queues": {
+    "producers": [{ "binding": "JOBS", "queue": "jobs" }],
+    "consumers": [{ "queue": "jobs" }],
+  },
+  "triggers": { "crons": ["0 * * * *"] },
+  "ai": { "binding": "AI" },
+  "vars": { "API_URL": "https://example.com/a//b", "RETRIES": 12345678, "FLAGS": { "beta": true } },
+  "env": {
+    "staging": {
+      "vars": { "API_URL": "https://staging.example.com" },
+      "d1_databases": [{ "binding": "DB", "database_name": "shop-staging", "database_id": "d1-2" }],
+      "routes": ["staging.example.com/*"],
+    },
+  },
+}
+`
+
+func writeWrangler(t *testing.T, name, content string) string {
+	t.Helper()
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+func TestDetect_JSONC(t *testing.T) {
+	w, err := Detect(writeWrangler(t, "wrangler.jsonc", wranglerJSONC))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if w.File != "wrangler.jsonc" || w.Name != "shop" || len(w.CompatibilityFlags) != 1 {
+		t.Errorf("config = %+v", w)
+	}
+	if len(w.KVNamespaces) != 1 || w.KVNamespaces[0].PreviewID != "kv-preview" {
+		t.Errorf("kv_namespaces = %+v", w.KVNamespaces)
+	}
+	if len(w.Queues.Producers) != 1 || w.Env["staging"].D1Databases[0].DatabaseID != "d1-2" {
+		t.Errorf("queues = %+v, env = %+v", w.Queues, w.Env)
+	}
+
+	cfg := ConvertWranglerToAerostack(w)
+	if cfg.Vars["API_URL"] != "https://example.com/a//b" || cfg.Vars["RETRIES"] != "12345678" {
+		t.Errorf("vars = %v", cfg.Vars)
+	}
+	if _, ok := cfg.Vars["FLAGS"]; ok {
+		t.Error("object vars should not be converted")
+	}
+	if len(cfg.R2Buckets) != 1 || cfg.R2Buckets[0].BucketName != "uploads" {
+		t.Errorf("r2_buckets = %+v", cfg.R2Buckets)
+	}
+	if !cfg.AI || len(cfg.Queues) != 1 || cfg.EnvOverrides["staging"].Vars["API_URL"] != "https://staging.example.com" {
+		t.Errorf("cfg = %+v", cfg)
+	}
+
+	var keys []string
+	for _, u := range w.Unmapped() {
+		keys = append(keys, u.Key)
+	}
+	want := []string{
+		"d1_databases[0].migrations_dir",
+		"queues.consumers",
+		"triggers",
+		"vars.FLAGS",
+		"env.staging.routes",
+	}
+	if strings.Join(keys, ",") != strings.Join(want, ",") {
+		t.Errorf("unmapped = %v, want %v", keys, want)
+	}
+}
+
+func TestDetect_TOML(t *testing.T) {
+	w, err := Detect(writeWrangler(t, "wrangler.toml", `
+name = "shop"
+main = "src/index.ts"
+compatibility_date = "2024-09-23"
+
+[ai]
+binding = "MODEL"
+
+[vars]
+RETRIES = 3
+
+[[durable_objects.bindings]]
+name = "ROOM"
+class_name = "Room"
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if w.File != "wrangler.toml" || ConvertWranglerToAerostack(w).Vars["RETRIES"] != "3" {
+		t.Errorf("config = %+v", w)
+	}
+	unmapped := w.Unmapped()
+	if len(unmapped) != 2 || unmapped[0].Key != "ai.binding" || unmapped[1].Key != "durable_objects" {
+		t.Errorf("unmapped = %+v", unmapped)
+	}
+}
+
+func TestDetect_NotFound(t *testing.T) {
+	if _, err := Detect(t.TempDir()); err == nil {
+		t.Error("expected an error without a wrangler config")
+	}
+}
+
+func TestStripJSONC(t *testing.T) {
+	in := `{"a": "x // not a comment", /* c */ "b": [1, 2,], // tail
+}`
+	out := string(stripJSONC([]byte(in)))
+	if len(out) != len(in) {
+		t.Errorf("length changed: %d -> %d", len(in), len(out))
+	}
+	if !strings.Contains(out, `"x // not a comment"`) || strings.Contains(out, "/*") || strings.Contains(out, "2,]") {
+		t.Errorf("stripJSONC = %s", out)
+	}
+}
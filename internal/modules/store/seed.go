@@ -3,6 +3,8 @@ package store
 import (
 	"context"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/aerostackdev/cli/internal/agent"
 )
@@ -15,22 +17,19 @@ func NewSeederAI(agent *agent.Agent) *SeederAI {
 	return &SeederAI{agent: agent}
 }
 
-func (s *SeederAI) Seed(ctx context.Context, smart bool) error {
-	if !smart {
-		fmt.Println("🌱 Running standard seed...")
-		// Call standard seed script (e.g., npm run seed)
-		return nil
-	}
-
+// Generate asks the agent to write a SQL fixture with realistic demo records into seedsDir,
+// where 'aerostack store seed' picks it up. migrationDirs are read to learn the data model.
+func (s *SeederAI) Generate(ctx context.Context, seedsDir string, migrationDirs ...string) error {
 	fmt.Println("🧠 Running Smart Seed...")
 
-	// 1. Analyze Schema to understand what to seed
-	prompt := `You are the Aerostack Smart Seeder.
-Analyze the project's SQL migrations in 'packages/api/migrations'.
+	file := fmt.Sprintf("%s/%s_smart.sql", seedsDir, time.Now().Format("20060102150405"))
+	prompt := fmt.Sprintf(`You are the Aerostack Smart Seeder.
+Analyze the project's SQL migrations in '%s'.
 Understand the data model (Products, Users, etc.).
-Generate a SQL script to insert 5-10 realistic demo records for the main tables.
-Use the 'write_file' tool to save this to 'packages/api/seed_custom.sql'.
-Then, instruct the user to run 'npx wrangler d1 execute ...' to apply it.`
+Generate a SQL script to insert 5-10 realistic demo records for the main tables,
+inserting referenced rows before the rows that reference them.
+Use the 'write_file' tool to save this to '%s'.
+Then, instruct the user to run 'aerostack store seed' to apply it.`, strings.Join(migrationDirs, "' and '"), file)
 
 	return s.agent.Resolve(ctx, prompt)
 }
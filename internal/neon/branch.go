@@ -0,0 +1,194 @@
+package neon
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// PreviewBranchPrefix names the branches created for git branches, e.g. preview/feature-x.
+const PreviewBranchPrefix = "preview/"
+
+// Branch represents a Neon branch
+type Branch struct {
+	ID           string `json:"id"`
+	ProjectID    string `json:"project_id"`
+	ParentID     string `json:"parent_id"`
+	Name         string `json:"name"`
+	CurrentState string `json:"current_state"`
+	Default      bool   `json:"default"`
+	Primary      bool   `json:"primary"` // older API responses use primary instead of default
+	CreatedAt    string `json:"created_at"`
+}
+
+// IsDefault reports whether b is the project's default (root) branch.
+func (b Branch) IsDefault() bool {
+	return b.Default || b.Primary
+}
+
+// CreateBranchResponse is the response from creating a branch.
+type CreateBranchResponse struct {
+	Branch         Branch `json:"branch"`
+	ConnectionURIs []struct {
+		ConnectionURI string `json:"connection_uri"`
+	} `json:"connection_uris"`
+}
+
+var previewNameRe = regexp.MustCompile(`[^A-Za-z0-9._/-]+`)
+
+// PreviewBranchName returns the Neon branch name for a git branch.
+func PreviewBranchName(gitBranch string) string {
+	return PreviewBranchPrefix + strings.Trim(previewNameRe.ReplaceAllString(gitBranch, "-"), "-")
+}
+
+// ListBranches lists a project's branches.
+func (c *Client) ListBranches(projectID string) ([]Branch, error) {
+	var result struct {
+		Branches []Branch `json:"branches"`
+	}
+	if err := c.do("GET", "/projects/"+url.PathEscape(projectID)+"/branches", nil, &result); err != nil {
+		return nil, fmt.Errorf("failed to list branches: %w", err)
+	}
+	return result.Branches, nil
+}
+
+// FindBranch returns the branch with the given name or ID, or nil when there is none.
+func FindBranch(branches []Branch, name string) *Branch {
+	for i, b := range branches {
+		if b.Name == name || b.ID == name {
+			return &branches[i]
+		}
+	}
+	return nil
+}
+
+// DefaultBranch returns the project's default branch, or nil when the list has none.
+func DefaultBranch(branches []Branch) *Branch {
+	for i, b := range branches {
+		if b.IsDefault() {
+			return &branches[i]
+		}
+	}
+	return nil
+}
+
+// CreateBranch creates a branch of parentID (the default branch when empty) with a
+// read-write compute endpoint, so it can be connected to right away.
+func (c *Client) CreateBranch(projectID, name, parentID string) (*CreateBranchResponse, error) {
+	req := map[string]any{
+		"branch":    map[string]string{"name": name},
+		"endpoints": []map[string]string{{"type": "read_write"}},
+	}
+	if parentID != "" {
+		req["branch"] = map[string]string{"name": name, "parent_id": parentID}
+	}
+	var result CreateBranchResponse
+	if err := c.do("POST", "/projects/"+url.PathEscape(projectID)+"/branches", req, &result); err != nil {
+		return nil, fmt.Errorf("failed to create branch %s: %w", name, err)
+	}
+	return &result, nil
+}
+
+// DeleteBranch deletes a branch and its compute endpoints.
+func (c *Client) DeleteBranch(projectID, branchID string) error {
+	if err := c.do("DELETE", "/projects/"+url.PathEscape(projectID)+"/branches/"+url.PathEscape(branchID), nil, nil); err != nil {
+		return fmt.Errorf("failed to delete branch: %w", err)
+	}
+	return nil
+}
+
+// ResetBranch replaces a branch's data with the current state of its parent.
+func (c *Client) ResetBranch(projectID string, branch Branch) error {
+	if branch.ParentID == "" {
+		return fmt.Errorf("branch %s has no parent to reset from", branch.Name)
+	}
+	req := map[string]string{"source_branch_id": branch.ParentID}
+	path := "/projects/" + url.PathEscape(projectID) + "/branches/" + url.PathEscape(branch.ID) + "/restore"
+	if err := c.do("POST", path, req, nil); err != nil {
+		return fmt.Errorf("failed to reset branch %s: %w", branch.Name, err)
+	}
+	return nil
+}
+
+// BranchConnectionString returns a connection URI for database on the branch, as role.
+// An empty database means the branch's first database; an empty role means the
+// database's owner.
+func (c *Client) BranchConnectionString(projectID, branchID, database, role string) (string, error) {
+	if database == "" || role == "" {
+		var dbs struct {
+			Databases []Database `json:"databases"`
+		}
+		path := "/projects/" + url.PathEscape(projectID) + "/branches/" + url.PathEscape(branchID) + "/databases"
+		if err := c.do("GET", path, nil, &dbs); err != nil {
+			return "", fmt.Errorf("failed to list databases: %w", err)
+		}
+		if len(dbs.Databases) == 0 {
+			return "", fmt.Errorf("branch has no databases")
+		}
+		db := &dbs.Databases[0]
+		if database != "" {
+			db = nil
+			for i := range dbs.Databases {
+				if dbs.Databases[i].Name == database {
+					db = &dbs.Databases[i]
+				}
+			}
+			if db == nil {
+				return "", fmt.Errorf("branch has no database %q", database)
+			}
+		}
+		database = db.Name
+		if role == "" {
+			role = db.OwnerName
+		}
+	}
+
+	q := url.Values{"branch_id": {branchID}, "database_name": {database}, "role_name": {role}}
+	var result struct {
+		URI string `json:"uri"`
+	}
+	if err := c.do("GET", "/projects/"+url.PathEscape(projectID)+"/connection_uri?"+q.Encode(), nil, &result); err != nil {
+		return "", fmt.Errorf("failed to get connection string: %w", err)
+	}
+	return result.URI, nil
+}
+
+// ConnectionTarget returns the database and role a Postgres connection string (URI or
+// key=value form) connects with. Either is empty when the string doesn't name it.
+func ConnectionTarget(connStr string) (database, role string) {
+	if u, err := url.Parse(connStr); err == nil && (u.Scheme == "postgres" || u.Scheme == "postgresql") {
+		return strings.TrimPrefix(u.Path, "/"), u.User.Username()
+	}
+	for _, field := range strings.Fields(connStr) {
+		key, value, ok := strings.Cut(field, "=")
+		if !ok {
+			continue
+		}
+		value = strings.Trim(value, "'")
+		switch key {
+		case "dbname":
+			database = value
+		case "user":
+			role = value
+		}
+	}
+	return database, role
+}
+
+// EnsureBranch returns the named branch, creating it from the default branch when it
+// does not exist yet. created reports whether it was just created.
+func (c *Client) EnsureBranch(projectID, name string) (branch *Branch, created bool, err error) {
+	branches, err := c.ListBranches(projectID)
+	if err != nil {
+		return nil, false, err
+	}
+	if b := FindBranch(branches, name); b != nil {
+		return b, false, nil
+	}
+	result, err := c.CreateBranch(projectID, name, "")
+	if err != nil {
+		return nil, false, err
+	}
+	return &result.Branch, true, nil
+}
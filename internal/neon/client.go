@@ -13,15 +13,17 @@ const neonAPIBase = "https://console.neon.tech/api/v2"
 
 // Client is a Neon API client
 type Client struct {
-	apiKey string
-	client *http.Client
+	apiKey  string
+	baseURL string
+	client  *http.Client
 }
 
 // NewClient creates a new Neon API client
 func NewClient(apiKey string) *Client {
 	return &Client{
-		apiKey: apiKey,
-		client: &http.Client{},
+		apiKey:  apiKey,
+		baseURL: neonAPIBase,
+		client:  &http.Client{},
 	}
 }
 
@@ -106,37 +108,55 @@ func (c *Client) CreateProject(name, region string) (*CreateProjectResponse, err
 		req.Project.RegionID = region
 	}
 
-	body, err := json.Marshal(req)
-	if err != nil {
-		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	var result CreateProjectResponse
+	if err := c.do("POST", "/projects", req, &result); err != nil {
+		return nil, fmt.Errorf("failed to create project: %w", err)
 	}
+	return &result, nil
+}
 
-	httpReq, err := http.NewRequest("POST", neonAPIBase+"/projects", bytes.NewBuffer(body))
-	if err != nil {
-		return nil, err
+// do sends a JSON request to the Neon API and decodes the response into out (if non-nil).
+func (c *Client) do(method, path string, in, out any) error {
+	var body io.Reader
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return fmt.Errorf("failed to marshal request: %w", err)
+		}
+		body = bytes.NewReader(data)
 	}
 
+	httpReq, err := http.NewRequest(method, c.baseURL+path, body)
+	if err != nil {
+		return err
+	}
 	httpReq.Header.Set("Authorization", "Bearer "+c.apiKey)
 	httpReq.Header.Set("Content-Type", "application/json")
 	httpReq.Header.Set("Accept", "application/json")
 
 	resp, err := c.client.Do(httpReq)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create project: %w", err)
+		return err
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusCreated {
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
 		bodyBytes, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("API error (%d): %s", resp.StatusCode, string(bodyBytes))
+		var apiErr struct {
+			Message string `json:"message"`
+		}
+		if json.Unmarshal(bodyBytes, &apiErr) == nil && apiErr.Message != "" {
+			return fmt.Errorf("API error (%d): %s", resp.StatusCode, apiErr.Message)
+		}
+		return fmt.Errorf("API error (%d): %s", resp.StatusCode, string(bodyBytes))
 	}
-
-	var result CreateProjectResponse
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return nil, fmt.Errorf("failed to decode response: %w", err)
+	if out == nil {
+		return nil
 	}
-
-	return &result, nil
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
 }
 
 // BuildConnectionString builds a Postgres connection string
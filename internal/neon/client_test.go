@@ -0,0 +1,205 @@
+package neon
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// fakeNeon is an in-memory stand-in for the parts of the Neon API the client uses.
+type fakeNeon struct {
+	branches []Branch
+	restored map[string]string // branch ID -> source branch ID
+	nextID   int
+}
+
+func newFakeNeon(t *testing.T) (*Client, *fakeNeon) {
+	t.Helper()
+	f := &fakeNeon{
+		branches: []Branch{{ID: "br-main", Name: "main", Default: true, CurrentState: "ready"}},
+		restored: map[string]string{},
+	}
+	srv := httptest.NewServer(f)
+	t.Cleanup(srv.Close)
+	c := NewClient("key")
+	c.baseURL = srv.URL
+	return c, f
+}
+
+func (f *fakeNeon) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("Authorization") != "Bearer key" {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(map[string]string{"message": "authentication required"})
+		return
+	}
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/projects/"), "/")
+	if parts[0] != "proj" {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]string{"message": "project not found"})
+		return
+	}
+	reply := func(status int, v any) {
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(v)
+	}
+
+	switch {
+	case r.Method == "GET" && len(parts) == 2 && parts[1] == "branches":
+		reply(200, map[string]any{"branches": f.branches})
+	case r.Method == "POST" && len(parts) == 2 && parts[1] == "branches":
+		var req struct {
+			Branch struct {
+				Name     string `json:"name"`
+				ParentID string `json:"parent_id"`
+			} `json:"branch"`
+			Endpoints []map[string]string `json:"endpoints"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		if len(req.Endpoints) != 1 || req.Endpoints[0]["type"] != "read_write" {
+			reply(400, map[string]string{"message": "expected a read_write endpoint"})
+			return
+		}
+		parent := req.Branch.ParentID
+		if parent == "" {
+			parent = "br-main"
+		}
+		f.nextID++
+		b := Branch{ID: fmt.Sprintf("br-%d", f.nextID), Name: req.Branch.Name, ParentID: parent, CurrentState: "init"}
+		f.branches = append(f.branches, b)
+		reply(201, map[string]any{"branch": b, "connection_uris": []map[string]string{{"connection_uri": "postgresql://app:pw@" + b.ID + ".neon.tech/app"}}})
+	case r.Method == "DELETE" && len(parts) == 3:
+		for i, b := range f.branches {
+			if b.ID == parts[2] {
+				f.branches = append(f.branches[:i], f.branches[i+1:]...)
+				reply(200, map[string]any{"branch": b})
+				return
+			}
+		}
+		reply(404, map[string]string{"message": "branch not found"})
+	case r.Method == "POST" && len(parts) == 4 && parts[3] == "restore":
+		var req struct {
+			SourceBranchID string `json:"source_branch_id"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		f.restored[parts[2]] = req.SourceBranchID
+		reply(200, map[string]any{})
+	case r.Method == "GET" && len(parts) == 4 && parts[3] == "databases":
+		reply(200, map[string]any{"databases": []Database{{Name: "app", OwnerName: "app_owner"}, {Name: "analytics", OwnerName: "analytics_owner"}}})
+	case r.Method == "GET" && len(parts) == 2 && parts[1] == "connection_uri":
+		q := r.URL.Query()
+		reply(200, map[string]string{"uri": fmt.Sprintf("postgresql://%s:pw@%s.neon.tech/%s", q.Get("role_name"), q.Get("branch_id"), q.Get("database_name"))})
+	default:
+		reply(404, map[string]string{"message": "not found"})
+	}
+}
+
+func TestClient_BranchLifecycle(t *testing.T) {
+	c, fake := newFakeNeon(t)
+
+	b, created, err := c.EnsureBranch("proj", "preview/feature-x")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !created || b.ParentID != "br-main" {
+		t.Errorf("EnsureBranch = %+v, created %v", b, created)
+	}
+	again, created, err := c.EnsureBranch("proj", "preview/feature-x")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if created || again.ID != b.ID {
+		t.Errorf("second EnsureBranch = %+v, created %v; want the existing branch", again, created)
+	}
+
+	for _, tc := range []struct{ database, role, want string }{
+		{"", "", "postgresql://app_owner:pw@br-1.neon.tech/app"},
+		{"analytics", "", "postgresql://analytics_owner:pw@br-1.neon.tech/analytics"},
+		{"analytics", "reader", "postgresql://reader:pw@br-1.neon.tech/analytics"},
+	} {
+		connStr, err := c.BranchConnectionString("proj", b.ID, tc.database, tc.role)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if connStr != tc.want {
+			t.Errorf("BranchConnectionString(%q, %q) = %s, want %s", tc.database, tc.role, connStr, tc.want)
+		}
+	}
+	if _, err := c.BranchConnectionString("proj", b.ID, "missing", ""); err == nil {
+		t.Error("expected an error for a database the branch doesn't have")
+	}
+
+	branches, err := c.ListBranches("proj")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if DefaultBranch(branches).Name != "main" || FindBranch(branches, "preview/feature-x") == nil {
+		t.Errorf("branches = %+v", branches)
+	}
+
+	if err := c.ResetBranch("proj", *b); err != nil {
+		t.Fatal(err)
+	}
+	if fake.restored[b.ID] != "br-main" {
+		t.Errorf("reset restored from %q, want the parent br-main", fake.restored[b.ID])
+	}
+	if err := c.ResetBranch("proj", *DefaultBranch(branches)); err == nil {
+		t.Error("resetting a branch without a parent should fail")
+	}
+
+	if err := c.DeleteBranch("proj", b.ID); err != nil {
+		t.Fatal(err)
+	}
+	if len(fake.branches) != 1 {
+		t.Errorf("branches after delete = %+v", fake.branches)
+	}
+	if err := c.DeleteBranch("proj", b.ID); err == nil || !strings.Contains(err.Error(), "branch not found") {
+		t.Errorf("err = %v, want the API's message", err)
+	}
+}
+
+func TestClient_CreateBranchWithParent(t *testing.T) {
+	c, _ := newFakeNeon(t)
+	staging, err := c.CreateBranch("proj", "staging", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	child, err := c.CreateBranch("proj", "staging-copy", staging.Branch.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if child.Branch.ParentID != staging.Branch.ID || len(child.ConnectionURIs) != 1 {
+		t.Errorf("child = %+v", child)
+	}
+	if _, err := c.ListBranches("other"); err == nil {
+		t.Error("expected an error for an unknown project")
+	}
+}
+
+func TestPreviewBranchName(t *testing.T) {
+	for in, want := range map[string]string{
+		"feature/login": "preview/feature/login",
+		"fix #12":       "preview/fix-12",
+		"main":          "preview/main",
+	} {
+		if got := PreviewBranchName(in); got != want {
+			t.Errorf("PreviewBranchName(%q) = %s, want %s", in, got, want)
+		}
+	}
+}
+
+func TestConnectionTarget(t *testing.T) {
+	for in, want := range map[string][2]string{
+		"postgresql://reader:pw@ep-x.neon.tech/analytics?sslmode=require": {"analytics", "reader"},
+		"postgres://ep-x.neon.tech/app":                                   {"app", ""},
+		"host=ep-x.neon.tech user=app_owner dbname='app' sslmode=require": {"app", "app_owner"},
+		"": {"", ""},
+	} {
+		database, role := ConnectionTarget(in)
+		if database != want[0] || role != want[1] {
+			t.Errorf("ConnectionTarget(%q) = %q, %q, want %q, %q", in, database, role, want[0], want[1])
+		}
+	}
+}
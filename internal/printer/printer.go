@@ -4,6 +4,7 @@ import (
 	"fmt"
 
 	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/lipgloss/table"
 )
 
 var (
@@ -89,3 +90,34 @@ func KeyVal(key, val string) string {
 func Link(url string) string {
 	return brandStyle.Underline(true).Render(url)
 }
+
+// ServiceColors is the palette cycled through for per-worker log prefixes.
+var ServiceColors = []lipgloss.Color{BrandCyan, Lavender, Emerald, Amber, OffWhite, Slate}
+
+// ServiceTag formats a worker name as a colored, left-aligned log prefix of the given width.
+func ServiceTag(name string, index, width int) string {
+	color := ServiceColors[index%len(ServiceColors)]
+	tag := lipgloss.NewStyle().Foreground(color).Bold(true).Render(fmt.Sprintf("%-*s", width, name))
+	return tag + mutedStyle.Render(" │")
+}
+
+// Muted renders text in the muted slate color.
+func Muted(s string) string {
+	return mutedStyle.Render(s)
+}
+
+// Table renders rows under bold headers with muted rounded borders.
+func Table(headers []string, rows [][]string) string {
+	return table.New().
+		Border(lipgloss.RoundedBorder()).
+		BorderStyle(mutedStyle).
+		Headers(headers...).
+		Rows(rows...).
+		StyleFunc(func(row, col int) lipgloss.Style {
+			if row == table.HeaderRow {
+				return boldStyle.Foreground(BrandCyan).Padding(0, 1)
+			}
+			return lipgloss.NewStyle().Padding(0, 1)
+		}).
+		String()
+}
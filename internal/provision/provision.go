@@ -41,11 +41,10 @@ func isPlaceholderID(id string) bool {
 // Cloudflare account when IDs are placeholders. Updates aerostack.toml with real IDs.
 // Extensible: add more resource types (Vectorize, AI, etc.) as SDK supports them.
 func ProvisionCloudflareResources(cfg *devserver.AerostackConfig, env string, projectRoot string) error {
-	// Get D1 config for this env
-	dbs := cfg.EnvOverrides[env]
-	if len(dbs) == 0 {
-		dbs = cfg.D1Databases
-	}
+	// Resolve bindings for this env. ForEnv shares the backing slices with cfg,
+	// so IDs filled in below are visible to the caller.
+	envCfg := cfg.ForEnv(env)
+	dbs := envCfg.D1Databases
 
 	modified := false
 	if len(dbs) > 0 {
@@ -83,8 +82,8 @@ func ProvisionCloudflareResources(cfg *devserver.AerostackConfig, env string, pr
 	}
 
 	// 2. KV
-	for i := range cfg.KVNamespaces {
-		ns := &cfg.KVNamespaces[i]
+	for i := range envCfg.KVNamespaces {
+		ns := &envCfg.KVNamespaces[i]
 		if !isPlaceholderID(ns.ID) {
 			continue
 		}
@@ -99,9 +98,35 @@ func ProvisionCloudflareResources(cfg *devserver.AerostackConfig, env string, pr
 		_ = updateConfigValue(configPath, "id", "local-kv", id)
 	}
 
-	// 3. Queues
-	for i := range cfg.Queues {
-		q := &cfg.Queues[i]
+	// 3. R2
+	for i := range envCfg.R2Buckets {
+		b := &envCfg.R2Buckets[i]
+		if !isPlaceholderBucket(b.BucketName) {
+			continue
+		}
+		// A bucket declared under [env.<env>] gets its own env-suffixed name; one
+		// inherited from the top level is shared by every env and named without it.
+		section, nameEnv := "r2_buckets", ""
+		if env != "" && cfg.EnvOverrides[env].R2Buckets != nil {
+			section, nameEnv = "env."+env+".r2_buckets", env
+		}
+		name := r2BucketName(cfg.Name, b.Binding, nameEnv)
+		fmt.Printf("   R2 (%s): creating bucket %q in your account...\n", b.Binding, name)
+		if err := createR2Bucket(name, projectRoot); err != nil {
+			// Might already exist, we'll try to continue
+			fmt.Printf("   ⚠ R2 bucket creation note: %v\n", err)
+		}
+		b.BucketName = name
+		fmt.Printf("   ✓ Using R2 bucket %q\n", name)
+		configPath := filepath.Join(projectRoot, "aerostack.toml")
+		if err := updateConfigBucketName(configPath, section, b.Binding, name); err != nil {
+			fmt.Printf("   ⚠ Could not update aerostack.toml: %v (bucket used for this run)\n", err)
+		}
+	}
+
+	// 4. Queues
+	for i := range envCfg.Queues {
+		q := &envCfg.Queues[i]
 		// Queues don't have IDs in aerostack.toml, just names.
 		// If name is "local-queue", we should create a real one.
 		if q.Name != "local-queue" {
@@ -139,6 +164,79 @@ func createKV(name string, projectRoot string) (string, error) {
 	return matches[0], nil
 }
 
+// isPlaceholderBucket reports bucket names that stand in for a bucket not created yet:
+// the local-<binding> stub used when bucket_name is omitted, or a YOUR_... template.
+func isPlaceholderBucket(name string) bool {
+	name = strings.TrimSpace(name)
+	return name == "" || strings.HasPrefix(name, "local-") || strings.HasPrefix(name, "YOUR_") || strings.HasPrefix(name, "your_")
+}
+
+var bucketNameInvalidRe = regexp.MustCompile(`[^a-z0-9-]+`)
+
+// r2BucketName names a provisioned bucket <project>-<binding>[-<env>], within R2's rules:
+// lowercase letters, digits and hyphens, at most 63 characters.
+func r2BucketName(project, binding, env string) string {
+	name := project + "-" + binding
+	if env != "" {
+		name += "-" + env
+	}
+	name = strings.Trim(bucketNameInvalidRe.ReplaceAllString(strings.ToLower(name), "-"), "-")
+	if len(name) > 63 {
+		name = strings.TrimRight(name[:63], "-")
+	}
+	return name
+}
+
+func createR2Bucket(name string, projectRoot string) error {
+	cmd := exec.Command("npx", "-y", "wrangler@latest", "r2", "bucket", "create", name)
+	cmd.Dir = projectRoot
+	return cmd.Run()
+}
+
+// updateConfigBucketName sets bucket_name in the [[<section>]] block for binding
+// (e.g. section "r2_buckets" or "env.staging.r2_buckets"), replacing the placeholder
+// or adding the key when it was omitted. Blocks in other tables are left alone.
+func updateConfigBucketName(path, section, binding, newName string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	lines := strings.Split(string(data), "\n")
+	header := "[[" + section + "]]"
+	bindingRe := regexp.MustCompile(`^\s*binding\s*=\s*"` + regexp.QuoteMeta(binding) + `"`)
+	bucketRe := regexp.MustCompile(`^\s*bucket_name\s*=`)
+	line := `bucket_name = "` + newName + `"`
+
+	for start := 0; start < len(lines); start++ {
+		if strings.ReplaceAll(strings.TrimSpace(lines[start]), " ", "") != header {
+			continue
+		}
+		end := start + 1
+		for end < len(lines) && !strings.HasPrefix(strings.TrimSpace(lines[end]), "[") {
+			end++
+		}
+		bindingAt, bucketAt := -1, -1
+		for i := start + 1; i < end; i++ {
+			if bindingRe.MatchString(lines[i]) {
+				bindingAt = i
+			} else if bucketRe.MatchString(lines[i]) {
+				bucketAt = i
+			}
+		}
+		switch {
+		case bindingAt < 0:
+			start = end - 1
+			continue
+		case bucketAt >= 0:
+			lines[bucketAt] = line
+		default:
+			lines = append(lines[:bindingAt+1], append([]string{line}, lines[bindingAt+1:]...)...)
+		}
+		return os.WriteFile(path, []byte(strings.Join(lines, "\n")), 0644)
+	}
+	return fmt.Errorf("no %s block for %s", header, binding)
+}
+
 func createQueue(name string, projectRoot string) error {
 	cmd := exec.Command("npx", "-y", "wrangler@latest", "queues", "create", name)
 	cmd.Dir = projectRoot
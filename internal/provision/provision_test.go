@@ -0,0 +1,97 @@
+package provision
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/aerostackdev/cli/internal/devserver"
+)
+
+// stubWrangler puts an npx on PATH that succeeds without touching Cloudflare.
+func stubWrangler(t *testing.T) {
+	t.Helper()
+	bin := t.TempDir()
+	if err := os.WriteFile(filepath.Join(bin, "npx"), []byte("#!/bin/sh\nexit 0\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", bin)
+}
+
+func TestProvisionR2_EnvBucketStaysInEnvBlock(t *testing.T) {
+	stubWrangler(t)
+	root := t.TempDir()
+	path := filepath.Join(root, "aerostack.toml")
+	toml := `name = "shop"
+
+[[r2_buckets]]
+binding = "UPLOADS"
+bucket_name = "YOUR_BUCKET"
+
+[env.staging]
+
+[[env.staging.r2_buckets]]
+binding = "UPLOADS"
+bucket_name = "YOUR_BUCKET"
+`
+	if err := os.WriteFile(path, []byte(toml), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := devserver.ParseAerostackToml(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ProvisionCloudflareResources(cfg, "staging", root); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := os.ReadFile(path)
+	top, env, _ := strings.Cut(string(data), "[env.staging]")
+	if !strings.Contains(env, `bucket_name = "shop-uploads-staging"`) {
+		t.Errorf("staging block not updated:\n%s", data)
+	}
+	if !strings.Contains(top, `bucket_name = "YOUR_BUCKET"`) {
+		t.Errorf("top-level block changed by staging provision:\n%s", data)
+	}
+
+	cfg, err = devserver.ParseAerostackToml(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ProvisionCloudflareResources(cfg, "production", root); err != nil {
+		t.Fatal(err)
+	}
+	data, _ = os.ReadFile(path)
+	top, env, _ = strings.Cut(string(data), "[env.staging]")
+	if !strings.Contains(top, `bucket_name = "shop-uploads"`) {
+		t.Errorf("top-level block not updated for production:\n%s", data)
+	}
+	if !strings.Contains(env, `bucket_name = "shop-uploads-staging"`) {
+		t.Errorf("staging block changed by production provision:\n%s", data)
+	}
+}
+
+func TestUpdateConfigBucketName_AddsMissingKey(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "aerostack.toml")
+	toml := `[[r2_buckets]]
+binding = "AVATARS"
+
+[[r2_buckets]]
+binding = "UPLOADS"
+`
+	if err := os.WriteFile(path, []byte(toml), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := updateConfigBucketName(path, "r2_buckets", "UPLOADS", "shop-uploads"); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := os.ReadFile(path)
+	want := "[[r2_buckets]]\nbinding = \"AVATARS\"\n\n[[r2_buckets]]\nbinding = \"UPLOADS\"\nbucket_name = \"shop-uploads\"\n"
+	if string(data) != want {
+		t.Errorf("got:\n%s\nwant:\n%s", data, want)
+	}
+	if err := updateConfigBucketName(path, "env.staging.r2_buckets", "UPLOADS", "x"); err == nil {
+		t.Error("expected error for missing env block")
+	}
+}